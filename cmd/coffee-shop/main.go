@@ -1,12 +1,17 @@
 package main
 
 import (
+	"bufio"
 	"context"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"os"
+	"strings"
 
 	"coffee-shop/internal/app"
+	"coffee-shop/internal/model"
 	"coffee-shop/internal/transport/http/server"
 	"coffee-shop/internal/utils"
 )
@@ -25,17 +30,28 @@ var (
 	configPath string
 	port       string
 	dir        string
+	storage    string
 )
 
 func init() {
 	flag.StringVar(&port, "port", "8080", "Port number")
 	flag.StringVar(&dir, "dir", "./data", "Path to the directory")
 	flag.StringVar(&configPath, "cfg", "configs/server.yaml", "Path to the config file")
+	flag.StringVar(&storage, "storage", "", "Storage backend: postgres, json or memory")
 
 	flag.Usage = utils.CustomUsage
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "import-legacy" {
+		importLegacy(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "create-staff" {
+		createStaff(os.Args[2:])
+		return
+	}
+
 	flag.Parse()
 
 	err := utils.ValidatePort(port)
@@ -53,6 +69,9 @@ func main() {
 	ctx := context.Background()
 
 	cfg := server.NewConfig(configPath, ":"+port, dir)
+	if storage != "" {
+		cfg.Storage = storage
+	}
 
 	application, err := app.New(ctx, cfg)
 	if err != nil {
@@ -66,3 +85,51 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// importLegacy runs the import-legacy command, which migrates the data directory
+// of the JSON version of the shop into the database.
+func importLegacy(args []string) {
+	flags := flag.NewFlagSet("import-legacy", flag.ExitOnError)
+	legacyDir := flags.String("dir", "./data", "Path to the legacy data directory")
+	flags.Usage = utils.CustomUsage
+	flags.Parse(args)
+
+	err := app.ImportLegacy(context.Background(), *legacyDir, os.Stdout)
+	if err != nil {
+		fmt.Println("failed to import the legacy data:", err)
+		os.Exit(1)
+	}
+}
+
+// createStaff runs the create-staff command, which creates a staff member, e.g. the
+// first admin. The password is read from the first line of the standard input.
+func createStaff(args []string) {
+	flags := flag.NewFlagSet("create-staff", flag.ExitOnError)
+	username := flags.String("username", "", "Username of the staff member")
+	role := flags.String("role", model.RoleBarista, "Role of the staff member: barista, manager or admin")
+	apiKey := flags.Bool("api-key", false, "Issue an API key")
+	staffDir := flags.String("dir", "./data", "Path to the data directory")
+	staffStorage := flags.String("storage", "", "Storage backend: postgres or json")
+	flags.Usage = utils.CustomUsage
+	flags.Parse(args)
+
+	fmt.Fprint(os.Stderr, "Password (empty for none): ")
+	password, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		fmt.Println("failed to read the password:", err)
+		os.Exit(1)
+	}
+	fmt.Fprintln(os.Stderr)
+
+	cfg := server.NewConfig(configPath, "", *staffDir)
+	if *staffStorage != "" {
+		cfg.Storage = *staffStorage
+	}
+
+	staff := model.Staff{Username: *username, Role: *role}
+	err = app.CreateStaff(context.Background(), cfg, staff, strings.TrimRight(password, "\r\n"), *apiKey, os.Stdout)
+	if err != nil {
+		fmt.Println("failed to create the staff member:", err)
+		os.Exit(1)
+	}
+}
@@ -8,12 +8,16 @@ $$;
 
 CREATE TYPE order_status AS ENUM ('open', 'closed');
 CREATE TYPE unit_types AS ENUM ('g', 'kg', 'ml', 'l', 'pcs', 'shots');
+CREATE TYPE payment_tender AS ENUM ('cash', 'card', 'voucher');
+CREATE TYPE staff_role AS ENUM ('barista', 'manager', 'admin');
 
 CREATE TABLE inventory (
     IngredientID SERIAL PRIMARY KEY,
     Name VARCHAR(50) NOT NULL,
     Quantity INT NOT NULL CHECK(Quantity >= 0),
-    Unit unit_types NOT NULL
+    Unit unit_types NOT NULL,
+    Version INT NOT NULL DEFAULT 1,
+    ArchivedAt TIMESTAMPTZ
 );
 
 CREATE TABLE inventory_transactions (
@@ -21,7 +25,7 @@ CREATE TABLE inventory_transactions (
     IngredientID INT NOT NULL,
     Quantity_change INT NOT NULL,
     Reason TEXT NOT NULL,
-    CreatedAt TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CreatedAt TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
     FOREIGN KEY (IngredientID) REFERENCES inventory(IngredientID)
 );
 
@@ -29,15 +33,18 @@ CREATE TABLE menu_items (
     ID SERIAL PRIMARY KEY,
     Name VARCHAR(50) NOT NULL,
     Description TEXT NOT NULL,
-    Price NUMERIC(10, 2) NOT NULL CHECK(Price >= 0)
+    Category VARCHAR(50) NOT NULL DEFAULT 'general',
+    Price NUMERIC(10, 2) NOT NULL CHECK(Price >= 0),
+    Version INT NOT NULL DEFAULT 1,
+    ArchivedAt TIMESTAMPTZ
 );
 
 CREATE TABLE price_history (
     HistoryID SERIAL PRIMARY KEY,
     Menu_ItemID INT NOT NULL,
-    old_price NUMERIC(10, 2) NOT NULL CHECK(old_price > 0),
+    old_price NUMERIC(10, 2) NOT NULL CHECK(old_price >= 0),
     new_price NUMERIC(10, 2) NOT NULL CHECK(new_price > 0),
-    ChangedAt TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    ChangedAt TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
     FOREIGN KEY (Menu_ItemID) REFERENCES menu_items(ID)
 );
 
@@ -54,25 +61,174 @@ CREATE TABLE orders (
     CustomerName VARCHAR(50) NOT NULL,
     Status order_status DEFAULT 'open',
     Notes JSONB,
-    CreatedAt TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+    DiscountTotal NUMERIC(10, 2) NOT NULL DEFAULT 0 CHECK(DiscountTotal >= 0),
+    Subtotal NUMERIC(10, 2),
+    TaxTotal NUMERIC(10, 2),
+    Total NUMERIC(10, 2),
+    CreatedAt TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    ClosedAt TIMESTAMPTZ,
+    Reserved BOOLEAN NOT NULL DEFAULT FALSE,
+    Version INT NOT NULL DEFAULT 1
 );
 
 CREATE TABLE order_items (
     OrderID INT NOT NULL,
     ProductID INT NOT NULL,
     Quantity INT NOT NULL CHECK(Quantity >= 0),
+    Price NUMERIC(10, 2) NOT NULL DEFAULT 0 CHECK(Price >= 0),
+    Modifiers TEXT[] NOT NULL DEFAULT '{}',
     FOREIGN KEY (OrderID) REFERENCES orders(ID),
     FOREIGN KEY (ProductID) REFERENCES menu_items(ID)
 );
 
+-- Ingredients consumed per unit of the products of an order, copied from the recipes
+-- when the stock of the order is written off
+CREATE TABLE order_consumption (
+    OrderID INT NOT NULL,
+    ProductID INT NOT NULL,
+    IngredientID INT NOT NULL,
+    Quantity INT NOT NULL CHECK(Quantity > 0),
+    PRIMARY KEY (OrderID, ProductID, IngredientID),
+    FOREIGN KEY (OrderID) REFERENCES orders(ID) ON DELETE CASCADE,
+    FOREIGN KEY (IngredientID) REFERENCES inventory(IngredientID)
+);
+
+-- Tax rate per menu category. The 'default' row applies to categories without their own rate.
+CREATE TABLE tax_rates (
+    Category VARCHAR(50) PRIMARY KEY,
+    Rate NUMERIC(5, 4) NOT NULL CHECK(Rate >= 0 AND Rate < 1),
+    Inclusive BOOLEAN NOT NULL DEFAULT FALSE
+);
+
+-- Tax lines stored when an order is closed
+CREATE TABLE order_taxes (
+    OrderID INT NOT NULL,
+    Category VARCHAR(50) NOT NULL,
+    Rate NUMERIC(5, 4) NOT NULL,
+    Inclusive BOOLEAN NOT NULL,
+    TaxableAmount NUMERIC(10, 2) NOT NULL,
+    TaxAmount NUMERIC(10, 2) NOT NULL,
+    PRIMARY KEY (OrderID, Category),
+    FOREIGN KEY (OrderID) REFERENCES orders(ID) ON DELETE CASCADE
+);
+
+-- Payments of orders. Refunds are negative rows pointing to the refunded payment.
+CREATE TABLE payments (
+    ID SERIAL PRIMARY KEY,
+    OrderID INT NOT NULL,
+    Tender payment_tender NOT NULL,
+    Amount NUMERIC(10, 2) NOT NULL CHECK(Amount <> 0),
+    Tip NUMERIC(10, 2) NOT NULL DEFAULT 0 CHECK(Tip >= 0),
+    Tendered NUMERIC(10, 2) NOT NULL DEFAULT 0 CHECK(Tendered >= 0),
+    Change NUMERIC(10, 2) NOT NULL DEFAULT 0 CHECK(Change >= 0),
+    RefundOf INT,
+    Reference TEXT NOT NULL DEFAULT '',
+    Reason TEXT NOT NULL DEFAULT '',
+    CreatedAt TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (OrderID) REFERENCES orders(ID),
+    FOREIGN KEY (RefundOf) REFERENCES payments(ID),
+    CHECK((Amount < 0) = (RefundOf IS NOT NULL))
+);
+
+-- Items returned by a refund
+CREATE TABLE refund_items (
+    PaymentID INT NOT NULL,
+    ProductID INT NOT NULL,
+    Quantity INT NOT NULL CHECK(Quantity > 0),
+    FOREIGN KEY (PaymentID) REFERENCES payments(ID),
+    FOREIGN KEY (ProductID) REFERENCES menu_items(ID)
+);
+
 CREATE TABLE order_status_history (
     ID SERIAL PRIMARY KEY,
     OrderID INT NOT NULL,
-    OpenedAt TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-    ClosedAt TIMESTAMP,
+    OpenedAt TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    ClosedAt TIMESTAMPTZ,
     FOREIGN KEY (OrderID) REFERENCES orders(ID)
 );
 
+-- End-of-day (Z) reports. The figures are kept in Summary as they were at closing time.
+CREATE TABLE daily_closings (
+    ID SERIAL PRIMARY KEY,
+    BusinessDate DATE NOT NULL UNIQUE,
+    PeriodFrom TIMESTAMPTZ NOT NULL,
+    PeriodTo TIMESTAMPTZ NOT NULL,
+    OpeningFloat NUMERIC(10, 2) NOT NULL DEFAULT 0 CHECK(OpeningFloat >= 0),
+    ExpectedCash NUMERIC(10, 2) NOT NULL,
+    CountedCash NUMERIC(10, 2) NOT NULL CHECK(CountedCash >= 0),
+    Notes TEXT NOT NULL DEFAULT '',
+    Summary JSONB NOT NULL,
+    CreatedAt TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    CHECK(PeriodFrom < PeriodTo)
+);
+
+-- Closings are immutable once stored
+CREATE FUNCTION reject_closing_change() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'daily closings cannot be changed';
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER daily_closings_immutable
+BEFORE UPDATE OR DELETE ON daily_closings
+FOR EACH ROW EXECUTE FUNCTION reject_closing_change();
+
+-- Staff who sign in to the shop. Passwords and API keys are stored as hashes.
+CREATE TABLE staff (
+    ID SERIAL PRIMARY KEY,
+    Username VARCHAR(50) NOT NULL UNIQUE,
+    Role staff_role NOT NULL,
+    PasswordHash TEXT,
+    APIKeyHash TEXT UNIQUE,
+    CreatedAt TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    CHECK(PasswordHash IS NOT NULL OR APIKeyHash IS NOT NULL)
+);
+
+-- Changes of inventory, menu items and orders made through the API. Before and After
+-- hold the record as it was and as it became. ActorID is kept without a foreign key,
+-- so that entries outlive the staff who made them.
+CREATE TABLE audit_log (
+    ID SERIAL PRIMARY KEY,
+    ActorID INT,
+    Actor VARCHAR(50) NOT NULL DEFAULT '',
+    Action VARCHAR(20) NOT NULL,
+    EntityType VARCHAR(20) NOT NULL,
+    EntityID INT NOT NULL,
+    Before JSONB,
+    After JSONB,
+    RequestID TEXT,
+    IP TEXT,
+    CreatedAt TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+-- The audit log is append-only
+CREATE FUNCTION reject_audit_change() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit log entries cannot be changed';
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_log_append_only
+BEFORE UPDATE OR DELETE ON audit_log
+FOR EACH ROW EXECUTE FUNCTION reject_audit_change();
+
+CREATE TRIGGER audit_log_no_truncate
+BEFORE TRUNCATE ON audit_log
+FOR EACH STATEMENT EXECUTE FUNCTION reject_audit_change();
+
+-- Requests made with an Idempotency-Key header and their responses, kept until
+-- ExpiresAt. A row without a response belongs to a request that is still handled.
+CREATE TABLE idempotency_keys (
+    IdempotencyKey TEXT PRIMARY KEY,
+    RequestHash TEXT NOT NULL,
+    Completed BOOLEAN NOT NULL DEFAULT FALSE,
+    Status INT,
+    Header JSONB,
+    Body BYTEA,
+    CreatedAt TIMESTAMPTZ NOT NULL,
+    ExpiresAt TIMESTAMPTZ NOT NULL
+);
+
 -- menu_items
 CREATE INDEX idx_menu_items_name ON menu_items (Name);
 
@@ -83,6 +239,14 @@ CREATE INDEX idx_inventory_name ON inventory (Name);
 CREATE INDEX idx_orders_customer_name ON orders (CustomerName);
 CREATE INDEX idx_orders_status ON orders (Status);
 CREATE INDEX idx_orders_created_at ON orders (CreatedAt);
+CREATE INDEX idx_orders_closed_at ON orders (ClosedAt) WHERE Status = 'closed';
+
+-- order_status_history
+CREATE INDEX idx_order_status_history_order_id ON order_status_history (OrderID);
+
+-- payments
+CREATE INDEX idx_payments_order_id ON payments (OrderID);
+CREATE INDEX idx_payments_created_at ON payments (CreatedAt);
 
 -- order_items
 CREATE INDEX idx_order_items_order_id ON order_items (OrderID);
@@ -92,21 +256,34 @@ CREATE INDEX idx_order_items_product_id ON order_items (ProductID);
 CREATE INDEX idx_menu_item_ingredients_menu_id ON menu_item_ingredients (MenuID);
 CREATE INDEX idx_menu_item_ingredients_ingredient_id ON menu_item_ingredients (IngredientID);
 
+-- audit_log
+CREATE INDEX idx_audit_log_entity ON audit_log (EntityType, EntityID, CreatedAt);
+CREATE INDEX idx_audit_log_created_at ON audit_log (CreatedAt);
+
+-- idempotency_keys
+CREATE INDEX idx_idempotency_keys_expires_at ON idempotency_keys (ExpiresAt);
+
 -- search indexes for full text search
 CREATE INDEX idx_menu_item_search_id ON menu_items USING gin(to_tsvector('english', name || ' ' || COALESCE(description, '')));
 
 -- Mock data for menu_items
-INSERT INTO menu_items (Name, Description, Price) VALUES
-('Caffe Latte', 'Espresso with steamed milk', 3.50),
-('Blueberry Muffin', 'Freshly baked muffin with blueberries', 2.00),
-('Espresso', 'Strong and bold coffee', 2.50),
-('Cappuccino', 'Espresso with steamed milk and foam', 3.00),
-('Mocha', 'Espresso with steamed milk and chocolate', 3.75),
-('Iced Latte', 'Iced espresso with milk', 3.80),
-('Americano', 'Espresso diluted with hot water', 2.80),
-('Carrot Cake', 'Delicious spiced cake with cream cheese frosting', 2.50),
-('Vanilla Latte', 'Espresso with steamed milk and vanilla syrup', 3.60),
-('Chocolate Croissant', 'Flaky croissant with chocolate filling', 2.80);
+INSERT INTO menu_items (Name, Description, Category, Price) VALUES
+('Caffe Latte', 'Espresso with steamed milk', 'drinks', 3.50),
+('Blueberry Muffin', 'Freshly baked muffin with blueberries', 'bakery', 2.00),
+('Espresso', 'Strong and bold coffee', 'drinks', 2.50),
+('Cappuccino', 'Espresso with steamed milk and foam', 'drinks', 3.00),
+('Mocha', 'Espresso with steamed milk and chocolate', 'drinks', 3.75),
+('Iced Latte', 'Iced espresso with milk', 'drinks', 3.80),
+('Americano', 'Espresso diluted with hot water', 'drinks', 2.80),
+('Carrot Cake', 'Delicious spiced cake with cream cheese frosting', 'bakery', 2.50),
+('Vanilla Latte', 'Espresso with steamed milk and vanilla syrup', 'drinks', 3.60),
+('Chocolate Croissant', 'Flaky croissant with chocolate filling', 'bakery', 2.80);
+
+-- Mock data for tax_rates
+INSERT INTO tax_rates (Category, Rate, Inclusive) VALUES
+('default', 0.1200, TRUE),
+('drinks', 0.1200, TRUE),
+('bakery', 0.0500, FALSE);
 
 -- Mock data for inventory
 INSERT INTO inventory (Name, Quantity, Unit) VALUES
@@ -196,3 +373,6 @@ INSERT INTO order_items (OrderID, ProductID, Quantity) VALUES
 (16, 8, 1),  -- Peter: 1 Carrot Cake
 (17, 10, 2),  -- Quincy: 2 Chocolate Croissants
 (18, 2, 1);  -- Rebecca: 1 Blueberry Muffin
+
+-- Order items keep the menu price at the moment of ordering
+UPDATE order_items SET Price = menu_items.Price FROM menu_items WHERE menu_items.ID = order_items.ProductID;
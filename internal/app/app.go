@@ -1,15 +1,17 @@
 package app
 
 import (
-	"coffee-shop/internal/repository/postgres"
+	"coffee-shop/internal/auth"
+	"coffee-shop/internal/events"
+	"coffee-shop/internal/receipt"
 	"coffee-shop/internal/service"
 	"coffee-shop/internal/transport/http/handler"
 	"coffee-shop/internal/transport/http/server"
 	"coffee-shop/pkg/logger"
 	"context"
-	"database/sql"
 	"fmt"
 	"log/slog"
+	"time"
 
 	_ "github.com/lib/pq"
 )
@@ -26,36 +28,114 @@ func New(ctx context.Context, cfg *server.Config) (*App, error) {
 	log := logger.SetupLogger(&logger.LoggerOptions{Env: cfg.Env, LogFilepath: cfg.Log_file})
 	log.Info("logger is initialized successfully")
 
-	db, err := sql.Open("postgres", connStr)
+	location, err := time.LoadLocation(cfg.Timezone)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("invalid shop time zone: %w", err)
 	}
 
-	// TODO: Repository
-	inventoryRepo := postgres.NewInventory(db)
-	postgres.NewMenu(db)
-	postgres.NewOrder(db)
-
-	// TODO: UseCase
-
-	inventoryService := service.NewInventoryService(inventoryRepo)
-
-	// TODO: http service
+	// Repository
+	repos, err := newRepositories(cfg, location)
+	if err != nil {
+		return nil, err
+	}
 
-	inventoryhandler := handler.NewInventoryHandler(inventoryService, log)
+	// UseCase
+	orderEvents := events.NewBroker(0, 0)
+	inventoryService := service.NewInventoryService(repos.inventory, repos.audit, repos.tx)
+	menuService := service.NewMenuService(repos.menu, repos.menuIngredients, repos.priceHistory, repos.inventory, repos.audit, repos.tx)
+	orderService := service.NewOrderService(service.OrderRepos{
+		OrderRepo:           repos.orders,
+		OrderItemsRepo:      repos.orderItems,
+		HistoryRepo:         repos.history,
+		OrderTaxesRepo:      repos.orderTaxes,
+		MenuRepo:            repos.menu,
+		MenuIngredientsRepo: repos.menuIngredients,
+		InventoryRepo:       repos.inventory,
+		LedgerRepo:          repos.ledger,
+		TaxRepo:             repos.taxes,
+		PaymentRepo:         repos.payments,
+		ConsumptionRepo:     repos.consumption,
+		ClosingRepo:         repos.closings,
+		AuditRepo:           repos.audit,
+	}, repos.tx, orderEvents)
+	paymentService := service.NewPaymentService(repos.payments, orderService, repos.inventory, repos.ledger, repos.menuIngredients, repos.consumption, repos.closings, repos.tx)
+	renderer, err := receipt.New(cfg.Receipt)
+	if err != nil {
+		return nil, err
+	}
+	receiptService := service.NewReceiptService(orderService, paymentService, repos.menu, renderer)
+	taxService := service.NewTaxService(repos.taxes)
+	importService := service.NewImportService(repos.menu, repos.menuIngredients, repos.priceHistory, repos.inventory, repos.audit, repos.tx)
+	credentials, err := newCredentials(cfg.Auth, log)
+	if err != nil {
+		return nil, err
+	}
+	authService := service.NewAuthService(repos.staff, credentials)
+	auditService := service.NewAuditService(repos.audit)
+	idempotencyService := service.NewIdempotencyService(repos.idempotencyKeys, cfg.IdempotencyTTL)
+
+	// http service
+	exporter := handler.NewExporter(cfg.Export, location, log)
+	inventoryhandler := handler.NewInventoryHandler(inventoryService, exporter, log)
+	menuHandler := handler.NewMenuHandler(menuService, log)
+	orderHandler := handler.NewOrderHandler(orderService, exporter, log)
+	orderStreamHandler := handler.NewOrderStreamHandler(orderEvents, cfg.Stations, log)
+	taxHandler := handler.NewTaxHandler(taxService, log)
+	paymentHandler := handler.NewPaymentHandler(paymentService, log)
+	receiptHandler := handler.NewReceiptHandler(receiptService, log)
+	importHandler := handler.NewImportHandler(importService, log)
+	authHandler := handler.NewAuthHandler(authService, log)
+	auditHandler := handler.NewAuditHandler(auditService, location, log)
+	idempotencyHandler := handler.NewIdempotencyHandler(idempotencyService, log)
 
 	srv := server.New(cfg, log)
+	srv.SetupAuthRoutes(authHandler)
+	srv.SetupIdempotency(idempotencyHandler)
 	srv.SetupInventoryRoutes(inventoryhandler)
+	srv.SetupMenuRoutes(menuHandler)
+	srv.SetupOrderRoutes(orderHandler)
+	srv.SetupOrderStreamRoutes(orderStreamHandler)
+	srv.SetupTaxRoutes(taxHandler)
+	srv.SetupPaymentRoutes(paymentHandler)
+	srv.SetupReceiptRoutes(receiptHandler)
+	srv.SetupImportRoutes(importHandler)
+	srv.SetupAuditRoutes(auditHandler)
+
+	// Reports and end-of-day closings are SQL aggregates, available with postgres only.
+	if repos.reports != nil {
+		reportService := service.NewReportService(repos.reports, repos.inventory, location)
+		closingService := service.NewClosingService(repos.reports, repos.closings, renderer, repos.tx, location)
+		srv.SetupReportRoutes(handler.NewReportHandler(reportService, exporter, location, log))
+		srv.SetupClosingRoutes(handler.NewClosingHandler(closingService, location, log))
+	} else {
+		log.Warn("reports and end-of-day closings are disabled", slog.String("storage", cfg.Storage))
+	}
+
 	return &App{
 		httpServer: srv,
 		log:        log,
 	}, nil
 }
 
+// newCredentials returns the credentials that sign the access tokens with the configured secret.
+func newCredentials(cfg server.AuthConfig, log *slog.Logger) (*auth.Credentials, error) {
+	secret := []byte(cfg.Secret)
+	if len(secret) == 0 {
+		log.Warn("auth secret is not set, access tokens will not survive a restart", slog.String("env", server.AuthSecretEnv))
+		secret = auth.NewSecret()
+	}
+
+	credentials, err := auth.New(secret, cfg.TokenTTL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid auth config: %w", err)
+	}
+	return credentials, nil
+}
+
 func (a *App) Close() {
 	err := a.httpServer.Shutdown()
 	if err != nil {
-		a.log.Error("failed to shutdown the service:", err)
+		a.log.Error("failed to shutdown the service", slog.String("error", err.Error()))
 	}
 }
 
@@ -0,0 +1,49 @@
+package app
+
+import (
+	"coffee-shop/internal/events"
+	"coffee-shop/internal/legacy"
+	"coffee-shop/internal/repository/postgres"
+	"coffee-shop/internal/service"
+	"context"
+	"database/sql"
+	"io"
+)
+
+// ImportLegacy migrates the data directory of the JSON version of the shop into the
+// database and writes the reconciliation report to w. Nothing is migrated if the
+// files cannot be read or the migration fails.
+func ImportLegacy(ctx context.Context, dir string, w io.Writer) error {
+	data, err := legacy.Read(dir)
+	if err != nil {
+		return err
+	}
+
+	db, err := sql.Open("postgres", connStr)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	transactor := postgres.NewTransactor(db)
+	orderService := service.NewOrderService(service.OrderRepos{
+		OrderRepo:           postgres.NewOrder(db),
+		OrderItemsRepo:      postgres.NewOrderItems(db),
+		HistoryRepo:         postgres.NewOrderStatusHistory(db),
+		OrderTaxesRepo:      postgres.NewOrderTaxes(db),
+		MenuRepo:            postgres.NewMenu(db),
+		MenuIngredientsRepo: postgres.NewMenuItemIngredients(db),
+		InventoryRepo:       postgres.NewInventory(db),
+		LedgerRepo:          postgres.NewInventoryTransactions(db),
+		TaxRepo:             postgres.NewTaxRate(db),
+		PaymentRepo:         postgres.NewPayment(db),
+		ConsumptionRepo:     postgres.NewOrderConsumption(db),
+	}, transactor, events.NewBroker(0, 0))
+
+	report, err := service.NewLegacyService(orderService, transactor).MigrateLegacy(ctx, data)
+	if err != nil {
+		return err
+	}
+
+	return legacy.PrintReport(w, dir, *report)
+}
@@ -0,0 +1,42 @@
+package app
+
+import (
+	"coffee-shop/internal/auth"
+	"coffee-shop/internal/model"
+	"coffee-shop/internal/service"
+	"coffee-shop/internal/transport/http/server"
+	"context"
+	"fmt"
+	"io"
+	"time"
+)
+
+// CreateStaff creates a staff member in the configured storage, e.g. the first admin,
+// and writes the issued API key to w. The key is not stored and cannot be shown again.
+func CreateStaff(ctx context.Context, cfg *server.Config, staff model.Staff, password string, withAPIKey bool, w io.Writer) error {
+	if cfg.Storage == StorageMemory {
+		return fmt.Errorf("staff cannot be created in the %s storage", StorageMemory)
+	}
+
+	repos, err := newRepositories(cfg, time.UTC)
+	if err != nil {
+		return err
+	}
+
+	// Only the hashing of the credentials is used, so the signing secret does not matter.
+	credentials, err := auth.New(auth.NewSecret(), cfg.Auth.TokenTTL)
+	if err != nil {
+		return err
+	}
+
+	created, apiKey, err := service.NewAuthService(repos.staff, credentials).AddStaff(ctx, staff, password, withAPIKey)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "Staff member %s (%s) is created with ID %d\n", created.Username, created.Role, created.ID)
+	if apiKey != "" {
+		fmt.Fprintf(w, "API key: %s\nStore it now, it cannot be shown again.\n", apiKey)
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package app
+
+import (
+	"coffee-shop/internal/repository/json"
+	"coffee-shop/internal/repository/memory"
+	"coffee-shop/internal/repository/postgres"
+	"coffee-shop/internal/service"
+	"coffee-shop/internal/transport/http/server"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Storage backends selected by the storage config key.
+const (
+	StoragePostgres = "postgres"
+	StorageJSON     = "json"
+	StorageMemory   = "memory"
+)
+
+// repositories holds the repositories of the selected storage backend.
+// Reports exist only in postgres, so reports is nil for the other backends.
+type repositories struct {
+	tx              service.Transactor
+	inventory       service.InventoryRepo
+	ledger          service.InventoryTransactionsRepo
+	menu            service.MenuRepo
+	menuIngredients service.MenuItemIngredientsRepo
+	priceHistory    service.PriceHistoryRepo
+	orders          service.OrderRepo
+	orderItems      service.OrderItemsRepo
+	history         service.OrderStatusHistoryRepo
+	consumption     service.OrderConsumptionRepo
+	orderTaxes      service.OrderTaxesRepo
+	taxes           service.TaxRateRepo
+	payments        service.PaymentRepo
+	closings        service.ClosingRepo
+	reports         service.ReportRepo
+	staff           service.StaffRepo
+	audit           service.AuditRepo
+	idempotencyKeys service.IdempotencyRepo
+}
+
+func newRepositories(cfg *server.Config, location *time.Location) (*repositories, error) {
+	switch cfg.Storage {
+	case StoragePostgres:
+		db, err := sql.Open("postgres", connStr)
+		if err != nil {
+			return nil, err
+		}
+		return postgresRepositories(db, location), nil
+	case StorageJSON:
+		db, err := json.Open(cfg.GetDataDir())
+		if err != nil {
+			return nil, err
+		}
+		return memoryRepositories(db), nil
+	case StorageMemory:
+		return memoryRepositories(memory.NewDB()), nil
+	default:
+		return nil, fmt.Errorf("unknown storage %q: use %s, %s or %s", cfg.Storage, StoragePostgres, StorageJSON, StorageMemory)
+	}
+}
+
+func postgresRepositories(db *sql.DB, location *time.Location) *repositories {
+	return &repositories{
+		tx:              postgres.NewTransactor(db),
+		inventory:       postgres.NewInventory(db),
+		ledger:          postgres.NewInventoryTransactions(db),
+		menu:            postgres.NewMenu(db),
+		menuIngredients: postgres.NewMenuItemIngredients(db),
+		priceHistory:    postgres.NewPriceHistory(db),
+		orders:          postgres.NewOrder(db),
+		orderItems:      postgres.NewOrderItems(db),
+		history:         postgres.NewOrderStatusHistory(db),
+		consumption:     postgres.NewOrderConsumption(db),
+		orderTaxes:      postgres.NewOrderTaxes(db),
+		taxes:           postgres.NewTaxRate(db),
+		payments:        postgres.NewPayment(db),
+		closings:        postgres.NewDailyClosing(db, location),
+		reports:         postgres.NewReport(db),
+		staff:           postgres.NewStaff(db),
+		audit:           postgres.NewAudit(db),
+		idempotencyKeys: postgres.NewIdempotencyKeys(db),
+	}
+}
+
+func memoryRepositories(db *memory.DB) *repositories {
+	return &repositories{
+		tx:              db,
+		inventory:       memory.NewInventory(db),
+		ledger:          memory.NewInventoryTransactions(db),
+		menu:            memory.NewMenu(db),
+		menuIngredients: memory.NewMenuItemIngredients(db),
+		priceHistory:    memory.NewPriceHistory(db),
+		orders:          memory.NewOrder(db),
+		orderItems:      memory.NewOrderItems(db),
+		history:         memory.NewOrderStatusHistory(db),
+		consumption:     memory.NewOrderConsumption(db),
+		orderTaxes:      memory.NewOrderTaxes(db),
+		taxes:           memory.NewTaxRate(db),
+		payments:        memory.NewPayment(db),
+		closings:        memory.NewDailyClosing(db),
+		staff:           memory.NewStaff(db),
+		audit:           memory.NewAudit(db),
+		idempotencyKeys: memory.NewIdempotencyKeys(db),
+	}
+}
@@ -0,0 +1,216 @@
+// Package auth hashes the passwords and API keys of the staff and signs their access
+// tokens, which are JSON Web Tokens signed with HMAC-SHA256.
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"coffee-shop/internal/model"
+)
+
+const (
+	// passwordIterations is the PBKDF2 work factor of new password hashes. Stored
+	// hashes keep the factor they were made with.
+	passwordIterations = 600_000
+	passwordScheme     = "pbkdf2-sha256"
+	saltSize           = 16
+	keySize            = 32
+
+	// apiKeyPrefix makes API keys recognizable, e.g. in logs and secret scanners.
+	apiKeyPrefix = "cs_"
+)
+
+// dummyHash is checked in place of an empty hash, so that a missing user or password
+// takes as long to reject as a wrong password. It is the hash of a random password.
+const dummyHash = "pbkdf2-sha256$600000$bwU1FZg+nbniaLjNkkPVuw$y/OM6640XrZfOZ0xJqo1aK0bnH8eBBRmmRjeVu9pfB0"
+
+// MinSecretSize is the minimal size of a signing secret in bytes.
+const MinSecretSize = 32
+
+// Credentials hashes passwords and API keys, and issues and verifies access tokens
+// signed with a secret.
+type Credentials struct {
+	secret []byte
+	ttl    time.Duration
+	now    func() time.Time
+}
+
+// New returns Credentials that sign tokens with the secret, valid for ttl.
+func New(secret []byte, ttl time.Duration) (*Credentials, error) {
+	if len(secret) < MinSecretSize {
+		return nil, fmt.Errorf("auth: the signing secret must be at least %d bytes long", MinSecretSize)
+	}
+	if ttl <= 0 {
+		return nil, errors.New("auth: the token lifetime must be positive")
+	}
+	return &Credentials{secret: secret, ttl: ttl, now: time.Now}, nil
+}
+
+// NewSecret returns a random signing secret.
+func NewSecret() []byte {
+	return []byte(rand.Text() + rand.Text())
+}
+
+// HashPassword returns the salted PBKDF2 hash of the password, in the form
+// pbkdf2-sha256$<iterations>$<salt>$<hash>.
+func (c *Credentials) HashPassword(password string) (string, error) {
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key, err := pbkdf2.Key(sha256.New, password, salt, passwordIterations, keySize)
+	if err != nil {
+		return "", err
+	}
+
+	enc := base64.RawStdEncoding
+	return fmt.Sprintf("%s$%d$%s$%s", passwordScheme, passwordIterations, enc.EncodeToString(salt), enc.EncodeToString(key)), nil
+}
+
+// CheckPassword reports whether the password matches the hash made by HashPassword.
+// An empty hash never matches, but takes as long to check as a real one.
+func (c *Credentials) CheckPassword(hash, password string) bool {
+	if hash == "" {
+		c.CheckPassword(dummyHash, password)
+		return false
+	}
+
+	parts := strings.Split(hash, "$")
+	if len(parts) != 4 || parts[0] != passwordScheme {
+		return false
+	}
+
+	iterations, err := strconv.Atoi(parts[1])
+	if err != nil || iterations <= 0 {
+		return false
+	}
+	enc := base64.RawStdEncoding
+	salt, err := enc.DecodeString(parts[2])
+	if err != nil {
+		return false
+	}
+	want, err := enc.DecodeString(parts[3])
+	if err != nil {
+		return false
+	}
+
+	got, err := pbkdf2.Key(sha256.New, password, salt, iterations, len(want))
+	if err != nil {
+		return false
+	}
+	return subtle.ConstantTimeCompare(got, want) == 1
+}
+
+// NewAPIKey returns a random API key and its hash. Only the hash is stored, so the
+// key is shown once when it is issued.
+func (c *Credentials) NewAPIKey() (string, string) {
+	key := apiKeyPrefix + rand.Text()
+	return key, c.HashAPIKey(key)
+}
+
+// HashAPIKey returns the hash of the API key. API keys are random, so a plain
+// SHA-256 hash is enough to keep them from being read from the storage.
+func (c *Credentials) HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// header is the JOSE header of every token.
+var header = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+
+type claims struct {
+	Subject   string `json:"sub"`
+	Username  string `json:"name"`
+	Role      string `json:"role"`
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// Sign issues an access token for the principal.
+func (c *Credentials) Sign(principal model.Principal) (model.Token, error) {
+	now := c.now()
+	expires := now.Add(c.ttl)
+
+	payload, err := marshal(claims{
+		Subject:   strconv.Itoa(principal.StaffID),
+		Username:  principal.Username,
+		Role:      principal.Role,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: expires.Unix(),
+	})
+	if err != nil {
+		return model.Token{}, err
+	}
+
+	unsigned := header + "." + payload
+	return model.Token{
+		Value:     unsigned + "." + c.signature(unsigned),
+		ExpiresAt: time.Unix(expires.Unix(), 0),
+		Principal: principal,
+	}, nil
+}
+
+// Verify checks the signature and the expiry of the token and returns its principal.
+// The following errors may be returned:
+// - model.ErrNotValidToken if the token is malformed, expired or signed with another secret.
+func (c *Credentials) Verify(token string) (model.Principal, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 || parts[0] != header {
+		return model.Principal{}, model.ErrNotValidToken
+	}
+
+	want := c.signature(parts[0] + "." + parts[1])
+	if !hmac.Equal([]byte(parts[2]), []byte(want)) {
+		return model.Principal{}, model.ErrNotValidToken
+	}
+
+	var cl claims
+	if err := unmarshal(parts[1], &cl); err != nil {
+		return model.Principal{}, model.ErrNotValidToken
+	}
+	if c.now().Unix() >= cl.ExpiresAt {
+		return model.Principal{}, model.ErrNotValidToken.WithMessage("access token has expired")
+	}
+
+	id, err := strconv.Atoi(cl.Subject)
+	if err != nil || id <= 0 || !model.ValidRole(cl.Role) {
+		return model.Principal{}, model.ErrNotValidToken
+	}
+	return model.Principal{StaffID: id, Username: cl.Username, Role: cl.Role}, nil
+}
+
+func (c *Credentials) signature(unsigned string) string {
+	mac := hmac.New(sha256.New, c.secret)
+	mac.Write([]byte(unsigned))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// marshal encodes the claims as a base64url encoded JSON object.
+func marshal(cl claims) (string, error) {
+	data, err := json.Marshal(cl)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func unmarshal(payload string, cl *claims) error {
+	data, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, cl)
+}
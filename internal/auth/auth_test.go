@@ -0,0 +1,185 @@
+package auth
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"coffee-shop/internal/model"
+)
+
+var secret = []byte("0123456789abcdef0123456789abcdef")
+
+func newCredentials(t *testing.T, now time.Time) *Credentials {
+	t.Helper()
+	c, err := New(secret, time.Hour)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	c.now = func() time.Time { return now }
+	return c
+}
+
+// forge signs the header and the payload as they are, so that tokens the shop
+// would never issue can be checked.
+func forge(c *Credentials, header, payload string) string {
+	enc := base64.RawURLEncoding
+	unsigned := enc.EncodeToString([]byte(header)) + "." + enc.EncodeToString([]byte(payload))
+	return unsigned + "." + c.signature(unsigned)
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		secret  []byte
+		ttl     time.Duration
+		wantErr bool
+	}{
+		{"valid", secret, time.Hour, false},
+		{"short secret", secret[:MinSecretSize-1], time.Hour, true},
+		{"zero lifetime", secret, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New(tt.secret, tt.ttl); (err != nil) != tt.wantErr {
+				t.Errorf("New: %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPassword(t *testing.T) {
+	c := newCredentials(t, time.Now())
+
+	hash, err := c.HashPassword("correct horse")
+	if err != nil {
+		t.Fatalf("HashPassword: %v", err)
+	}
+	if !strings.HasPrefix(hash, "pbkdf2-sha256$600000$") {
+		t.Errorf("hash = %q, want the pbkdf2-sha256 scheme with 600000 iterations", hash)
+	}
+	if again, _ := c.HashPassword("correct horse"); again == hash {
+		t.Errorf("two hashes of the same password are equal, want different salts")
+	}
+
+	parts := strings.Split(hash, "$")
+	tests := []struct {
+		name     string
+		hash     string
+		password string
+		want     bool
+	}{
+		{"matching password", hash, "correct horse", true},
+		{"wrong password", hash, "correct horsE", false},
+		{"empty password", hash, "", false},
+		{"empty hash", "", "correct horse", false},
+		{"dummy hash", dummyHash, "correct horse", false},
+		{"other scheme", "bcrypt$" + strings.Join(parts[1:], "$"), "correct horse", false},
+		{"missing part", strings.Join(parts[:3], "$"), "correct horse", false},
+		{"bad iterations", strings.Join([]string{parts[0], "0", parts[2], parts[3]}, "$"), "correct horse", false},
+		{"bad salt", strings.Join([]string{parts[0], parts[1], "!", parts[3]}, "$"), "correct horse", false},
+		{"bad key", strings.Join([]string{parts[0], parts[1], parts[2], "!"}, "$"), "correct horse", false},
+		{"fewer iterations", strings.Join([]string{parts[0], "1000", parts[2], parts[3]}, "$"), "correct horse", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := c.CheckPassword(tt.hash, tt.password); got != tt.want {
+				t.Errorf("CheckPassword = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAPIKey(t *testing.T) {
+	c := newCredentials(t, time.Now())
+
+	key, hash := c.NewAPIKey()
+	if !strings.HasPrefix(key, apiKeyPrefix) {
+		t.Errorf("key = %q, want the %q prefix", key, apiKeyPrefix)
+	}
+	if c.HashAPIKey(key) != hash {
+		t.Errorf("HashAPIKey of the issued key differs from its hash")
+	}
+	if other, _ := c.NewAPIKey(); other == key {
+		t.Errorf("two issued keys are equal")
+	}
+}
+
+func TestSignVerify(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	c := newCredentials(t, now)
+	principal := model.Principal{StaffID: 7, Username: "ann", Role: model.RoleManager}
+
+	token, err := c.Sign(principal)
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+	if !token.ExpiresAt.Equal(now.Add(time.Hour)) {
+		t.Errorf("ExpiresAt = %v, want %v", token.ExpiresAt, now.Add(time.Hour))
+	}
+
+	other, err := New([]byte(strings.Repeat("x", MinSecretSize)), time.Hour)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	other.now = c.now
+	foreign, err := other.Sign(principal)
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+
+	parts := strings.Split(token.Value, ".")
+	hs256 := `{"alg":"HS256","typ":"JWT"}`
+	tampered := []byte(parts[2])
+	tampered[0] ^= 1
+
+	tests := []struct {
+		name  string
+		token string
+		now   time.Time
+		want  model.Principal
+		valid bool
+	}{
+		{name: "issued token", token: token.Value, now: now, want: principal, valid: true},
+		{name: "just before expiry", token: token.Value, now: now.Add(time.Hour - time.Second), want: principal, valid: true},
+		{name: "expired", token: token.Value, now: now.Add(time.Hour)},
+		{name: "tampered signature", token: parts[0] + "." + parts[1] + "." + string(tampered), now: now},
+		{name: "tampered payload", token: parts[0] + "." + base64.RawURLEncoding.EncodeToString([]byte(payload("7", model.RoleAdmin))) + "." + parts[2], now: now},
+		{name: "signed with another secret", token: foreign.Value, now: now},
+		{name: "alg none", token: forge(c, `{"alg":"none","typ":"JWT"}`, payload("7", model.RoleManager)), now: now},
+		{name: "alg HS512", token: forge(c, `{"alg":"HS512","typ":"JWT"}`, payload("7", model.RoleManager)), now: now},
+		{name: "typ other than JWT", token: forge(c, `{"alg":"HS256","typ":"JWE"}`, payload("7", model.RoleManager)), now: now},
+		{name: "unknown role", token: forge(c, hs256, payload("7", "owner")), now: now},
+		{name: "empty subject", token: forge(c, hs256, payload("", model.RoleManager)), now: now},
+		{name: "subject not a staff ID", token: forge(c, hs256, payload("0", model.RoleManager)), now: now},
+		{name: "payload not JSON", token: forge(c, hs256, "not json"), now: now},
+		{name: "missing signature", token: parts[0] + "." + parts[1], now: now},
+		{name: "empty", token: "", now: now},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c.now = func() time.Time { return tt.now }
+
+			got, err := c.Verify(tt.token)
+			if tt.valid {
+				if err != nil || got != tt.want {
+					t.Errorf("Verify = %+v, %v, want %+v", got, err, tt.want)
+				}
+				return
+			}
+			if !errors.Is(err, model.ErrNotValidToken) {
+				t.Errorf("Verify: %v, want %v", err, model.ErrNotValidToken)
+			}
+		})
+	}
+}
+
+// payload returns the claims of an hour long token of ann issued at 1700000000.
+func payload(subject, role string) string {
+	return `{"sub":"` + subject + `","name":"ann","role":"` + role + `","iat":1700000000,"exp":1700003600}`
+}
@@ -0,0 +1,104 @@
+// Package events fans out order events to the subscribers of the live order queue.
+package events
+
+import (
+	"sync"
+	"time"
+
+	"coffee-shop/internal/model"
+)
+
+const (
+	defaultHistorySize = 256
+	defaultBufferSize  = 64
+)
+
+// Broker keeps the latest events in memory, so that a reconnecting client can
+// resume from the last event it received.
+type Broker struct {
+	mu          sync.Mutex
+	lastID      uint64
+	history     []model.OrderEvent
+	historySize int
+	bufferSize  int
+	subscribers map[chan model.OrderEvent]struct{}
+}
+
+// NewBroker creates a broker that keeps historySize events for resuming and buffers
+// bufferSize events per subscriber. Event IDs start from the current time in
+// microseconds, so IDs received before a restart are older than the new ones.
+func NewBroker(historySize, bufferSize int) *Broker {
+	if historySize <= 0 {
+		historySize = defaultHistorySize
+	}
+	if bufferSize <= 0 {
+		bufferSize = defaultBufferSize
+	}
+
+	return &Broker{
+		lastID:      uint64(time.Now().UnixMicro()),
+		historySize: historySize,
+		bufferSize:  bufferSize,
+		subscribers: make(map[chan model.OrderEvent]struct{}),
+	}
+}
+
+// Publish assigns the next ID to the event and sends it to every subscriber.
+// A subscriber that does not keep up is unsubscribed and its channel is closed;
+// it can resubscribe with the ID of the last event it received.
+func (b *Broker) Publish(event model.OrderEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.lastID++
+	event.ID = b.lastID
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+
+	b.history = append(b.history, event)
+	if len(b.history) > b.historySize {
+		b.history = b.history[len(b.history)-b.historySize:]
+	}
+
+	for ch := range b.subscribers {
+		select {
+		case ch <- event:
+		default:
+			delete(b.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+// Subscribe returns the kept events published after lastEventID, the channel of
+// the following events and the function that unsubscribes. A zero lastEventID
+// skips the kept events.
+func (b *Broker) Subscribe(lastEventID uint64) ([]model.OrderEvent, <-chan model.OrderEvent, func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var missed []model.OrderEvent
+	if lastEventID > 0 {
+		for _, e := range b.history {
+			if e.ID > lastEventID {
+				missed = append(missed, e)
+			}
+		}
+	}
+
+	ch := make(chan model.OrderEvent, b.bufferSize)
+	b.subscribers[ch] = struct{}{}
+
+	unsubscribe := func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		if _, ok := b.subscribers[ch]; ok {
+			delete(b.subscribers, ch)
+			close(ch)
+		}
+	}
+
+	return missed, ch, unsubscribe
+}
@@ -0,0 +1,66 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+)
+
+type csvWriter struct {
+	w       *csv.Writer
+	headers []header
+	record  []string
+}
+
+func newCSVWriter(w io.Writer, headers []header) (*csvWriter, error) {
+	cw := &csvWriter{w: csv.NewWriter(w), headers: headers, record: make([]string, len(headers))}
+
+	for i, h := range headers {
+		cw.record[i] = h.Title
+	}
+	if err := cw.w.Write(cw.record); err != nil {
+		return nil, err
+	}
+
+	return cw, nil
+}
+
+func (cw *csvWriter) WriteRow(values []any) error {
+	for i, v := range values {
+		cw.record[i] = csvValue(cw.headers[i].Type, v)
+	}
+	return cw.w.Write(cw.record)
+}
+
+func (cw *csvWriter) Close() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+// csvValue formats a cell. Texts that a spreadsheet would take for a formula
+// are prefixed with a quote.
+func csvValue(typ Type, v any) string {
+	switch typ {
+	case Decimal:
+		if f, ok := float(v); ok {
+			return strconv.FormatFloat(f, 'f', 2, 64)
+		}
+		return ""
+	case Date, DateTime:
+		t, ok := timeValue(v)
+		if !ok {
+			return ""
+		}
+		if typ == Date {
+			return t.Format(time.DateOnly)
+		}
+		return t.Format(time.DateTime)
+	default:
+		s := text(v)
+		if s != "" && (s[0] == '=' || s[0] == '+' || s[0] == '-' || s[0] == '@') && typ == Text {
+			return "'" + s
+		}
+		return s
+	}
+}
@@ -0,0 +1,162 @@
+// Package export writes tables as CSV or XLSX spreadsheets row by row, so that
+// large exports can be streamed without holding all the rows in memory.
+package export
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// MIME types of the export formats
+const (
+	MIMECSV  = "text/csv"
+	MIMEXLSX = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+)
+
+var (
+	ErrUnknownFormat = errors.New("unknown export format")
+	ErrUnknownColumn = errors.New("unknown export column")
+)
+
+// Type tells how the values of a column are formatted.
+type Type int
+
+const (
+	Text     Type = iota
+	Int           // int
+	Decimal       // float64 or *float64 with two decimals
+	Date          // time.Time or *time.Time, the date only
+	DateTime      // time.Time or *time.Time
+)
+
+// Column is a column of a table of T. Value returns the cell of a row;
+// nil and the zero time leave the cell empty.
+type Column[T any] struct {
+	Key   string
+	Title string
+	Type  Type
+	Value func(row T) any
+}
+
+// Columns is the set of columns a table of T may be exported with.
+type Columns[T any] []Column[T]
+
+// Select returns the columns with the given keys in the given order,
+// or all columns if no key is given.
+func (cs Columns[T]) Select(keys []string) (Columns[T], error) {
+	if len(keys) == 0 {
+		return cs, nil
+	}
+
+	selected := make(Columns[T], 0, len(keys))
+	for _, key := range keys {
+		i := cs.index(strings.TrimSpace(key))
+		if i < 0 {
+			return nil, fmt.Errorf("%w: %q", ErrUnknownColumn, key)
+		}
+		selected = append(selected, cs[i])
+	}
+
+	return selected, nil
+}
+
+// Keys returns the keys of the columns.
+func (cs Columns[T]) Keys() []string {
+	keys := make([]string, len(cs))
+	for i, c := range cs {
+		keys[i] = c.Key
+	}
+	return keys
+}
+
+func (cs Columns[T]) index(key string) int {
+	for i, c := range cs {
+		if c.Key == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// Options configures an export.
+type Options struct {
+	Sheet    string         // name of the worksheet of XLSX files
+	Location *time.Location // location times are written in; their own location if nil
+}
+
+// header describes a column to the format writers.
+type header struct {
+	Title string
+	Type  Type
+}
+
+// rowWriter writes the rows of a table in a spreadsheet format.
+type rowWriter interface {
+	WriteRow(values []any) error
+	Close() error
+}
+
+// Write writes the table in the format of the MIME type to w. The rows are
+// produced by each, which calls the given function for every row in turn.
+func Write[T any](w io.Writer, mime string, opts Options, columns Columns[T], each func(fn func(row T) error) error) error {
+	headers := make([]header, len(columns))
+	for i, c := range columns {
+		headers[i] = header{Title: c.Title, Type: c.Type}
+	}
+
+	var (
+		rw  rowWriter
+		err error
+	)
+	switch mime {
+	case MIMECSV:
+		rw, err = newCSVWriter(w, headers)
+	case MIMEXLSX:
+		rw, err = newXLSXWriter(w, opts.Sheet, headers)
+	default:
+		return ErrUnknownFormat
+	}
+	if err != nil {
+		return err
+	}
+
+	values := make([]any, len(columns))
+	err = each(func(row T) error {
+		for i, c := range columns {
+			values[i] = inLocation(c.Value(row), opts.Location)
+		}
+		return rw.WriteRow(values)
+	})
+	if err != nil {
+		return err
+	}
+
+	return rw.Close()
+}
+
+// Rows returns an each function for Write over the rows of a slice.
+func Rows[T any](rows []T) func(fn func(row T) error) error {
+	return func(fn func(row T) error) error {
+		for _, row := range rows {
+			if err := fn(row); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// Extension returns the file extension of the export format.
+func Extension(mime string) string {
+	switch mime {
+	case MIMECSV:
+		return ".csv"
+	case MIMEXLSX:
+		return ".xlsx"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,77 @@
+package export
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// float returns the number of a decimal cell.
+func float(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case *float64:
+		if n == nil {
+			return 0, false
+		}
+		return *n, true
+	case int:
+		return float64(n), true
+	default:
+		return 0, false
+	}
+}
+
+// timeValue returns the time of a date cell. The zero time is an empty cell.
+func timeValue(v any) (time.Time, bool) {
+	switch t := v.(type) {
+	case time.Time:
+		return t, !t.IsZero()
+	case *time.Time:
+		if t == nil {
+			return time.Time{}, false
+		}
+		return *t, !t.IsZero()
+	default:
+		return time.Time{}, false
+	}
+}
+
+// text returns the text of a cell.
+func text(v any) string {
+	switch s := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return s
+	case int:
+		return strconv.Itoa(s)
+	case *int:
+		if s == nil {
+			return ""
+		}
+		return strconv.Itoa(*s)
+	case bool:
+		return strconv.FormatBool(s)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// inLocation moves the time of a cell to the location.
+func inLocation(v any, location *time.Location) any {
+	if location == nil {
+		return v
+	}
+
+	switch t := v.(type) {
+	case time.Time:
+		return t.In(location)
+	case *time.Time:
+		if t != nil {
+			return t.In(location)
+		}
+	}
+	return v
+}
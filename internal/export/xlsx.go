@@ -0,0 +1,217 @@
+package export
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Static parts of the XLSX package
+const (
+	xlsxContentTypes = xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+		`</Types>`
+
+	xlsxRels = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`
+
+	xlsxWorkbookRels = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>` +
+		`</Relationships>`
+
+	// The cell styles are referenced by their index: 0 default, 1 header,
+	// 2 integer, 3 decimal, 4 date, 5 date and time.
+	xlsxStyles = xml.Header + `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+		`<numFmts count="2"><numFmt numFmtId="164" formatCode="yyyy-mm-dd"/><numFmt numFmtId="165" formatCode="yyyy-mm-dd hh:mm:ss"/></numFmts>` +
+		`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+		`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+		`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+		`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+		`<cellXfs count="6">` +
+		`<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+		`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/>` +
+		`<xf numFmtId="1" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+		`<xf numFmtId="4" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+		`<xf numFmtId="164" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+		`<xf numFmtId="165" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+		`</cellXfs>` +
+		`</styleSheet>`
+
+	xlsxSheetStart = xml.Header + `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+		`<sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews>` +
+		`<sheetData>`
+	xlsxSheetEnd = `</sheetData></worksheet>`
+)
+
+// Cell styles of xlsxStyles
+const (
+	styleHeader   = 1
+	styleInt      = 2
+	styleDecimal  = 3
+	styleDate     = 4
+	styleDateTime = 5
+)
+
+// maxSheetName is the longest worksheet name Excel accepts.
+const maxSheetName = 31
+
+// excelEpoch is day zero of the serial dates of spreadsheets.
+var excelEpoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+
+// xlsxWriter writes a workbook with a single worksheet. The worksheet is the last
+// part of the package and is streamed into the zip archive row by row.
+type xlsxWriter struct {
+	zip     *zip.Writer
+	sheet   *bufio.Writer
+	headers []header
+	row     int
+}
+
+func newXLSXWriter(w io.Writer, sheet string, headers []header) (*xlsxWriter, error) {
+	zw := zip.NewWriter(w)
+
+	parts := []struct{ name, content string }{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRels},
+		{"xl/workbook.xml", xlsxWorkbook(sheet)},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+		{"xl/styles.xml", xlsxStyles},
+	}
+	for _, p := range parts {
+		f, err := zw.Create(p.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(f, p.content); err != nil {
+			return nil, err
+		}
+	}
+
+	f, err := zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+
+	xw := &xlsxWriter{zip: zw, sheet: bufio.NewWriter(f), headers: headers}
+	xw.sheet.WriteString(xlsxSheetStart)
+
+	titles := make([]any, len(headers))
+	for i, h := range headers {
+		titles[i] = h.Title
+	}
+	xw.writeRow(titles, true)
+
+	return xw, nil
+}
+
+func (xw *xlsxWriter) WriteRow(values []any) error {
+	xw.writeRow(values, false)
+	return nil
+}
+
+func (xw *xlsxWriter) Close() error {
+	xw.sheet.WriteString(xlsxSheetEnd)
+	if err := xw.sheet.Flush(); err != nil {
+		return err
+	}
+	return xw.zip.Close()
+}
+
+// writeRow writes a row of cells. Errors are kept by the buffered writer and
+// returned when it is flushed on Close.
+func (xw *xlsxWriter) writeRow(values []any, isHeader bool) {
+	xw.row++
+	row := strconv.Itoa(xw.row)
+
+	xw.sheet.WriteString(`<row r="` + row + `">`)
+	for i, v := range values {
+		ref := columnName(i) + row
+		if isHeader {
+			xw.writeText(ref, text(v), styleHeader)
+			continue
+		}
+
+		switch xw.headers[i].Type {
+		case Int:
+			if n, ok := float(v); ok {
+				xw.writeNumber(ref, strconv.FormatFloat(n, 'f', -1, 64), styleInt)
+			}
+		case Decimal:
+			if n, ok := float(v); ok {
+				xw.writeNumber(ref, strconv.FormatFloat(n, 'f', -1, 64), styleDecimal)
+			}
+		case Date, DateTime:
+			if t, ok := timeValue(v); ok {
+				style := styleDateTime
+				if xw.headers[i].Type == Date {
+					style = styleDate
+				}
+				xw.writeNumber(ref, strconv.FormatFloat(serialDate(t), 'f', -1, 64), style)
+			}
+		default:
+			if s := text(v); s != "" {
+				xw.writeText(ref, s, 0)
+			}
+		}
+	}
+	xw.sheet.WriteString(`</row>`)
+}
+
+func (xw *xlsxWriter) writeNumber(ref, value string, style int) {
+	xw.sheet.WriteString(`<c r="` + ref + `" s="` + strconv.Itoa(style) + `"><v>` + value + `</v></c>`)
+}
+
+func (xw *xlsxWriter) writeText(ref, value string, style int) {
+	xw.sheet.WriteString(`<c r="` + ref + `" s="` + strconv.Itoa(style) + `" t="inlineStr"><is><t xml:space="preserve">`)
+	_ = xml.EscapeText(xw.sheet, []byte(value))
+	xw.sheet.WriteString(`</t></is></c>`)
+}
+
+func xlsxWorkbook(sheet string) string {
+	var name strings.Builder
+	for _, r := range sheet {
+		// Characters that are not allowed in worksheet names
+		if !strings.ContainsRune(`[]:*?/\`, r) {
+			name.WriteRune(r)
+		}
+	}
+	title := []rune(name.String())
+	if len(title) > maxSheetName {
+		title = title[:maxSheetName]
+	}
+	if len(title) == 0 {
+		title = []rune("Sheet1")
+	}
+
+	var escaped strings.Builder
+	_ = xml.EscapeText(&escaped, []byte(string(title)))
+
+	return xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+		`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets><sheet name="` + escaped.String() + `" sheetId="1" r:id="rId1"/></sheets></workbook>`
+}
+
+// columnName returns the letters of the zero-based column index: A, B, ..., Z, AA, AB, ...
+func columnName(i int) string {
+	name := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		name = string(rune('A'+(i-1)%26)) + name
+	}
+	return name
+}
+
+// serialDate returns the spreadsheet serial date of the wall clock time of t.
+func serialDate(t time.Time) float64 {
+	wall := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+	return wall.Sub(excelEpoch).Hours() / 24
+}
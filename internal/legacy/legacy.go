@@ -0,0 +1,177 @@
+// Package legacy reads the data directory of the JSON version of the shop and
+// prints the reconciliation report of its migration.
+package legacy
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"coffee-shop/internal/model"
+	"coffee-shop/internal/utils"
+)
+
+// Files of the legacy data directory
+const (
+	InventoryFile = "inventory.json"
+	MenuFile      = "menu_items.json"
+	OrdersFile    = "orders.json"
+	ReportFile    = "report.json"
+)
+
+type inventoryItem struct {
+	IngredientID string  `json:"ingredient_id"`
+	Name         string  `json:"name"`
+	Quantity     float64 `json:"quantity"`
+	Unit         string  `json:"unit"`
+}
+
+type menuItem struct {
+	ProductID   string           `json:"product_id"`
+	Name        string           `json:"name"`
+	Description string           `json:"description"`
+	Category    string           `json:"category"`
+	Price       float64          `json:"price"`
+	Ingredients []menuIngredient `json:"ingredients"`
+}
+
+type menuIngredient struct {
+	IngredientID string  `json:"ingredient_id"`
+	Quantity     float64 `json:"quantity"`
+}
+
+type order struct {
+	OrderID      string      `json:"order_id"`
+	CustomerName string      `json:"customer_name"`
+	Items        []orderItem `json:"items"`
+	Status       string      `json:"status"`
+	CreatedAt    string      `json:"created_at"`
+}
+
+type orderItem struct {
+	ProductID string `json:"product_id"`
+	Quantity  int    `json:"quantity"`
+}
+
+// Read reads the legacy inventory, menu and orders from the directory. A missing or
+// empty file holds no records, as it did for the JSON version. An error is returned
+// if a file is not valid JSON or a quantity or creation time cannot be converted.
+func Read(dir string) (model.LegacyData, error) {
+	var data model.LegacyData
+
+	var inventory []inventoryItem
+	if err := readFile(filepath.Join(dir, InventoryFile), &inventory); err != nil {
+		return model.LegacyData{}, err
+	}
+	for _, item := range inventory {
+		quantity, err := wholeQuantity(item.Quantity)
+		if err != nil {
+			return model.LegacyData{}, fmt.Errorf("%s: item %q: %w", InventoryFile, item.IngredientID, err)
+		}
+		data.Inventory = append(data.Inventory, model.LegacyInventoryItem{
+			ID: item.IngredientID,
+			Item: model.Inventory{
+				Name:     strings.TrimSpace(item.Name),
+				Quantity: quantity,
+				Unit:     strings.TrimSpace(item.Unit),
+			},
+		})
+	}
+
+	var menu []menuItem
+	if err := readFile(filepath.Join(dir, MenuFile), &menu); err != nil {
+		return model.LegacyData{}, err
+	}
+	for _, item := range menu {
+		legacyItem := model.LegacyMenuItem{
+			ID: item.ProductID,
+			Item: model.MenuItem{
+				Name:        strings.TrimSpace(item.Name),
+				Description: strings.TrimSpace(item.Description),
+				Category:    strings.TrimSpace(item.Category),
+				Price:       item.Price,
+			},
+		}
+		for _, ingredient := range item.Ingredients {
+			quantity, err := wholeQuantity(ingredient.Quantity)
+			if err != nil {
+				return model.LegacyData{}, fmt.Errorf("%s: item %q: ingredient %q: %w", MenuFile, item.ProductID, ingredient.IngredientID, err)
+			}
+			legacyItem.Ingredients = append(legacyItem.Ingredients, model.LegacyIngredient{
+				IngredientID: ingredient.IngredientID,
+				Quantity:     quantity,
+			})
+		}
+		data.Menu = append(data.Menu, legacyItem)
+	}
+
+	var orders []order
+	if err := readFile(filepath.Join(dir, OrdersFile), &orders); err != nil {
+		return model.LegacyData{}, err
+	}
+	for _, o := range orders {
+		createdAt, err := time.Parse(time.RFC3339, o.CreatedAt)
+		if err != nil {
+			return model.LegacyData{}, fmt.Errorf("%s: order %q: invalid created_at %q", OrdersFile, o.OrderID, o.CreatedAt)
+		}
+
+		legacyOrder := model.LegacyOrder{
+			ID: o.OrderID,
+			Order: model.Order{
+				CustomerName: strings.TrimSpace(o.CustomerName),
+				Status:       strings.ToLower(strings.TrimSpace(o.Status)),
+				CreateAt:     createdAt,
+			},
+		}
+		for _, item := range o.Items {
+			legacyOrder.Items = append(legacyOrder.Items, model.LegacyOrderItem{
+				ProductID: item.ProductID,
+				Quantity:  item.Quantity,
+			})
+		}
+		data.Orders = append(data.Orders, legacyOrder)
+	}
+
+	return data, nil
+}
+
+// readFile decodes the JSON array of the file into v, leaving it empty if the file
+// does not exist or is empty.
+func readFile(path string, v any) error {
+	exists, err := utils.FileExists(path)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if utils.FileEmpty(file) {
+		return nil
+	}
+
+	if err := json.NewDecoder(file).Decode(v); err != nil {
+		return fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+
+	return nil
+}
+
+// wholeQuantity converts a legacy quantity, which was a JSON number, to the whole
+// quantities the inventory is kept in.
+func wholeQuantity(quantity float64) (int, error) {
+	if quantity != math.Trunc(quantity) || math.Abs(quantity) > math.MaxInt32 {
+		return 0, fmt.Errorf("quantity %v is not a whole number", quantity)
+	}
+	return int(quantity), nil
+}
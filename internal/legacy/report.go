@@ -0,0 +1,68 @@
+package legacy
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"coffee-shop/internal/model"
+)
+
+// PrintReport writes the reconciliation report of a migration: the records read,
+// created, updated and skipped per file, the orders summary, the new IDs of the
+// legacy records and the reasons the skipped ones were not migrated.
+func PrintReport(w io.Writer, dir string, r model.LegacyReport) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Migrated legacy data from %s\n\n", dir)
+	fmt.Fprintln(tw, "file\tread\tcreated\tupdated\tskipped\t")
+	for _, row := range []struct {
+		file  string
+		count model.LegacyCount
+	}{
+		{InventoryFile, r.Inventory},
+		{MenuFile, r.Menu},
+		{OrdersFile, r.Orders},
+	} {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t\n", row.file, row.count.Read, row.count.Created, row.count.Updated, row.count.Skipped)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "\nOrders: %d closed, %d open, sales %.2f\n", r.ClosedOrders, r.OpenOrders, r.Sales)
+	fmt.Fprintf(w, "%s is not migrated: reports are computed from the orders.\n", ReportFile)
+
+	if len(r.IDs) > 0 {
+		fmt.Fprintln(w, "\nNew IDs:")
+		tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		for _, id := range r.IDs {
+			fmt.Fprintf(tw, "  %s\t%s\t-> %d\n", id.Kind, id.LegacyID, id.ID)
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+
+	if len(r.Skipped) > 0 {
+		fmt.Fprintln(w, "\nSkipped:")
+		tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		for _, skip := range r.Skipped {
+			fmt.Fprintf(tw, "  %s\t%q\t%s\n", skip.Kind, skip.LegacyID, reason(skip.Err))
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// reason describes why a record was skipped.
+func reason(err error) string {
+	var shopErr *model.Error
+	if errors.As(err, &shopErr) {
+		return shopErr.Message
+	}
+	return err.Error()
+}
@@ -0,0 +1,89 @@
+package model
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// Actions recorded in the audit log.
+const (
+	AuditCreate  = "create"
+	AuditUpdate  = "update"
+	AuditDelete  = "delete"
+	AuditClose   = "close"
+	AuditArchive = "archive"
+	AuditRestore = "restore"
+)
+
+// Types of the records whose changes are recorded in the audit log.
+const (
+	AuditInventory = "inventory"
+	AuditMenu      = "menu"
+	AuditOrder     = "order"
+)
+
+// MaxAuditEntries is the maximal number of audit entries returned at once.
+const MaxAuditEntries = 1000
+
+// AuditEntry is a change of a record made through the API. Before and After hold the
+// record as JSON; Before is empty for a created record and After for a deleted one.
+// Entries are never changed or deleted.
+type AuditEntry struct {
+	ID         int
+	ActorID    int
+	Actor      string
+	Action     string
+	EntityType string
+	EntityID   int
+	Before     json.RawMessage
+	After      json.RawMessage
+	RequestID  string
+	IP         string
+	CreatedAt  time.Time
+}
+
+// AuditQuery selects entries of the audit log, newest first. Zero fields do not
+// restrict the entries.
+type AuditQuery struct {
+	EntityType string
+	EntityID   int
+	Period     Period
+	Limit      int
+}
+
+func (q *AuditQuery) Validate() error {
+	switch {
+	case q.EntityType != "" && q.EntityType != AuditInventory && q.EntityType != AuditMenu && q.EntityType != AuditOrder:
+		return ErrNotValidAuditEntity
+	case q.EntityID < 0 || q.EntityID > 0 && q.EntityType == "":
+		return ErrNotValidAuditEntity
+	case !q.Period.From.IsZero() && !q.Period.To.IsZero() && !q.Period.From.Before(q.Period.To):
+		return ErrNotValidPeriod
+	case q.Limit < 1 || q.Limit > MaxAuditEntries:
+		return ErrNotValidLimit.WithMessage("limit must be between 1 and 1000")
+	default:
+		return nil
+	}
+}
+
+// Origin describes who made a request and where it came from. It is recorded with
+// the changes the request makes.
+type Origin struct {
+	Principal Principal
+	RequestID string
+	IP        string
+}
+
+type originKey struct{}
+
+// WithOrigin returns a copy of ctx that carries the origin of the request.
+func WithOrigin(ctx context.Context, origin Origin) context.Context {
+	return context.WithValue(ctx, originKey{}, origin)
+}
+
+// OriginFrom returns the origin carried by ctx, or the zero Origin.
+func OriginFrom(ctx context.Context) Origin {
+	origin, _ := ctx.Value(originKey{}).(Origin)
+	return origin
+}
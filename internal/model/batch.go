@@ -0,0 +1,35 @@
+package model
+
+// MaxBatchOrders limits the number of orders processed by a single batch.
+const MaxBatchOrders = 100
+
+// BatchOrderResult is the outcome of a single order of a batch. Index is the
+// position of the order in the batch. Err is set for rejected orders.
+type BatchOrderResult struct {
+	Index   int
+	OrderID int
+	Total   float64
+	Err     error
+}
+
+func (r *BatchOrderResult) Accepted() bool {
+	return r.Err == nil
+}
+
+// BatchResult sums up a processed batch. Revenue is the total of the accepted
+// orders and Consumed the stock reserved for them.
+type BatchResult struct {
+	Results  []BatchOrderResult
+	Accepted int
+	Rejected int
+	Revenue  float64
+	Consumed []IngredientUsage
+}
+
+// IngredientUsage is the quantity of an ingredient taken from the inventory.
+type IngredientUsage struct {
+	IngredientID int
+	Name         string
+	Unit         string
+	Quantity     int
+}
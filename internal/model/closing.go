@@ -0,0 +1,69 @@
+package model
+
+import "time"
+
+// DailyClosing is the end-of-day (Z) report of a business day. It covers the period
+// from the previous closing, or the start of the day for the first one, to the closing
+// time, and is never changed once stored. Orders closed within a closed period cannot be
+// modified afterwards; orders left open carry over to the next business day.
+type DailyClosing struct {
+	ID           int
+	BusinessDate time.Time
+	Period
+
+	ClosedOrders int
+	OpenOrders   []OpenOrder
+
+	GrossSales float64 // item prices before discounts
+	Discounts  float64
+	NetSales   float64 // sales without discounts and taxes
+	Taxes      float64
+	Total      float64 // what the customers were charged
+	Tips       float64
+	Refunds    int
+	Refunded   float64
+
+	TaxLines []TaxReportLine
+	Tenders  []PaymentReportLine
+
+	OpeningFloat   float64
+	ExpectedCash   float64
+	CountedCash    float64
+	CashDifference float64 // positive when the drawer is over, negative when short
+
+	Notes     string
+	CreatedAt time.Time
+}
+
+// OpenOrder is an order still open at the closing time.
+type OpenOrder struct {
+	ID           int
+	CustomerName string
+	CreatedAt    time.Time
+}
+
+// ClosingRequest closes a business day. The counted cash is compared against the
+// opening float plus the cash taken within the closing period.
+type ClosingRequest struct {
+	BusinessDate time.Time
+	OpeningFloat float64
+	CountedCash  float64
+	Notes        string
+}
+
+func (r *ClosingRequest) Validate() error {
+	if r.OpeningFloat < 0 || r.CountedCash < 0 {
+		return ErrNotValidCashCount
+	}
+	return nil
+}
+
+// Tender returns the payments of the tender, or an empty line if there were none.
+func (c *DailyClosing) Tender(tender string) PaymentReportLine {
+	for _, line := range c.Tenders {
+		if line.Tender == tender {
+			return line
+		}
+	}
+	return PaymentReportLine{Tender: tender}
+}
@@ -1,55 +1,231 @@
 package model
 
-import (
-	"errors"
+// Kind classifies errors by how a client should react to them.
+type Kind int
+
+const (
+	KindInternal             Kind = iota // a failure of the service
+	KindInvalid                          // the request is malformed or breaks a rule
+	KindNotFound                         // the requested record does not exist
+	KindConflict                         // the request conflicts with the current state of the records
+	KindUnsupported                      // the request body is in an unsupported format
+	KindNotAcceptable                    // the response cannot be produced in an acceptable format
+	KindTooLarge                         // the request body exceeds the size limit
+	KindUnauthorized                     // the request carries no valid credentials
+	KindForbidden                        // the principal is not allowed to make the request
+	KindUnprocessable                    // the request is well-formed but cannot be processed as it is
+	KindPreconditionFailed               // the record changed since the client read it
+	KindPreconditionRequired             // the request must be conditional on the version of the record
 )
 
+// Error is an error of the shop. Code is stable and machine-readable, Title is a
+// short summary of the error and Message explains it to the user.
+type Error struct {
+	Kind    Kind
+	Code    string
+	Title   string
+	Message string
+
+	// base is the error this one was derived from by WithMessage.
+	base *Error
+}
+
+func NewError(kind Kind, code, title, message string) *Error {
+	return &Error{
+		Kind:    kind,
+		Code:    code,
+		Title:   title,
+		Message: message,
+	}
+}
+
+func (e *Error) Error() string {
+	return e.Title
+}
+
+// Is reports whether e was derived from target by WithMessage.
+func (e *Error) Is(target error) bool {
+	return e.base != nil && e.base == target
+}
+
+// WithMessage returns the error with a message about this occurrence of it,
+// e.g. the reason a request body could not be parsed.
+func (e *Error) WithMessage(message string) *Error {
+	base := e
+	if e.base != nil {
+		base = e.base
+	}
+	return &Error{Kind: e.Kind, Code: e.Code, Title: e.Title, Message: message, base: base}
+}
+
 var (
+	// Request errors
+
+	ErrInternal      = NewError(KindInternal, "internal", "internal server error", "the request could not be processed")
+	ErrNotValidBody  = NewError(KindInvalid, "invalid_body", "invalid request body", "request body is not valid")
+	ErrNotValidQuery = NewError(KindInvalid, "invalid_query", "invalid query parameters", "query parameters are not valid")
+	ErrBodyTooLarge  = NewError(KindTooLarge, "body_too_large", "request body too large", "request body exceeds the size limit")
+	ErrNotValidPatch = NewError(KindUnsupported, "invalid_patch_format", "invalid patch format", "patch must be application/merge-patch+json or application/json-patch+json")
+	ErrPatchConflict = NewError(KindConflict, "patch_conflict", "patch conflict", "the patch cannot be applied to the current record")
+
+	// Authentication errors
+
+	ErrUnauthenticated     = NewError(KindUnauthorized, "unauthenticated", "authentication required", "the request requires a valid access token or API key")
+	ErrNotValidCredentials = NewError(KindUnauthorized, "invalid_credentials", "invalid credentials", "username or password is not valid")
+	ErrNotValidToken       = NewError(KindUnauthorized, "invalid_token", "invalid access token", "access token is malformed, expired or not signed by the shop")
+	ErrNotValidAPIKey      = NewError(KindUnauthorized, "invalid_api_key", "invalid API key", "API key is not valid")
+	ErrForbidden           = NewError(KindForbidden, "forbidden", "forbidden", "the role of the staff member does not allow the request")
+
+	// Repository errors
+
+	ErrNotFound         = NewError(KindNotFound, "not_found", "record not found", "the record does not exist")
+	ErrDuplicate        = NewError(KindConflict, "duplicate", "record already exists", "a record with the same key already exists")
+	ErrReferenced       = NewError(KindConflict, "referenced", "record is referenced", "the record is still referred to by other records")
+	ErrMissingReference = NewError(KindInvalid, "missing_reference", "missing reference", "the record refers to a record that does not exist")
+	ErrConstraint       = NewError(KindInvalid, "constraint_violation", "constraint violation", "the record breaks a rule of the storage")
+	ErrVersionMismatch  = NewError(KindPreconditionFailed, "version_mismatch", "version mismatch", "the record was changed since it was read; read it again and retry")
+	ErrVersionRequired  = NewError(KindPreconditionRequired, "version_required", "version required", "the request requires an If-Match header with the ETag of the record")
+
+	// Staff errors
+
+	ErrNotValidStaffID  = NewError(KindInvalid, "invalid_staff_id", "invalid staff ID", "staff ID is not valid")
+	ErrNotValidUsername = NewError(KindInvalid, "invalid_username", "invalid username", "username must be from 1 to 50 characters long")
+	ErrNotValidRole     = NewError(KindInvalid, "invalid_role", "invalid role", "role must be one of barista, manager, admin")
+	ErrNotValidPassword = NewError(KindInvalid, "invalid_password", "invalid password", "password must be at least 8 characters long")
+	ErrNoCredentials    = NewError(KindInvalid, "missing_credentials", "missing credentials", "staff member needs a password or an API key")
+	ErrNotUniqueStaff   = NewError(KindConflict, "duplicate_username", "not unique username", "staff member with the same username already exists")
+	ErrStaffNotFound    = NewError(KindNotFound, "staff_not_found", "staff member not found", "staff member with the given ID does not exist")
+
 	// Inventory errors
 
-	ErrNotValidIngredientID   error = errors.New("invalid ingredient ID")
-	ErrNotUniqueID            error = errors.New("not unique ingredient ID")
-	ErrNoItem                 error = errors.New("item not found")
-	ErrNotValidIngredientName error = errors.New("invalid ingredient Name")
-	ErrNotValidQuantity       error = errors.New("invalid ingredient Quantity")
-	ErrNotValidUnit           error = errors.New("invalid ingredient Unit")
+	ErrNotValidIngredientID   = NewError(KindInvalid, "invalid_ingredient_id", "invalid ingredient ID", "ingredient ID is not valid")
+	ErrNotUniqueID            = NewError(KindConflict, "duplicate_ingredient_id", "not unique ingredient ID", "item with the same ID already exists")
+	ErrNoItem                 = NewError(KindNotFound, "ingredient_not_found", "item not found", "item with the given ID does not exist")
+	ErrNotValidIngredientName = NewError(KindInvalid, "invalid_ingredient_name", "invalid ingredient Name", "ingredient name is not valid")
+	ErrNotValidQuantity       = NewError(KindInvalid, "invalid_ingredient_quantity", "invalid ingredient Quantity", "ingredient quantity is not valid")
+	ErrNotValidUnit           = NewError(KindInvalid, "invalid_ingredient_unit", "invalid ingredient Unit", "ingredient unit must be one of g, kg, ml, l, pcs, shots")
+
+	ErrNotValidTransactionReason = NewError(KindInvalid, "invalid_transaction_reason", "invalid inventory transaction reason", "inventory transaction reason cannot be empty")
 
 	// Menu errors
 
-	ErrNotValidMenuID           error = errors.New("invalid product ID")
-	ErrNotUniqueMenuID          error = errors.New("not unique product ID")
-	ErrNotValidMenuName         error = errors.New("invalid product Name")
-	ErrNotValidMenuDescription  error = errors.New("invalid product Description")
-	ErrNotValidPrice            error = errors.New("invalid product Price")
-	ErrDuplicateMenuIngredients error = errors.New("invalid product Ingredients")
-	ErrNotEnoughIngredients     error = errors.New("invalid product Ingredients")
+	ErrNotValidMenuID           = NewError(KindInvalid, "invalid_product_id", "invalid product ID", "product ID is not valid")
+	ErrNotUniqueMenuID          = NewError(KindConflict, "duplicate_product_id", "not unique product ID", "product with the same ID already exists")
+	ErrNotValidMenuName         = NewError(KindInvalid, "invalid_product_name", "invalid product Name", "product name is not valid")
+	ErrNotValidMenuDescription  = NewError(KindInvalid, "invalid_product_description", "invalid product Description", "product description cannot be empty")
+	ErrNotValidPrice            = NewError(KindInvalid, "invalid_product_price", "invalid product Price", "product price must be greater than 0")
+	ErrDuplicateMenuIngredients = NewError(KindInvalid, "duplicate_product_ingredients", "invalid product Ingredients", "ingredients of the product must not be repeated")
+	ErrNotEnoughIngredients     = NewError(KindInvalid, "missing_product_ingredients", "invalid product Ingredients", "product must contain at least 1 ingredient")
+	ErrNotValidMenuCategory     = NewError(KindInvalid, "invalid_product_category", "invalid product Category", "product category cannot be empty")
+	ErrMenuItemNotFound         = NewError(KindNotFound, "product_not_found", "product not found", "product with the given ID does not exist")
 
 	// Order errors
 
-	ErrNotValidOrderID           error = errors.New("invalid order ID")
-	ErrNotValidOrderCustomerName error = errors.New("invalid order CustomeName")
-	ErrNotValidOrderStatus       error = errors.New("invalid order status")
-	ErrNotValidOrderNotes        error = errors.New("invalid order notes")
-	ErrDuplicateOrderItems       error = errors.New("duplicate order Items")
-	ErrNotValidOrderItems        error = errors.New("invalid order Items")
-	ErrNotValidOrderProductID    error = errors.New("invalid order product ID")
-	ErrNotValidStatusField       error = errors.New("invalid order product ID")
-	ErrNotValidCreatedAt         error = errors.New("invalid request")
+	ErrNotValidOrderID           = NewError(KindInvalid, "invalid_order_id", "invalid order ID", "order ID is not valid")
+	ErrNotValidOrderCustomerName = NewError(KindInvalid, "invalid_customer_name", "invalid order customer name", "order customer name is not valid")
+	ErrNotValidOrderStatus       = NewError(KindInvalid, "invalid_order_status", "invalid order status", "order status is not valid")
+	ErrNotValidOrderNotes        = NewError(KindInvalid, "invalid_order_notes", "invalid order notes", "orders notes is not valid")
+	ErrDuplicateOrderItems       = NewError(KindInvalid, "duplicate_order_items", "duplicate order Items", "the items in the order must not be repeated")
+	ErrNotValidOrderItems        = NewError(KindInvalid, "invalid_order_items", "invalid order Items", "order items is not valid")
+	ErrNotValidOrderProductID    = NewError(KindInvalid, "invalid_order_product_id", "invalid order product ID", "product ID is not valid")
+	ErrNotValidStatusField       = NewError(KindInvalid, "invalid_order_status_field", "invalid order status field", "order status cannot be set manually")
+	ErrNotValidCreatedAt         = NewError(KindInvalid, "invalid_created_at", "invalid request", "created_at field cannot be set manually")
+	ErrNotValidDiscount          = NewError(KindInvalid, "invalid_order_discount", "invalid order discount", "order discount cannot be negative")
+	ErrNotValidBatch             = NewError(KindInvalid, "invalid_order_batch", "invalid order batch", "batch must contain from 1 to 100 orders")
+	ErrNotValidStation           = NewError(KindInvalid, "invalid_station", "invalid station", "station is not configured")
+	ErrNotValidOrderModifier     = NewError(KindInvalid, "invalid_order_modifier", "invalid order item modifier", "item modifiers must be non-empty and at most 50 characters long")
+
+	ErrNoOrder                    = NewError(KindNotFound, "order_not_found", "order not found", "order not found")
+	ErrProductNotFound            = NewError(KindInvalid, "unknown_product", "product not found", "the product is not on the menu")
+	ErrProductArchived            = NewError(KindInvalid, "archived_product", "product archived", "the product was taken off the menu")
+	ErrNotEnoughInventoryQuantity = NewError(KindInvalid, "insufficient_stock", "invalid ingredient quantity", "not enough ingredient quantity")
+	ErrInventoryItemNotFound      = NewError(KindInvalid, "unknown_ingredient", "ingredient not found", "ingredient not found")
+	ErrIngredientArchived         = NewError(KindInvalid, "archived_ingredient", "ingredient archived", "archived ingredients cannot be added to recipes")
+	ErrOrderClosed                = NewError(KindInvalid, "order_closed", "order is closed", "can not edit the closed order")
+	ErrNotUniqueOrder             = NewError(KindConflict, "duplicate_order_id", "not unique order ID", "order ID must be unique")
 
 	// Order status history errors
 
-	ErrNotValidStatusHistoryTime error = errors.New("invalid time for history status")
+	ErrNotValidStatusHistoryTime = NewError(KindInvalid, "invalid_status_history_time", "invalid time for history status", "invalid time for history status")
 
 	// Price history errors
 
-	ErrNotValidPriceHistoryID error = errors.New("invalid price history id")
-	ErrNotValidChangedAtTime  error = errors.New("invalid price history time")
+	ErrNotValidPriceHistoryID = NewError(KindInvalid, "invalid_price_history_id", "invalid price history id", "price history id is not valid")
+	ErrNotValidChangedAtTime  = NewError(KindInvalid, "invalid_price_history_time", "invalid price history time", "price history time is not valid")
+
+	// Tax errors
+
+	ErrNotValidTaxCategory = NewError(KindInvalid, "invalid_tax_category", "invalid tax category", "tax category cannot be empty")
+	ErrNotValidTaxRate     = NewError(KindInvalid, "invalid_tax_rate", "invalid tax rate", "tax rate must be between 0 and 1")
+	ErrTaxRateNotFound     = NewError(KindNotFound, "tax_rate_not_found", "tax rate not found", "tax rate for the given category does not exist")
+
+	// Payment errors
+
+	ErrNotValidTender        = NewError(KindInvalid, "invalid_tender", "invalid payment tender", "payment tender must be one of cash, card, voucher")
+	ErrNotValidPaymentAmount = NewError(KindInvalid, "invalid_payment_amount", "invalid payment amount", "payment amount must be greater than 0")
+	ErrNotValidTip           = NewError(KindInvalid, "invalid_tip", "invalid payment tip", "tip cannot be negative")
+	ErrNotValidTendered      = NewError(KindInvalid, "invalid_tendered", "invalid tendered amount", "tendered cash must cover the amount and the tip")
+	ErrNotValidVoucher       = NewError(KindInvalid, "invalid_voucher", "invalid voucher", "voucher payments require a voucher reference")
+	ErrNotValidRefund        = NewError(KindInvalid, "invalid_refund", "invalid refund", "refund takes either an amount or items, not both")
+	ErrNotValidRefundReason  = NewError(KindInvalid, "invalid_refund_reason", "invalid refund reason", "refund reason cannot be empty")
+	ErrNotValidRestock       = NewError(KindInvalid, "invalid_restock", "invalid restock", "restock requires the refunded items or a full refund of the order")
+	ErrNotValidSplit         = NewError(KindInvalid, "invalid_split", "invalid split", "split must be even with at least 2 parts or by items covering the whole order")
+	ErrOverpayment           = NewError(KindInvalid, "overpayment", "overpayment", "payment amount exceeds the order balance")
+	ErrOrderNotPaid          = NewError(KindConflict, "order_not_paid", "order is not paid", "order can be closed only when it is fully paid")
+	ErrOrderNotClosed        = NewError(KindConflict, "order_not_closed", "order is not closed", "only closed orders can be refunded")
+	ErrOrderHasPayments      = NewError(KindConflict, "order_has_payments", "order has payments", "orders with payments cannot be deleted")
+	ErrRefundExceedsPaid     = NewError(KindInvalid, "refund_exceeds_paid", "refund exceeds paid amount", "refund amount exceeds the refundable amount")
+	ErrRefundItemsExceeded   = NewError(KindInvalid, "refund_items_exceeded", "refund items exceeded", "refunded quantity exceeds the ordered quantity")
+	ErrPaymentNotFound       = NewError(KindNotFound, "payment_not_found", "payment not found", "payment with the given ID does not exist")
+
+	// Receipt errors
+
+	ErrNotValidReceiptFormat = NewError(KindInvalid, "invalid_receipt_format", "invalid receipt format", "receipt format must be one of text, html, escpos")
+
+	// Export errors
+
+	ErrNotAcceptable         = NewError(KindNotAcceptable, "not_acceptable", "not acceptable", "response format must be one of json, csv, xlsx")
+	ErrNotValidExportColumns = NewError(KindInvalid, "invalid_export_columns", "invalid export columns", "export columns are not valid")
+
+	// Import errors
+
+	ErrNotValidImport           = NewError(KindInvalid, "invalid_import", "invalid import", "import must contain from 1 to 1000 rows")
+	ErrNotValidImportFile       = NewError(KindInvalid, "invalid_import_file", "invalid import file", "import file is not valid")
+	ErrNotValidImportFormat     = NewError(KindUnsupported, "invalid_import_format", "invalid import format", "import file must be one of csv, json")
+	ErrDuplicateImportRow       = NewError(KindInvalid, "duplicate_import_row", "duplicate import row", "item with the same name appears more than once in the file")
+	ErrImportIngredientNotFound = NewError(KindInvalid, "unknown_import_ingredient", "ingredient not found", "recipe ingredient does not exist in the inventory")
+
+	// Legacy migration errors
+
+	ErrNotValidLegacyID  = NewError(KindInvalid, "invalid_legacy_id", "invalid legacy ID", "legacy record ID is empty or repeated")
+	ErrLegacyReference   = NewError(KindInvalid, "missing_legacy_reference", "missing legacy reference", "record refers to a legacy record that is missing or was skipped")
+	ErrLegacyOrderExists = NewError(KindConflict, "legacy_order_exists", "legacy order exists", "order was migrated by an earlier run")
+
+	// Idempotency errors
+
+	ErrNotValidIdempotencyKey = NewError(KindInvalid, "invalid_idempotency_key", "invalid idempotency key", "idempotency key must be at most 255 characters long")
+	ErrIdempotencyKeyReused   = NewError(KindUnprocessable, "idempotency_key_reused", "idempotency key reused", "idempotency key was already used for a different request")
+	ErrIdempotencyInProgress  = NewError(KindConflict, "idempotency_in_progress", "request in progress", "a request with the same idempotency key is still being processed")
+
+	// Audit errors
+
+	ErrNotValidAuditEntity = NewError(KindInvalid, "invalid_audit_entity", "invalid audit entity", "entity must be one of inventory, menu, order, and an ID requires an entity")
+
+	// Report errors
+
+	ErrNotValidPeriod      = NewError(KindInvalid, "invalid_period", "invalid report period", "report period start must be before its end")
+	ErrNotValidGroupBy     = NewError(KindInvalid, "invalid_group_by", "invalid report grouping", "report grouping must be one of hour, day, week, month")
+	ErrNotValidLimit       = NewError(KindInvalid, "invalid_limit", "invalid report limit", "report limit must be between 1 and 100")
+	ErrNotValidRankBy      = NewError(KindInvalid, "invalid_rank_by", "invalid report ranking", "report ranking must be one of quantity, revenue")
+	ErrNotValidUsageSource = NewError(KindInvalid, "invalid_usage_source", "invalid usage source", "usage source must be one of ledger, orders")
+	ErrNotValidForecast    = NewError(KindInvalid, "invalid_forecast", "invalid forecast parameters", "window must be between 1 and 365 days, lead time and cover between 0 and 365 days")
+	ErrTooManyBuckets      = NewError(KindInvalid, "too_many_buckets", "too many report buckets", "report period is too long for the grouping")
+
+	// Closing errors
 
-	ErrNoOrder                    error = errors.New("order not found")
-	ErrOrderProductNotFound       error = errors.New("product not found")
-	ErrNotEnoughInventoryQuantity error = errors.New("invalid ingredient quantity")
-	ErrProductNotFound            error = errors.New("product not found")
-	ErrInventoryItemNotFound      error = errors.New("order not found")
-	ErrOrderClosed                error = errors.New("order is closed")
-	ErrNotUniqueOrder             error = errors.New("not unique order ID")
+	ErrNotValidCashCount    = NewError(KindInvalid, "invalid_cash_count", "invalid cash count", "opening float and counted cash cannot be negative")
+	ErrNotValidBusinessDate = NewError(KindInvalid, "invalid_business_date", "invalid business date", "business date must be a past or current date in the format YYYY-MM-DD")
+	ErrDayClosed            = NewError(KindConflict, "day_closed", "business day is closed", "the business day or a later one is already closed")
+	ErrClosingNotFound      = NewError(KindNotFound, "closing_not_found", "closing not found", "the business day is not closed")
+	ErrOrderLocked          = NewError(KindConflict, "order_locked", "order is locked", "orders of a closed business day cannot be modified")
 )
@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+// IdempotencyRecord is a request made with an idempotency key and, once it was
+// handled, its response. A record is kept until it expires; a request whose handling
+// failed releases its key by deleting the record.
+type IdempotencyRecord struct {
+	Key         string
+	RequestHash string
+	Completed   bool
+	Status      int
+	Header      map[string][]string
+	Body        []byte
+	CreatedAt   time.Time
+	ExpiresAt   time.Time
+}
@@ -0,0 +1,59 @@
+package model
+
+// MaxImportRows limits the number of rows of a single import file.
+const MaxImportRows = 1000
+
+// Import row actions
+const (
+	ImportActionCreate = "create"
+	ImportActionUpdate = "update"
+)
+
+// MenuImportRow is a menu item read from an import file. Row is the 1-based
+// position of the record in the file. Ingredients refer to inventory items by
+// name. Err is set when the record could not be read.
+type MenuImportRow struct {
+	Row         int
+	Item        MenuItem
+	Ingredients []ImportIngredient
+	Err         error
+}
+
+// ImportIngredient is a recipe line of an imported menu item.
+type ImportIngredient struct {
+	Name     string
+	Quantity int
+}
+
+// InventoryImportRow is an inventory item read from an import file.
+type InventoryImportRow struct {
+	Row  int
+	Item Inventory
+	Err  error
+}
+
+// ImportRowResult is the outcome of a single row. Rows matching an existing item
+// by name update it, the others create a new one. ID is the ID of the updated item,
+// or of the created one once the import is applied. Err is set for rejected rows.
+type ImportRowResult struct {
+	Row    int
+	Action string
+	ID     int
+	Name   string
+	Err    error
+}
+
+func (r *ImportRowResult) Accepted() bool {
+	return r.Err == nil
+}
+
+// ImportResult sums up an import. The changes are applied only when it is not
+// a dry run and no row is rejected.
+type ImportResult struct {
+	DryRun   bool
+	Applied  bool
+	Rows     []ImportRowResult
+	Created  int
+	Updated  int
+	Rejected int
+}
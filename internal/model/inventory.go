@@ -1,10 +1,60 @@
 package model
 
+import (
+	"slices"
+	"time"
+)
+
+// Units are the units inventory items are measured in.
+var Units = []string{"g", "kg", "ml", "l", "pcs", "shots"}
+
 type Inventory struct {
 	IngredientID int
 	Name         string
 	Quantity     int
 	Unit         string
+
+	// Version counts the changes of the item, starting at 1. An update or a delete
+	// with a version is applied only to the item with that version.
+	Version int
+
+	// ArchivedAt is set when the item is archived. Archived items are hidden from
+	// lists but kept for the recipes, the ledger and the reports that refer to them.
+	// They cannot be added to recipes, but recipes that use them keep consuming them.
+	ArchivedAt time.Time
+}
+
+// IsArchived reports whether the item is archived.
+func (r *Inventory) IsArchived() bool {
+	return !r.ArchivedAt.IsZero()
+}
+
+// InventoryPatch holds the fields of an inventory item that are changed. Nil fields
+// are kept.
+type InventoryPatch struct {
+	Name     *string
+	Quantity *int
+	Unit     *string
+}
+
+// Changes returns the patch that turns the item into updated.
+func (r *Inventory) Changes(updated Inventory) InventoryPatch {
+	var patch InventoryPatch
+	if updated.Name != r.Name {
+		patch.Name = &updated.Name
+	}
+	if updated.Quantity != r.Quantity {
+		patch.Quantity = &updated.Quantity
+	}
+	if updated.Unit != r.Unit {
+		patch.Unit = &updated.Unit
+	}
+	return patch
+}
+
+// IsEmpty reports whether the patch changes no field.
+func (p InventoryPatch) IsEmpty() bool {
+	return p == InventoryPatch{}
 }
 
 func (r *Inventory) Validate() error {
@@ -13,7 +63,7 @@ func (r *Inventory) Validate() error {
 		return ErrNotValidIngredientName
 	case r.Quantity <= 0:
 		return ErrNotValidQuantity
-	case r.Unit == "":
+	case !slices.Contains(Units, r.Unit):
 		return ErrNotValidUnit
 	default:
 		return nil
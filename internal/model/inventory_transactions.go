@@ -10,16 +10,15 @@ type InventoryTransactions struct {
 	CreatedAt      time.Time
 }
 
+// QuantityChange is negative when stock is consumed and positive when it is replenished.
 func (r *InventoryTransactions) Validate() error {
 	switch {
-	case r.TransactionID <= 0:
-		return ErrInventoryItemNotFound
 	case r.IngredientId <= 0:
-		return ErrDuplicateMenuIngredients
-	case r.QuantityChange <= 0:
-		return ErrDuplicateMenuIngredients
+		return ErrNotValidIngredientID
+	case r.QuantityChange == 0:
+		return ErrNotValidQuantity
 	case r.Reason == "":
-		return ErrDuplicateMenuIngredients
+		return ErrNotValidTransactionReason
 	default:
 		return nil
 	}
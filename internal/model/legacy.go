@@ -0,0 +1,85 @@
+package model
+
+// LegacyData is the content of the data directory of the JSON version of the shop.
+// Records there are identified by strings, e.g. "menu3", and refer to each other by them.
+type LegacyData struct {
+	Inventory []LegacyInventoryItem
+	Menu      []LegacyMenuItem
+	Orders    []LegacyOrder
+}
+
+type LegacyInventoryItem struct {
+	ID   string
+	Item Inventory
+}
+
+type LegacyMenuItem struct {
+	ID          string
+	Item        MenuItem
+	Ingredients []LegacyIngredient
+}
+
+// LegacyIngredient is a recipe line referring to a legacy inventory item.
+type LegacyIngredient struct {
+	IngredientID string
+	Quantity     int
+}
+
+// LegacyOrder is a legacy order. Its items refer to legacy menu items.
+type LegacyOrder struct {
+	ID    string
+	Order Order
+	Items []LegacyOrderItem
+}
+
+type LegacyOrderItem struct {
+	ProductID string
+	Quantity  int
+}
+
+// Legacy record kinds
+const (
+	LegacyInventory = "inventory"
+	LegacyMenu      = "menu"
+	LegacyOrders    = "orders"
+)
+
+// LegacyReport reconciles the migrated legacy data with the files it was read from.
+type LegacyReport struct {
+	Inventory LegacyCount
+	Menu      LegacyCount
+	Orders    LegacyCount
+
+	// IDs maps the legacy IDs of the migrated records to their new IDs.
+	IDs []LegacyID
+
+	// Skipped are the records that were not migrated.
+	Skipped []LegacySkip
+
+	// ClosedOrders, OpenOrders and Sales sum up the migrated orders.
+	// Sales is the total of the closed ones.
+	ClosedOrders int
+	OpenOrders   int
+	Sales        float64
+}
+
+// LegacyCount counts the records of one kind. Updated are the records matched to
+// an existing item by name; orders migrated by an earlier run are counted as skipped.
+type LegacyCount struct {
+	Read    int
+	Created int
+	Updated int
+	Skipped int
+}
+
+type LegacyID struct {
+	Kind     string
+	LegacyID string
+	ID       int
+}
+
+type LegacySkip struct {
+	Kind     string
+	LegacyID string
+	Err      error
+}
@@ -1,20 +1,71 @@
 package model
 
+import "time"
+
+// DefaultMenuCategory is the category of menu items created without one.
+const DefaultMenuCategory = "general"
+
 type MenuItem struct {
 	ID          int
 	Name        string
 	Description string
+	Category    string
 	Price       float64
+
+	// Version counts the changes of the item, starting at 1. An update or a delete
+	// with a version is applied only to the item with that version.
+	Version int
+
+	// ArchivedAt is set when the item is taken off the menu. Archived items are
+	// hidden from lists and cannot be ordered, but are kept for past orders and reports.
+	ArchivedAt time.Time
+}
+
+// IsArchived reports whether the item is archived.
+func (r *MenuItem) IsArchived() bool {
+	return !r.ArchivedAt.IsZero()
+}
+
+// MenuItemPatch holds the fields of a menu item that are changed. Nil fields are kept.
+type MenuItemPatch struct {
+	Name        *string
+	Description *string
+	Category    *string
+	Price       *float64
+}
+
+// Changes returns the patch that turns the item into updated.
+func (r *MenuItem) Changes(updated MenuItem) MenuItemPatch {
+	var patch MenuItemPatch
+	if updated.Name != r.Name {
+		patch.Name = &updated.Name
+	}
+	if updated.Description != r.Description {
+		patch.Description = &updated.Description
+	}
+	if updated.Category != r.Category {
+		patch.Category = &updated.Category
+	}
+	if updated.Price != r.Price {
+		patch.Price = &updated.Price
+	}
+	return patch
+}
+
+// IsEmpty reports whether the patch changes no field.
+func (p MenuItemPatch) IsEmpty() bool {
+	return p == MenuItemPatch{}
 }
 
+// ID is assigned by the repository, so it is not validated here.
 func (r *MenuItem) Validate() error {
 	switch {
-	case r.ID <= 0:
-		return ErrNotValidMenuID
 	case r.Name == "":
 		return ErrNotValidMenuName
 	case r.Description == "":
 		return ErrNotValidMenuDescription
+	case r.Category == "":
+		return ErrNotValidMenuCategory
 	case r.Price <= 0:
 		return ErrNotValidPrice
 	default:
@@ -6,10 +6,9 @@ type MenuItemIngredients struct {
 	Quantity     int
 }
 
+// MenuID is set by the menu service when the item is saved, so it is not validated here.
 func (r *MenuItemIngredients) Validate() error {
 	switch {
-	case r.MenuID <= 0:
-		return ErrNotValidMenuID
 	case r.IngredientID <= 0:
 		return ErrNotValidIngredientID
 	case r.Quantity <= 0:
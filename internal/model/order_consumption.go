@@ -0,0 +1,11 @@
+package model
+
+// OrderConsumption is the quantity of an ingredient that one unit of a product of the
+// order consumed. It is copied from the recipe when the stock of the order is written
+// off, so that restocks put back what was taken even if the recipe changed since.
+type OrderConsumption struct {
+	OrderID      int
+	ProductID    int
+	IngredientID int
+	Quantity     int
+}
@@ -0,0 +1,60 @@
+package model
+
+import (
+	"slices"
+	"time"
+)
+
+// Order event types
+const (
+	OrderEventCreated   = "order.created"
+	OrderEventUpdated   = "order.updated"
+	OrderEventClosed    = "order.closed"
+	OrderEventCancelled = "order.cancelled"
+)
+
+// OrderEvent is published to the live order queue whenever an order changes.
+// ID is assigned by the broker and grows with every event.
+type OrderEvent struct {
+	ID           uint64
+	Type         string
+	OrderID      int
+	Status       string
+	CustomerName string
+	Notes        string
+	Items        []OrderEventItem
+	OccurredAt   time.Time
+}
+
+// OrderEventItem is an order item with the menu data a station needs to prepare it.
+type OrderEventItem struct {
+	ProductID int
+	Name      string
+	Category  string
+	Quantity  int
+	Modifiers []string
+}
+
+// ForStation returns the event with only the items of the given menu categories.
+// A new order without such items is of no interest to the station and is skipped;
+// other events are always delivered, so the station can drop the order from its queue.
+// An empty category list keeps the event unchanged.
+func (e OrderEvent) ForStation(categories []string) (OrderEvent, bool) {
+	if len(categories) == 0 {
+		return e, true
+	}
+
+	var items []OrderEventItem
+	for _, item := range e.Items {
+		if slices.Contains(categories, item.Category) {
+			items = append(items, item)
+		}
+	}
+
+	if len(items) == 0 && e.Type == OrderEventCreated {
+		return OrderEvent{}, false
+	}
+
+	e.Items = items
+	return e, true
+}
@@ -1,20 +1,39 @@
 package model
 
+import "strings"
+
 type OrderItems struct {
 	OrderID   int
 	ProductID int
 	Quantity  int
+
+	// Price is the unit price of the product at the moment the order was placed.
+	Price float64
+
+	// Modifiers are the free-form preparation notes of the item, e.g. "oat milk".
+	// They are printed on receipts and kitchen tickets and do not change the price.
+	Modifiers []string
 }
 
+// OrderID is set by the order repository, so it is not validated here.
 func (r *OrderItems) Validate() error {
 	switch {
-	case r.OrderID <= 0:
-		return ErrNotValidOrderID
 	case r.ProductID <= 0:
-		return ErrNotValidMenuID
+		return ErrNotValidOrderProductID
 	case r.Quantity <= 0:
 		return ErrNotValidQuantity
+	case !validModifiers(r.Modifiers):
+		return ErrNotValidOrderModifier
 	default:
 		return nil
 	}
 }
+
+func validModifiers(modifiers []string) bool {
+	for _, m := range modifiers {
+		if strings.TrimSpace(m) == "" || len(m) > 50 {
+			return false
+		}
+	}
+	return true
+}
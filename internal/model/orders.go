@@ -2,26 +2,69 @@ package model
 
 import "time"
 
+const (
+	OrderStatusOpen   = "open"
+	OrderStatusClosed = "closed"
+)
+
 type Order struct {
 	ID           int
 	CustomerName string
 	Status       string
 	Notes        string
+	Items        []OrderItems
 	CreateAt     time.Time
+	ClosedAt     time.Time
+
+	// Reserved is set when the ingredients were written off as the order was placed,
+	// as batch orders do. Such an order does not consume stock again when it is closed
+	// and gives the stock back when it is cancelled.
+	Reserved bool
+
+	// Version counts the changes of the order, starting at 1. An update or a delete
+	// with a version is applied only to the order with that version.
+	Version int
+
+	// Totals are computed and stored when the order is closed.
+	// DiscountTotal is set by the client while the order is open.
+	OrderTotals
+}
+
+// OrderTotals is the receipt breakdown of an order.
+// Subtotal is the sum of the line prices as listed on the menu, so it already
+// contains inclusive taxes. Total is Subtotal - DiscountTotal + exclusive taxes.
+type OrderTotals struct {
+	Subtotal      float64
+	DiscountTotal float64
+	TaxTotal      float64
+	Total         float64
+	Taxes         []TaxLine
 }
 
 // TODO: Write inventory suffiency validation
-// TODO: Implement status and date logic
 
 func (r *Order) Validate() error {
 	switch {
-	case r.ID <= 0:
-		return ErrNotValidOrderID
 	case r.CustomerName == "":
 		return ErrNotValidOrderCustomerName
-	case r.Status == "":
+	case r.Status != OrderStatusOpen && r.Status != OrderStatusClosed:
 		return ErrNotValidOrderStatus
-	default:
-		return nil
+	case r.DiscountTotal < 0:
+		return ErrNotValidDiscount
+	case len(r.Items) == 0:
+		return ErrNotValidOrderItems
+	}
+
+	seen := make(map[int]bool, len(r.Items))
+	for _, item := range r.Items {
+		if err := item.Validate(); err != nil {
+			return err
+		}
+		if seen[item.ProductID] {
+			return ErrDuplicateOrderItems
+		}
+		seen[item.ProductID] = true
 	}
+
+	return nil
 }
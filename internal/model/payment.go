@@ -0,0 +1,122 @@
+package model
+
+import "time"
+
+const (
+	TenderCash    = "cash"
+	TenderCard    = "card"
+	TenderVoucher = "voucher"
+)
+
+// Payment is a single tender applied to an order. Refunds are stored as
+// payments with a negative Amount that point to the refunded payment.
+type Payment struct {
+	ID        int
+	OrderID   int
+	Tender    string
+	Amount    float64
+	Tip       float64
+	Tendered  float64
+	Change    float64
+	RefundOf  int
+	Reference string
+	Reason    string
+	CreatedAt time.Time
+}
+
+func (p *Payment) IsRefund() bool {
+	return p.Amount < 0
+}
+
+// Validate checks a payment received from a customer. Refund rows are built by the service.
+func (p *Payment) Validate() error {
+	switch {
+	case p.Tender != TenderCash && p.Tender != TenderCard && p.Tender != TenderVoucher:
+		return ErrNotValidTender
+	case p.Amount <= 0:
+		return ErrNotValidPaymentAmount
+	case p.Tip < 0:
+		return ErrNotValidTip
+	case p.Tendered < 0:
+		return ErrNotValidTendered
+	case p.Tender == TenderVoucher && p.Reference == "":
+		return ErrNotValidVoucher
+	default:
+		return nil
+	}
+}
+
+// PaymentBalance sums up the payments of an order.
+// Paid and Refunded do not include tips, Balance is what is still due.
+type PaymentBalance struct {
+	Due      float64
+	Paid     float64
+	Refunded float64
+	Tips     float64
+	Balance  float64
+}
+
+// Split modes
+const (
+	SplitEven  = "even"
+	SplitItems = "items"
+)
+
+// SplitRequest describes how the bill of an order is divided between guests.
+// Even splits use Parts, item splits use Groups with the items of every guest.
+type SplitRequest struct {
+	Mode   string
+	Parts  int
+	Groups [][]OrderItems
+}
+
+// SplitShare is the part of the bill paid by a single guest.
+type SplitShare struct {
+	Part   int
+	Items  []OrderItems
+	Amount float64
+}
+
+// RefundRequest describes a full or partial refund of a closed order.
+// Without Items and Amount the whole paid amount is refunded. With Items the
+// amount is their share of the order total. PaymentID limits the refund to a single payment.
+// Restock needs to know the returned items, so it is taken only with Items or
+// with a full refund of the whole order.
+type RefundRequest struct {
+	PaymentID int
+	Amount    float64
+	Items     []OrderItems
+	Restock   bool
+	Reason    string
+}
+
+func (r *RefundRequest) Validate() error {
+	switch {
+	case r.Amount < 0:
+		return ErrNotValidPaymentAmount
+	case r.Amount > 0 && len(r.Items) > 0:
+		return ErrNotValidRefund
+	case r.Reason == "":
+		return ErrNotValidRefundReason
+	case r.Restock && len(r.Items) == 0 && (r.Amount > 0 || r.PaymentID > 0):
+		return ErrNotValidRestock
+	}
+
+	for _, item := range r.Items {
+		if err := item.Validate(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// PaymentReportLine sums up the payments of a tender within a period.
+type PaymentReportLine struct {
+	Tender   string
+	Payments int
+	Amount   float64
+	Tips     float64
+	Refunds  int
+	Refunded float64
+}
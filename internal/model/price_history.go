@@ -2,10 +2,12 @@ package model
 
 import "time"
 
+// PriceHistory is a change of the price of a menu item.
 type PriceHistory struct {
 	HistoryID  int
 	MenuItemID int
-	Price      float64
+	OldPrice   float64
+	NewPrice   float64
 	ChangedAt  time.Time
 }
 
@@ -15,7 +17,7 @@ func (r *PriceHistory) Validate() error {
 		return ErrNotValidPriceHistoryID
 	case r.MenuItemID <= 0:
 		return ErrNotValidMenuID
-	case r.Price <= 0:
+	case r.OldPrice < 0 || r.NewPrice <= 0:
 		return ErrNotValidPrice
 	default:
 		return nil
@@ -0,0 +1,41 @@
+package model
+
+import "time"
+
+// Receipt formats
+const (
+	ReceiptFormatText   = "text"
+	ReceiptFormatHTML   = "html"
+	ReceiptFormatESCPOS = "escpos"
+)
+
+// Receipt collects everything printed on the receipt and the kitchen ticket of an order.
+type Receipt struct {
+	Order     Order
+	Lines     []ReceiptLine
+	Totals    OrderTotals
+	Payments  []Payment
+	Balance   PaymentBalance
+	PrintedAt time.Time
+}
+
+// ReceiptLine is an order item with the menu data needed to print it.
+type ReceiptLine struct {
+	ProductID int
+	Name      string
+	Category  string
+	Quantity  int
+	Modifiers []string
+	Price     float64
+	Amount    float64
+}
+
+// ValidReceiptFormat reports whether the receipt can be rendered in the format.
+func ValidReceiptFormat(format string) bool {
+	switch format {
+	case ReceiptFormatText, ReceiptFormatHTML, ReceiptFormatESCPOS:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,39 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Types of the records that refer to menu items and inventory items.
+const (
+	ReferenceOrderItems      = "order_items"
+	ReferenceRefundItems     = "refund_items"
+	ReferencePriceHistory    = "price_history"
+	ReferenceRecipes         = "menu_item_ingredients"
+	ReferenceInventoryLedger = "inventory_transactions"
+)
+
+// Reference counts the records of a type that refer to a record.
+type Reference struct {
+	EntityType string
+	Count      int
+}
+
+// ReferencedError is returned when a record cannot be deleted because other records
+// refer to it. It wraps ErrReferenced.
+type ReferencedError struct {
+	References []Reference
+}
+
+func (e *ReferencedError) Error() string {
+	refs := make([]string, len(e.References))
+	for i, ref := range e.References {
+		refs[i] = fmt.Sprintf("%d %s", ref.Count, ref.EntityType)
+	}
+	return ErrReferenced.Error() + ": " + strings.Join(refs, ", ")
+}
+
+func (e *ReferencedError) Unwrap() error {
+	return ErrReferenced
+}
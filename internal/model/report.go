@@ -0,0 +1,255 @@
+package model
+
+import "time"
+
+// Period is a half-open time range [From, To) used by the reports.
+type Period struct {
+	From time.Time
+	To   time.Time
+}
+
+func (p *Period) Validate() error {
+	if !p.From.Before(p.To) {
+		return ErrNotValidPeriod
+	}
+	return nil
+}
+
+// TaxReportLine is the tax collected for a category and rate within a period.
+type TaxReportLine struct {
+	Category      string
+	Rate          float64
+	Inclusive     bool
+	Orders        int
+	TaxableAmount float64
+	TaxAmount     float64
+}
+
+// Sales report groupings
+const (
+	GroupByHour  = "hour"
+	GroupByDay   = "day"
+	GroupByWeek  = "week"
+	GroupByMonth = "month"
+)
+
+// Order status filters of the sales report
+const (
+	SalesStatusClosed = OrderStatusClosed
+	SalesStatusOpen   = OrderStatusOpen
+	SalesStatusAll    = "all"
+)
+
+// SalesQuery selects the orders of the sales report and how they are grouped.
+// Buckets start at the boundaries of the hour, day, week or month in Location.
+type SalesQuery struct {
+	Period
+	GroupBy  string
+	Status   string
+	Location *time.Location
+}
+
+func (q *SalesQuery) Validate() error {
+	if err := q.Period.Validate(); err != nil {
+		return err
+	}
+
+	switch q.GroupBy {
+	case GroupByHour, GroupByDay, GroupByWeek, GroupByMonth:
+	default:
+		return ErrNotValidGroupBy
+	}
+
+	switch q.Status {
+	case SalesStatusClosed, SalesStatusOpen, SalesStatusAll:
+	default:
+		return ErrNotValidOrderStatus
+	}
+
+	return nil
+}
+
+// Statuses returns the order statuses selected by the status filter.
+func (q *SalesQuery) Statuses() []string {
+	if q.Status == SalesStatusAll {
+		return []string{OrderStatusOpen, OrderStatusClosed}
+	}
+	return []string{q.Status}
+}
+
+// SalesBucket sums up the orders sold within a bucket of the sales report.
+// Revenue is the price of the items less the discount, without exclusive taxes.
+// Refunds are the refunds made within the bucket on the same basis, and NetRevenue
+// is the revenue less the refunds.
+type SalesBucket struct {
+	Start         time.Time
+	Orders        int
+	Revenue       float64
+	Refunds       float64
+	NetRevenue    float64
+	Items         int
+	AverageTicket float64
+}
+
+// Popular items rankings
+const (
+	RankByQuantity = "quantity"
+	RankByRevenue  = "revenue"
+)
+
+// MaxPopularItems limits the number of items of the popular items report.
+const MaxPopularItems = 100
+
+// PopularItemsQuery selects the closed orders of the popular items report and how
+// the items are ranked. An empty Category includes every category.
+type PopularItemsQuery struct {
+	Period
+	Limit    int
+	RankBy   string
+	Category string
+}
+
+func (q *PopularItemsQuery) Validate() error {
+	if err := q.Period.Validate(); err != nil {
+		return err
+	}
+
+	if q.Limit <= 0 || q.Limit > MaxPopularItems {
+		return ErrNotValidLimit
+	}
+
+	switch q.RankBy {
+	case RankByQuantity, RankByRevenue:
+		return nil
+	default:
+		return ErrNotValidRankBy
+	}
+}
+
+// Previous returns the period of the same length right before the query period.
+func (q *PopularItemsQuery) Previous() Period {
+	return Period{From: q.From.Add(-q.To.Sub(q.From)), To: q.From}
+}
+
+// PopularItem is the sales of a menu item within the period and the previous period
+// of the same length. Revenue is defined as in SalesBucket, less the share of the
+// item in the refunds of its orders. Share is the part of the ranked measure of all
+// items, in percent.
+// Trend is the change of the ranked measure against the previous period, in percent;
+// it is nil if the item was not sold in the previous period.
+type PopularItem struct {
+	ProductID        int
+	Name             string
+	Category         string
+	Quantity         int
+	Revenue          float64
+	Share            float64
+	PreviousQuantity int
+	PreviousRevenue  float64
+	Trend            *float64
+}
+
+// Sources of the ingredient usage report
+const (
+	UsageSourceLedger = "ledger"
+	UsageSourceOrders = "orders"
+)
+
+// IngredientUsageQuery selects how the ingredient usage is found. The ledger source
+// sums the net stock written off for orders, the orders source recomputes it from
+// the items of the closed orders and the current recipes.
+type IngredientUsageQuery struct {
+	Period
+	Source   string
+	Location *time.Location
+}
+
+func (q *IngredientUsageQuery) Validate() error {
+	if err := q.Period.Validate(); err != nil {
+		return err
+	}
+
+	switch q.Source {
+	case UsageSourceLedger, UsageSourceOrders:
+		return nil
+	default:
+		return ErrNotValidUsageSource
+	}
+}
+
+// IngredientDailyUsage is the usage of an ingredient within a day of the shop calendar.
+type IngredientDailyUsage struct {
+	IngredientUsage
+	Day time.Time
+}
+
+// IngredientUsageSummary is the usage of an ingredient within a period and per day.
+type IngredientUsageSummary struct {
+	IngredientUsage
+	Days []DailyUsage
+}
+
+type DailyUsage struct {
+	Day      time.Time
+	Quantity int
+}
+
+// ForecastQuery configures the inventory forecast. The daily usage is the moving
+// average over the last Window complete days. A reorder is suggested when the stock
+// does not last LeadTime days for the delivery plus Cover days after it.
+type ForecastQuery struct {
+	Window   int
+	LeadTime int
+	Cover    int
+}
+
+func (q *ForecastQuery) Validate() error {
+	switch {
+	case q.Window <= 0 || q.Window > 365:
+		return ErrNotValidForecast
+	case q.LeadTime < 0 || q.LeadTime > 365:
+		return ErrNotValidForecast
+	case q.Cover < 0 || q.Cover > 365:
+		return ErrNotValidForecast
+	default:
+		return nil
+	}
+}
+
+// InventoryForecast projects when an ingredient runs out. DaysUntilStockout and
+// StockoutDate are nil if the ingredient was not used within the window.
+type InventoryForecast struct {
+	IngredientID      int
+	Name              string
+	Unit              string
+	Quantity          int
+	AverageDailyUsage float64
+	DaysUntilStockout *float64
+	StockoutDate      *time.Time
+	ReorderQuantity   int
+}
+
+// HeatmapCell sums up the orders placed within an hour of a weekday of the shop calendar.
+// AverageOrders is the number of orders on an average such hour within the period.
+// Revenue is the revenue of the closed orders as in SalesBucket, less their refunds.
+// The preparation time of an order is the time between its opening and closing.
+type HeatmapCell struct {
+	Weekday         time.Weekday
+	Hour            int
+	Orders          int
+	Revenue         float64
+	AverageOrders   float64
+	PrepOrders      int
+	PrepTime        time.Duration
+	AveragePrepTime time.Duration
+}
+
+// Heatmap is the weekday × hour grid of the orders placed within a period,
+// starting on Monday at midnight.
+type Heatmap struct {
+	Period
+	Cells           []HeatmapCell
+	Orders          int
+	Revenue         float64
+	AveragePrepTime time.Duration
+}
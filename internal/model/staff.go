@@ -0,0 +1,76 @@
+package model
+
+import (
+	"time"
+	"unicode/utf8"
+)
+
+// Roles of the staff, from the least to the most privileged. A role is allowed
+// everything the roles before it are allowed.
+const (
+	RoleBarista = "barista"
+	RoleManager = "manager"
+	RoleAdmin   = "admin"
+)
+
+var roleRanks = map[string]int{
+	RoleBarista: 1,
+	RoleManager: 2,
+	RoleAdmin:   3,
+}
+
+// MinPasswordLength is the minimal length of a staff password in characters.
+const MinPasswordLength = 8
+
+// Staff is a member of the staff who can sign in to the shop. Passwords and API keys
+// are stored as hashes only; a member has a password, an API key or both.
+type Staff struct {
+	ID           int
+	Username     string
+	Role         string
+	PasswordHash string
+	APIKeyHash   string
+	CreatedAt    time.Time
+}
+
+func (s *Staff) Validate() error {
+	switch {
+	case s.Username == "" || utf8.RuneCountInString(s.Username) > 50:
+		return ErrNotValidUsername
+	case !ValidRole(s.Role):
+		return ErrNotValidRole
+	default:
+		return nil
+	}
+}
+
+// Principal returns the principal of the staff member.
+func (s *Staff) Principal() Principal {
+	return Principal{StaffID: s.ID, Username: s.Username, Role: s.Role}
+}
+
+// ValidRole reports whether role is one of the staff roles.
+func ValidRole(role string) bool {
+	_, ok := roleRanks[role]
+	return ok
+}
+
+// Principal is the authenticated staff member on whose behalf a request is made.
+type Principal struct {
+	StaffID  int
+	Username string
+	Role     string
+}
+
+// HasRole reports whether the principal has the role or a more privileged one.
+func (p Principal) HasRole(role string) bool {
+	rank, ok := roleRanks[p.Role]
+	return ok && rank >= roleRanks[role]
+}
+
+// Token is a signed access token issued at sign in.
+type Token struct {
+	Value     string
+	ExpiresAt time.Time
+	Principal Principal
+}
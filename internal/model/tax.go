@@ -0,0 +1,33 @@
+package model
+
+// DefaultTaxCategory is the tax rate applied to categories without their own rate.
+const DefaultTaxCategory = "default"
+
+// TaxRate is the tax configured for a menu category.
+// Inclusive rates are already contained in the menu price, exclusive rates are added on top of it.
+type TaxRate struct {
+	Category  string
+	Rate      float64
+	Inclusive bool
+}
+
+func (r *TaxRate) Validate() error {
+	switch {
+	case r.Category == "":
+		return ErrNotValidTaxCategory
+	case r.Rate < 0 || r.Rate >= 1:
+		return ErrNotValidTaxRate
+	default:
+		return nil
+	}
+}
+
+// TaxLine is the tax charged on an order for a single category.
+type TaxLine struct {
+	OrderID       int
+	Category      string
+	Rate          float64
+	Inclusive     bool
+	TaxableAmount float64
+	TaxAmount     float64
+}
@@ -0,0 +1,102 @@
+package receipt
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ESC/POS commands
+const (
+	escInit       = "\x1b@"     // ESC @: reset the printer
+	escBoldOn     = "\x1bE\x01" // ESC E 1
+	escBoldOff    = "\x1bE\x00" // ESC E 0
+	escDoubleOn   = "\x1d!\x01" // GS ! 1: double height, the line width is kept
+	escDoubleOff  = "\x1d!\x00" // GS ! 0
+	escFeed       = "\x1bd\x04" // ESC d 4: feed four lines
+	escCut        = "\x1dV\x01" // GS V 1: partial cut
+	dateTimeFmt   = "2006-01-02 15:04"
+	replacingChar = '?'
+)
+
+// textFuncs returns the functions of the text templates. With escpos set, bold and
+// big emit ESC/POS commands; otherwise they return the text unchanged.
+func textFuncs(width int, escpos bool) map[string]any {
+	funcs := commonFuncs()
+
+	funcs["width"] = func() int { return width }
+	funcs["line"] = func() string { return strings.Repeat("-", width) }
+	funcs["center"] = func(s string) string { return center(s, width) }
+	funcs["cols"] = func(left, right string) string { return columns(left, right, width) }
+	funcs["bold"] = func(s string) string {
+		if escpos {
+			return escBoldOn + s + escBoldOff
+		}
+		return s
+	}
+	funcs["big"] = func(s string) string {
+		if escpos {
+			return escDoubleOn + s + escDoubleOff
+		}
+		return s
+	}
+
+	return funcs
+}
+
+func htmlFuncs() map[string]any {
+	return commonFuncs()
+}
+
+func commonFuncs() map[string]any {
+	return map[string]any{
+		"money":    func(amount float64) string { return fmt.Sprintf("%.2f", amount) },
+		"neg":      func(amount float64) float64 { return -amount },
+		"percent":  func(rate float64) string { return fmt.Sprintf("%g%%", rate*100) },
+		"datetime": func(t time.Time) string { return t.Format(dateTimeFmt) },
+		"date":     func(t time.Time) string { return t.Format(time.DateOnly) },
+		"join":     strings.Join,
+		"upper":    strings.ToUpper,
+	}
+}
+
+// center pads s with spaces so that it is centered on a line of the given width.
+func center(s string, width int) string {
+	n := utf8.RuneCountInString(s)
+	if n >= width {
+		return s
+	}
+	return strings.Repeat(" ", (width-n)/2) + s
+}
+
+// columns aligns left to the start and right to the end of the line.
+// The left text is cut if both do not fit.
+func columns(left, right string, width int) string {
+	space := width - utf8.RuneCountInString(right) - 1
+	if space < 1 {
+		return left + " " + right
+	}
+
+	runes := []rune(left)
+	if len(runes) > space {
+		runes = runes[:space]
+	}
+
+	return string(runes) + strings.Repeat(" ", width-len(runes)-utf8.RuneCountInString(right)) + right
+}
+
+// toASCII replaces the characters that a printer in its default code page cannot print.
+func toASCII(b []byte) []byte {
+	res := make([]byte, 0, len(b))
+	for len(b) > 0 {
+		r, size := utf8.DecodeRune(b)
+		if r < utf8.RuneSelf {
+			res = append(res, byte(r))
+		} else {
+			res = append(res, replacingChar)
+		}
+		b = b[size:]
+	}
+	return res
+}
@@ -0,0 +1,196 @@
+// Package receipt renders order receipts, kitchen tickets and end-of-day reports
+// as plain text, HTML or raw ESC/POS bytes for thermal printers.
+package receipt
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	htmltemplate "html/template"
+	"io"
+	"os"
+	texttemplate "text/template"
+
+	"coffee-shop/internal/model"
+)
+
+// Content types of the rendered formats
+const (
+	ContentTypeText   = "text/plain; charset=utf-8"
+	ContentTypeHTML   = "text/html; charset=utf-8"
+	ContentTypeESCPOS = "application/octet-stream"
+)
+
+const defaultWidth = 42
+
+var ErrUnknownFormat = errors.New("unknown receipt format")
+
+//go:embed templates
+var defaultTemplates embed.FS
+
+// Shop is the header and footer printed on every receipt.
+type Shop struct {
+	Name    string
+	Address string
+	Phone   string
+	TaxID   string
+	Footer  string
+}
+
+// Config configures the renderer. Template fields hold paths to template files;
+// the built-in templates are used for the empty ones. Text templates are used for
+// both the text and the ESC/POS formats.
+type Config struct {
+	Shop  Shop
+	Width int // characters per line; 42 fits 80 mm paper
+
+	ReceiptTemplate     string
+	ReceiptHTMLTemplate string
+	TicketTemplate      string
+	TicketHTMLTemplate  string
+	ClosingTemplate     string
+	ClosingHTMLTemplate string
+}
+
+// document is a receipt or ticket template parsed for every format.
+type document struct {
+	text   *texttemplate.Template
+	escpos *texttemplate.Template
+	html   *htmltemplate.Template
+}
+
+type Renderer struct {
+	shop    Shop
+	receipt document
+	ticket  document
+	closing document
+}
+
+// view is the data passed to the receipt and ticket templates.
+type view struct {
+	model.Receipt
+	Shop Shop
+}
+
+// closingView is the data passed to the end-of-day templates.
+type closingView struct {
+	model.DailyClosing
+	Shop Shop
+}
+
+func New(cfg Config) (*Renderer, error) {
+	if cfg.Width <= 0 {
+		cfg.Width = defaultWidth
+	}
+
+	receipt, err := parseDocument(cfg.Width, "receipt", cfg.ReceiptTemplate, cfg.ReceiptHTMLTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	ticket, err := parseDocument(cfg.Width, "ticket", cfg.TicketTemplate, cfg.TicketHTMLTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	closing, err := parseDocument(cfg.Width, "closing", cfg.ClosingTemplate, cfg.ClosingHTMLTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Renderer{shop: cfg.Shop, receipt: receipt, ticket: ticket, closing: closing}, nil
+}
+
+// Receipt renders the customer receipt of the order and returns it with its content type.
+func (r *Renderer) Receipt(format string, receipt model.Receipt) ([]byte, string, error) {
+	return r.render(r.receipt, format, view{Receipt: receipt, Shop: r.shop})
+}
+
+// Ticket renders the kitchen ticket of the order and returns it with its content type.
+func (r *Renderer) Ticket(format string, receipt model.Receipt) ([]byte, string, error) {
+	return r.render(r.ticket, format, view{Receipt: receipt, Shop: r.shop})
+}
+
+// Closing renders the end-of-day report and returns it with its content type.
+func (r *Renderer) Closing(format string, closing model.DailyClosing) ([]byte, string, error) {
+	return r.render(r.closing, format, closingView{DailyClosing: closing, Shop: r.shop})
+}
+
+func (r *Renderer) render(doc document, format string, data any) ([]byte, string, error) {
+	var buf bytes.Buffer
+	switch format {
+	case model.ReceiptFormatText:
+		if err := doc.text.Execute(&buf, data); err != nil {
+			return nil, "", err
+		}
+		return buf.Bytes(), ContentTypeText, nil
+	case model.ReceiptFormatHTML:
+		if err := doc.html.Execute(&buf, data); err != nil {
+			return nil, "", err
+		}
+		return buf.Bytes(), ContentTypeHTML, nil
+	case model.ReceiptFormatESCPOS:
+		buf.WriteString(escInit)
+		if err := doc.escpos.Execute(&buf, data); err != nil {
+			return nil, "", err
+		}
+		buf.WriteString(escFeed + escCut)
+		return toASCII(buf.Bytes()), ContentTypeESCPOS, nil
+	default:
+		return nil, "", ErrUnknownFormat
+	}
+}
+
+func parseDocument(width int, name, textPath, htmlPath string) (document, error) {
+	textSrc, err := readTemplate(textPath, "templates/"+name+".txt.tmpl")
+	if err != nil {
+		return document{}, err
+	}
+
+	htmlSrc, err := readTemplate(htmlPath, "templates/"+name+".html.tmpl")
+	if err != nil {
+		return document{}, err
+	}
+
+	var doc document
+	doc.text, err = texttemplate.New(name).Funcs(textFuncs(width, false)).Parse(textSrc)
+	if err != nil {
+		return document{}, err
+	}
+
+	doc.escpos, err = texttemplate.New(name).Funcs(textFuncs(width, true)).Parse(textSrc)
+	if err != nil {
+		return document{}, err
+	}
+
+	doc.html, err = htmltemplate.New(name).Funcs(htmlFuncs()).Parse(htmlSrc)
+	if err != nil {
+		return document{}, err
+	}
+
+	return doc, nil
+}
+
+// readTemplate reads the template file at path, or the built-in template if path is empty.
+func readTemplate(path, builtin string) (string, error) {
+	var (
+		f   io.ReadCloser
+		err error
+	)
+	if path == "" {
+		f, err = defaultTemplates.Open(builtin)
+	} else {
+		f, err = os.Open(path)
+	}
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	src, err := io.ReadAll(f)
+	if err != nil {
+		return "", err
+	}
+
+	return string(src), nil
+}
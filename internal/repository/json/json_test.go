@@ -0,0 +1,104 @@
+package json_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"coffee-shop/internal/model"
+	"coffee-shop/internal/repository/json"
+	"coffee-shop/internal/repository/memory"
+	"coffee-shop/internal/repository/repotest"
+)
+
+func TestContract(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) repotest.Repos {
+		return repotest.Memory(open(t, t.TempDir()))
+	})
+}
+
+// TestSharedFile checks that two DBs on the same directory, as two processes
+// would have, see the changes of each other and keep assigning unique IDs.
+func TestSharedFile(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	first := memory.NewMenu(open(t, dir))
+	second := memory.NewMenu(open(t, dir))
+
+	latte := model.MenuItem{Name: "latte", Description: "milk coffee", Category: "drinks", Price: 3.5}
+	firstID, err := first.Create(ctx, latte)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	secondID, err := second.Create(ctx, latte)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if secondID == firstID {
+		t.Fatalf("both DBs assigned ID %d", firstID)
+	}
+
+	items, err := first.GetAll(ctx)
+	if err != nil {
+		t.Fatalf("GetAll: %v", err)
+	}
+	if len(items) != 2 {
+		t.Errorf("GetAll returned %d items, want the items of both DBs", len(items))
+	}
+}
+
+// TestReopen checks that the records survive a restart and that a failed
+// transaction leaves the file as it was.
+func TestReopen(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	db := open(t, dir)
+
+	id, err := memory.NewMenu(db).Create(ctx, model.MenuItem{Name: "latte", Description: "milk coffee", Category: "drinks", Price: 3.5})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	before, err := os.ReadFile(filepath.Join(dir, "store.json"))
+	if err != nil {
+		t.Fatalf("read store: %v", err)
+	}
+
+	err = db.WithinTx(ctx, func(ctx context.Context) error {
+		if err := memory.NewMenu(db).Delete(ctx, id, 0); err != nil {
+			return err
+		}
+		return model.ErrReferenced
+	})
+	if err == nil {
+		t.Fatalf("WithinTx succeeded")
+	}
+	after, _ := os.ReadFile(filepath.Join(dir, "store.json"))
+	if string(after) != string(before) {
+		t.Errorf("failed transaction changed the store")
+	}
+
+	item, err := memory.NewMenu(open(t, dir)).Get(ctx, id)
+	if err != nil {
+		t.Fatalf("Get after reopen: %v", err)
+	}
+	if item.Name != "latte" || item.Price != 3.5 {
+		t.Errorf("Get after reopen = %+v", item)
+	}
+
+	entries, _ := os.ReadDir(dir)
+	for _, entry := range entries {
+		if entry.Name() != "store.json" && entry.Name() != "store.lock" {
+			t.Errorf("temporary file %s was left behind", entry.Name())
+		}
+	}
+}
+
+func open(t *testing.T, dir string) *memory.DB {
+	t.Helper()
+	db, err := json.Open(dir)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	return db
+}
@@ -0,0 +1,15 @@
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd || windows)
+
+package json
+
+import "os"
+
+// lock does nothing on platforms without file locks. The records are still
+// replaced atomically, but only one process should use the data directory.
+func lock(file *os.File, write bool) error {
+	return nil
+}
+
+func unlock(file *os.File) error {
+	return nil
+}
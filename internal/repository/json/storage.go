@@ -0,0 +1,159 @@
+// Package json keeps the records of all repositories in a JSON file in the data
+// directory, so that the shop can run without a database. The repositories are
+// those of the memory package: the file is locked while it is read or written,
+// and every change replaces it as a whole.
+package json
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"coffee-shop/internal/repository/memory"
+)
+
+const (
+	storeFile = "store.json"
+	lockFile  = "store.lock"
+)
+
+// Storage keeps the records of a memory.DB in the store.json file. Several
+// processes may share the file: store.lock serializes their access to it.
+type Storage struct {
+	path     string
+	lockPath string
+	lock     *os.File
+
+	// loaded is set when the records in memory match the file described by stat.
+	// A nil stat means that there is no file yet.
+	loaded bool
+	stat   os.FileInfo
+}
+
+func NewStorage(dir string) *Storage {
+	return &Storage{
+		path:     filepath.Join(dir, storeFile),
+		lockPath: filepath.Join(dir, lockFile),
+	}
+}
+
+// Open returns a DB that keeps its records in the data directory, creating the
+// directory if needed. The repositories of the memory package work on the DB.
+func Open(dir string) (*memory.DB, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create the data directory: %w", err)
+	}
+
+	return memory.Open(NewStorage(dir))
+}
+
+// Lock locks the lock file, exclusively when the records are about to be changed.
+func (s *Storage) Lock(write bool) error {
+	file, err := os.OpenFile(s.lockPath, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
+	}
+
+	if err := lock(file, write); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to lock %s: %w", s.lockPath, err)
+	}
+
+	s.lock = file
+	return nil
+}
+
+func (s *Storage) Unlock() error {
+	if s.lock == nil {
+		return nil
+	}
+
+	err := unlock(s.lock)
+	if closeErr := s.lock.Close(); err == nil {
+		err = closeErr
+	}
+	s.lock = nil
+	return err
+}
+
+// Load reads the records if the file was replaced since it was last loaded or
+// saved, and returns nil otherwise. A missing file holds no records.
+func (s *Storage) Load() (*memory.Data, error) {
+	file, err := os.Open(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		if s.loaded && s.stat == nil {
+			return nil, nil
+		}
+		s.loaded, s.stat = true, nil
+		return &memory.Data{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	stat, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if s.loaded && s.unchanged(stat) {
+		return nil, nil
+	}
+
+	var data memory.Data
+	if err := json.NewDecoder(file).Decode(&data); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", s.path, err)
+	}
+
+	s.loaded, s.stat = true, stat
+	return &data, nil
+}
+
+// Save writes the records to a temporary file in the data directory and renames
+// it over the store file, so that readers never see a partly written file.
+func (s *Storage) Save(data *memory.Data) error {
+	s.loaded = false
+
+	content, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	temp, err := os.CreateTemp(filepath.Dir(s.path), ".store-*.json")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+
+	_, err = temp.Write(content)
+	if err == nil {
+		err = temp.Chmod(0o644)
+	}
+	if err == nil {
+		err = temp.Sync()
+	}
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", temp.Name(), err)
+	}
+
+	if err := os.Rename(temp.Name(), s.path); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", s.path, err)
+	}
+
+	if stat, err := os.Stat(s.path); err == nil {
+		s.loaded, s.stat = true, stat
+	}
+	return nil
+}
+
+// unchanged reports whether stat describes the file the records were last loaded from or saved to.
+func (s *Storage) unchanged(stat os.FileInfo) bool {
+	return s.stat != nil && os.SameFile(s.stat, stat) &&
+		s.stat.ModTime().Equal(stat.ModTime()) && s.stat.Size() == stat.Size()
+}
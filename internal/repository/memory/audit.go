@@ -0,0 +1,51 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"coffee-shop/internal/model"
+)
+
+type Audit struct {
+	db *DB
+}
+
+func NewAudit(db *DB) *Audit {
+	return &Audit{db: db}
+}
+
+// Create appends the entry to the audit log and returns its generated ID.
+func (r *Audit) Create(ctx context.Context, entry model.AuditEntry) (int, error) {
+	err := r.db.update(ctx, func(d *Data) error {
+		d.Sequences.Audit++
+		entry.ID = d.Sequences.Audit
+		entry.CreatedAt = time.Now()
+		d.Audit = append(d.Audit, entry)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return entry.ID, nil
+}
+
+// Find returns the entries selected by the query, newest first.
+func (r *Audit) Find(ctx context.Context, q model.AuditQuery) ([]model.AuditEntry, error) {
+	var entries []model.AuditEntry
+	err := r.db.view(ctx, func(d *Data) error {
+		for i := len(d.Audit) - 1; i >= 0 && len(entries) < q.Limit; i-- {
+			e := d.Audit[i]
+			switch {
+			case q.EntityType != "" && e.EntityType != q.EntityType,
+				q.EntityID > 0 && e.EntityID != q.EntityID,
+				!q.Period.From.IsZero() && e.CreatedAt.Before(q.Period.From),
+				!q.Period.To.IsZero() && !e.CreatedAt.Before(q.Period.To):
+				continue
+			}
+			entries = append(entries, e)
+		}
+		return nil
+	})
+	return entries, err
+}
@@ -0,0 +1,103 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"coffee-shop/internal/model"
+)
+
+type DailyClosing struct {
+	db *DB
+}
+
+func NewDailyClosing(db *DB) *DailyClosing {
+	return &DailyClosing{db: db}
+}
+
+// Create stores the closing and returns its generated ID. A business date can be closed only once.
+func (r *DailyClosing) Create(ctx context.Context, closing model.DailyClosing) (int, error) {
+	err := r.db.update(ctx, func(d *Data) error {
+		if slices.ContainsFunc(d.Closings, func(c model.DailyClosing) bool {
+			return sameDate(c.BusinessDate, closing.BusinessDate)
+		}) {
+			return model.ErrDuplicate
+		}
+
+		d.Sequences.Closings++
+		closing.ID = d.Sequences.Closings
+		closing.CreatedAt = time.Now()
+		d.Closings = append(d.Closings, closing)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return closing.ID, nil
+}
+
+// Get returns the closing of the business date.
+func (r *DailyClosing) Get(ctx context.Context, date time.Time) (model.DailyClosing, error) {
+	var closing model.DailyClosing
+	err := r.db.view(ctx, func(d *Data) error {
+		i := slices.IndexFunc(d.Closings, func(c model.DailyClosing) bool {
+			return sameDate(c.BusinessDate, date)
+		})
+		if i < 0 {
+			return model.ErrNotFound
+		}
+		closing = d.Closings[i]
+		return nil
+	})
+	return closing, err
+}
+
+// GetAll returns the closings, the latest first.
+func (r *DailyClosing) GetAll(ctx context.Context) ([]model.DailyClosing, error) {
+	var closings []model.DailyClosing
+	err := r.db.view(ctx, func(d *Data) error {
+		closings = append(closings, d.Closings...)
+		return nil
+	})
+	slices.SortStableFunc(closings, func(a, b model.DailyClosing) int {
+		return b.BusinessDate.Compare(a.BusinessDate)
+	})
+	return closings, err
+}
+
+// Last returns the closing of the latest business date, or model.ErrNotFound if no day is closed yet.
+func (r *DailyClosing) Last(ctx context.Context) (model.DailyClosing, error) {
+	closings, err := r.GetAll(ctx)
+	if err != nil {
+		return model.DailyClosing{}, err
+	}
+	if len(closings) == 0 {
+		return model.DailyClosing{}, model.ErrNotFound
+	}
+	return closings[0], nil
+}
+
+// OrderLocked reports whether the order was closed within the period of a closing.
+func (r *DailyClosing) OrderLocked(ctx context.Context, orderID int) (bool, error) {
+	var locked bool
+	err := r.db.view(ctx, func(d *Data) error {
+		i, ok := find(d.Orders, orderID, orderKey)
+		if !ok || d.Orders[i].Status != model.OrderStatusClosed {
+			return nil
+		}
+
+		for _, closing := range d.Closings {
+			if d.Orders[i].ClosedAt.Before(closing.To) {
+				locked = true
+				return nil
+			}
+		}
+		return nil
+	})
+	return locked, err
+}
+
+func sameDate(a, b time.Time) bool {
+	return a.Format(time.DateOnly) == b.Format(time.DateOnly)
+}
@@ -0,0 +1,279 @@
+// Package memory keeps the records of all repositories in memory. On its own it
+// serves tests and demos; with a Storage it is the base of file-backed repositories.
+package memory
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"coffee-shop/internal/model"
+)
+
+// Data holds the records of all repositories. Records of every table are kept
+// in the order of their IDs; tables without IDs keep the insertion order.
+// Records are replaced as a whole, and the slices they hold are never changed in place.
+type Data struct {
+	Inventory          []model.Inventory
+	InventoryLedger    []model.InventoryTransactions
+	Menu               []model.MenuItem
+	MenuIngredients    []model.MenuItemIngredients
+	PriceHistory       []model.PriceHistory
+	Orders             []model.Order
+	OrderItems         []model.OrderItems
+	OrderStatusHistory []model.OrderStatusHistory
+	OrderConsumption   []model.OrderConsumption
+	TaxRates           []model.TaxRate
+	OrderTaxes         []model.TaxLine
+	Payments           []model.Payment
+	RefundItems        []RefundItem
+	Closings           []model.DailyClosing
+	Staff              []model.Staff
+	Audit              []model.AuditEntry
+	IdempotencyKeys    []model.IdempotencyRecord
+
+	// Sequences hold the last ID assigned to each table.
+	Sequences Sequences
+}
+
+// RefundItem is an item returned by the refund with the given payment ID.
+type RefundItem struct {
+	PaymentID int
+	ProductID int
+	Quantity  int
+}
+
+type Sequences struct {
+	Inventory          int
+	InventoryLedger    int
+	Menu               int
+	PriceHistory       int
+	Orders             int
+	OrderStatusHistory int
+	Payments           int
+	Closings           int
+	Staff              int
+	Audit              int
+}
+
+// clone copies the tables, so that a transaction can be rolled back.
+func (d *Data) clone() *Data {
+	return &Data{
+		Inventory:          slices.Clone(d.Inventory),
+		InventoryLedger:    slices.Clone(d.InventoryLedger),
+		Menu:               slices.Clone(d.Menu),
+		MenuIngredients:    slices.Clone(d.MenuIngredients),
+		PriceHistory:       slices.Clone(d.PriceHistory),
+		Orders:             slices.Clone(d.Orders),
+		OrderItems:         slices.Clone(d.OrderItems),
+		OrderStatusHistory: slices.Clone(d.OrderStatusHistory),
+		OrderConsumption:   slices.Clone(d.OrderConsumption),
+		TaxRates:           slices.Clone(d.TaxRates),
+		OrderTaxes:         slices.Clone(d.OrderTaxes),
+		Payments:           slices.Clone(d.Payments),
+		RefundItems:        slices.Clone(d.RefundItems),
+		Closings:           slices.Clone(d.Closings),
+		Staff:              slices.Clone(d.Staff),
+		Audit:              slices.Clone(d.Audit),
+		IdempotencyKeys:    slices.Clone(d.IdempotencyKeys),
+		Sequences:          d.Sequences,
+	}
+}
+
+// versions sets the version of records stored before records had versions to 1.
+func (d *Data) versions() {
+	for i := range d.Inventory {
+		d.Inventory[i].Version = max(d.Inventory[i].Version, 1)
+	}
+	for i := range d.Menu {
+		d.Menu[i].Version = max(d.Menu[i].Version, 1)
+	}
+	for i := range d.Orders {
+		d.Orders[i].Version = max(d.Orders[i].Version, 1)
+	}
+}
+
+// checkVersion returns model.ErrVersionMismatch if version is set and differs from
+// the version of the stored record.
+func checkVersion(stored, version int) error {
+	if version != 0 && version != stored {
+		return model.ErrVersionMismatch
+	}
+	return nil
+}
+
+// Storage persists the records of a DB, e.g. in a file shared by several processes.
+type Storage interface {
+	// Lock locks the stored records, exclusively when they are about to be changed.
+	Lock(write bool) error
+	Unlock() error
+	// Load returns the stored records if they changed since they were last loaded
+	// or saved, and nil otherwise. After a failed Save it returns the stored records.
+	Load() (*Data, error)
+	Save(data *Data) error
+}
+
+type txKey struct{}
+
+// DB holds the records of all repositories. It is safe for concurrent use: every
+// repository call holds its lock, and a transaction holds it until it ends.
+type DB struct {
+	mu      sync.Mutex
+	data    *Data
+	storage Storage
+}
+
+// NewDB creates an empty DB that keeps its records in memory only.
+func NewDB() *DB {
+	return &DB{data: &Data{}}
+}
+
+// Open creates a DB that keeps its records in the storage.
+func Open(storage Storage) (*DB, error) {
+	db := &DB{data: &Data{}, storage: storage}
+	if err := db.begin(false); err != nil {
+		return nil, err
+	}
+	if err := db.end(false); err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+// WithinTx runs fn holding the DB lock. Repository calls made with the context passed
+// to fn take part in the transaction: their changes are saved if fn returns nil and
+// discarded otherwise, also when fn panics or calls runtime.Goexit, which releases
+// the lock as well. Nested calls reuse the transaction that is already in the context.
+func (db *DB) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if db.inTx(ctx) {
+		return fn(ctx)
+	}
+
+	if err := db.begin(true); err != nil {
+		return err
+	}
+
+	snapshot := db.data.clone()
+	committed := false
+	defer func() {
+		if !committed {
+			db.data = snapshot
+			_ = db.end(false)
+		}
+	}()
+
+	if err := fn(context.WithValue(ctx, txKey{}, db)); err != nil {
+		return err
+	}
+
+	committed = true
+	return db.end(true)
+}
+
+// view runs fn with the records for reading.
+func (db *DB) view(ctx context.Context, fn func(d *Data) error) error {
+	if db.inTx(ctx) {
+		return fn(db.data)
+	}
+
+	if err := db.begin(false); err != nil {
+		return err
+	}
+	err := fn(db.data)
+	if endErr := db.end(false); err == nil {
+		err = endErr
+	}
+	return err
+}
+
+// update runs fn with the records for changing them. Outside of a transaction the
+// changes are saved when fn returns nil, so fn must not leave partial changes behind
+// when it fails.
+func (db *DB) update(ctx context.Context, fn func(d *Data) error) error {
+	if db.inTx(ctx) {
+		return fn(db.data)
+	}
+
+	if err := db.begin(true); err != nil {
+		return err
+	}
+	if err := fn(db.data); err != nil {
+		_ = db.end(false)
+		return err
+	}
+	return db.end(true)
+}
+
+func (db *DB) inTx(ctx context.Context) bool {
+	tx, _ := ctx.Value(txKey{}).(*DB)
+	return tx == db
+}
+
+// begin takes the DB lock and the storage lock, and loads the stored records if
+// another process changed them.
+func (db *DB) begin(write bool) error {
+	db.mu.Lock()
+	if db.storage == nil {
+		return nil
+	}
+
+	if err := db.storage.Lock(write); err != nil {
+		db.mu.Unlock()
+		return err
+	}
+
+	data, err := db.storage.Load()
+	if err != nil {
+		_ = db.storage.Unlock()
+		db.mu.Unlock()
+		return err
+	}
+	if data != nil {
+		data.versions()
+		db.data = data
+	}
+
+	return nil
+}
+
+// end saves the records if save is set and releases the locks taken by begin.
+func (db *DB) end(save bool) error {
+	defer db.mu.Unlock()
+	if db.storage == nil {
+		return nil
+	}
+
+	var err error
+	if save {
+		err = db.storage.Save(db.data)
+	}
+	if unlockErr := db.storage.Unlock(); err == nil {
+		err = unlockErr
+	}
+	return err
+}
+
+// find returns the index of the record with the given ID in a table kept in the order of IDs.
+func find[T any](records []T, id int, key func(T) int) (int, bool) {
+	return slices.BinarySearchFunc(records, id, func(r T, id int) int {
+		return key(r) - id
+	})
+}
+
+// count returns the number of records for which match reports true.
+func count[T any](records []T, match func(T) bool) int {
+	n := 0
+	for _, r := range records {
+		if match(r) {
+			n++
+		}
+	}
+	return n
+}
+
+// references returns the references that count any records.
+func references(refs ...model.Reference) []model.Reference {
+	return slices.DeleteFunc(refs, func(ref model.Reference) bool {
+		return ref.Count == 0
+	})
+}
@@ -0,0 +1,71 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"coffee-shop/internal/model"
+)
+
+type IdempotencyKeys struct {
+	db *DB
+}
+
+func NewIdempotencyKeys(db *DB) *IdempotencyKeys {
+	return &IdempotencyKeys{db: db}
+}
+
+// Reserve stores the record unless a record with its key that expires after now
+// exists, and reports whether it did. Otherwise it returns the existing record.
+// Expired records are removed.
+func (r *IdempotencyKeys) Reserve(ctx context.Context, record model.IdempotencyRecord, now time.Time) (model.IdempotencyRecord, bool, error) {
+	var existing model.IdempotencyRecord
+	reserved := false
+	err := r.db.update(ctx, func(d *Data) error {
+		d.IdempotencyKeys = slices.DeleteFunc(d.IdempotencyKeys, func(k model.IdempotencyRecord) bool {
+			return !k.ExpiresAt.After(now)
+		})
+
+		i := slices.IndexFunc(d.IdempotencyKeys, func(k model.IdempotencyRecord) bool { return k.Key == record.Key })
+		if i >= 0 {
+			existing = d.IdempotencyKeys[i]
+			return nil
+		}
+
+		d.IdempotencyKeys = append(d.IdempotencyKeys, record)
+		reserved = true
+		return nil
+	})
+	return existing, reserved, err
+}
+
+// Complete stores the response of the record with the key of the given record.
+func (r *IdempotencyKeys) Complete(ctx context.Context, record model.IdempotencyRecord) error {
+	return r.db.update(ctx, func(d *Data) error {
+		i := slices.IndexFunc(d.IdempotencyKeys, func(k model.IdempotencyRecord) bool { return k.Key == record.Key })
+		if i < 0 {
+			return model.ErrNotFound
+		}
+
+		stored := d.IdempotencyKeys[i]
+		stored.Completed = record.Completed
+		stored.Status = record.Status
+		stored.Header = record.Header
+		stored.Body = record.Body
+		d.IdempotencyKeys[i] = stored
+		return nil
+	})
+}
+
+func (r *IdempotencyKeys) Delete(ctx context.Context, key string) error {
+	return r.db.update(ctx, func(d *Data) error {
+		i := slices.IndexFunc(d.IdempotencyKeys, func(k model.IdempotencyRecord) bool { return k.Key == key })
+		if i < 0 {
+			return model.ErrNotFound
+		}
+
+		d.IdempotencyKeys = slices.Delete(d.IdempotencyKeys, i, i+1)
+		return nil
+	})
+}
@@ -0,0 +1,221 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"coffee-shop/internal/model"
+)
+
+type Inventory struct {
+	db *DB
+}
+
+func NewInventory(db *DB) *Inventory {
+	return &Inventory{db: db}
+}
+
+func inventoryKey(item model.Inventory) int {
+	return item.IngredientID
+}
+
+// Create inserts a new inventory item and returns its generated ID.
+func (r *Inventory) Create(ctx context.Context, item model.Inventory) (int, error) {
+	err := r.db.update(ctx, func(d *Data) error {
+		d.Sequences.Inventory++
+		item.IngredientID = d.Sequences.Inventory
+		item.Version = 1
+		d.Inventory = append(d.Inventory, item)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return item.IngredientID, nil
+}
+
+func (r *Inventory) Get(ctx context.Context, id int) (model.Inventory, error) {
+	var item model.Inventory
+	err := r.db.view(ctx, func(d *Data) error {
+		i, ok := find(d.Inventory, id, inventoryKey)
+		if !ok {
+			return model.ErrNotFound
+		}
+		item = d.Inventory[i]
+		return nil
+	})
+	return item, err
+}
+
+func (r *Inventory) GetAll(ctx context.Context) ([]model.Inventory, error) {
+	var items []model.Inventory
+	err := r.db.view(ctx, func(d *Data) error {
+		items = append(items, d.Inventory...)
+		return nil
+	})
+	return items, err
+}
+
+// Each calls fn for every inventory item in the order of their IDs. The items are
+// read before the first call, so fn may use the repositories.
+func (r *Inventory) Each(ctx context.Context, fn func(item model.Inventory) error) error {
+	items, err := r.GetAll(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		if err := fn(item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Update replaces the item and increments its version. An archived item stays archived.
+// If the version of item is set, only the item with that version is replaced.
+func (r *Inventory) Update(ctx context.Context, id int, item model.Inventory) error {
+	return r.db.update(ctx, func(d *Data) error {
+		i, ok := find(d.Inventory, id, inventoryKey)
+		if !ok {
+			return model.ErrNotFound
+		}
+		if err := checkVersion(d.Inventory[i].Version, item.Version); err != nil {
+			return err
+		}
+		item.IngredientID = id
+		item.Version = d.Inventory[i].Version + 1
+		item.ArchivedAt = d.Inventory[i].ArchivedAt
+		d.Inventory[i] = item
+		return nil
+	})
+}
+
+// Patch changes the fields set in the patch and increments the version of the item.
+// If version is set, only the item with that version is changed.
+func (r *Inventory) Patch(ctx context.Context, id int, version int, patch model.InventoryPatch) error {
+	return r.db.update(ctx, func(d *Data) error {
+		i, ok := find(d.Inventory, id, inventoryKey)
+		if !ok {
+			return model.ErrNotFound
+		}
+		item := &d.Inventory[i]
+		if err := checkVersion(item.Version, version); err != nil {
+			return err
+		}
+
+		if patch.Name != nil {
+			item.Name = *patch.Name
+		}
+		if patch.Quantity != nil {
+			item.Quantity = *patch.Quantity
+		}
+		if patch.Unit != nil {
+			item.Unit = *patch.Unit
+		}
+		item.Version++
+		return nil
+	})
+}
+
+// AdjustQuantity adds delta to the quantity of the ingredient.
+// It reports false if the ingredient does not exist or the stock would become negative.
+func (r *Inventory) AdjustQuantity(ctx context.Context, id int, delta int) (bool, error) {
+	var adjusted bool
+	err := r.db.update(ctx, func(d *Data) error {
+		i, ok := find(d.Inventory, id, inventoryKey)
+		if !ok || d.Inventory[i].Quantity+delta < 0 {
+			return nil
+		}
+		d.Inventory[i].Quantity += delta
+		d.Inventory[i].Version++
+		adjusted = true
+		return nil
+	})
+	return adjusted, err
+}
+
+// Archive sets the time the item was archived at and increments its version. If
+// version is set, only the item with that version is archived.
+func (r *Inventory) Archive(ctx context.Context, id int, version int, archivedAt time.Time) error {
+	return r.db.update(ctx, func(d *Data) error {
+		i, ok := find(d.Inventory, id, inventoryKey)
+		if !ok {
+			return model.ErrNotFound
+		}
+		item := &d.Inventory[i]
+		if err := checkVersion(item.Version, version); err != nil {
+			return err
+		}
+
+		item.ArchivedAt = archivedAt
+		item.Version++
+		return nil
+	})
+}
+
+// Restore clears the archival time of the item and increments its version. If
+// version is set, only the item with that version is restored.
+func (r *Inventory) Restore(ctx context.Context, id int, version int) error {
+	return r.db.update(ctx, func(d *Data) error {
+		i, ok := find(d.Inventory, id, inventoryKey)
+		if !ok {
+			return model.ErrNotFound
+		}
+		item := &d.Inventory[i]
+		if err := checkVersion(item.Version, version); err != nil {
+			return err
+		}
+
+		item.ArchivedAt = time.Time{}
+		item.Version++
+		return nil
+	})
+}
+
+// References counts the recipes and ledger entries that refer to the item.
+func (r *Inventory) References(ctx context.Context, id int) ([]model.Reference, error) {
+	var refs []model.Reference
+	err := r.db.view(ctx, func(d *Data) error {
+		refs = references(
+			model.Reference{EntityType: model.ReferenceRecipes, Count: count(d.MenuIngredients, func(ingredient model.MenuItemIngredients) bool {
+				return ingredient.IngredientID == id
+			})},
+			model.Reference{EntityType: model.ReferenceInventoryLedger, Count: count(d.InventoryLedger, func(t model.InventoryTransactions) bool {
+				return t.IngredientId == id
+			})},
+		)
+		return nil
+	})
+	return refs, err
+}
+
+// Delete removes the inventory item. Items used by recipes or recorded in the
+// inventory ledger cannot be deleted. If version is set, only the item with that
+// version is deleted.
+func (r *Inventory) Delete(ctx context.Context, id int, version int) error {
+	return r.db.update(ctx, func(d *Data) error {
+		i, ok := find(d.Inventory, id, inventoryKey)
+		if !ok {
+			return model.ErrNotFound
+		}
+		if err := checkVersion(d.Inventory[i].Version, version); err != nil {
+			return err
+		}
+
+		for _, ingredient := range d.MenuIngredients {
+			if ingredient.IngredientID == id {
+				return model.ErrReferenced
+			}
+		}
+		for _, t := range d.InventoryLedger {
+			if t.IngredientId == id {
+				return model.ErrReferenced
+			}
+		}
+
+		d.Inventory = slices.Delete(d.Inventory, i, i+1)
+		return nil
+	})
+}
@@ -0,0 +1,45 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"coffee-shop/internal/model"
+)
+
+type InventoryTransactions struct {
+	db *DB
+}
+
+func NewInventoryTransactions(db *DB) *InventoryTransactions {
+	return &InventoryTransactions{db: db}
+}
+
+// Create appends a new record to the inventory ledger.
+func (r *InventoryTransactions) Create(ctx context.Context, transaction model.InventoryTransactions) error {
+	return r.db.update(ctx, func(d *Data) error {
+		if _, ok := find(d.Inventory, transaction.IngredientId, inventoryKey); !ok {
+			return model.ErrMissingReference
+		}
+
+		d.Sequences.InventoryLedger++
+		transaction.TransactionID = d.Sequences.InventoryLedger
+		transaction.CreatedAt = time.Now()
+		d.InventoryLedger = append(d.InventoryLedger, transaction)
+		return nil
+	})
+}
+
+// GetAllWithID returns the ledger records of the ingredient with the given ID, newest first.
+func (r *InventoryTransactions) GetAllWithID(ctx context.Context, id int) ([]model.InventoryTransactions, error) {
+	var transactions []model.InventoryTransactions
+	err := r.db.view(ctx, func(d *Data) error {
+		for i := len(d.InventoryLedger) - 1; i >= 0; i-- {
+			if d.InventoryLedger[i].IngredientId == id {
+				transactions = append(transactions, d.InventoryLedger[i])
+			}
+		}
+		return nil
+	})
+	return transactions, err
+}
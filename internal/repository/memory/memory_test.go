@@ -0,0 +1,14 @@
+package memory_test
+
+import (
+	"testing"
+
+	"coffee-shop/internal/repository/memory"
+	"coffee-shop/internal/repository/repotest"
+)
+
+func TestContract(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) repotest.Repos {
+		return repotest.Memory(memory.NewDB())
+	})
+}
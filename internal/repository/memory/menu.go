@@ -0,0 +1,203 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"coffee-shop/internal/model"
+)
+
+type Menu struct {
+	db *DB
+}
+
+func NewMenu(db *DB) *Menu {
+	return &Menu{db: db}
+}
+
+func menuKey(item model.MenuItem) int {
+	return item.ID
+}
+
+// Create inserts a new menu item and returns its generated ID.
+func (r *Menu) Create(ctx context.Context, menu model.MenuItem) (int, error) {
+	err := r.db.update(ctx, func(d *Data) error {
+		d.Sequences.Menu++
+		menu.ID = d.Sequences.Menu
+		menu.Version = 1
+		d.Menu = append(d.Menu, menu)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return menu.ID, nil
+}
+
+func (r *Menu) Get(ctx context.Context, id int) (model.MenuItem, error) {
+	var item model.MenuItem
+	err := r.db.view(ctx, func(d *Data) error {
+		i, ok := find(d.Menu, id, menuKey)
+		if !ok {
+			return model.ErrNotFound
+		}
+		item = d.Menu[i]
+		return nil
+	})
+	return item, err
+}
+
+func (r *Menu) GetAll(ctx context.Context) ([]model.MenuItem, error) {
+	var items []model.MenuItem
+	err := r.db.view(ctx, func(d *Data) error {
+		items = append(items, d.Menu...)
+		return nil
+	})
+	return items, err
+}
+
+// Update replaces the menu item and increments its version. An archived item stays
+// archived. If the version of menu is set, only the item with that version is replaced.
+func (r *Menu) Update(ctx context.Context, id int, menu model.MenuItem) error {
+	return r.db.update(ctx, func(d *Data) error {
+		i, ok := find(d.Menu, id, menuKey)
+		if !ok {
+			return model.ErrNotFound
+		}
+		if err := checkVersion(d.Menu[i].Version, menu.Version); err != nil {
+			return err
+		}
+		menu.ID = id
+		menu.Version = d.Menu[i].Version + 1
+		menu.ArchivedAt = d.Menu[i].ArchivedAt
+		d.Menu[i] = menu
+		return nil
+	})
+}
+
+// Patch changes the fields set in the patch and increments the version of the menu
+// item. If version is set, only the item with that version is changed.
+func (r *Menu) Patch(ctx context.Context, id int, version int, patch model.MenuItemPatch) error {
+	return r.db.update(ctx, func(d *Data) error {
+		i, ok := find(d.Menu, id, menuKey)
+		if !ok {
+			return model.ErrNotFound
+		}
+		item := &d.Menu[i]
+		if err := checkVersion(item.Version, version); err != nil {
+			return err
+		}
+
+		if patch.Name != nil {
+			item.Name = *patch.Name
+		}
+		if patch.Description != nil {
+			item.Description = *patch.Description
+		}
+		if patch.Category != nil {
+			item.Category = *patch.Category
+		}
+		if patch.Price != nil {
+			item.Price = *patch.Price
+		}
+		item.Version++
+		return nil
+	})
+}
+
+// Archive sets the time the menu item was taken off the menu at and increments its
+// version. If version is set, only the item with that version is archived.
+func (r *Menu) Archive(ctx context.Context, id int, version int, archivedAt time.Time) error {
+	return r.db.update(ctx, func(d *Data) error {
+		i, ok := find(d.Menu, id, menuKey)
+		if !ok {
+			return model.ErrNotFound
+		}
+		item := &d.Menu[i]
+		if err := checkVersion(item.Version, version); err != nil {
+			return err
+		}
+
+		item.ArchivedAt = archivedAt
+		item.Version++
+		return nil
+	})
+}
+
+// Restore puts the menu item back on the menu and increments its version. If version
+// is set, only the item with that version is restored.
+func (r *Menu) Restore(ctx context.Context, id int, version int) error {
+	return r.db.update(ctx, func(d *Data) error {
+		i, ok := find(d.Menu, id, menuKey)
+		if !ok {
+			return model.ErrNotFound
+		}
+		item := &d.Menu[i]
+		if err := checkVersion(item.Version, version); err != nil {
+			return err
+		}
+
+		item.ArchivedAt = time.Time{}
+		item.Version++
+		return nil
+	})
+}
+
+// References counts the order items, refunded items and price changes that refer to
+// the menu item. Its own ingredients are not counted, as they are deleted with it.
+func (r *Menu) References(ctx context.Context, id int) ([]model.Reference, error) {
+	var refs []model.Reference
+	err := r.db.view(ctx, func(d *Data) error {
+		refs = references(
+			model.Reference{EntityType: model.ReferenceOrderItems, Count: count(d.OrderItems, func(item model.OrderItems) bool {
+				return item.ProductID == id
+			})},
+			model.Reference{EntityType: model.ReferenceRefundItems, Count: count(d.RefundItems, func(item RefundItem) bool {
+				return item.ProductID == id
+			})},
+			model.Reference{EntityType: model.ReferencePriceHistory, Count: count(d.PriceHistory, func(change model.PriceHistory) bool {
+				return change.MenuItemID == id
+			})},
+		)
+		return nil
+	})
+	return refs, err
+}
+
+// Delete removes the menu item with its recipe. Items that were ordered, refunded
+// or that have a price history cannot be deleted. If version is set, only the item
+// with that version is deleted.
+func (r *Menu) Delete(ctx context.Context, id int, version int) error {
+	return r.db.update(ctx, func(d *Data) error {
+		i, ok := find(d.Menu, id, menuKey)
+		if !ok {
+			return model.ErrNotFound
+		}
+		if err := checkVersion(d.Menu[i].Version, version); err != nil {
+			return err
+		}
+
+		for _, item := range d.OrderItems {
+			if item.ProductID == id {
+				return model.ErrReferenced
+			}
+		}
+		for _, item := range d.RefundItems {
+			if item.ProductID == id {
+				return model.ErrReferenced
+			}
+		}
+		for _, change := range d.PriceHistory {
+			if change.MenuItemID == id {
+				return model.ErrReferenced
+			}
+		}
+
+		d.Menu = slices.Delete(d.Menu, i, i+1)
+		d.MenuIngredients = slices.DeleteFunc(d.MenuIngredients, func(ingredient model.MenuItemIngredients) bool {
+			return ingredient.MenuID == id
+		})
+		return nil
+	})
+}
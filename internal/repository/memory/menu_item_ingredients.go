@@ -0,0 +1,82 @@
+package memory
+
+import (
+	"context"
+	"slices"
+
+	"coffee-shop/internal/model"
+)
+
+type MenuItemIngredients struct {
+	db *DB
+}
+
+func NewMenuItemIngredients(db *DB) *MenuItemIngredients {
+	return &MenuItemIngredients{db: db}
+}
+
+func (r *MenuItemIngredients) Create(ctx context.Context, menu_ingredients model.MenuItemIngredients) error {
+	return r.db.update(ctx, func(d *Data) error {
+		_, menuExists := find(d.Menu, menu_ingredients.MenuID, menuKey)
+		_, ingredientExists := find(d.Inventory, menu_ingredients.IngredientID, inventoryKey)
+		if !menuExists || !ingredientExists {
+			return model.ErrMissingReference
+		}
+
+		d.MenuIngredients = append(d.MenuIngredients, menu_ingredients)
+		return nil
+	})
+}
+
+// GetAllWithID returns the ingredients of the menu item with the given ID in the order of ingredient IDs.
+func (r *MenuItemIngredients) GetAllWithID(ctx context.Context, id int) ([]model.MenuItemIngredients, error) {
+	var ingredients []model.MenuItemIngredients
+	err := r.db.view(ctx, func(d *Data) error {
+		for _, ingredient := range d.MenuIngredients {
+			if ingredient.MenuID == id {
+				ingredients = append(ingredients, ingredient)
+			}
+		}
+		slices.SortFunc(ingredients, func(a, b model.MenuItemIngredients) int {
+			return a.IngredientID - b.IngredientID
+		})
+		return nil
+	})
+	return ingredients, err
+}
+
+// Update changes the quantity of a single ingredient of the menu item with the given ID.
+func (r *MenuItemIngredients) Update(ctx context.Context, id int, menu_ingredients model.MenuItemIngredients) error {
+	return r.db.update(ctx, func(d *Data) error {
+		for i, ingredient := range d.MenuIngredients {
+			if ingredient.MenuID == id && ingredient.IngredientID == menu_ingredients.IngredientID {
+				d.MenuIngredients[i].Quantity = menu_ingredients.Quantity
+			}
+		}
+		return nil
+	})
+}
+
+// DeleteIngredient removes a single ingredient of the menu item with the given ID.
+func (r *MenuItemIngredients) DeleteIngredient(ctx context.Context, id int, ingredientID int) error {
+	return r.db.update(ctx, func(d *Data) error {
+		i := slices.IndexFunc(d.MenuIngredients, func(ingredient model.MenuItemIngredients) bool {
+			return ingredient.MenuID == id && ingredient.IngredientID == ingredientID
+		})
+		if i < 0 {
+			return model.ErrNotFound
+		}
+		d.MenuIngredients = slices.Delete(d.MenuIngredients, i, i+1)
+		return nil
+	})
+}
+
+// Delete removes all ingredients of the menu item with the given ID.
+func (r *MenuItemIngredients) Delete(ctx context.Context, id int) error {
+	return r.db.update(ctx, func(d *Data) error {
+		d.MenuIngredients = slices.DeleteFunc(d.MenuIngredients, func(ingredient model.MenuItemIngredients) bool {
+			return ingredient.MenuID == id
+		})
+		return nil
+	})
+}
@@ -0,0 +1,171 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"coffee-shop/internal/model"
+)
+
+type Order struct {
+	db *DB
+}
+
+func NewOrder(db *DB) *Order {
+	return &Order{db: db}
+}
+
+func orderKey(order model.Order) int {
+	return order.ID
+}
+
+// Create inserts a new order and returns its generated ID. The order is created
+// now unless its creation time is set, as it is for migrated orders. Items and
+// totals are not stored with the order.
+func (r *Order) Create(ctx context.Context, order model.Order) (int, error) {
+	err := r.db.update(ctx, func(d *Data) error {
+		d.Sequences.Orders++
+		order.ID = d.Sequences.Orders
+		order.Version = 1
+		if order.CreateAt.IsZero() {
+			order.CreateAt = time.Now()
+		}
+		order.Items = nil
+		order.OrderTotals = model.OrderTotals{DiscountTotal: order.DiscountTotal}
+		order.ClosedAt = time.Time{}
+		d.Orders = append(d.Orders, order)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return order.ID, nil
+}
+
+func (r *Order) Get(ctx context.Context, id int) (model.Order, error) {
+	var order model.Order
+	err := r.db.view(ctx, func(d *Data) error {
+		i, ok := find(d.Orders, id, orderKey)
+		if !ok {
+			return model.ErrNotFound
+		}
+		order = d.Orders[i]
+		return nil
+	})
+	return order, err
+}
+
+// Lock only checks that the order exists: a transaction holds the DB lock until it ends.
+func (r *Order) Lock(ctx context.Context, id int) error {
+	return r.db.view(ctx, func(d *Data) error {
+		if _, ok := find(d.Orders, id, orderKey); !ok {
+			return model.ErrNotFound
+		}
+		return nil
+	})
+}
+
+func (r *Order) GetAll(ctx context.Context) ([]model.Order, error) {
+	var orders []model.Order
+	err := r.db.view(ctx, func(d *Data) error {
+		orders = append(orders, d.Orders...)
+		return nil
+	})
+	return orders, err
+}
+
+// Each calls fn for every order in the order of their IDs. The orders are read
+// before the first call, so fn may use the repositories.
+func (r *Order) Each(ctx context.Context, fn func(order model.Order) error) error {
+	orders, err := r.GetAll(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, order := range orders {
+		if err := fn(order); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Update changes the customer name, status, notes and discount of the order and
+// increments its version. If the version of order is set, only the order with that
+// version is changed.
+func (r *Order) Update(ctx context.Context, id int, order model.Order) error {
+	return r.db.update(ctx, func(d *Data) error {
+		i, ok := find(d.Orders, id, orderKey)
+		if !ok {
+			return model.ErrNotFound
+		}
+		stored := &d.Orders[i]
+		if err := checkVersion(stored.Version, order.Version); err != nil {
+			return err
+		}
+		stored.Version++
+		stored.CustomerName = order.CustomerName
+		stored.Status = order.Status
+		stored.Notes = order.Notes
+		stored.DiscountTotal = order.DiscountTotal
+		return nil
+	})
+}
+
+// Close stores the totals of the open order and marks it closed.
+// It reports false if the order does not exist or is not open.
+func (r *Order) Close(ctx context.Context, order model.Order) (bool, error) {
+	var closed bool
+	err := r.db.update(ctx, func(d *Data) error {
+		i, ok := find(d.Orders, order.ID, orderKey)
+		if !ok || d.Orders[i].Status != model.OrderStatusOpen {
+			return nil
+		}
+
+		stored := &d.Orders[i]
+		stored.Status = model.OrderStatusClosed
+		stored.OrderTotals = model.OrderTotals{
+			Subtotal:      order.Subtotal,
+			DiscountTotal: order.DiscountTotal,
+			TaxTotal:      order.TaxTotal,
+			Total:         order.Total,
+		}
+		stored.ClosedAt = order.ClosedAt
+		stored.Version++
+		closed = true
+		return nil
+	})
+	return closed, err
+}
+
+// Delete removes the order with its tax lines and consumption. Orders that still have items,
+// payments or status history cannot be deleted. If version is set, only the order
+// with that version is deleted.
+func (r *Order) Delete(ctx context.Context, id int, version int) error {
+	return r.db.update(ctx, func(d *Data) error {
+		i, ok := find(d.Orders, id, orderKey)
+		if !ok {
+			return model.ErrNotFound
+		}
+		if err := checkVersion(d.Orders[i].Version, version); err != nil {
+			return err
+		}
+
+		referenced := slices.ContainsFunc(d.OrderItems, func(item model.OrderItems) bool { return item.OrderID == id }) ||
+			slices.ContainsFunc(d.Payments, func(p model.Payment) bool { return p.OrderID == id }) ||
+			slices.ContainsFunc(d.OrderStatusHistory, func(h model.OrderStatusHistory) bool { return h.OrderID == id })
+		if referenced {
+			return model.ErrReferenced
+		}
+
+		d.Orders = slices.Delete(d.Orders, i, i+1)
+		d.OrderTaxes = slices.DeleteFunc(d.OrderTaxes, func(line model.TaxLine) bool {
+			return line.OrderID == id
+		})
+		d.OrderConsumption = slices.DeleteFunc(d.OrderConsumption, func(c model.OrderConsumption) bool {
+			return c.OrderID == id
+		})
+		return nil
+	})
+}
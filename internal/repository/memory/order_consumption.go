@@ -0,0 +1,60 @@
+package memory
+
+import (
+	"context"
+	"slices"
+
+	"coffee-shop/internal/model"
+)
+
+type OrderConsumption struct {
+	db *DB
+}
+
+func NewOrderConsumption(db *DB) *OrderConsumption {
+	return &OrderConsumption{db: db}
+}
+
+// Save replaces the consumption recorded for the order with the given ID.
+func (r *OrderConsumption) Save(ctx context.Context, id int, lines []model.OrderConsumption) error {
+	return r.db.update(ctx, func(d *Data) error {
+		if _, ok := find(d.Orders, id, orderKey); !ok {
+			return model.ErrMissingReference
+		}
+		for _, line := range lines {
+			if _, ok := find(d.Inventory, line.IngredientID, inventoryKey); !ok {
+				return model.ErrMissingReference
+			}
+		}
+
+		d.OrderConsumption = slices.DeleteFunc(d.OrderConsumption, func(c model.OrderConsumption) bool {
+			return c.OrderID == id
+		})
+		for _, line := range lines {
+			line.OrderID = id
+			d.OrderConsumption = append(d.OrderConsumption, line)
+		}
+		return nil
+	})
+}
+
+// GetAllWithID returns the consumption recorded for the order with the given ID
+// in the order of product and ingredient IDs.
+func (r *OrderConsumption) GetAllWithID(ctx context.Context, id int) ([]model.OrderConsumption, error) {
+	var lines []model.OrderConsumption
+	err := r.db.view(ctx, func(d *Data) error {
+		for _, line := range d.OrderConsumption {
+			if line.OrderID == id {
+				lines = append(lines, line)
+			}
+		}
+		slices.SortStableFunc(lines, func(a, b model.OrderConsumption) int {
+			if a.ProductID != b.ProductID {
+				return a.ProductID - b.ProductID
+			}
+			return a.IngredientID - b.IngredientID
+		})
+		return nil
+	})
+	return lines, err
+}
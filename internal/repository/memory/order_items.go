@@ -0,0 +1,58 @@
+package memory
+
+import (
+	"context"
+	"slices"
+
+	"coffee-shop/internal/model"
+)
+
+type OrderItems struct {
+	db *DB
+}
+
+func NewOrderItems(db *DB) *OrderItems {
+	return &OrderItems{db: db}
+}
+
+func (r *OrderItems) Create(ctx context.Context, order_items model.OrderItems) error {
+	return r.db.update(ctx, func(d *Data) error {
+		_, orderExists := find(d.Orders, order_items.OrderID, orderKey)
+		_, productExists := find(d.Menu, order_items.ProductID, menuKey)
+		if !orderExists || !productExists {
+			return model.ErrMissingReference
+		}
+
+		order_items.Modifiers = slices.Clone(order_items.Modifiers)
+		d.OrderItems = append(d.OrderItems, order_items)
+		return nil
+	})
+}
+
+// GetAllWithID returns all items of the order with the given ID in the order of product IDs.
+func (r *OrderItems) GetAllWithID(ctx context.Context, id int) ([]model.OrderItems, error) {
+	var items []model.OrderItems
+	err := r.db.view(ctx, func(d *Data) error {
+		for _, item := range d.OrderItems {
+			if item.OrderID == id {
+				item.Modifiers = slices.Clone(item.Modifiers)
+				items = append(items, item)
+			}
+		}
+		slices.SortStableFunc(items, func(a, b model.OrderItems) int {
+			return a.ProductID - b.ProductID
+		})
+		return nil
+	})
+	return items, err
+}
+
+// Delete removes all items of the order with the given ID.
+func (r *OrderItems) Delete(ctx context.Context, id int) error {
+	return r.db.update(ctx, func(d *Data) error {
+		d.OrderItems = slices.DeleteFunc(d.OrderItems, func(item model.OrderItems) bool {
+			return item.OrderID == id
+		})
+		return nil
+	})
+}
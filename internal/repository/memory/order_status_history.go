@@ -0,0 +1,57 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"coffee-shop/internal/model"
+)
+
+type OrderStatusHistory struct {
+	db *DB
+}
+
+func NewOrderStatusHistory(db *DB) *OrderStatusHistory {
+	return &OrderStatusHistory{db: db}
+}
+
+// Create opens a new status history record for the order.
+func (r *OrderStatusHistory) Create(ctx context.Context, order_history model.OrderStatusHistory) error {
+	return r.db.update(ctx, func(d *Data) error {
+		if _, ok := find(d.Orders, order_history.OrderID, orderKey); !ok {
+			return model.ErrMissingReference
+		}
+
+		d.Sequences.OrderStatusHistory++
+		d.OrderStatusHistory = append(d.OrderStatusHistory, model.OrderStatusHistory{
+			ID:       d.Sequences.OrderStatusHistory,
+			OrderID:  order_history.OrderID,
+			OpenedAt: time.Now(),
+		})
+		return nil
+	})
+}
+
+// Close sets the closing time of the open status history record of the order.
+func (r *OrderStatusHistory) Close(ctx context.Context, orderID int) error {
+	return r.db.update(ctx, func(d *Data) error {
+		now := time.Now()
+		for i, h := range d.OrderStatusHistory {
+			if h.OrderID == orderID && h.ClosedAt.IsZero() {
+				d.OrderStatusHistory[i].ClosedAt = now
+			}
+		}
+		return nil
+	})
+}
+
+// DeleteByOrder removes the status history of the order.
+func (r *OrderStatusHistory) DeleteByOrder(ctx context.Context, orderID int) error {
+	return r.db.update(ctx, func(d *Data) error {
+		d.OrderStatusHistory = slices.DeleteFunc(d.OrderStatusHistory, func(h model.OrderStatusHistory) bool {
+			return h.OrderID == orderID
+		})
+		return nil
+	})
+}
@@ -0,0 +1,113 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"coffee-shop/internal/model"
+)
+
+type Payment struct {
+	db *DB
+}
+
+func NewPayment(db *DB) *Payment {
+	return &Payment{db: db}
+}
+
+func paymentKey(p model.Payment) int {
+	return p.ID
+}
+
+// Create inserts a new payment or refund and returns its generated ID.
+func (r *Payment) Create(ctx context.Context, payment model.Payment) (int, error) {
+	err := r.db.update(ctx, func(d *Data) error {
+		if _, ok := find(d.Orders, payment.OrderID, orderKey); !ok {
+			return model.ErrMissingReference
+		}
+		if payment.RefundOf != 0 {
+			if _, ok := find(d.Payments, payment.RefundOf, paymentKey); !ok {
+				return model.ErrMissingReference
+			}
+		}
+
+		d.Sequences.Payments++
+		payment.ID = d.Sequences.Payments
+		payment.CreatedAt = time.Now()
+		d.Payments = append(d.Payments, payment)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return payment.ID, nil
+}
+
+func (r *Payment) Get(ctx context.Context, id int) (model.Payment, error) {
+	var payment model.Payment
+	err := r.db.view(ctx, func(d *Data) error {
+		i, ok := find(d.Payments, id, paymentKey)
+		if !ok {
+			return model.ErrNotFound
+		}
+		payment = d.Payments[i]
+		return nil
+	})
+	return payment, err
+}
+
+// GetAllWithID returns the payments and refunds of the order with the given ID in the order they were made.
+func (r *Payment) GetAllWithID(ctx context.Context, id int) ([]model.Payment, error) {
+	var payments []model.Payment
+	err := r.db.view(ctx, func(d *Data) error {
+		for _, p := range d.Payments {
+			if p.OrderID == id {
+				payments = append(payments, p)
+			}
+		}
+		return nil
+	})
+	return payments, err
+}
+
+// CreateRefundItem records an item returned by the refund with the given payment ID.
+func (r *Payment) CreateRefundItem(ctx context.Context, paymentID int, item model.OrderItems) error {
+	return r.db.update(ctx, func(d *Data) error {
+		_, paymentExists := find(d.Payments, paymentID, paymentKey)
+		_, productExists := find(d.Menu, item.ProductID, menuKey)
+		if !paymentExists || !productExists {
+			return model.ErrMissingReference
+		}
+
+		d.RefundItems = append(d.RefundItems, RefundItem{PaymentID: paymentID, ProductID: item.ProductID, Quantity: item.Quantity})
+		return nil
+	})
+}
+
+// RefundedItems returns the quantities of the products already refunded for the order.
+func (r *Payment) RefundedItems(ctx context.Context, orderID int) ([]model.OrderItems, error) {
+	var items []model.OrderItems
+	err := r.db.view(ctx, func(d *Data) error {
+		quantities := make(map[int]int)
+		for _, item := range d.RefundItems {
+			i, ok := find(d.Payments, item.PaymentID, paymentKey)
+			if !ok || d.Payments[i].OrderID != orderID {
+				continue
+			}
+			if _, seen := quantities[item.ProductID]; !seen {
+				items = append(items, model.OrderItems{OrderID: orderID, ProductID: item.ProductID})
+			}
+			quantities[item.ProductID] += item.Quantity
+		}
+
+		for i := range items {
+			items[i].Quantity = quantities[items[i].ProductID]
+		}
+		slices.SortFunc(items, func(a, b model.OrderItems) int {
+			return a.ProductID - b.ProductID
+		})
+		return nil
+	})
+	return items, err
+}
@@ -0,0 +1,49 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"coffee-shop/internal/model"
+)
+
+type PriceHistory struct {
+	db *DB
+}
+
+func NewPriceHistory(db *DB) *PriceHistory {
+	return &PriceHistory{db: db}
+}
+
+// Create records a price change of a menu item and returns its generated ID.
+func (r *PriceHistory) Create(ctx context.Context, change model.PriceHistory) (int, error) {
+	err := r.db.update(ctx, func(d *Data) error {
+		if _, ok := find(d.Menu, change.MenuItemID, menuKey); !ok {
+			return model.ErrMissingReference
+		}
+
+		d.Sequences.PriceHistory++
+		change.HistoryID = d.Sequences.PriceHistory
+		change.ChangedAt = time.Now()
+		d.PriceHistory = append(d.PriceHistory, change)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return change.HistoryID, nil
+}
+
+// GetAllWithID returns the price changes of the menu item with the given ID, oldest first.
+func (r *PriceHistory) GetAllWithID(ctx context.Context, id int) ([]model.PriceHistory, error) {
+	var changes []model.PriceHistory
+	err := r.db.view(ctx, func(d *Data) error {
+		for _, c := range d.PriceHistory {
+			if c.MenuItemID == id {
+				changes = append(changes, c)
+			}
+		}
+		return nil
+	})
+	return changes, err
+}
@@ -0,0 +1,109 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"coffee-shop/internal/model"
+)
+
+type Staff struct {
+	db *DB
+}
+
+func NewStaff(db *DB) *Staff {
+	return &Staff{db: db}
+}
+
+func staffKey(staff model.Staff) int {
+	return staff.ID
+}
+
+// Create stores the staff member and returns its generated ID. Usernames and API
+// keys are unique.
+func (r *Staff) Create(ctx context.Context, staff model.Staff) (int, error) {
+	err := r.db.update(ctx, func(d *Data) error {
+		if conflictingStaff(d.Staff, 0, staff) {
+			return model.ErrDuplicate
+		}
+
+		d.Sequences.Staff++
+		staff.ID = d.Sequences.Staff
+		staff.CreatedAt = time.Now()
+		d.Staff = append(d.Staff, staff)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return staff.ID, nil
+}
+
+func (r *Staff) Get(ctx context.Context, id int) (model.Staff, error) {
+	return r.getBy(ctx, func(s model.Staff) bool { return s.ID == id })
+}
+
+func (r *Staff) GetByUsername(ctx context.Context, username string) (model.Staff, error) {
+	return r.getBy(ctx, func(s model.Staff) bool { return s.Username == username })
+}
+
+// GetByAPIKey returns the staff member the API key with the given hash was issued to.
+func (r *Staff) GetByAPIKey(ctx context.Context, hash string) (model.Staff, error) {
+	if hash == "" {
+		return model.Staff{}, model.ErrNotFound
+	}
+	return r.getBy(ctx, func(s model.Staff) bool { return s.APIKeyHash == hash })
+}
+
+func (r *Staff) getBy(ctx context.Context, match func(s model.Staff) bool) (model.Staff, error) {
+	var staff model.Staff
+	err := r.db.view(ctx, func(d *Data) error {
+		i := slices.IndexFunc(d.Staff, match)
+		if i < 0 {
+			return model.ErrNotFound
+		}
+		staff = d.Staff[i]
+		return nil
+	})
+	return staff, err
+}
+
+// GetAll returns the staff in the order of their IDs.
+func (r *Staff) GetAll(ctx context.Context) ([]model.Staff, error) {
+	var staff []model.Staff
+	err := r.db.view(ctx, func(d *Data) error {
+		staff = append(staff, d.Staff...)
+		return nil
+	})
+	return staff, err
+}
+
+// Update replaces the role and the credentials of the staff member. The username is kept.
+func (r *Staff) Update(ctx context.Context, id int, staff model.Staff) error {
+	return r.db.update(ctx, func(d *Data) error {
+		i, ok := find(d.Staff, id, staffKey)
+		if !ok {
+			return model.ErrNotFound
+		}
+
+		updated := d.Staff[i]
+		updated.Role = staff.Role
+		updated.PasswordHash = staff.PasswordHash
+		updated.APIKeyHash = staff.APIKeyHash
+		if conflictingStaff(d.Staff, id, updated) {
+			return model.ErrDuplicate
+		}
+
+		d.Staff[i] = updated
+		return nil
+	})
+}
+
+// conflictingStaff reports whether a member other than the one with the given ID has
+// the username or the API key of staff.
+func conflictingStaff(all []model.Staff, id int, staff model.Staff) bool {
+	return slices.ContainsFunc(all, func(s model.Staff) bool {
+		return s.ID != id && (s.Username == staff.Username || staff.APIKeyHash != "" && s.APIKeyHash == staff.APIKeyHash)
+	})
+}
@@ -0,0 +1,107 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"coffee-shop/internal/model"
+)
+
+type TaxRate struct {
+	db *DB
+}
+
+func NewTaxRate(db *DB) *TaxRate {
+	return &TaxRate{db: db}
+}
+
+// Save creates the tax rate of the category or replaces the existing one.
+func (r *TaxRate) Save(ctx context.Context, rate model.TaxRate) error {
+	return r.db.update(ctx, func(d *Data) error {
+		i, ok := slices.BinarySearchFunc(d.TaxRates, rate.Category, compareCategory)
+		if ok {
+			d.TaxRates[i] = rate
+		} else {
+			d.TaxRates = slices.Insert(d.TaxRates, i, rate)
+		}
+		return nil
+	})
+}
+
+func (r *TaxRate) Get(ctx context.Context, category string) (model.TaxRate, error) {
+	var rate model.TaxRate
+	err := r.db.view(ctx, func(d *Data) error {
+		i, ok := slices.BinarySearchFunc(d.TaxRates, category, compareCategory)
+		if !ok {
+			return model.ErrNotFound
+		}
+		rate = d.TaxRates[i]
+		return nil
+	})
+	return rate, err
+}
+
+// GetAll returns the tax rates in the order of their categories.
+func (r *TaxRate) GetAll(ctx context.Context) ([]model.TaxRate, error) {
+	var rates []model.TaxRate
+	err := r.db.view(ctx, func(d *Data) error {
+		rates = append(rates, d.TaxRates...)
+		return nil
+	})
+	return rates, err
+}
+
+// Delete removes the tax rate of the category. It reports false if there was no such rate.
+func (r *TaxRate) Delete(ctx context.Context, category string) (bool, error) {
+	var deleted bool
+	err := r.db.update(ctx, func(d *Data) error {
+		i, ok := slices.BinarySearchFunc(d.TaxRates, category, compareCategory)
+		if ok {
+			d.TaxRates = slices.Delete(d.TaxRates, i, i+1)
+			deleted = true
+		}
+		return nil
+	})
+	return deleted, err
+}
+
+func compareCategory(rate model.TaxRate, category string) int {
+	return strings.Compare(rate.Category, category)
+}
+
+type OrderTaxes struct {
+	db *DB
+}
+
+func NewOrderTaxes(db *DB) *OrderTaxes {
+	return &OrderTaxes{db: db}
+}
+
+func (r *OrderTaxes) Create(ctx context.Context, line model.TaxLine) error {
+	return r.db.update(ctx, func(d *Data) error {
+		if _, ok := find(d.Orders, line.OrderID, orderKey); !ok {
+			return model.ErrMissingReference
+		}
+
+		d.OrderTaxes = append(d.OrderTaxes, line)
+		return nil
+	})
+}
+
+// GetAllWithID returns the tax lines stored for the order with the given ID in the order of their categories.
+func (r *OrderTaxes) GetAllWithID(ctx context.Context, id int) ([]model.TaxLine, error) {
+	var lines []model.TaxLine
+	err := r.db.view(ctx, func(d *Data) error {
+		for _, line := range d.OrderTaxes {
+			if line.OrderID == id {
+				lines = append(lines, line)
+			}
+		}
+		slices.SortStableFunc(lines, func(a, b model.TaxLine) int {
+			return strings.Compare(a.Category, b.Category)
+		})
+		return nil
+	})
+	return lines, err
+}
@@ -0,0 +1,101 @@
+package postgres
+
+import (
+	"coffee-shop/internal/model"
+	"coffee-shop/internal/repository/postgres/dao"
+	"context"
+	"database/sql"
+	"strconv"
+	"strings"
+)
+
+type Audit struct {
+	conn  *sql.DB
+	table string
+}
+
+const (
+	tableAudit = "audit_log"
+)
+
+func NewAudit(conn *sql.DB) *Audit {
+	return &Audit{
+		conn:  conn,
+		table: tableAudit,
+	}
+}
+
+// Create appends the entry to the audit log and returns its generated ID.
+// The table rejects updates and deletes, so entries are never changed.
+func (r *Audit) Create(ctx context.Context, entry model.AuditEntry) (int, error) {
+	object := dao.FromAuditEntry(entry)
+	query := "INSERT INTO " + r.table + " (actorid, actor, action, entitytype, entityid, before, after, requestid, ip) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id"
+
+	var id int
+	err := conn(ctx, r.conn).QueryRowContext(ctx, query, object.ActorID, object.Actor, object.Action, object.EntityType, object.EntityID,
+		nullJSON(object.Before), nullJSON(object.After), object.RequestID, object.IP).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// Find returns the entries selected by the query, newest first.
+func (r *Audit) Find(ctx context.Context, q model.AuditQuery) ([]model.AuditEntry, error) {
+	var (
+		conditions []string
+		args       []any
+	)
+	where := func(condition string, arg any) {
+		args = append(args, arg)
+		conditions = append(conditions, condition+" $"+strconv.Itoa(len(args)))
+	}
+	if q.EntityType != "" {
+		where("entitytype =", q.EntityType)
+	}
+	if q.EntityID > 0 {
+		where("entityid =", q.EntityID)
+	}
+	if !q.Period.From.IsZero() {
+		where("createdat >=", q.Period.From)
+	}
+	if !q.Period.To.IsZero() {
+		where("createdat <", q.Period.To)
+	}
+
+	query := "SELECT id, actorid, actor, action, entitytype, entityid, before, after, requestid, ip, createdat FROM " + r.table
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	args = append(args, q.Limit)
+	query += " ORDER BY createdat DESC, id DESC LIMIT $" + strconv.Itoa(len(args))
+
+	rows, err := conn(ctx, r.conn).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []model.AuditEntry
+	for rows.Next() {
+		var e dao.AuditEntry
+		err := rows.Scan(&e.ID, &e.ActorID, &e.Actor, &e.Action, &e.EntityType, &e.EntityID, &e.Before, &e.After, &e.RequestID, &e.IP, &e.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, dao.ToAuditEntry(e))
+	}
+
+	return entries, rows.Err()
+}
+
+// nullJSON returns the JSON document as a string, or nil for an empty one, so that
+// it is stored in a JSONB column as NULL.
+func nullJSON(data []byte) any {
+	if len(data) == 0 {
+		return nil
+	}
+	return string(data)
+}
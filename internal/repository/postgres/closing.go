@@ -0,0 +1,118 @@
+package postgres
+
+import (
+	"coffee-shop/internal/model"
+	"coffee-shop/internal/repository/postgres/dao"
+	"context"
+	"database/sql"
+	"time"
+)
+
+type DailyClosing struct {
+	conn     *sql.DB
+	table    string
+	location *time.Location
+}
+
+const (
+	tableDailyClosing = "daily_closings"
+
+	closingColumns = "id, businessdate, periodfrom, periodto, openingfloat, expectedcash, countedcash, notes, summary, createdat"
+)
+
+// NewDailyClosing creates the closing repository. Business dates are returned as
+// midnight in the location.
+func NewDailyClosing(conn *sql.DB, location *time.Location) *DailyClosing {
+	return &DailyClosing{
+		conn:     conn,
+		table:    tableDailyClosing,
+		location: location,
+	}
+}
+
+// Create stores the closing and returns its generated ID.
+func (r *DailyClosing) Create(ctx context.Context, closing model.DailyClosing) (int, error) {
+	object := dao.FromDailyClosing(closing)
+	query := "INSERT INTO " + r.table + " (businessdate, periodfrom, periodto, openingfloat, expectedcash, countedcash, notes, summary) " +
+		"VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id"
+
+	var id int
+	err := conn(ctx, r.conn).QueryRowContext(ctx, query, object.BusinessDate.Format(time.DateOnly), object.PeriodFrom, object.PeriodTo,
+		object.OpeningFloat, object.ExpectedCash, object.CountedCash, object.Notes, object.Summary).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// Get returns the closing of the business date.
+func (r *DailyClosing) Get(ctx context.Context, date time.Time) (model.DailyClosing, error) {
+	query := "SELECT " + closingColumns + " FROM " + r.table + " WHERE businessdate = $1"
+
+	closing, err := scanClosing(conn(ctx, r.conn).QueryRowContext(ctx, query, date.Format(time.DateOnly)))
+	if err != nil {
+		return model.DailyClosing{}, err
+	}
+
+	return dao.ToDailyClosing(closing, r.location), nil
+}
+
+// GetAll returns the closings, the latest first.
+func (r *DailyClosing) GetAll(ctx context.Context) ([]model.DailyClosing, error) {
+	query := "SELECT " + closingColumns + " FROM " + r.table + " ORDER BY businessdate DESC"
+
+	rows, err := conn(ctx, r.conn).QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var closings []model.DailyClosing
+	for rows.Next() {
+		closing, err := scanClosing(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		closings = append(closings, dao.ToDailyClosing(closing, r.location))
+	}
+
+	return closings, rows.Err()
+}
+
+// Last returns the closing of the latest business date, or model.ErrNotFound if no day is closed yet.
+func (r *DailyClosing) Last(ctx context.Context) (model.DailyClosing, error) {
+	query := "SELECT " + closingColumns + " FROM " + r.table + " ORDER BY businessdate DESC LIMIT 1"
+
+	closing, err := scanClosing(conn(ctx, r.conn).QueryRowContext(ctx, query))
+	if err != nil {
+		return model.DailyClosing{}, err
+	}
+
+	return dao.ToDailyClosing(closing, r.location), nil
+}
+
+// OrderLocked reports whether the order was closed within the period of a closing.
+func (r *DailyClosing) OrderLocked(ctx context.Context, orderID int) (bool, error) {
+	query := `SELECT EXISTS (
+			SELECT 1 FROM ` + tableOrder + ` o
+			WHERE o.id = $1 AND o.status = 'closed'
+				AND o.closedat < (SELECT MAX(periodto) FROM ` + r.table + `)
+		)`
+
+	var locked bool
+	err := conn(ctx, r.conn).QueryRowContext(ctx, query, orderID).Scan(&locked)
+	if err != nil {
+		return false, err
+	}
+
+	return locked, nil
+}
+
+func scanClosing(row rowScanner) (dao.DailyClosing, error) {
+	var closing dao.DailyClosing
+	err := row.Scan(&closing.ID, &closing.BusinessDate, &closing.PeriodFrom, &closing.PeriodTo, &closing.OpeningFloat,
+		&closing.ExpectedCash, &closing.CountedCash, &closing.Notes, &closing.Summary, &closing.CreatedAt)
+	return closing, err
+}
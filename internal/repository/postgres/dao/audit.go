@@ -0,0 +1,54 @@
+package dao
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"coffee-shop/internal/model"
+)
+
+type AuditEntry struct {
+	ID         int            `json:"id" db:"id"`
+	ActorID    sql.NullInt64  `json:"actor_id" db:"actorid"`
+	Actor      string         `json:"actor" db:"actor"`
+	Action     string         `json:"action" db:"action"`
+	EntityType string         `json:"entity_type" db:"entitytype"`
+	EntityID   int            `json:"entity_id" db:"entityid"`
+	Before     []byte         `json:"before" db:"before"`
+	After      []byte         `json:"after" db:"after"`
+	RequestID  sql.NullString `json:"request_id" db:"requestid"`
+	IP         sql.NullString `json:"ip" db:"ip"`
+	CreatedAt  time.Time      `json:"created_at" db:"createdat"`
+}
+
+func FromAuditEntry(e model.AuditEntry) AuditEntry {
+	return AuditEntry{
+		ID:         e.ID,
+		ActorID:    sql.NullInt64{Int64: int64(e.ActorID), Valid: e.ActorID > 0},
+		Actor:      e.Actor,
+		Action:     e.Action,
+		EntityType: e.EntityType,
+		EntityID:   e.EntityID,
+		Before:     e.Before,
+		After:      e.After,
+		RequestID:  sql.NullString{String: e.RequestID, Valid: e.RequestID != ""},
+		IP:         sql.NullString{String: e.IP, Valid: e.IP != ""},
+	}
+}
+
+func ToAuditEntry(e AuditEntry) model.AuditEntry {
+	return model.AuditEntry{
+		ID:         e.ID,
+		ActorID:    int(e.ActorID.Int64),
+		Actor:      e.Actor,
+		Action:     e.Action,
+		EntityType: e.EntityType,
+		EntityID:   e.EntityID,
+		Before:     json.RawMessage(e.Before),
+		After:      json.RawMessage(e.After),
+		RequestID:  e.RequestID.String,
+		IP:         e.IP.String,
+		CreatedAt:  e.CreatedAt,
+	}
+}
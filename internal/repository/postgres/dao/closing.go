@@ -0,0 +1,153 @@
+package dao
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"coffee-shop/internal/model"
+)
+
+type DailyClosing struct {
+	ID           int            `json:"id" db:"id"`
+	BusinessDate time.Time      `json:"business_date" db:"businessdate"`
+	PeriodFrom   time.Time      `json:"period_from" db:"periodfrom"`
+	PeriodTo     time.Time      `json:"period_to" db:"periodto"`
+	OpeningFloat float64        `json:"opening_float" db:"openingfloat"`
+	ExpectedCash float64        `json:"expected_cash" db:"expectedcash"`
+	CountedCash  float64        `json:"counted_cash" db:"countedcash"`
+	Notes        string         `json:"notes" db:"notes"`
+	Summary      ClosingSummary `json:"summary" db:"summary"`
+	CreatedAt    time.Time      `json:"created_at" db:"createdat"`
+}
+
+// ClosingSummary holds the figures of a closing stored in its JSONB column.
+type ClosingSummary struct {
+	ClosedOrders   int                 `json:"closed_orders"`
+	OpenOrders     []ClosingOpenOrder  `json:"open_orders"`
+	GrossSales     float64             `json:"gross_sales"`
+	Discounts      float64             `json:"discounts"`
+	NetSales       float64             `json:"net_sales"`
+	Taxes          float64             `json:"taxes"`
+	Total          float64             `json:"total"`
+	Tips           float64             `json:"tips"`
+	Refunds        int                 `json:"refunds"`
+	Refunded       float64             `json:"refunded"`
+	TaxLines       []ClosingTaxLine    `json:"tax_lines"`
+	Tenders        []ClosingTenderLine `json:"tenders"`
+	CashDifference float64             `json:"cash_difference"`
+}
+
+type ClosingOpenOrder struct {
+	ID           int       `json:"id"`
+	CustomerName string    `json:"customer_name"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type ClosingTaxLine struct {
+	Category      string  `json:"category"`
+	Rate          float64 `json:"rate"`
+	Inclusive     bool    `json:"inclusive"`
+	Orders        int     `json:"orders"`
+	TaxableAmount float64 `json:"taxable_amount"`
+	TaxAmount     float64 `json:"tax_amount"`
+}
+
+type ClosingTenderLine struct {
+	Tender   string  `json:"tender"`
+	Payments int     `json:"payments"`
+	Amount   float64 `json:"amount"`
+	Tips     float64 `json:"tips"`
+	Refunds  int     `json:"refunds"`
+	Refunded float64 `json:"refunded"`
+}
+
+func (s ClosingSummary) Value() (driver.Value, error) {
+	return json.Marshal(s)
+}
+
+func (s *ClosingSummary) Scan(src any) error {
+	b, ok := src.([]byte)
+	if !ok {
+		return errors.New("closing summary must be JSON")
+	}
+	return json.Unmarshal(b, s)
+}
+
+func FromDailyClosing(c model.DailyClosing) DailyClosing {
+	object := DailyClosing{
+		ID:           c.ID,
+		BusinessDate: c.BusinessDate,
+		PeriodFrom:   c.From,
+		PeriodTo:     c.To,
+		OpeningFloat: c.OpeningFloat,
+		ExpectedCash: c.ExpectedCash,
+		CountedCash:  c.CountedCash,
+		Notes:        c.Notes,
+		Summary: ClosingSummary{
+			ClosedOrders:   c.ClosedOrders,
+			OpenOrders:     []ClosingOpenOrder{},
+			GrossSales:     c.GrossSales,
+			Discounts:      c.Discounts,
+			NetSales:       c.NetSales,
+			Taxes:          c.Taxes,
+			Total:          c.Total,
+			Tips:           c.Tips,
+			Refunds:        c.Refunds,
+			Refunded:       c.Refunded,
+			TaxLines:       []ClosingTaxLine{},
+			Tenders:        []ClosingTenderLine{},
+			CashDifference: c.CashDifference,
+		},
+	}
+
+	for _, o := range c.OpenOrders {
+		object.Summary.OpenOrders = append(object.Summary.OpenOrders, ClosingOpenOrder(o))
+	}
+	for _, l := range c.TaxLines {
+		object.Summary.TaxLines = append(object.Summary.TaxLines, ClosingTaxLine(l))
+	}
+	for _, l := range c.Tenders {
+		object.Summary.Tenders = append(object.Summary.Tenders, ClosingTenderLine(l))
+	}
+
+	return object
+}
+
+// ToDailyClosing converts the stored closing. The business date is returned as
+// midnight in the given location.
+func ToDailyClosing(c DailyClosing, location *time.Location) model.DailyClosing {
+	closing := model.DailyClosing{
+		ID:             c.ID,
+		BusinessDate:   time.Date(c.BusinessDate.Year(), c.BusinessDate.Month(), c.BusinessDate.Day(), 0, 0, 0, 0, location),
+		Period:         model.Period{From: c.PeriodFrom.In(location), To: c.PeriodTo.In(location)},
+		ClosedOrders:   c.Summary.ClosedOrders,
+		GrossSales:     c.Summary.GrossSales,
+		Discounts:      c.Summary.Discounts,
+		NetSales:       c.Summary.NetSales,
+		Taxes:          c.Summary.Taxes,
+		Total:          c.Summary.Total,
+		Tips:           c.Summary.Tips,
+		Refunds:        c.Summary.Refunds,
+		Refunded:       c.Summary.Refunded,
+		OpeningFloat:   c.OpeningFloat,
+		ExpectedCash:   c.ExpectedCash,
+		CountedCash:    c.CountedCash,
+		CashDifference: c.Summary.CashDifference,
+		Notes:          c.Notes,
+		CreatedAt:      c.CreatedAt,
+	}
+
+	for _, o := range c.Summary.OpenOrders {
+		closing.OpenOrders = append(closing.OpenOrders, model.OpenOrder(o))
+	}
+	for _, l := range c.Summary.TaxLines {
+		closing.TaxLines = append(closing.TaxLines, model.TaxReportLine(l))
+	}
+	for _, l := range c.Summary.Tenders {
+		closing.Tenders = append(closing.Tenders, model.PaymentReportLine(l))
+	}
+
+	return closing
+}
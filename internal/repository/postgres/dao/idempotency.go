@@ -0,0 +1,60 @@
+package dao
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"coffee-shop/internal/model"
+)
+
+type IdempotencyRecord struct {
+	Key         string        `json:"key" db:"idempotencykey"`
+	RequestHash string        `json:"request_hash" db:"requesthash"`
+	Completed   bool          `json:"completed" db:"completed"`
+	Status      sql.NullInt64 `json:"status" db:"status"`
+	Header      []byte        `json:"header" db:"header"`
+	Body        []byte        `json:"body" db:"body"`
+	CreatedAt   time.Time     `json:"created_at" db:"createdat"`
+	ExpiresAt   time.Time     `json:"expires_at" db:"expiresat"`
+}
+
+func FromIdempotencyRecord(r model.IdempotencyRecord) (IdempotencyRecord, error) {
+	object := IdempotencyRecord{
+		Key:         r.Key,
+		RequestHash: r.RequestHash,
+		Completed:   r.Completed,
+		Status:      sql.NullInt64{Int64: int64(r.Status), Valid: r.Completed},
+		Body:        r.Body,
+		CreatedAt:   r.CreatedAt,
+		ExpiresAt:   r.ExpiresAt,
+	}
+
+	if r.Header != nil {
+		header, err := json.Marshal(r.Header)
+		if err != nil {
+			return IdempotencyRecord{}, err
+		}
+		object.Header = header
+	}
+	return object, nil
+}
+
+func ToIdempotencyRecord(r IdempotencyRecord) (model.IdempotencyRecord, error) {
+	record := model.IdempotencyRecord{
+		Key:         r.Key,
+		RequestHash: r.RequestHash,
+		Completed:   r.Completed,
+		Status:      int(r.Status.Int64),
+		Body:        r.Body,
+		CreatedAt:   r.CreatedAt,
+		ExpiresAt:   r.ExpiresAt,
+	}
+
+	if len(r.Header) > 0 {
+		if err := json.Unmarshal(r.Header, &record.Header); err != nil {
+			return model.IdempotencyRecord{}, err
+		}
+	}
+	return record, nil
+}
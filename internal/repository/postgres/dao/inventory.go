@@ -1,12 +1,19 @@
 package dao
 
-import "coffee-shop/internal/model"
+import (
+	"database/sql"
+	"time"
+
+	"coffee-shop/internal/model"
+)
 
 type Inventory struct {
-	Id       int    `json:"ingredient_id" db:"ingredientid"`
-	Name     string `json:"name" db:"name"`
-	Quantity int    `json:"quantity" db:"quantity"`
-	Unit     string `json:"unit" db:"unit"`
+	Id         int          `json:"ingredient_id" db:"ingredientid"`
+	Name       string       `json:"name" db:"name"`
+	Quantity   int          `json:"quantity" db:"quantity"`
+	Unit       string       `json:"unit" db:"unit"`
+	Version    int          `json:"version" db:"version"`
+	ArchivedAt sql.NullTime `json:"archived_at" db:"archivedat"`
 }
 
 func FromInventory(item model.Inventory) Inventory {
@@ -14,6 +21,11 @@ func FromInventory(item model.Inventory) Inventory {
 		Name:     item.Name,
 		Quantity: item.Quantity,
 		Unit:     item.Unit,
+		Version:  item.Version,
+		ArchivedAt: sql.NullTime{
+			Time:  item.ArchivedAt,
+			Valid: item.IsArchived(),
+		},
 	}
 }
 
@@ -23,5 +35,34 @@ func ToInventory(item Inventory) model.Inventory {
 		Name:         item.Name,
 		Quantity:     item.Quantity,
 		Unit:         item.Unit,
+		Version:      item.Version,
+		ArchivedAt:   item.ArchivedAt.Time,
+	}
+}
+
+type InventoryTransactions struct {
+	TransactionID  int       `json:"transaction_id" db:"transactionid"`
+	IngredientID   int       `json:"ingredient_id" db:"ingredientid"`
+	QuantityChange int       `json:"quantity_change" db:"quantity_change"`
+	Reason         string    `json:"reason" db:"reason"`
+	CreatedAt      time.Time `json:"created_at" db:"createdat"`
+}
+
+func FromInventoryTransactions(t model.InventoryTransactions) InventoryTransactions {
+	return InventoryTransactions{
+		TransactionID:  t.TransactionID,
+		IngredientID:   t.IngredientId,
+		QuantityChange: t.QuantityChange,
+		Reason:         t.Reason,
+	}
+}
+
+func ToInventoryTransactions(t InventoryTransactions) model.InventoryTransactions {
+	return model.InventoryTransactions{
+		TransactionID:  t.TransactionID,
+		IngredientId:   t.IngredientID,
+		QuantityChange: t.QuantityChange,
+		Reason:         t.Reason,
+		CreatedAt:      t.CreatedAt,
 	}
 }
@@ -1,12 +1,19 @@
 package dao
 
-import "coffee-shop/internal/model"
+import (
+	"coffee-shop/internal/model"
+	"database/sql"
+	"time"
+)
 
 type MenuItem struct {
-	Id          int     `json:"id" db:"id"`
-	Name        string  `json:"name" db:"name"`
-	Description string  `json:"description" db:"description"`
-	Price       float64 `json:"price" db:"price"`
+	Id          int          `json:"id" db:"id"`
+	Name        string       `json:"name" db:"name"`
+	Description string       `json:"description" db:"description"`
+	Category    string       `json:"category" db:"category"`
+	Price       float64      `json:"price" db:"price"`
+	Version     int          `json:"version" db:"version"`
+	ArchivedAt  sql.NullTime `json:"archived_at" db:"archivedat"`
 }
 
 func FromMenu(m model.MenuItem) MenuItem {
@@ -14,7 +21,13 @@ func FromMenu(m model.MenuItem) MenuItem {
 		Id:          m.ID,
 		Name:        m.Name,
 		Description: m.Description,
+		Category:    m.Category,
 		Price:       m.Price,
+		Version:     m.Version,
+		ArchivedAt: sql.NullTime{
+			Time:  m.ArchivedAt,
+			Valid: m.IsArchived(),
+		},
 	}
 }
 
@@ -23,13 +36,16 @@ func ToMenu(m MenuItem) model.MenuItem {
 		ID:          m.Id,
 		Name:        m.Name,
 		Description: m.Description,
+		Category:    m.Category,
 		Price:       m.Price,
+		Version:     m.Version,
+		ArchivedAt:  m.ArchivedAt.Time,
 	}
 }
 
 type MenuItemIngredients struct {
-	MenuID       int `json:"menu_id" db:"menu_id"`
-	IngredientID int `json:"ingredient_id" db:"ingredient_id"`
+	MenuID       int `json:"menu_id" db:"menuid"`
+	IngredientID int `json:"ingredient_id" db:"ingredientid"`
 	Quantity     int `json:"quantity" db:"quantity"`
 }
 
@@ -48,3 +64,31 @@ func ToIngredients(m MenuItemIngredients) model.MenuItemIngredients {
 		Quantity:     m.Quantity,
 	}
 }
+
+type PriceHistory struct {
+	HistoryID  int       `json:"history_id" db:"historyid"`
+	MenuItemID int       `json:"menu_item_id" db:"menu_itemid"`
+	OldPrice   float64   `json:"old_price" db:"old_price"`
+	NewPrice   float64   `json:"new_price" db:"new_price"`
+	ChangedAt  time.Time `json:"changed_at" db:"changedat"`
+}
+
+func FromPriceHistory(m model.PriceHistory) PriceHistory {
+	return PriceHistory{
+		HistoryID:  m.HistoryID,
+		MenuItemID: m.MenuItemID,
+		OldPrice:   m.OldPrice,
+		NewPrice:   m.NewPrice,
+		ChangedAt:  m.ChangedAt,
+	}
+}
+
+func ToPriceHistory(m PriceHistory) model.PriceHistory {
+	return model.PriceHistory{
+		HistoryID:  m.HistoryID,
+		MenuItemID: m.MenuItemID,
+		OldPrice:   m.OldPrice,
+		NewPrice:   m.NewPrice,
+		ChangedAt:  m.ChangedAt,
+	}
+}
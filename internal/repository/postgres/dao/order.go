@@ -1,21 +1,38 @@
 package dao
 
-import "coffee-shop/internal/model"
+import (
+	"database/sql"
+	"time"
+
+	"coffee-shop/internal/model"
+
+	"github.com/lib/pq"
+)
 
 type Order struct {
-	OrderID      int    `json:"order_id" db:"order_id"`
-	CustomerName string `json:"customer_name" db:"customer_name"`
-	Status       string `json:"status" db:"status"`
-	Notes        string `json:"notes" db:"notes"`
-	CreatedAt    string `json:"created_at" db:"created_at"`
+	OrderID       int             `json:"order_id" db:"id"`
+	CustomerName  string          `json:"customer_name" db:"customername"`
+	Status        string          `json:"status" db:"status"`
+	Notes         string          `json:"notes" db:"notes"`
+	DiscountTotal float64         `json:"discount_total" db:"discounttotal"`
+	Subtotal      sql.NullFloat64 `json:"subtotal" db:"subtotal"`
+	TaxTotal      sql.NullFloat64 `json:"tax_total" db:"taxtotal"`
+	Total         sql.NullFloat64 `json:"total" db:"total"`
+	CreatedAt     time.Time       `json:"created_at" db:"createdat"`
+	ClosedAt      sql.NullTime    `json:"closed_at" db:"closedat"`
+	Reserved      bool            `json:"reserved" db:"reserved"`
+	Version       int             `json:"version" db:"version"`
 }
 
 func FromOrder(o model.Order) Order {
 	return Order{
-		OrderID:      o.ID,
-		CustomerName: o.CustomerName,
-		Status:       o.Status,
-		Notes:        o.Notes,
+		OrderID:       o.ID,
+		CustomerName:  o.CustomerName,
+		Status:        o.Status,
+		Notes:         o.Notes,
+		DiscountTotal: o.DiscountTotal,
+		Reserved:      o.Reserved,
+		Version:       o.Version,
 	}
 }
 
@@ -25,13 +42,25 @@ func ToOrder(o Order) model.Order {
 		CustomerName: o.CustomerName,
 		Status:       o.Status,
 		Notes:        o.Notes,
+		CreateAt:     o.CreatedAt,
+		ClosedAt:     o.ClosedAt.Time,
+		Reserved:     o.Reserved,
+		Version:      o.Version,
+		OrderTotals: model.OrderTotals{
+			Subtotal:      o.Subtotal.Float64,
+			DiscountTotal: o.DiscountTotal,
+			TaxTotal:      o.TaxTotal.Float64,
+			Total:         o.Total.Float64,
+		},
 	}
 }
 
 type OrderItems struct {
-	OrderID   int `json:"order_id" db:"order_id"`
-	ProductID int `json:"product_id" db:"product_id"`
-	Quantity  int `json:"quantity" db:"quantity"`
+	OrderID   int            `json:"order_id" db:"orderid"`
+	ProductID int            `json:"product_id" db:"productid"`
+	Quantity  int            `json:"quantity" db:"quantity"`
+	Price     float64        `json:"price" db:"price"`
+	Modifiers pq.StringArray `json:"modifiers" db:"modifiers"`
 }
 
 func FromOrderItems(o model.OrderItems) OrderItems {
@@ -39,6 +68,8 @@ func FromOrderItems(o model.OrderItems) OrderItems {
 		OrderID:   o.OrderID,
 		ProductID: o.ProductID,
 		Quantity:  o.Quantity,
+		Price:     o.Price,
+		Modifiers: pq.StringArray(o.Modifiers),
 	}
 }
 
@@ -47,14 +78,16 @@ func ToOrderItems(o OrderItems) model.OrderItems {
 		OrderID:   o.OrderID,
 		ProductID: o.ProductID,
 		Quantity:  o.Quantity,
+		Price:     o.Price,
+		Modifiers: []string(o.Modifiers),
 	}
 }
 
 type OrderStatusHistory struct {
-	ID       int    `json:"id" db:"id"`
-	OrderID  int    `json:"order_id" db:"order_id"`
-	OpenedAt string `json:"opened_at" db:"opened_at"`
-	ClosedAt string `json:"closed_at" db:"closed_at"`
+	ID       int          `json:"id" db:"id"`
+	OrderID  int          `json:"order_id" db:"orderid"`
+	OpenedAt time.Time    `json:"opened_at" db:"openedat"`
+	ClosedAt sql.NullTime `json:"closed_at" db:"closedat"`
 }
 
 func FromOrderStatusHistory(o model.OrderStatusHistory) OrderStatusHistory {
@@ -66,7 +99,55 @@ func FromOrderStatusHistory(o model.OrderStatusHistory) OrderStatusHistory {
 
 func ToOrderStatusHistory(o OrderStatusHistory) model.OrderStatusHistory {
 	return model.OrderStatusHistory{
-		ID:      o.ID,
-		OrderID: o.OrderID,
+		ID:       o.ID,
+		OrderID:  o.OrderID,
+		OpenedAt: o.OpenedAt,
+		ClosedAt: o.ClosedAt.Time,
 	}
 }
+
+type TaxLine struct {
+	OrderID       int     `json:"order_id" db:"orderid"`
+	Category      string  `json:"category" db:"category"`
+	Rate          float64 `json:"rate" db:"rate"`
+	Inclusive     bool    `json:"inclusive" db:"inclusive"`
+	TaxableAmount float64 `json:"taxable_amount" db:"taxableamount"`
+	TaxAmount     float64 `json:"tax_amount" db:"taxamount"`
+}
+
+func FromTaxLine(t model.TaxLine) TaxLine {
+	return TaxLine{
+		OrderID:       t.OrderID,
+		Category:      t.Category,
+		Rate:          t.Rate,
+		Inclusive:     t.Inclusive,
+		TaxableAmount: t.TaxableAmount,
+		TaxAmount:     t.TaxAmount,
+	}
+}
+
+func ToTaxLine(t TaxLine) model.TaxLine {
+	return model.TaxLine{
+		OrderID:       t.OrderID,
+		Category:      t.Category,
+		Rate:          t.Rate,
+		Inclusive:     t.Inclusive,
+		TaxableAmount: t.TaxableAmount,
+		TaxAmount:     t.TaxAmount,
+	}
+}
+
+type OrderConsumption struct {
+	OrderID      int `json:"order_id" db:"orderid"`
+	ProductID    int `json:"product_id" db:"productid"`
+	IngredientID int `json:"ingredient_id" db:"ingredientid"`
+	Quantity     int `json:"quantity" db:"quantity"`
+}
+
+func FromOrderConsumption(c model.OrderConsumption) OrderConsumption {
+	return OrderConsumption(c)
+}
+
+func ToOrderConsumption(c OrderConsumption) model.OrderConsumption {
+	return model.OrderConsumption(c)
+}
@@ -0,0 +1,53 @@
+package dao
+
+import (
+	"database/sql"
+	"time"
+
+	"coffee-shop/internal/model"
+)
+
+type Payment struct {
+	ID        int           `json:"id" db:"id"`
+	OrderID   int           `json:"order_id" db:"orderid"`
+	Tender    string        `json:"tender" db:"tender"`
+	Amount    float64       `json:"amount" db:"amount"`
+	Tip       float64       `json:"tip" db:"tip"`
+	Tendered  float64       `json:"tendered" db:"tendered"`
+	Change    float64       `json:"change" db:"change"`
+	RefundOf  sql.NullInt64 `json:"refund_of" db:"refundof"`
+	Reference string        `json:"reference" db:"reference"`
+	Reason    string        `json:"reason" db:"reason"`
+	CreatedAt time.Time     `json:"created_at" db:"createdat"`
+}
+
+func FromPayment(p model.Payment) Payment {
+	return Payment{
+		ID:        p.ID,
+		OrderID:   p.OrderID,
+		Tender:    p.Tender,
+		Amount:    p.Amount,
+		Tip:       p.Tip,
+		Tendered:  p.Tendered,
+		Change:    p.Change,
+		RefundOf:  sql.NullInt64{Int64: int64(p.RefundOf), Valid: p.RefundOf > 0},
+		Reference: p.Reference,
+		Reason:    p.Reason,
+	}
+}
+
+func ToPayment(p Payment) model.Payment {
+	return model.Payment{
+		ID:        p.ID,
+		OrderID:   p.OrderID,
+		Tender:    p.Tender,
+		Amount:    p.Amount,
+		Tip:       p.Tip,
+		Tendered:  p.Tendered,
+		Change:    p.Change,
+		RefundOf:  int(p.RefundOf.Int64),
+		Reference: p.Reference,
+		Reason:    p.Reason,
+		CreatedAt: p.CreatedAt,
+	}
+}
@@ -0,0 +1,38 @@
+package dao
+
+import (
+	"database/sql"
+	"time"
+
+	"coffee-shop/internal/model"
+)
+
+type Staff struct {
+	ID           int            `json:"id" db:"id"`
+	Username     string         `json:"username" db:"username"`
+	Role         string         `json:"role" db:"role"`
+	PasswordHash sql.NullString `json:"password_hash" db:"passwordhash"`
+	APIKeyHash   sql.NullString `json:"api_key_hash" db:"apikeyhash"`
+	CreatedAt    time.Time      `json:"created_at" db:"createdat"`
+}
+
+func FromStaff(s model.Staff) Staff {
+	return Staff{
+		ID:           s.ID,
+		Username:     s.Username,
+		Role:         s.Role,
+		PasswordHash: sql.NullString{String: s.PasswordHash, Valid: s.PasswordHash != ""},
+		APIKeyHash:   sql.NullString{String: s.APIKeyHash, Valid: s.APIKeyHash != ""},
+	}
+}
+
+func ToStaff(s Staff) model.Staff {
+	return model.Staff{
+		ID:           s.ID,
+		Username:     s.Username,
+		Role:         s.Role,
+		PasswordHash: s.PasswordHash.String,
+		APIKeyHash:   s.APIKeyHash.String,
+		CreatedAt:    s.CreatedAt,
+	}
+}
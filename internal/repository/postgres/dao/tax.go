@@ -0,0 +1,25 @@
+package dao
+
+import "coffee-shop/internal/model"
+
+type TaxRate struct {
+	Category  string  `json:"category" db:"category"`
+	Rate      float64 `json:"rate" db:"rate"`
+	Inclusive bool    `json:"inclusive" db:"inclusive"`
+}
+
+func FromTaxRate(t model.TaxRate) TaxRate {
+	return TaxRate{
+		Category:  t.Category,
+		Rate:      t.Rate,
+		Inclusive: t.Inclusive,
+	}
+}
+
+func ToTaxRate(t TaxRate) model.TaxRate {
+	return model.TaxRate{
+		Category:  t.Category,
+		Rate:      t.Rate,
+		Inclusive: t.Inclusive,
+	}
+}
@@ -0,0 +1,86 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"coffee-shop/internal/model"
+
+	"github.com/lib/pq"
+)
+
+// Codes of the PostgreSQL errors that are reported as model errors.
+const (
+	codeNotNullViolation    = "23502"
+	codeForeignKeyViolation = "23503"
+	codeUniqueViolation     = "23505"
+	codeCheckViolation      = "23514"
+)
+
+// dbError translates an error of the database into the error taxonomy of the model.
+// A missing row becomes model.ErrNotFound and a broken constraint becomes the model
+// error of its kind, wrapped with the message of the database. Other errors are
+// returned as they are.
+func dbError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.ErrNotFound
+	}
+
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return err
+	}
+
+	switch pqErr.Code {
+	case codeUniqueViolation:
+		return fmt.Errorf("%w: %s", model.ErrDuplicate, pqErr.Message)
+	case codeForeignKeyViolation:
+		// The same code is used when the referenced row is missing and when a
+		// row that is still referenced is changed or deleted.
+		if strings.HasPrefix(pqErr.Message, "update or delete") {
+			return fmt.Errorf("%w: %s", model.ErrReferenced, pqErr.Message)
+		}
+		return fmt.Errorf("%w: %s", model.ErrMissingReference, pqErr.Message)
+	case codeCheckViolation, codeNotNullViolation:
+		return fmt.Errorf("%w: %s", model.ErrConstraint, pqErr.Message)
+	}
+	return err
+}
+
+// versioned returns nil if the statement changed a row. Otherwise it returns
+// model.ErrVersionMismatch if the row with the ID in the key column of the table
+// exists, as the statement was conditional on its version, and model.ErrNotFound if not.
+func versioned(ctx context.Context, db *sql.DB, res sql.Result, table, key string, id int) error {
+	err := found(res)
+	if !errors.Is(err, model.ErrNotFound) {
+		return err
+	}
+
+	var exists bool
+	query := "SELECT EXISTS (SELECT 1 FROM " + table + " WHERE " + key + " = $1)"
+	if err := conn(ctx, db).QueryRowContext(ctx, query, id).Scan(&exists); err != nil {
+		return err
+	}
+	if exists {
+		return model.ErrVersionMismatch
+	}
+	return model.ErrNotFound
+}
+
+// found returns model.ErrNotFound if the statement changed no rows.
+func found(res sql.Result) error {
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return model.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package postgres
+
+import (
+	"coffee-shop/internal/model"
+	"coffee-shop/internal/repository/postgres/dao"
+	"context"
+	"database/sql"
+	"time"
+)
+
+type IdempotencyKeys struct {
+	conn  *sql.DB
+	table string
+}
+
+const (
+	tableIdempotencyKeys = "idempotency_keys"
+)
+
+func NewIdempotencyKeys(conn *sql.DB) *IdempotencyKeys {
+	return &IdempotencyKeys{
+		conn:  conn,
+		table: tableIdempotencyKeys,
+	}
+}
+
+// Reserve stores the record unless a record with its key that expires after now
+// exists, and reports whether it did. Otherwise it returns the existing record.
+// Expired records are removed.
+func (r *IdempotencyKeys) Reserve(ctx context.Context, record model.IdempotencyRecord, now time.Time) (model.IdempotencyRecord, bool, error) {
+	object, err := dao.FromIdempotencyRecord(record)
+	if err != nil {
+		return model.IdempotencyRecord{}, false, err
+	}
+
+	_, err = conn(ctx, r.conn).ExecContext(ctx, "DELETE FROM "+r.table+" WHERE expiresat <= $1", now)
+	if err != nil {
+		return model.IdempotencyRecord{}, false, err
+	}
+
+	query := "INSERT INTO " + r.table + " (idempotencykey, requesthash, createdat, expiresat) VALUES ($1, $2, $3, $4) ON CONFLICT (idempotencykey) DO NOTHING"
+	res, err := conn(ctx, r.conn).ExecContext(ctx, query, object.Key, object.RequestHash, object.CreatedAt, object.ExpiresAt)
+	if err != nil {
+		return model.IdempotencyRecord{}, false, err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return model.IdempotencyRecord{}, false, err
+	}
+	if affected == 1 {
+		return model.IdempotencyRecord{}, true, nil
+	}
+
+	// The key is taken. A missing row means that it was released meanwhile.
+	var existing dao.IdempotencyRecord
+	query = "SELECT idempotencykey, requesthash, completed, status, header, body, createdat, expiresat FROM " + r.table + " WHERE idempotencykey = $1"
+	err = conn(ctx, r.conn).QueryRowContext(ctx, query, object.Key).Scan(&existing.Key, &existing.RequestHash, &existing.Completed,
+		&existing.Status, &existing.Header, &existing.Body, &existing.CreatedAt, &existing.ExpiresAt)
+	if err != nil {
+		return model.IdempotencyRecord{}, false, err
+	}
+
+	stored, err := dao.ToIdempotencyRecord(existing)
+	return stored, false, err
+}
+
+// Complete stores the response of the record with the key of the given record.
+func (r *IdempotencyKeys) Complete(ctx context.Context, record model.IdempotencyRecord) error {
+	object, err := dao.FromIdempotencyRecord(record)
+	if err != nil {
+		return err
+	}
+	query := "UPDATE " + r.table + " SET completed = $1, status = $2, header = $3, body = $4 WHERE idempotencykey = $5"
+
+	res, err := conn(ctx, r.conn).ExecContext(ctx, query, object.Completed, object.Status, nullJSON(object.Header), object.Body, object.Key)
+	if err != nil {
+		return err
+	}
+
+	return found(res)
+}
+
+func (r *IdempotencyKeys) Delete(ctx context.Context, key string) error {
+	res, err := conn(ctx, r.conn).ExecContext(ctx, "DELETE FROM "+r.table+" WHERE idempotencykey = $1", key)
+	if err != nil {
+		return err
+	}
+
+	return found(res)
+}
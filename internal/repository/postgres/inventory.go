@@ -5,6 +5,7 @@ import (
 	"coffee-shop/internal/repository/postgres/dao"
 	"context"
 	"database/sql"
+	"time"
 )
 
 type Inventory struct {
@@ -23,23 +24,25 @@ func NewInventory(conn *sql.DB) *Inventory {
 	}
 }
 
-func (i *Inventory) Create(ctx context.Context, item model.Inventory) error {
+// Create inserts a new inventory item and returns its generated ID.
+func (i *Inventory) Create(ctx context.Context, item model.Inventory) (int, error) {
 	object := dao.FromInventory(item)
-	query := "INSERT INTO " + i.table + " (name, quantity, unit) VALUES ($1, $2, $3)"
+	query := "INSERT INTO " + i.table + " (name, quantity, unit) VALUES ($1, $2, $3) RETURNING ingredientid"
 
-	_, err := i.conn.Exec(query, object.Name, object.Quantity, object.Unit)
+	var id int
+	err := conn(ctx, i.conn).QueryRowContext(ctx, query, object.Name, object.Quantity, object.Unit).Scan(&id)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
-	return nil
+	return id, nil
 }
 
 func (i *Inventory) Get(ctx context.Context, id int) (model.Inventory, error) {
 	var item dao.Inventory
-	query := "SELECT ingredientid, name, quantity, unit FROM " + i.table + " WHERE ingredientid = $1"
+	query := "SELECT ingredientid, name, quantity, unit, version, archivedat FROM " + i.table + " WHERE ingredientid = $1"
 
-	err := i.conn.QueryRow(query, id).Scan(&item.Id, &item.Name, &item.Quantity, &item.Unit)
+	err := conn(ctx, i.conn).QueryRowContext(ctx, query, id).Scan(&item.Id, &item.Name, &item.Quantity, &item.Unit, &item.Version, &item.ArchivedAt)
 	if err != nil {
 		return model.Inventory{}, err
 	}
@@ -48,47 +51,141 @@ func (i *Inventory) Get(ctx context.Context, id int) (model.Inventory, error) {
 }
 
 func (i *Inventory) GetAll(ctx context.Context) ([]model.Inventory, error) {
-	query := "SELECT * FROM " + i.table
-
-	rows, err := i.conn.Query(query)
+	var items []model.Inventory
+	err := i.Each(ctx, func(item model.Inventory) error {
+		items = append(items, item)
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
+
+	return items, nil
+}
+
+// Each calls fn for every inventory item in the order of their IDs, reading them one at a time.
+// An error returned by fn stops the iteration and is returned.
+func (i *Inventory) Each(ctx context.Context, fn func(item model.Inventory) error) error {
+	query := "SELECT ingredientid, name, quantity, unit, version, archivedat FROM " + i.table + " ORDER BY ingredientid"
+
+	rows, err := conn(ctx, i.conn).QueryContext(ctx, query)
+	if err != nil {
+		return err
+	}
 	defer rows.Close()
 
-	var items []model.Inventory
 	for rows.Next() {
 		var item dao.Inventory
-		err := rows.Scan(&item.Id, &item.Name, &item.Quantity, &item.Unit)
+		err := rows.Scan(&item.Id, &item.Name, &item.Quantity, &item.Unit, &item.Version, &item.ArchivedAt)
 		if err != nil {
-			return nil, err
+			return err
 		}
 
-		items = append(items, dao.ToInventory(item))
+		if err := fn(dao.ToInventory(item)); err != nil {
+			return err
+		}
 	}
 
-	return items, nil
+	return rows.Err()
 }
 
+// Update replaces the item and increments its version. If the version of item is set,
+// only the item with that version is replaced.
 func (i *Inventory) Update(ctx context.Context, id int, item model.Inventory) error {
-	query := "UPDATE " + i.table + " SET name = $1, quantity = $2, unit = $3 WHERE ingredientid = $4"
+	query := "UPDATE " + i.table + " SET name = $1, quantity = $2, unit = $3, version = version + 1 WHERE ingredientid = $4 AND ($5 = 0 OR version = $5)"
 	daoItem := dao.FromInventory(item)
 
-	_, err := i.conn.Exec(query, daoItem.Name, daoItem.Quantity, daoItem.Unit, id)
+	res, err := conn(ctx, i.conn).ExecContext(ctx, query, daoItem.Name, daoItem.Quantity, daoItem.Unit, id, daoItem.Version)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	return versioned(ctx, i.conn, res, i.table, "ingredientid", id)
+}
+
+// AdjustQuantity adds delta to the quantity of the ingredient.
+// It reports false if the ingredient does not exist or the stock would become negative.
+func (i *Inventory) AdjustQuantity(ctx context.Context, id int, delta int) (bool, error) {
+	query := "UPDATE " + i.table + " SET quantity = quantity + $1, version = version + 1 WHERE ingredientid = $2 AND quantity + $1 >= 0"
+
+	res, err := conn(ctx, i.conn).ExecContext(ctx, query, delta, id)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+// Patch changes the fields set in the patch and increments the version of the item.
+// If version is set, only the item with that version is changed.
+func (i *Inventory) Patch(ctx context.Context, id int, version int, patch model.InventoryPatch) error {
+	var changes assignments
+	if patch.Name != nil {
+		changes.set("name", *patch.Name)
+	}
+	if patch.Quantity != nil {
+		changes.set("quantity", *patch.Quantity)
+	}
+	if patch.Unit != nil {
+		changes.set("unit", *patch.Unit)
+	}
+	query, args := changes.update(i.table, "ingredientid", id, version)
+
+	res, err := conn(ctx, i.conn).ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	return versioned(ctx, i.conn, res, i.table, "ingredientid", id)
+}
+
+// Archive sets the time the item was archived at and increments its version. If
+// version is set, only the item with that version is archived.
+func (i *Inventory) Archive(ctx context.Context, id int, version int, archivedAt time.Time) error {
+	query := "UPDATE " + i.table + " SET archivedat = $1, version = version + 1 WHERE ingredientid = $2 AND ($3 = 0 OR version = $3)"
+
+	res, err := conn(ctx, i.conn).ExecContext(ctx, query, archivedAt, id, version)
+	if err != nil {
+		return err
+	}
+
+	return versioned(ctx, i.conn, res, i.table, "ingredientid", id)
+}
+
+// Restore clears the archival time of the item and increments its version. If
+// version is set, only the item with that version is restored.
+func (i *Inventory) Restore(ctx context.Context, id int, version int) error {
+	query := "UPDATE " + i.table + " SET archivedat = NULL, version = version + 1 WHERE ingredientid = $1 AND ($2 = 0 OR version = $2)"
+
+	res, err := conn(ctx, i.conn).ExecContext(ctx, query, id, version)
+	if err != nil {
+		return err
+	}
+
+	return versioned(ctx, i.conn, res, i.table, "ingredientid", id)
+}
+
+// References counts the recipes and ledger entries that refer to the item.
+func (i *Inventory) References(ctx context.Context, id int) ([]model.Reference, error) {
+	return references(ctx, i.conn, id,
+		referrer{model.ReferenceRecipes, tableMenuItemIngredients, "ingredientid"},
+		referrer{model.ReferenceInventoryLedger, tableInventoryTransactions, "ingredientid"},
+	)
 }
 
-func (i *Inventory) Delete(ctx context.Context, id int) error {
-	query := "DELETE FROM " + i.table + " WHERE ingredientid = $1"
+// Delete deletes the item. If version is set, only the item with that version is deleted.
+func (i *Inventory) Delete(ctx context.Context, id int, version int) error {
+	query := "DELETE FROM " + i.table + " WHERE ingredientid = $1 AND ($2 = 0 OR version = $2)"
 
-	_, err := i.conn.Exec(query, id)
+	res, err := conn(ctx, i.conn).ExecContext(ctx, query, id, version)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	return versioned(ctx, i.conn, res, i.table, "ingredientid", id)
 }
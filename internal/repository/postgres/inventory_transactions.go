@@ -0,0 +1,61 @@
+package postgres
+
+import (
+	"coffee-shop/internal/model"
+	"coffee-shop/internal/repository/postgres/dao"
+	"context"
+	"database/sql"
+)
+
+type InventoryTransactions struct {
+	conn  *sql.DB
+	table string
+}
+
+const (
+	tableInventoryTransactions = "inventory_transactions"
+)
+
+func NewInventoryTransactions(conn *sql.DB) *InventoryTransactions {
+	return &InventoryTransactions{
+		conn:  conn,
+		table: tableInventoryTransactions,
+	}
+}
+
+// Create appends a new record to the inventory ledger.
+func (r *InventoryTransactions) Create(ctx context.Context, transaction model.InventoryTransactions) error {
+	object := dao.FromInventoryTransactions(transaction)
+	query := "INSERT INTO " + r.table + " (ingredientid, quantity_change, reason) VALUES ($1, $2, $3)"
+
+	_, err := conn(ctx, r.conn).ExecContext(ctx, query, object.IngredientID, object.QuantityChange, object.Reason)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GetAllWithID returns the ledger records of the ingredient with the given ID, newest first.
+func (r *InventoryTransactions) GetAllWithID(ctx context.Context, id int) ([]model.InventoryTransactions, error) {
+	var transactions []model.InventoryTransactions
+	query := "SELECT transactionid, ingredientid, quantity_change, reason, createdat FROM " + r.table + " WHERE ingredientid = $1 ORDER BY createdat DESC, transactionid DESC"
+
+	rows, err := conn(ctx, r.conn).QueryContext(ctx, query, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var t dao.InventoryTransactions
+		err := rows.Scan(&t.TransactionID, &t.IngredientID, &t.QuantityChange, &t.Reason, &t.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		transactions = append(transactions, dao.ToInventoryTransactions(t))
+	}
+
+	return transactions, rows.Err()
+}
@@ -5,6 +5,7 @@ import (
 	"coffee-shop/internal/repository/postgres/dao"
 	"context"
 	"database/sql"
+	"time"
 )
 
 type Menu struct {
@@ -13,7 +14,7 @@ type Menu struct {
 }
 
 const (
-	tableMenu = "menu"
+	tableMenu = "menu_items"
 )
 
 func NewMenu(conn *sql.DB) *Menu {
@@ -23,25 +24,27 @@ func NewMenu(conn *sql.DB) *Menu {
 	}
 }
 
-func (r *Menu) Create(ctx context.Context, menu model.MenuItem) error {
+// Create inserts a new menu item and returns its generated ID.
+func (r *Menu) Create(ctx context.Context, menu model.MenuItem) (int, error) {
 	object := dao.FromMenu(menu)
-	query := "INSERT INTO " + r.table + " (name, description, price) VALUES ($1, $2, $3)"
+	query := "INSERT INTO " + r.table + " (name, description, category, price) VALUES ($1, $2, $3, $4) RETURNING id"
 
-	_, err := r.conn.Exec(query, object.Name, object.Description, object.Price)
+	var id int
+	err := conn(ctx, r.conn).QueryRowContext(ctx, query, object.Name, object.Description, object.Category, object.Price).Scan(&id)
 	if err != nil {
-		return nil
+		return 0, err
 	}
 
-	return nil
+	return id, nil
 }
 
 func (r *Menu) Get(ctx context.Context, id int) (model.MenuItem, error) {
 	var menu dao.MenuItem
-	query := "SELECT id, name, description, price FROM " + r.table + " WHERE id = $1"
+	query := "SELECT id, name, description, category, price, version, archivedat FROM " + r.table + " WHERE id = $1"
 
-	err := r.conn.QueryRow(query, id).Scan(&menu.Id, &menu.Name, &menu.Description, &menu.Price)
+	err := conn(ctx, r.conn).QueryRowContext(ctx, query, id).Scan(&menu.Id, &menu.Name, &menu.Description, &menu.Category, &menu.Price, &menu.Version, &menu.ArchivedAt)
 	if err != nil {
-		return model.MenuItem{}, nil
+		return model.MenuItem{}, err
 	}
 
 	return dao.ToMenu(menu), nil
@@ -49,45 +52,111 @@ func (r *Menu) Get(ctx context.Context, id int) (model.MenuItem, error) {
 
 func (r *Menu) GetAll(ctx context.Context) ([]model.MenuItem, error) {
 	var menu_all []model.MenuItem
-	query := "SELECT * FROM " + r.table
+	query := "SELECT id, name, description, category, price, version, archivedat FROM " + r.table + " ORDER BY id"
 
-	rows, err := r.conn.Query(query)
+	rows, err := conn(ctx, r.conn).QueryContext(ctx, query)
 	if err != nil {
 		return []model.MenuItem{}, err
 	}
+	defer rows.Close()
 
 	for rows.Next() {
 		var menu_item dao.MenuItem
-		err := rows.Scan(&menu_item.Id, &menu_item.Name, &menu_item.Description, &menu_item.Price)
+		err := rows.Scan(&menu_item.Id, &menu_item.Name, &menu_item.Description, &menu_item.Category, &menu_item.Price, &menu_item.Version, &menu_item.ArchivedAt)
 		if err != nil {
-			return []model.MenuItem{}, nil
+			return []model.MenuItem{}, err
 		}
 
 		menu_all = append(menu_all, dao.ToMenu(menu_item))
 	}
 
-	return menu_all, nil
+	return menu_all, rows.Err()
 }
 
+// Update replaces the menu item and increments its version. If the version of menu
+// is set, only the item with that version is replaced.
 func (r *Menu) Update(ctx context.Context, id int, menu model.MenuItem) error {
 	object := dao.FromMenu(menu)
-	query := "UPDATE " + r.table + " SET name = $1, description = $2, price = $3 WHERE id = $4"
+	query := "UPDATE " + r.table + " SET name = $1, description = $2, category = $3, price = $4, version = version + 1 WHERE id = $5 AND ($6 = 0 OR version = $6)"
 
-	_, err := r.conn.Exec(query, object.Name, object.Description, object.Price, id)
+	res, err := conn(ctx, r.conn).ExecContext(ctx, query, object.Name, object.Description, object.Category, object.Price, id, object.Version)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	return versioned(ctx, r.conn, res, r.table, "id", id)
 }
 
-func (r *Menu) Delete(ctx context.Context, id int) error {
-	query := "DELETE FROM " + r.table + " WHERE id = $1"
+// Patch changes the fields set in the patch and increments the version of the menu
+// item. If version is set, only the item with that version is changed.
+func (r *Menu) Patch(ctx context.Context, id int, version int, patch model.MenuItemPatch) error {
+	var changes assignments
+	if patch.Name != nil {
+		changes.set("name", *patch.Name)
+	}
+	if patch.Description != nil {
+		changes.set("description", *patch.Description)
+	}
+	if patch.Category != nil {
+		changes.set("category", *patch.Category)
+	}
+	if patch.Price != nil {
+		changes.set("price", *patch.Price)
+	}
+	query, args := changes.update(r.table, "id", id, version)
+
+	res, err := conn(ctx, r.conn).ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	return versioned(ctx, r.conn, res, r.table, "id", id)
+}
+
+// Archive sets the time the menu item was taken off the menu at and increments its
+// version. If version is set, only the item with that version is archived.
+func (r *Menu) Archive(ctx context.Context, id int, version int, archivedAt time.Time) error {
+	query := "UPDATE " + r.table + " SET archivedat = $1, version = version + 1 WHERE id = $2 AND ($3 = 0 OR version = $3)"
+
+	res, err := conn(ctx, r.conn).ExecContext(ctx, query, archivedAt, id, version)
+	if err != nil {
+		return err
+	}
+
+	return versioned(ctx, r.conn, res, r.table, "id", id)
+}
+
+// Restore puts the menu item back on the menu and increments its version. If version
+// is set, only the item with that version is restored.
+func (r *Menu) Restore(ctx context.Context, id int, version int) error {
+	query := "UPDATE " + r.table + " SET archivedat = NULL, version = version + 1 WHERE id = $1 AND ($2 = 0 OR version = $2)"
+
+	res, err := conn(ctx, r.conn).ExecContext(ctx, query, id, version)
+	if err != nil {
+		return err
+	}
+
+	return versioned(ctx, r.conn, res, r.table, "id", id)
+}
+
+// References counts the order items, refunded items and price changes that refer to
+// the menu item. Its own ingredients are not counted, as they are deleted with it.
+func (r *Menu) References(ctx context.Context, id int) ([]model.Reference, error) {
+	return references(ctx, r.conn, id,
+		referrer{model.ReferenceOrderItems, tableOrderItems, "productid"},
+		referrer{model.ReferenceRefundItems, tableRefundItems, "productid"},
+		referrer{model.ReferencePriceHistory, tablePriceHistory, "menu_itemid"},
+	)
+}
+
+// Delete deletes the menu item. If version is set, only the item with that version is deleted.
+func (r *Menu) Delete(ctx context.Context, id int, version int) error {
+	query := "DELETE FROM " + r.table + " WHERE id = $1 AND ($2 = 0 OR version = $2)"
 
-	_, err := r.conn.Exec(query, id)
+	res, err := conn(ctx, r.conn).ExecContext(ctx, query, id, version)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	return versioned(ctx, r.conn, res, r.table, "id", id)
 }
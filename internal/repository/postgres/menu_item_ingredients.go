@@ -25,9 +25,9 @@ func NewMenuItemIngredients(conn *sql.DB) *MenuItemIngredients {
 
 func (r *MenuItemIngredients) Create(ctx context.Context, menu_ingredients model.MenuItemIngredients) error {
 	object := dao.FromIngredients(menu_ingredients)
-	query := "INSERT INTO " + r.table + " (menu_id, ingredient_id, quantity) VALUES $1, $2, $3"
+	query := "INSERT INTO " + r.table + " (menuid, ingredientid, quantity) VALUES ($1, $2, $3)"
 
-	_, err := r.conn.Exec(query, object.MenuID, object.IngredientID, object.Quantity)
+	_, err := conn(ctx, r.conn).ExecContext(ctx, query, object.MenuID, object.IngredientID, object.Quantity)
 	if err != nil {
 		return err
 	}
@@ -35,14 +35,16 @@ func (r *MenuItemIngredients) Create(ctx context.Context, menu_ingredients model
 	return nil
 }
 
+// GetAllWithID returns the ingredients of the menu item with the given ID.
 func (r *MenuItemIngredients) GetAllWithID(ctx context.Context, id int) ([]model.MenuItemIngredients, error) {
 	var ingredients []model.MenuItemIngredients
-	query := "SELECT menu_id, ingredient_id, quantity FROM " + r.table + "WHERE menu_id = $1"
+	query := "SELECT menuid, ingredientid, quantity FROM " + r.table + " WHERE menuid = $1 ORDER BY ingredientid"
 
-	rows, err := r.conn.Query(query)
+	rows, err := conn(ctx, r.conn).QueryContext(ctx, query, id)
 	if err != nil {
 		return []model.MenuItemIngredients{}, err
 	}
+	defer rows.Close()
 
 	for rows.Next() {
 		var ing dao.MenuItemIngredients
@@ -54,14 +56,15 @@ func (r *MenuItemIngredients) GetAllWithID(ctx context.Context, id int) ([]model
 		ingredients = append(ingredients, dao.ToIngredients(ing))
 	}
 
-	return ingredients, nil
+	return ingredients, rows.Err()
 }
 
+// Update changes the quantity of a single ingredient of the menu item with the given ID.
 func (r *MenuItemIngredients) Update(ctx context.Context, id int, menu_ingredients model.MenuItemIngredients) error {
 	object := dao.FromIngredients(menu_ingredients)
-	query := "UPDATE " + r.table + " SET menu_id = $1, , ingredient_id = $2, quantity = $3 WHERE id = $4"
+	query := "UPDATE " + r.table + " SET quantity = $1 WHERE menuid = $2 AND ingredientid = $3"
 
-	_, err := r.conn.Exec(query, object.MenuID, object.IngredientID, object.Quantity)
+	_, err := conn(ctx, r.conn).ExecContext(ctx, query, object.Quantity, id, object.IngredientID)
 	if err != nil {
 		return err
 	}
@@ -69,10 +72,23 @@ func (r *MenuItemIngredients) Update(ctx context.Context, id int, menu_ingredien
 	return nil
 }
 
+// DeleteIngredient removes a single ingredient of the menu item with the given ID.
+func (r *MenuItemIngredients) DeleteIngredient(ctx context.Context, id int, ingredientID int) error {
+	query := "DELETE FROM " + r.table + " WHERE menuid = $1 AND ingredientid = $2"
+
+	res, err := conn(ctx, r.conn).ExecContext(ctx, query, id, ingredientID)
+	if err != nil {
+		return err
+	}
+
+	return found(res)
+}
+
+// Delete removes all ingredients of the menu item with the given ID.
 func (r *MenuItemIngredients) Delete(ctx context.Context, id int) error {
-	query := "DELETE FROM " + r.table + " WHERE menu_id = $1"
+	query := "DELETE FROM " + r.table + " WHERE menuid = $1"
 
-	_, err := r.conn.Exec(query, id)
+	_, err := conn(ctx, r.conn).ExecContext(ctx, query, id)
 	if err != nil {
 		return err
 	}
@@ -13,7 +13,9 @@ type Order struct {
 }
 
 const (
-	tableOrder = "order"
+	tableOrder = "orders"
+
+	orderColumns = "id, customername, status, COALESCE(notes->>'notes', ''), discounttotal, subtotal, taxtotal, total, createdat, closedat, reserved, version"
 )
 
 func NewOrder(conn *sql.DB) *Order {
@@ -23,71 +25,130 @@ func NewOrder(conn *sql.DB) *Order {
 	}
 }
 
-func (r *Order) Create(ctx context.Context, order model.Order) error {
+// Create inserts a new order and returns its generated ID. The order is created
+// now unless its creation time is set, as it is for migrated orders.
+func (r *Order) Create(ctx context.Context, order model.Order) (int, error) {
 	object := dao.FromOrder(order)
-	query := "INSERT INTO " + r.table + " (customer_name, status, notes) VALUES ($1, $2, $3)"
+	query := "INSERT INTO " + r.table + " (customername, status, notes, discounttotal, reserved, createdat) VALUES ($1, $2, jsonb_build_object('notes', $3::text), $4, $5, COALESCE($6::timestamptz, CURRENT_TIMESTAMP)) RETURNING id"
+
+	createdAt := sql.NullTime{Time: order.CreateAt, Valid: !order.CreateAt.IsZero()}
 
-	_, err := r.conn.Exec(query, object.CustomerName, object.Status, object.Notes)
+	var id int
+	err := conn(ctx, r.conn).QueryRowContext(ctx, query, object.CustomerName, object.Status, object.Notes, object.DiscountTotal, object.Reserved, createdAt).Scan(&id)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
-	return nil
+	return id, nil
 }
 
 func (r *Order) Get(ctx context.Context, id int) (model.Order, error) {
-	var order dao.Order
-	query := "SELECT order_id, customer_name, status, notes, created_at FROM " + r.table + " WHERE id = $1"
+	query := "SELECT " + orderColumns + " FROM " + r.table + " WHERE id = $1"
 
-	err := r.conn.QueryRow(query, id).Scan(&order.OrderID, &order.CustomerName, &order.Status, &order.Notes, &order.CreatedAt)
+	order, err := scanOrder(conn(ctx, r.conn).QueryRowContext(ctx, query, id))
 	if err != nil {
-		return model.Order{}, nil
+		return model.Order{}, err
 	}
 
 	return dao.ToOrder(order), nil
 }
 
+// Lock locks the row of the order until the end of the transaction.
+func (r *Order) Lock(ctx context.Context, id int) error {
+	query := "SELECT id FROM " + r.table + " WHERE id = $1 FOR UPDATE"
+
+	return conn(ctx, r.conn).QueryRowContext(ctx, query, id).Scan(&id)
+}
+
 func (r *Order) GetAll(ctx context.Context) ([]model.Order, error) {
 	var order_all []model.Order
-	query := "SELECT * FROM " + r.table
+	err := r.Each(ctx, func(order model.Order) error {
+		order_all = append(order_all, order)
+		return nil
+	})
+	if err != nil {
+		return []model.Order{}, err
+	}
+
+	return order_all, nil
+}
 
-	rows, err := r.conn.Query(query)
+// Each calls fn for every order in the order of their IDs, reading them one at a time.
+// An error returned by fn stops the iteration and is returned.
+func (r *Order) Each(ctx context.Context, fn func(order model.Order) error) error {
+	query := "SELECT " + orderColumns + " FROM " + r.table + " ORDER BY id"
+
+	rows, err := conn(ctx, r.conn).QueryContext(ctx, query)
 	if err != nil {
-		return []model.Order{}, nil
+		return err
 	}
+	defer rows.Close()
 
 	for rows.Next() {
-		var order dao.Order
-		err := rows.Scan(&order.OrderID, &order.CustomerName, &order.Status, &order.Notes, &order.CreatedAt)
+		order, err := scanOrder(rows)
 		if err != nil {
-			return []model.Order{}, nil
+			return err
 		}
 
-		order_all = append(order_all, dao.ToOrder(order))
+		if err := fn(dao.ToOrder(order)); err != nil {
+			return err
+		}
 	}
 
-	return order_all, nil
+	return rows.Err()
 }
 
+// Update replaces the order and increments its version. If the version of order is
+// set, only the order with that version is replaced.
 func (r *Order) Update(ctx context.Context, id int, order model.Order) error {
 	object := dao.FromOrder(order)
-	query := "UPDATE " + r.table + " SET customer_name = $1, status = $2, notes = $3 WHERE id = $4"
+	query := "UPDATE " + r.table + " SET customername = $1, status = $2, notes = jsonb_build_object('notes', $3::text), discounttotal = $4, version = version + 1 WHERE id = $5 AND ($6 = 0 OR version = $6)"
 
-	_, err := r.conn.Exec(query, object.CustomerName, object.Status, object.Notes, id)
+	res, err := conn(ctx, r.conn).ExecContext(ctx, query, object.CustomerName, object.Status, object.Notes, object.DiscountTotal, id, object.Version)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	return versioned(ctx, r.conn, res, r.table, "id", id)
 }
 
-func (r *Order) Delete(ctx context.Context, id int) error {
-	query := "DELETE FROM " + r.table + " WHERE id = $1"
+// Close marks the open order as closed and stores its computed totals.
+// It reports false if the order does not exist or is already closed.
+func (r *Order) Close(ctx context.Context, order model.Order) (bool, error) {
+	query := "UPDATE " + r.table + " SET status = $1, subtotal = $2, discounttotal = $3, taxtotal = $4, total = $5, closedat = $6, version = version + 1 WHERE id = $7 AND status = $8"
+
+	res, err := conn(ctx, r.conn).ExecContext(ctx, query, model.OrderStatusClosed, order.Subtotal, order.DiscountTotal, order.TaxTotal, order.Total, order.ClosedAt, order.ID, model.OrderStatusOpen)
+	if err != nil {
+		return false, err
+	}
 
-	_, err := r.conn.Exec(query, id)
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+// Delete deletes the order. If version is set, only the order with that version is deleted.
+func (r *Order) Delete(ctx context.Context, id int, version int) error {
+	query := "DELETE FROM " + r.table + " WHERE id = $1 AND ($2 = 0 OR version = $2)"
+
+	res, err := conn(ctx, r.conn).ExecContext(ctx, query, id, version)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	return versioned(ctx, r.conn, res, r.table, "id", id)
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanOrder(row rowScanner) (dao.Order, error) {
+	var order dao.Order
+	err := row.Scan(&order.OrderID, &order.CustomerName, &order.Status, &order.Notes, &order.DiscountTotal,
+		&order.Subtotal, &order.TaxTotal, &order.Total, &order.CreatedAt, &order.ClosedAt, &order.Reserved, &order.Version)
+	return order, err
 }
@@ -0,0 +1,67 @@
+package postgres
+
+import (
+	"coffee-shop/internal/model"
+	"coffee-shop/internal/repository/postgres/dao"
+	"context"
+	"database/sql"
+)
+
+type OrderConsumption struct {
+	conn  *sql.DB
+	table string
+}
+
+const (
+	tableOrderConsumption = "order_consumption"
+)
+
+func NewOrderConsumption(conn *sql.DB) *OrderConsumption {
+	return &OrderConsumption{
+		conn:  conn,
+		table: tableOrderConsumption,
+	}
+}
+
+// Save replaces the consumption recorded for the order with the given ID.
+func (r *OrderConsumption) Save(ctx context.Context, id int, lines []model.OrderConsumption) error {
+	query := "DELETE FROM " + r.table + " WHERE orderid = $1"
+	if _, err := conn(ctx, r.conn).ExecContext(ctx, query, id); err != nil {
+		return err
+	}
+
+	query = "INSERT INTO " + r.table + " (orderid, productid, ingredientid, quantity) VALUES ($1, $2, $3, $4)"
+	for _, line := range lines {
+		object := dao.FromOrderConsumption(line)
+		_, err := conn(ctx, r.conn).ExecContext(ctx, query, id, object.ProductID, object.IngredientID, object.Quantity)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetAllWithID returns the consumption recorded for the order with the given ID.
+func (r *OrderConsumption) GetAllWithID(ctx context.Context, id int) ([]model.OrderConsumption, error) {
+	var lines []model.OrderConsumption
+	query := "SELECT orderid, productid, ingredientid, quantity FROM " + r.table + " WHERE orderid = $1 ORDER BY productid, ingredientid"
+
+	rows, err := conn(ctx, r.conn).QueryContext(ctx, query, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var line dao.OrderConsumption
+		err := rows.Scan(&line.OrderID, &line.ProductID, &line.IngredientID, &line.Quantity)
+		if err != nil {
+			return nil, err
+		}
+
+		lines = append(lines, dao.ToOrderConsumption(line))
+	}
+
+	return lines, rows.Err()
+}
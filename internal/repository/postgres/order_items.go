@@ -25,9 +25,9 @@ func NewOrderItems(conn *sql.DB) *OrderItems {
 
 func (r *OrderItems) Create(ctx context.Context, order_items model.OrderItems) error {
 	object := dao.FromOrderItems(order_items)
-	query := "INSERT INTO " + r.table + " (order_id, product_id, quantity) VALUES ($1, $2, $3)"
+	query := "INSERT INTO " + r.table + " (orderid, productid, quantity, price, modifiers) VALUES ($1, $2, $3, $4, $5)"
 
-	_, err := r.conn.Exec(query, object.OrderID, object.ProductID, object.Quantity)
+	_, err := conn(ctx, r.conn).ExecContext(ctx, query, object.OrderID, object.ProductID, object.Quantity, object.Price, object.Modifiers)
 	if err != nil {
 		return err
 	}
@@ -35,34 +35,35 @@ func (r *OrderItems) Create(ctx context.Context, order_items model.OrderItems) e
 	return nil
 }
 
-func (r *OrderItems) Get(ctx context.Context, id int) (model.OrderItems, error) {
-	var order_items dao.OrderItems
-	query := "SELECT order_id, product_id, quantity FROM " + r.table + " WHERE id = $1"
+// GetAllWithID returns all items of the order with the given ID.
+func (r *OrderItems) GetAllWithID(ctx context.Context, id int) ([]model.OrderItems, error) {
+	var items []model.OrderItems
+	query := "SELECT orderid, productid, quantity, price, modifiers FROM " + r.table + " WHERE orderid = $1 ORDER BY productid"
 
-	err := r.conn.QueryRow(query, id).Scan(&order_items.OrderID, &order_items.ProductID, &order_items.Quantity)
+	rows, err := conn(ctx, r.conn).QueryContext(ctx, query, id)
 	if err != nil {
-		return model.OrderItems{}, err
+		return nil, err
 	}
+	defer rows.Close()
 
-	return dao.ToOrderItems(order_items), nil
-}
-
-func (r *OrderItems) UPDATE(ctx context.Context, id int, order_items model.OrderItems) error {
-	object := dao.FromOrderItems(order_items)
-	query := "UPDATE " + r.table + "SET product_id = $1, quantity = $2 WHERE id = $3"
+	for rows.Next() {
+		var item dao.OrderItems
+		err := rows.Scan(&item.OrderID, &item.ProductID, &item.Quantity, &item.Price, &item.Modifiers)
+		if err != nil {
+			return nil, err
+		}
 
-	_, err := r.conn.Exec(query, object.ProductID, object.Quantity, id)
-	if err != nil {
-		return err
+		items = append(items, dao.ToOrderItems(item))
 	}
 
-	return nil
+	return items, rows.Err()
 }
 
+// Delete removes all items of the order with the given ID.
 func (r *OrderItems) Delete(ctx context.Context, id int) error {
-	query := "DELETE FROM " + r.table + " WHERE id = $1"
+	query := "DELETE FROM " + r.table + " WHERE orderid = $1"
 
-	_, err := r.conn.Exec(query, id)
+	_, err := conn(ctx, r.conn).ExecContext(ctx, query, id)
 	if err != nil {
 		return err
 	}
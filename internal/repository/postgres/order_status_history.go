@@ -23,11 +23,12 @@ func NewOrderStatusHistory(conn *sql.DB) *OrderStatusHistory {
 	}
 }
 
+// Create opens a new status history record for the order.
 func (r *OrderStatusHistory) Create(ctx context.Context, order_history model.OrderStatusHistory) error {
 	object := dao.FromOrderStatusHistory(order_history)
-	query := "INSETR INTO " + r.table + "(order_id) VALUES ($1)"
+	query := "INSERT INTO " + r.table + " (orderid) VALUES ($1)"
 
-	_, err := r.conn.Exec(query, object.OrderID)
+	_, err := conn(ctx, r.conn).ExecContext(ctx, query, object.OrderID)
 	if err != nil {
 		return err
 	}
@@ -37,9 +38,9 @@ func (r *OrderStatusHistory) Create(ctx context.Context, order_history model.Ord
 
 func (r *OrderStatusHistory) Get(ctx context.Context, id int) (model.OrderStatusHistory, error) {
 	var order_history dao.OrderStatusHistory
-	query := "SELET id, order_id, opened_at, closed_at FROM " + r.table + " WHERE id = $1"
+	query := "SELECT id, orderid, openedat, closedat FROM " + r.table + " WHERE id = $1"
 
-	err := r.conn.QueryRow(query, id).Scan(&order_history.ID, &order_history.OrderID, &order_history.OpenedAt, &order_history.ClosedAt)
+	err := conn(ctx, r.conn).QueryRowContext(ctx, query, id).Scan(&order_history.ID, &order_history.OrderID, &order_history.OpenedAt, &order_history.ClosedAt)
 	if err != nil {
 		return model.OrderStatusHistory{}, err
 	}
@@ -47,10 +48,34 @@ func (r *OrderStatusHistory) Get(ctx context.Context, id int) (model.OrderStatus
 	return dao.ToOrderStatusHistory(order_history), nil
 }
 
+// Close sets the closing time of the open status history record of the order.
+func (r *OrderStatusHistory) Close(ctx context.Context, orderID int) error {
+	query := "UPDATE " + r.table + " SET closedat = CURRENT_TIMESTAMP WHERE orderid = $1 AND closedat IS NULL"
+
+	_, err := conn(ctx, r.conn).ExecContext(ctx, query, orderID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteByOrder removes the status history of the order.
+func (r *OrderStatusHistory) DeleteByOrder(ctx context.Context, orderID int) error {
+	query := "DELETE FROM " + r.table + " WHERE orderid = $1"
+
+	_, err := conn(ctx, r.conn).ExecContext(ctx, query, orderID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func (r *OrderStatusHistory) Delete(ctx context.Context, id int) error {
 	query := "DELETE FROM " + r.table + " WHERE id = $1"
 
-	_, err := r.conn.Exec(query, id)
+	_, err := conn(ctx, r.conn).ExecContext(ctx, query, id)
 	if err != nil {
 		return err
 	}
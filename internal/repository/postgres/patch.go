@@ -0,0 +1,27 @@
+package postgres
+
+import (
+	"fmt"
+	"strings"
+)
+
+// assignments collects the columns changed by a patch and their values.
+type assignments struct {
+	columns []string
+	args    []any
+}
+
+func (a *assignments) set(column string, value any) {
+	a.args = append(a.args, value)
+	a.columns = append(a.columns, fmt.Sprintf("%s = $%d", column, len(a.args)))
+}
+
+// update returns the statement that assigns the columns of the row with the ID in
+// the key column and increments its version. If version is set, only the row with
+// that version is changed.
+func (a *assignments) update(table, key string, id, version int) (string, []any) {
+	args := append(a.args, id, version)
+	query := fmt.Sprintf("UPDATE %s SET %s WHERE %s = $%d AND ($%d = 0 OR version = $%d)",
+		table, strings.Join(append(a.columns, "version = version + 1"), ", "), key, len(args)-1, len(args), len(args))
+	return query, args
+}
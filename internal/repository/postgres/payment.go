@@ -0,0 +1,123 @@
+package postgres
+
+import (
+	"coffee-shop/internal/model"
+	"coffee-shop/internal/repository/postgres/dao"
+	"context"
+	"database/sql"
+)
+
+type Payment struct {
+	conn  *sql.DB
+	table string
+}
+
+const (
+	tablePayment     = "payments"
+	tableRefundItems = "refund_items"
+
+	paymentColumns = "id, orderid, tender, amount, tip, tendered, change, refundof, reference, reason, createdat"
+)
+
+func NewPayment(conn *sql.DB) *Payment {
+	return &Payment{
+		conn:  conn,
+		table: tablePayment,
+	}
+}
+
+// Create inserts a new payment or refund and returns its generated ID.
+func (r *Payment) Create(ctx context.Context, payment model.Payment) (int, error) {
+	object := dao.FromPayment(payment)
+	query := "INSERT INTO " + r.table + " (orderid, tender, amount, tip, tendered, change, refundof, reference, reason) " +
+		"VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id"
+
+	var id int
+	err := conn(ctx, r.conn).QueryRowContext(ctx, query, object.OrderID, object.Tender, object.Amount, object.Tip,
+		object.Tendered, object.Change, object.RefundOf, object.Reference, object.Reason).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func (r *Payment) Get(ctx context.Context, id int) (model.Payment, error) {
+	query := "SELECT " + paymentColumns + " FROM " + r.table + " WHERE id = $1"
+
+	payment, err := scanPayment(conn(ctx, r.conn).QueryRowContext(ctx, query, id))
+	if err != nil {
+		return model.Payment{}, err
+	}
+
+	return dao.ToPayment(payment), nil
+}
+
+// GetAllWithID returns the payments and refunds of the order with the given ID in the order they were made.
+func (r *Payment) GetAllWithID(ctx context.Context, id int) ([]model.Payment, error) {
+	query := "SELECT " + paymentColumns + " FROM " + r.table + " WHERE orderid = $1 ORDER BY id"
+
+	rows, err := conn(ctx, r.conn).QueryContext(ctx, query, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var payments []model.Payment
+	for rows.Next() {
+		payment, err := scanPayment(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		payments = append(payments, dao.ToPayment(payment))
+	}
+
+	return payments, rows.Err()
+}
+
+// CreateRefundItem records an item returned by the refund with the given payment ID.
+func (r *Payment) CreateRefundItem(ctx context.Context, paymentID int, item model.OrderItems) error {
+	query := "INSERT INTO " + tableRefundItems + " (paymentid, productid, quantity) VALUES ($1, $2, $3)"
+
+	_, err := conn(ctx, r.conn).ExecContext(ctx, query, paymentID, item.ProductID, item.Quantity)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// RefundedItems returns the quantities of the products already refunded for the order.
+func (r *Payment) RefundedItems(ctx context.Context, orderID int) ([]model.OrderItems, error) {
+	query := `SELECT ri.productid, SUM(ri.quantity)
+		FROM ` + tableRefundItems + ` ri
+		JOIN ` + r.table + ` p ON p.id = ri.paymentid
+		WHERE p.orderid = $1
+		GROUP BY ri.productid
+		ORDER BY ri.productid`
+
+	rows, err := conn(ctx, r.conn).QueryContext(ctx, query, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []model.OrderItems
+	for rows.Next() {
+		item := model.OrderItems{OrderID: orderID}
+		if err := rows.Scan(&item.ProductID, &item.Quantity); err != nil {
+			return nil, err
+		}
+
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
+
+func scanPayment(row rowScanner) (dao.Payment, error) {
+	var p dao.Payment
+	err := row.Scan(&p.ID, &p.OrderID, &p.Tender, &p.Amount, &p.Tip, &p.Tendered, &p.Change, &p.RefundOf, &p.Reference, &p.Reason, &p.CreatedAt)
+	return p, err
+}
@@ -0,0 +1,47 @@
+package postgres_test
+
+import (
+	"database/sql"
+	"os"
+	"testing"
+
+	"coffee-shop/internal/repository/postgres"
+	"coffee-shop/internal/repository/repotest"
+
+	_ "github.com/lib/pq"
+)
+
+// TestContract runs against the database in DATABASE_URL, which must have the
+// schema of db/init.sql. Every test is rolled back, so the data is left as it was.
+func TestContract(t *testing.T) {
+	url := os.Getenv("DATABASE_URL")
+	if url == "" {
+		t.Skip("DATABASE_URL is not set")
+	}
+
+	db, err := sql.Open("postgres", url)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := db.Ping(); err != nil {
+		t.Fatalf("ping: %v", err)
+	}
+
+	repotest.Run(t, func(t *testing.T) repotest.Repos {
+		return repotest.Repos{
+			Tx:                 postgres.NewTransactor(db),
+			Inventory:          postgres.NewInventory(db),
+			Menu:               postgres.NewMenu(db),
+			MenuIngredients:    postgres.NewMenuItemIngredients(db),
+			PriceHistory:       postgres.NewPriceHistory(db),
+			Orders:             postgres.NewOrder(db),
+			OrderItems:         postgres.NewOrderItems(db),
+			OrderStatusHistory: postgres.NewOrderStatusHistory(db),
+			Staff:              postgres.NewStaff(db),
+			Audit:              postgres.NewAudit(db),
+			IdempotencyKeys:    postgres.NewIdempotencyKeys(db),
+			Shared:             true,
+		}
+	})
+}
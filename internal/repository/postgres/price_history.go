@@ -0,0 +1,62 @@
+package postgres
+
+import (
+	"coffee-shop/internal/model"
+	"coffee-shop/internal/repository/postgres/dao"
+	"context"
+	"database/sql"
+)
+
+type PriceHistory struct {
+	conn  *sql.DB
+	table string
+}
+
+const (
+	tablePriceHistory = "price_history"
+)
+
+func NewPriceHistory(conn *sql.DB) *PriceHistory {
+	return &PriceHistory{
+		conn:  conn,
+		table: tablePriceHistory,
+	}
+}
+
+// Create records a price change of a menu item and returns its generated ID.
+func (r *PriceHistory) Create(ctx context.Context, change model.PriceHistory) (int, error) {
+	object := dao.FromPriceHistory(change)
+	query := "INSERT INTO " + r.table + " (menu_itemid, old_price, new_price) VALUES ($1, $2, $3) RETURNING historyid"
+
+	var id int
+	err := conn(ctx, r.conn).QueryRowContext(ctx, query, object.MenuItemID, object.OldPrice, object.NewPrice).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// GetAllWithID returns the price changes of the menu item with the given ID, oldest first.
+func (r *PriceHistory) GetAllWithID(ctx context.Context, id int) ([]model.PriceHistory, error) {
+	var changes []model.PriceHistory
+	query := "SELECT historyid, menu_itemid, old_price, new_price, changedat FROM " + r.table + " WHERE menu_itemid = $1 ORDER BY changedat, historyid"
+
+	rows, err := conn(ctx, r.conn).QueryContext(ctx, query, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var c dao.PriceHistory
+		err := rows.Scan(&c.HistoryID, &c.MenuItemID, &c.OldPrice, &c.NewPrice, &c.ChangedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		changes = append(changes, dao.ToPriceHistory(c))
+	}
+
+	return changes, rows.Err()
+}
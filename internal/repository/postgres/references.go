@@ -0,0 +1,33 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"coffee-shop/internal/model"
+)
+
+// referrer is a column of a table that refers to records of another table.
+type referrer struct {
+	entityType string
+	table      string
+	column     string
+}
+
+// references counts the rows of every referrer that refer to the record with the ID
+// and returns the counts of the referrers that have any.
+func references(ctx context.Context, db *sql.DB, id int, referrers ...referrer) ([]model.Reference, error) {
+	var refs []model.Reference
+	for _, r := range referrers {
+		var count int
+		query := "SELECT COUNT(*) FROM " + r.table + " WHERE " + r.column + " = $1"
+		if err := conn(ctx, db).QueryRowContext(ctx, query, id).Scan(&count); err != nil {
+			return nil, err
+		}
+		if count > 0 {
+			refs = append(refs, model.Reference{EntityType: r.entityType, Count: count})
+		}
+	}
+
+	return refs, nil
+}
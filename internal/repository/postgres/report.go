@@ -0,0 +1,406 @@
+package postgres
+
+import (
+	"coffee-shop/internal/model"
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Report runs the aggregate queries used by the reports.
+type Report struct {
+	conn *sql.DB
+}
+
+func NewReport(conn *sql.DB) *Report {
+	return &Report{conn: conn}
+}
+
+// TaxSummary sums the tax lines of the orders closed within the period by category and rate.
+func (r *Report) TaxSummary(ctx context.Context, period model.Period) ([]model.TaxReportLine, error) {
+	query := `SELECT t.category, t.rate, t.inclusive, COUNT(DISTINCT t.orderid), SUM(t.taxableamount), SUM(t.taxamount)
+		FROM ` + tableOrderTaxes + ` t
+		JOIN ` + tableOrder + ` o ON o.id = t.orderid
+		WHERE o.closedat >= $1 AND o.closedat < $2
+		GROUP BY t.category, t.rate, t.inclusive
+		ORDER BY t.category, t.rate`
+
+	rows, err := conn(ctx, r.conn).QueryContext(ctx, query, period.From, period.To)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var lines []model.TaxReportLine
+	for rows.Next() {
+		var line model.TaxReportLine
+		err := rows.Scan(&line.Category, &line.Rate, &line.Inclusive, &line.Orders, &line.TaxableAmount, &line.TaxAmount)
+		if err != nil {
+			return nil, err
+		}
+
+		lines = append(lines, line)
+	}
+
+	return lines, rows.Err()
+}
+
+// PaymentSummary sums the payments and refunds made within the period by tender.
+func (r *Report) PaymentSummary(ctx context.Context, period model.Period) ([]model.PaymentReportLine, error) {
+	query := `SELECT tender,
+			COUNT(*) FILTER (WHERE amount > 0),
+			COALESCE(SUM(amount) FILTER (WHERE amount > 0), 0),
+			COALESCE(SUM(tip), 0),
+			COUNT(*) FILTER (WHERE amount < 0),
+			COALESCE(-SUM(amount) FILTER (WHERE amount < 0), 0)
+		FROM ` + tablePayment + `
+		WHERE createdat >= $1 AND createdat < $2
+		GROUP BY tender
+		ORDER BY tender`
+
+	rows, err := conn(ctx, r.conn).QueryContext(ctx, query, period.From, period.To)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var lines []model.PaymentReportLine
+	for rows.Next() {
+		var line model.PaymentReportLine
+		err := rows.Scan(&line.Tender, &line.Payments, &line.Amount, &line.Tips, &line.Refunds, &line.Refunded)
+		if err != nil {
+			return nil, err
+		}
+
+		lines = append(lines, line)
+	}
+
+	return lines, rows.Err()
+}
+
+// SalesSummary sums up the orders sold within the period per bucket of the grouping.
+// An order is sold when it is closed, an open order when it is created. Revenue is the
+// price of the items less the discount for open and closed orders alike, so exclusive
+// taxes, which are only known once an order is closed, are left out. Refunds made within
+// the period are bucketed by the time of the refund and brought to the same basis by the
+// share of revenue in the order total. Timestamps are bucketed in the location of the
+// query, and buckets without orders are included.
+func (r *Report) SalesSummary(ctx context.Context, q model.SalesQuery) ([]model.SalesBucket, error) {
+	query := `WITH items AS (
+			SELECT orderid, SUM(quantity) AS items, SUM(price * quantity) AS amount
+			FROM ` + tableOrderItems + `
+			GROUP BY orderid
+		), sales AS (
+			SELECT o.id,
+				date_trunc($4, COALESCE(o.closedat, o.createdat) AT TIME ZONE $3) AS bucket,
+				i.amount - o.discounttotal AS revenue,
+				i.items
+			FROM ` + tableOrder + ` o
+			JOIN items i ON i.orderid = o.id
+			WHERE o.status::text = ANY($5)
+				AND COALESCE(o.closedat, o.createdat) >= $1::timestamptz AND COALESCE(o.closedat, o.createdat) < $2::timestamptz
+		), refunds AS (
+			SELECT date_trunc($4, p.createdat AT TIME ZONE $3) AS bucket,
+				SUM(-p.amount * (i.amount - o.discounttotal) / NULLIF(o.total, 0)) AS refunded
+			FROM ` + tablePayment + ` p
+			JOIN ` + tableOrder + ` o ON o.id = p.orderid
+			JOIN items i ON i.orderid = o.id
+			WHERE p.refundof IS NOT NULL AND o.status::text = ANY($5)
+				AND p.createdat >= $1::timestamptz AND p.createdat < $2::timestamptz
+			GROUP BY 1
+		), buckets AS (
+			SELECT generate_series(
+				date_trunc($4, $1::timestamptz AT TIME ZONE $3),
+				($2::timestamptz AT TIME ZONE $3) - interval '1 microsecond',
+				('1 ' || $4)::interval
+			) AS bucket
+		)
+		SELECT b.bucket, COUNT(s.id), COALESCE(SUM(s.revenue), 0), COALESCE(SUM(s.items), 0), COALESCE(MAX(f.refunded), 0)
+		FROM buckets b
+		LEFT JOIN sales s ON s.bucket = b.bucket
+		LEFT JOIN refunds f ON f.bucket = b.bucket
+		GROUP BY b.bucket
+		ORDER BY b.bucket`
+
+	rows, err := conn(ctx, r.conn).QueryContext(ctx, query, q.From, q.To, q.Location.String(), q.GroupBy, pq.Array(q.Statuses()))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var buckets []model.SalesBucket
+	for rows.Next() {
+		var (
+			bucket model.SalesBucket
+			start  time.Time
+		)
+		err := rows.Scan(&start, &bucket.Orders, &bucket.Revenue, &bucket.Items, &bucket.Refunds)
+		if err != nil {
+			return nil, err
+		}
+
+		// The bucket start is a wall clock time of the query location
+		bucket.Start = time.Date(start.Year(), start.Month(), start.Day(), start.Hour(), start.Minute(), start.Second(), 0, q.Location)
+		buckets = append(buckets, bucket)
+	}
+
+	return buckets, rows.Err()
+}
+
+// PopularItems ranks the menu items sold in the orders closed within the period and
+// sums up their sales within the previous period of the same length in the same pass.
+// Revenue is defined as in SalesSummary: the price of the items less their share of
+// the order discount, without exclusive taxes, and less their share of the refunds
+// made against the order. Share is calculated against all items of the category filter.
+func (r *Report) PopularItems(ctx context.Context, q model.PopularItemsQuery) ([]model.PopularItem, error) {
+	query := `WITH amounts AS (
+			SELECT orderid, SUM(price * quantity) AS amount
+			FROM ` + tableOrderItems + `
+			GROUP BY orderid
+		), refunds AS (
+			SELECT orderid, SUM(-amount) AS refunded
+			FROM ` + tablePayment + `
+			WHERE refundof IS NOT NULL
+			GROUP BY orderid
+		), closed AS (
+			SELECT o.id, o.closedat,
+				COALESCE((a.amount - o.discounttotal) / NULLIF(a.amount, 0), 0)
+					* (1 - COALESCE(f.refunded / NULLIF(o.total, 0), 0)) AS net
+			FROM ` + tableOrder + ` o
+			JOIN amounts a ON a.orderid = o.id
+			LEFT JOIN refunds f ON f.orderid = o.id
+			WHERE o.status = 'closed'
+				AND o.closedat >= $3::timestamptz AND o.closedat < $2::timestamptz
+		), sales AS (
+			SELECT i.productid,
+				SUM(i.quantity) FILTER (WHERE o.closedat >= $1::timestamptz) AS quantity,
+				SUM(i.price * i.quantity * o.net) FILTER (WHERE o.closedat >= $1::timestamptz) AS revenue,
+				SUM(i.quantity) FILTER (WHERE o.closedat < $1::timestamptz) AS prev_quantity,
+				SUM(i.price * i.quantity * o.net) FILTER (WHERE o.closedat < $1::timestamptz) AS prev_revenue
+			FROM ` + tableOrderItems + ` i
+			JOIN closed o ON o.id = i.orderid
+			GROUP BY i.productid
+		), ranked AS (
+			SELECT m.id, m.name, m.category,
+				COALESCE(s.quantity, 0) AS quantity,
+				COALESCE(s.revenue, 0) AS revenue,
+				COALESCE(s.prev_quantity, 0) AS prev_quantity,
+				COALESCE(s.prev_revenue, 0) AS prev_revenue
+			FROM sales s
+			JOIN ` + tableMenu + ` m ON m.id = s.productid
+			WHERE $5 = '' OR m.category = $5
+		)
+		SELECT id, name, category, quantity, revenue, prev_quantity, prev_revenue,
+			CASE WHEN $6 = 'revenue'
+				THEN revenue * 100 / NULLIF(SUM(revenue) OVER (), 0)
+				ELSE quantity * 100 / NULLIF(SUM(quantity) OVER (), 0)
+			END AS share
+		FROM ranked
+		WHERE quantity > 0
+		ORDER BY
+			CASE WHEN $6 = 'revenue' THEN revenue ELSE quantity END DESC,
+			CASE WHEN $6 = 'revenue' THEN quantity ELSE revenue END DESC,
+			name
+		LIMIT $4`
+
+	prev := q.Previous()
+	rows, err := conn(ctx, r.conn).QueryContext(ctx, query, q.From, q.To, prev.From, q.Limit, q.Category, q.RankBy)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []model.PopularItem
+	for rows.Next() {
+		var (
+			item  model.PopularItem
+			share sql.NullFloat64
+		)
+		err := rows.Scan(&item.ProductID, &item.Name, &item.Category, &item.Quantity, &item.Revenue,
+			&item.PreviousQuantity, &item.PreviousRevenue, &share)
+		if err != nil {
+			return nil, err
+		}
+
+		item.Share = share.Float64
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
+
+// LedgerUsage sums the net stock written off from the inventory ledger within the
+// period per ingredient and day of the query location. Stock given back for refunds
+// and cancelled orders reduces the usage of the day it is returned.
+func (r *Report) LedgerUsage(ctx context.Context, q model.IngredientUsageQuery) ([]model.IngredientDailyUsage, error) {
+	query := `SELECT t.ingredientid, inv.name, inv.unit::text,
+			date_trunc('day', t.createdat AT TIME ZONE $3) AS day,
+			-SUM(t.quantity_change)
+		FROM ` + tableInventoryTransactions + ` t
+		JOIN ` + tableInventory + ` inv ON inv.ingredientid = t.ingredientid
+		WHERE t.createdat >= $1::timestamptz AND t.createdat < $2::timestamptz
+		GROUP BY t.ingredientid, inv.name, inv.unit, day
+		HAVING SUM(t.quantity_change) <> 0
+		ORDER BY inv.name, t.ingredientid, day`
+
+	return r.dailyUsage(ctx, query, q)
+}
+
+// RecipeUsage recomputes the ingredients used by the orders closed within the period
+// from their items and the current recipes, per ingredient and day of the query location.
+func (r *Report) RecipeUsage(ctx context.Context, q model.IngredientUsageQuery) ([]model.IngredientDailyUsage, error) {
+	query := `SELECT mi.ingredientid, inv.name, inv.unit::text,
+			date_trunc('day', o.closedat AT TIME ZONE $3) AS day,
+			SUM(i.quantity * mi.quantity)
+		FROM ` + tableOrder + ` o
+		JOIN ` + tableOrderItems + ` i ON i.orderid = o.id
+		JOIN ` + tableMenuItemIngredients + ` mi ON mi.menuid = i.productid
+		JOIN ` + tableInventory + ` inv ON inv.ingredientid = mi.ingredientid
+		WHERE o.status = 'closed'
+			AND o.closedat >= $1::timestamptz AND o.closedat < $2::timestamptz
+		GROUP BY mi.ingredientid, inv.name, inv.unit, day
+		ORDER BY inv.name, mi.ingredientid, day`
+
+	return r.dailyUsage(ctx, query, q)
+}
+
+func (r *Report) dailyUsage(ctx context.Context, query string, q model.IngredientUsageQuery) ([]model.IngredientDailyUsage, error) {
+	rows, err := conn(ctx, r.conn).QueryContext(ctx, query, q.From, q.To, q.Location.String())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var usage []model.IngredientDailyUsage
+	for rows.Next() {
+		var (
+			u   model.IngredientDailyUsage
+			day time.Time
+		)
+		err := rows.Scan(&u.IngredientID, &u.Name, &u.Unit, &day, &u.Quantity)
+		if err != nil {
+			return nil, err
+		}
+
+		u.Day = time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, q.Location)
+		usage = append(usage, u)
+	}
+
+	return usage, rows.Err()
+}
+
+// Heatmap counts the orders placed within the period per weekday and hour of the
+// location. Revenue and preparation time are summed over the closed orders; revenue
+// is defined as in SalesSummary, less the refunds made against the order on the same
+// basis. The preparation time is taken from the status history and, for orders
+// without one, from the order creation and closing times. Only non-empty cells are
+// returned.
+func (r *Report) Heatmap(ctx context.Context, period model.Period, location *time.Location) ([]model.HeatmapCell, error) {
+	query := `WITH amounts AS (
+			SELECT orderid, SUM(price * quantity) AS amount
+			FROM ` + tableOrderItems + `
+			GROUP BY orderid
+		), refunds AS (
+			SELECT orderid, SUM(-amount) AS refunded
+			FROM ` + tablePayment + `
+			WHERE refundof IS NOT NULL
+			GROUP BY orderid
+		)
+		SELECT EXTRACT(DOW FROM o.placedat)::int AS weekday,
+			EXTRACT(HOUR FROM o.placedat)::int AS hour,
+			COUNT(*),
+			COALESCE(SUM(o.revenue) FILTER (WHERE o.status = 'closed'), 0),
+			COUNT(o.prep) FILTER (WHERE o.status = 'closed'),
+			COALESCE(SUM(o.prep) FILTER (WHERE o.status = 'closed'), 0)
+		FROM (
+			SELECT o.status,
+				(COALESCE(a.amount, 0) - o.discounttotal) * (1 - COALESCE(f.refunded / NULLIF(o.total, 0), 0)) AS revenue,
+				o.createdat AT TIME ZONE $3 AS placedat,
+				EXTRACT(EPOCH FROM COALESCE(h.closedat - h.openedat, o.closedat - o.createdat)) AS prep
+			FROM ` + tableOrder + ` o
+			LEFT JOIN amounts a ON a.orderid = o.id
+			LEFT JOIN refunds f ON f.orderid = o.id
+			LEFT JOIN LATERAL (
+				SELECT MIN(openedat) AS openedat, MAX(closedat) AS closedat
+				FROM ` + tableOrderStatusHistory + `
+				WHERE orderid = o.id
+			) h ON TRUE
+			WHERE o.createdat >= $1::timestamptz AND o.createdat < $2::timestamptz
+		) o
+		GROUP BY weekday, hour
+		ORDER BY weekday, hour`
+
+	rows, err := conn(ctx, r.conn).QueryContext(ctx, query, period.From, period.To, location.String())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var cells []model.HeatmapCell
+	for rows.Next() {
+		var (
+			cell    model.HeatmapCell
+			weekday int
+			prep    float64
+		)
+		err := rows.Scan(&weekday, &cell.Hour, &cell.Orders, &cell.Revenue, &cell.PrepOrders, &prep)
+		if err != nil {
+			return nil, err
+		}
+
+		cell.Weekday = time.Weekday(weekday)
+		cell.PrepTime = time.Duration(prep * float64(time.Second))
+		cells = append(cells, cell)
+	}
+
+	return cells, rows.Err()
+}
+
+// ClosingSales sums up the orders closed within the period for the end-of-day report.
+// Only the order counts and totals are set on the returned closing.
+func (r *Report) ClosingSales(ctx context.Context, period model.Period) (model.DailyClosing, error) {
+	query := `SELECT COUNT(*),
+			COALESCE(SUM(subtotal), 0),
+			COALESCE(SUM(discounttotal), 0),
+			COALESCE(SUM(taxtotal), 0),
+			COALESCE(SUM(total), 0)
+		FROM ` + tableOrder + `
+		WHERE status = 'closed' AND closedat >= $1::timestamptz AND closedat < $2::timestamptz`
+
+	var closing model.DailyClosing
+	err := conn(ctx, r.conn).QueryRowContext(ctx, query, period.From, period.To).
+		Scan(&closing.ClosedOrders, &closing.GrossSales, &closing.Discounts, &closing.Taxes, &closing.Total)
+	if err != nil {
+		return model.DailyClosing{}, err
+	}
+
+	return closing, nil
+}
+
+// OpenOrders returns the orders created before the given time that are still open, the oldest first.
+func (r *Report) OpenOrders(ctx context.Context, before time.Time) ([]model.OpenOrder, error) {
+	query := `SELECT id, customername, createdat
+		FROM ` + tableOrder + `
+		WHERE status = 'open' AND createdat < $1::timestamptz
+		ORDER BY createdat, id`
+
+	rows, err := conn(ctx, r.conn).QueryContext(ctx, query, before)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var orders []model.OpenOrder
+	for rows.Next() {
+		var order model.OpenOrder
+		if err := rows.Scan(&order.ID, &order.CustomerName, &order.CreatedAt); err != nil {
+			return nil, err
+		}
+
+		orders = append(orders, order)
+	}
+
+	return orders, rows.Err()
+}
@@ -0,0 +1,102 @@
+package postgres
+
+import (
+	"coffee-shop/internal/model"
+	"coffee-shop/internal/repository/postgres/dao"
+	"context"
+	"database/sql"
+)
+
+type Staff struct {
+	conn  *sql.DB
+	table string
+}
+
+const (
+	tableStaff = "staff"
+
+	staffColumns = "id, username, role, passwordhash, apikeyhash, createdat"
+)
+
+func NewStaff(conn *sql.DB) *Staff {
+	return &Staff{
+		conn:  conn,
+		table: tableStaff,
+	}
+}
+
+// Create stores the staff member and returns its generated ID.
+func (r *Staff) Create(ctx context.Context, staff model.Staff) (int, error) {
+	object := dao.FromStaff(staff)
+	query := "INSERT INTO " + r.table + " (username, role, passwordhash, apikeyhash) VALUES ($1, $2, $3, $4) RETURNING id"
+
+	var id int
+	err := conn(ctx, r.conn).QueryRowContext(ctx, query, object.Username, object.Role, object.PasswordHash, object.APIKeyHash).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func (r *Staff) Get(ctx context.Context, id int) (model.Staff, error) {
+	return r.getBy(ctx, "id", id)
+}
+
+func (r *Staff) GetByUsername(ctx context.Context, username string) (model.Staff, error) {
+	return r.getBy(ctx, "username", username)
+}
+
+// GetByAPIKey returns the staff member the API key with the given hash was issued to.
+func (r *Staff) GetByAPIKey(ctx context.Context, hash string) (model.Staff, error) {
+	return r.getBy(ctx, "apikeyhash", hash)
+}
+
+func (r *Staff) getBy(ctx context.Context, column string, value any) (model.Staff, error) {
+	var s dao.Staff
+	query := "SELECT " + staffColumns + " FROM " + r.table + " WHERE " + column + " = $1"
+
+	err := conn(ctx, r.conn).QueryRowContext(ctx, query, value).Scan(&s.ID, &s.Username, &s.Role, &s.PasswordHash, &s.APIKeyHash, &s.CreatedAt)
+	if err != nil {
+		return model.Staff{}, err
+	}
+
+	return dao.ToStaff(s), nil
+}
+
+// GetAll returns the staff in the order of their IDs.
+func (r *Staff) GetAll(ctx context.Context) ([]model.Staff, error) {
+	var staff []model.Staff
+	query := "SELECT " + staffColumns + " FROM " + r.table + " ORDER BY id"
+
+	rows, err := conn(ctx, r.conn).QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var s dao.Staff
+		err := rows.Scan(&s.ID, &s.Username, &s.Role, &s.PasswordHash, &s.APIKeyHash, &s.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		staff = append(staff, dao.ToStaff(s))
+	}
+
+	return staff, rows.Err()
+}
+
+// Update replaces the role and the credentials of the staff member. The username is kept.
+func (r *Staff) Update(ctx context.Context, id int, staff model.Staff) error {
+	object := dao.FromStaff(staff)
+	query := "UPDATE " + r.table + " SET role = $1, passwordhash = $2, apikeyhash = $3 WHERE id = $4"
+
+	res, err := conn(ctx, r.conn).ExecContext(ctx, query, object.Role, object.PasswordHash, object.APIKeyHash, id)
+	if err != nil {
+		return err
+	}
+
+	return found(res)
+}
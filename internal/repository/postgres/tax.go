@@ -0,0 +1,139 @@
+package postgres
+
+import (
+	"coffee-shop/internal/model"
+	"coffee-shop/internal/repository/postgres/dao"
+	"context"
+	"database/sql"
+)
+
+type TaxRate struct {
+	conn  *sql.DB
+	table string
+}
+
+const (
+	tableTaxRate    = "tax_rates"
+	tableOrderTaxes = "order_taxes"
+)
+
+func NewTaxRate(conn *sql.DB) *TaxRate {
+	return &TaxRate{
+		conn:  conn,
+		table: tableTaxRate,
+	}
+}
+
+// Save creates the tax rate of the category or replaces the existing one.
+func (r *TaxRate) Save(ctx context.Context, rate model.TaxRate) error {
+	object := dao.FromTaxRate(rate)
+	query := "INSERT INTO " + r.table + " (category, rate, inclusive) VALUES ($1, $2, $3) " +
+		"ON CONFLICT (category) DO UPDATE SET rate = EXCLUDED.rate, inclusive = EXCLUDED.inclusive"
+
+	_, err := conn(ctx, r.conn).ExecContext(ctx, query, object.Category, object.Rate, object.Inclusive)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *TaxRate) Get(ctx context.Context, category string) (model.TaxRate, error) {
+	var rate dao.TaxRate
+	query := "SELECT category, rate, inclusive FROM " + r.table + " WHERE category = $1"
+
+	err := conn(ctx, r.conn).QueryRowContext(ctx, query, category).Scan(&rate.Category, &rate.Rate, &rate.Inclusive)
+	if err != nil {
+		return model.TaxRate{}, err
+	}
+
+	return dao.ToTaxRate(rate), nil
+}
+
+func (r *TaxRate) GetAll(ctx context.Context) ([]model.TaxRate, error) {
+	var rates []model.TaxRate
+	query := "SELECT category, rate, inclusive FROM " + r.table + " ORDER BY category"
+
+	rows, err := conn(ctx, r.conn).QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var rate dao.TaxRate
+		err := rows.Scan(&rate.Category, &rate.Rate, &rate.Inclusive)
+		if err != nil {
+			return nil, err
+		}
+
+		rates = append(rates, dao.ToTaxRate(rate))
+	}
+
+	return rates, rows.Err()
+}
+
+// Delete removes the tax rate of the category. It reports false if there was no such rate.
+func (r *TaxRate) Delete(ctx context.Context, category string) (bool, error) {
+	query := "DELETE FROM " + r.table + " WHERE category = $1"
+
+	res, err := conn(ctx, r.conn).ExecContext(ctx, query, category)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+type OrderTaxes struct {
+	conn  *sql.DB
+	table string
+}
+
+func NewOrderTaxes(conn *sql.DB) *OrderTaxes {
+	return &OrderTaxes{
+		conn:  conn,
+		table: tableOrderTaxes,
+	}
+}
+
+func (r *OrderTaxes) Create(ctx context.Context, line model.TaxLine) error {
+	object := dao.FromTaxLine(line)
+	query := "INSERT INTO " + r.table + " (orderid, category, rate, inclusive, taxableamount, taxamount) VALUES ($1, $2, $3, $4, $5, $6)"
+
+	_, err := conn(ctx, r.conn).ExecContext(ctx, query, object.OrderID, object.Category, object.Rate, object.Inclusive, object.TaxableAmount, object.TaxAmount)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GetAllWithID returns the tax lines stored for the order with the given ID.
+func (r *OrderTaxes) GetAllWithID(ctx context.Context, id int) ([]model.TaxLine, error) {
+	var lines []model.TaxLine
+	query := "SELECT orderid, category, rate, inclusive, taxableamount, taxamount FROM " + r.table + " WHERE orderid = $1 ORDER BY category"
+
+	rows, err := conn(ctx, r.conn).QueryContext(ctx, query, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var line dao.TaxLine
+		err := rows.Scan(&line.OrderID, &line.Category, &line.Rate, &line.Inclusive, &line.TaxableAmount, &line.TaxAmount)
+		if err != nil {
+			return nil, err
+		}
+
+		lines = append(lines, dao.ToTaxLine(line))
+	}
+
+	return lines, rows.Err()
+}
@@ -0,0 +1,90 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+)
+
+// querier is the subset of *sql.DB and *sql.Tx used by the repositories.
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// executor runs the queries of the repositories and reports their errors with dbError.
+type executor struct {
+	q querier
+}
+
+func (e executor) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	res, err := e.q.ExecContext(ctx, query, args...)
+	return res, dbError(err)
+}
+
+func (e executor) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	rows, err := e.q.QueryContext(ctx, query, args...)
+	return rows, dbError(err)
+}
+
+func (e executor) QueryRowContext(ctx context.Context, query string, args ...any) row {
+	return row{e.q.QueryRowContext(ctx, query, args...)}
+}
+
+// row is the result of QueryRowContext; a missing row is reported as model.ErrNotFound.
+type row struct {
+	*sql.Row
+}
+
+func (r row) Scan(dest ...any) error {
+	return dbError(r.Row.Scan(dest...))
+}
+
+type txKey struct{}
+
+// Transactor runs a group of repository calls inside a single database transaction.
+type Transactor struct {
+	conn *sql.DB
+}
+
+func NewTransactor(conn *sql.DB) *Transactor {
+	return &Transactor{conn: conn}
+}
+
+// WithinTx begins a transaction, stores it in the context passed to fn and
+// commits it if fn returns nil. Any error returned by fn rolls the transaction back,
+// and so does a panic or runtime.Goexit in fn, e.g. t.Fatal in a test, so that the
+// connection and its locks are not kept. Nested calls reuse the transaction that is
+// already in the context.
+func (t *Transactor) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := t.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	committed := false
+	defer func() {
+		if !committed {
+			_ = tx.Rollback()
+		}
+	}()
+
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return err
+	}
+
+	committed = true
+	return dbError(tx.Commit())
+}
+
+// conn returns the transaction stored in the context, or the plain connection otherwise.
+func conn(ctx context.Context, db *sql.DB) executor {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return executor{q: tx}
+	}
+	return executor{q: db}
+}
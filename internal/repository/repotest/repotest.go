@@ -0,0 +1,1077 @@
+// Package repotest holds the contract tests that every storage backend must pass,
+// so that the services behave the same whichever backend the shop runs on.
+package repotest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"coffee-shop/internal/model"
+	"coffee-shop/internal/repository/memory"
+	"coffee-shop/internal/service"
+)
+
+// missingID is an ID that no record gets in a test.
+const missingID = 1 << 30
+
+// Repos are the repositories of the backend under test.
+type Repos struct {
+	Tx                 service.Transactor
+	Inventory          service.InventoryRepo
+	Menu               service.MenuRepo
+	MenuIngredients    service.MenuItemIngredientsRepo
+	PriceHistory       service.PriceHistoryRepo
+	Orders             service.OrderRepo
+	OrderItems         service.OrderItemsRepo
+	OrderStatusHistory service.OrderStatusHistoryRepo
+	Staff              service.StaffRepo
+	Audit              service.AuditRepo
+	IdempotencyKeys    service.IdempotencyRepo
+
+	// Shared is set when the repositories keep records of other tests and users,
+	// as a database does. Every test then runs in a transaction that is rolled
+	// back, and the tests that need their own records are skipped.
+	Shared bool
+}
+
+// Memory returns the repositories of the memory package on the DB.
+func Memory(db *memory.DB) Repos {
+	return Repos{
+		Tx:                 db,
+		Inventory:          memory.NewInventory(db),
+		Menu:               memory.NewMenu(db),
+		MenuIngredients:    memory.NewMenuItemIngredients(db),
+		PriceHistory:       memory.NewPriceHistory(db),
+		Orders:             memory.NewOrder(db),
+		OrderItems:         memory.NewOrderItems(db),
+		OrderStatusHistory: memory.NewOrderStatusHistory(db),
+		Staff:              memory.NewStaff(db),
+		Audit:              memory.NewAudit(db),
+		IdempotencyKeys:    memory.NewIdempotencyKeys(db),
+	}
+}
+
+var errRollback = errors.New("rollback")
+
+// Run runs the contract tests. newRepos is called once for every test.
+func Run(t *testing.T, newRepos func(t *testing.T) Repos) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, ctx context.Context, r Repos)
+	}{
+		{"Inventory", testInventory},
+		{"InventoryAdjustQuantity", testInventoryAdjustQuantity},
+		{"InventoryPatch", testInventoryPatch},
+		{"InventoryArchive", testInventoryArchive},
+		{"Menu", testMenu},
+		{"MenuPatch", testMenuPatch},
+		{"MenuArchive", testMenuArchive},
+		{"MenuIngredients", testMenuIngredients},
+		{"PriceHistory", testPriceHistory},
+		{"Orders", testOrders},
+		{"OrderClose", testOrderClose},
+		{"OrderItems", testOrderItems},
+		{"OrderStatusHistory", testOrderStatusHistory},
+		{"Staff", testStaff},
+		{"Audit", testAudit},
+		{"IdempotencyKeys", testIdempotencyKeys},
+		{"MissingReference", testMissingReference},
+		{"ReferencedDelete", testReferencedDelete},
+		{"Rollback", testRollback},
+		{"Concurrency", testConcurrency},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newRepos(t)
+			ctx := context.Background()
+			if r.Shared {
+				ctx = rolledBackTx(t, r.Tx)
+			}
+			tt.fn(t, ctx, r)
+		})
+	}
+}
+
+// rolledBackTx begins a transaction that is rolled back when the test ends and returns
+// its context. The transaction is held open by another goroutine, so the test does
+// not run inside WithinTx, and a test that stops with t.Fatal is rolled back as well.
+func rolledBackTx(t *testing.T, tx service.Transactor) context.Context {
+	t.Helper()
+	txCtx := make(chan context.Context)
+	release := make(chan struct{})
+	result := make(chan error, 1)
+	go func() {
+		result <- tx.WithinTx(context.Background(), func(ctx context.Context) error {
+			txCtx <- ctx
+			<-release
+			return errRollback
+		})
+	}()
+
+	select {
+	case ctx := <-txCtx:
+		t.Cleanup(func() {
+			close(release)
+			if err := <-result; !errors.Is(err, errRollback) {
+				t.Errorf("rollback: %v", err)
+			}
+		})
+		return ctx
+	case err := <-result:
+		t.Fatalf("begin: %v", err)
+		return nil
+	}
+}
+
+func testInventory(t *testing.T, ctx context.Context, r Repos) {
+	first := createInventory(t, ctx, r, model.Inventory{Name: uniqueName(t, "milk"), Quantity: 1000, Unit: "ml"})
+	second := createInventory(t, ctx, r, model.Inventory{Name: uniqueName(t, "beans"), Quantity: 500, Unit: "g"})
+	if first.IngredientID <= 0 || second.IngredientID <= first.IngredientID {
+		t.Fatalf("IDs are not assigned in increasing order: %d, %d", first.IngredientID, second.IngredientID)
+	}
+
+	got, err := r.Inventory.Get(ctx, second.IngredientID)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if got != second {
+		t.Errorf("Get = %+v, want %+v", got, second)
+	}
+
+	items, err := r.Inventory.GetAll(ctx)
+	if err != nil {
+		t.Fatalf("GetAll: %v", err)
+	}
+	if !slices.IsSortedFunc(items, func(a, b model.Inventory) int { return a.IngredientID - b.IngredientID }) {
+		t.Errorf("GetAll is not in the order of IDs")
+	}
+	if !slices.Contains(items, first) || !slices.Contains(items, second) {
+		t.Errorf("GetAll misses the created items")
+	}
+
+	var seen []int
+	err = r.Inventory.Each(ctx, func(item model.Inventory) error {
+		seen = append(seen, item.IngredientID)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Each: %v", err)
+	}
+	if len(seen) != len(items) {
+		t.Errorf("Each visited %d items, GetAll returned %d", len(seen), len(items))
+	}
+
+	if first.Version != 1 {
+		t.Errorf("Version of a created item = %d, want 1", first.Version)
+	}
+	update := model.Inventory{Name: uniqueName(t, "oat milk"), Quantity: 750, Unit: "l", Version: first.Version}
+	if err := r.Inventory.Update(ctx, first.IngredientID, update); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	update.IngredientID = first.IngredientID
+	update.Version = 2
+	if got, _ := r.Inventory.Get(ctx, first.IngredientID); got != update {
+		t.Errorf("Get after Update = %+v, want %+v", got, update)
+	}
+
+	assertNotFound(t, "Update of a missing item", func() error {
+		return r.Inventory.Update(ctx, missingID, update)
+	})
+	assertVersionMismatch(t, "Update with an old version", func() error {
+		return r.Inventory.Update(ctx, first.IngredientID, first)
+	})
+	assertVersionMismatch(t, "Delete with an old version", func() error {
+		return r.Inventory.Delete(ctx, first.IngredientID, first.Version)
+	})
+
+	if err := r.Inventory.Delete(ctx, first.IngredientID, update.Version); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	assertNotFound(t, "Get after Delete", func() error {
+		_, err := r.Inventory.Get(ctx, first.IngredientID)
+		return err
+	})
+	assertNotFound(t, "Delete of a missing item", func() error {
+		return r.Inventory.Delete(ctx, missingID, 0)
+	})
+	assertNotFound(t, "Get of a missing item", func() error {
+		_, err := r.Inventory.Get(ctx, missingID)
+		return err
+	})
+
+	third := createInventory(t, ctx, r, model.Inventory{Name: uniqueName(t, "sugar"), Quantity: 10, Unit: "g"})
+	if third.IngredientID <= second.IngredientID {
+		t.Errorf("ID %d of a deleted item is reused or reordered: got %d", first.IngredientID, third.IngredientID)
+	}
+}
+
+func testInventoryAdjustQuantity(t *testing.T, ctx context.Context, r Repos) {
+	item := createInventory(t, ctx, r, model.Inventory{Name: uniqueName(t, "milk"), Quantity: 100, Unit: "ml"})
+
+	steps := []struct {
+		delta    int
+		adjusted bool
+		want     int
+	}{
+		{delta: 50, adjusted: true, want: 150},
+		{delta: -150, adjusted: true, want: 0},
+		{delta: -1, adjusted: false, want: 0},
+	}
+	for _, step := range steps {
+		adjusted, err := r.Inventory.AdjustQuantity(ctx, item.IngredientID, step.delta)
+		if err != nil {
+			t.Fatalf("AdjustQuantity(%d): %v", step.delta, err)
+		}
+		if adjusted != step.adjusted {
+			t.Errorf("AdjustQuantity(%d) = %v, want %v", step.delta, adjusted, step.adjusted)
+		}
+		got, _ := r.Inventory.Get(ctx, item.IngredientID)
+		if got.Quantity != step.want {
+			t.Errorf("quantity after AdjustQuantity(%d) = %d, want %d", step.delta, got.Quantity, step.want)
+		}
+		if changed := got.Version != item.Version; changed != step.adjusted {
+			t.Errorf("version after AdjustQuantity(%d) = %d, was %d", step.delta, got.Version, item.Version)
+		}
+		item = got
+	}
+
+	adjusted, err := r.Inventory.AdjustQuantity(ctx, missingID, 1)
+	if err != nil || adjusted {
+		t.Errorf("AdjustQuantity of a missing item = %v, %v, want false, nil", adjusted, err)
+	}
+}
+
+func testInventoryPatch(t *testing.T, ctx context.Context, r Repos) {
+	item := createInventory(t, ctx, r, model.Inventory{Name: uniqueName(t, "milk"), Quantity: 100, Unit: "ml"})
+
+	quantity := 250
+	if err := r.Inventory.Patch(ctx, item.IngredientID, item.Version, model.InventoryPatch{Quantity: &quantity}); err != nil {
+		t.Fatalf("Patch: %v", err)
+	}
+	want := item
+	want.Quantity = quantity
+	want.Version = item.Version + 1
+	if got, _ := r.Inventory.Get(ctx, item.IngredientID); got != want {
+		t.Errorf("Get after Patch = %+v, want %+v", got, want)
+	}
+
+	unit := "l"
+	if err := r.Inventory.Patch(ctx, item.IngredientID, 0, model.InventoryPatch{Unit: &unit}); err != nil {
+		t.Fatalf("Patch without a version: %v", err)
+	}
+	if got, _ := r.Inventory.Get(ctx, item.IngredientID); got.Unit != unit || got.Quantity != quantity || got.Version != want.Version+1 {
+		t.Errorf("Get after Patch without a version = %+v", got)
+	}
+
+	assertNotFound(t, "Patch of a missing item", func() error {
+		return r.Inventory.Patch(ctx, missingID, 0, model.InventoryPatch{Unit: &unit})
+	})
+	assertVersionMismatch(t, "Patch with an old version", func() error {
+		return r.Inventory.Patch(ctx, item.IngredientID, item.Version, model.InventoryPatch{Unit: &unit})
+	})
+}
+
+func testInventoryArchive(t *testing.T, ctx context.Context, r Repos) {
+	item := createInventory(t, ctx, r, model.Inventory{Name: uniqueName(t, "milk"), Quantity: 100, Unit: "ml"})
+
+	if err := r.Inventory.Archive(ctx, item.IngredientID, item.Version, time.Now()); err != nil {
+		t.Fatalf("Archive: %v", err)
+	}
+	got, _ := r.Inventory.Get(ctx, item.IngredientID)
+	if !got.IsArchived() || got.Version != item.Version+1 || got.Quantity != item.Quantity {
+		t.Errorf("Get after Archive = %+v, want an archived item of version %d", got, item.Version+1)
+	}
+	assertVersionMismatch(t, "Archive with an old version", func() error {
+		return r.Inventory.Archive(ctx, item.IngredientID, item.Version, time.Now())
+	})
+	assertNotFound(t, "Archive of a missing item", func() error {
+		return r.Inventory.Archive(ctx, missingID, 0, time.Now())
+	})
+
+	// An update keeps the item archived.
+	update := model.Inventory{Name: item.Name, Quantity: 50, Unit: item.Unit}
+	if err := r.Inventory.Update(ctx, item.IngredientID, update); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if got, _ := r.Inventory.Get(ctx, item.IngredientID); !got.IsArchived() {
+		t.Errorf("Get after Update = %+v, want an archived item", got)
+	}
+
+	if err := r.Inventory.Restore(ctx, item.IngredientID, 0); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if got, _ := r.Inventory.Get(ctx, item.IngredientID); got.IsArchived() || got.Version != item.Version+3 {
+		t.Errorf("Get after Restore = %+v, want an item of version %d that is not archived", got, item.Version+3)
+	}
+	assertVersionMismatch(t, "Restore with an old version", func() error {
+		return r.Inventory.Restore(ctx, item.IngredientID, item.Version)
+	})
+	assertNotFound(t, "Restore of a missing item", func() error {
+		return r.Inventory.Restore(ctx, missingID, 0)
+	})
+
+	refs, err := r.Inventory.References(ctx, item.IngredientID)
+	if err != nil || len(refs) != 0 {
+		t.Errorf("References of an unused item = %+v, %v, want none", refs, err)
+	}
+	menu := createMenuItem(t, ctx, r, "latte", 3.5)
+	if err := r.MenuIngredients.Create(ctx, model.MenuItemIngredients{MenuID: menu.ID, IngredientID: item.IngredientID, Quantity: 200}); err != nil {
+		t.Fatalf("Create ingredient: %v", err)
+	}
+	refs, err = r.Inventory.References(ctx, item.IngredientID)
+	want := []model.Reference{{EntityType: model.ReferenceRecipes, Count: 1}}
+	if err != nil || !slices.Equal(refs, want) {
+		t.Errorf("References = %+v, %v, want %+v", refs, err, want)
+	}
+}
+
+func testMenu(t *testing.T, ctx context.Context, r Repos) {
+	first := createMenuItem(t, ctx, r, "latte", 3.5)
+	second := createMenuItem(t, ctx, r, "croissant", 2.25)
+	if first.ID <= 0 || second.ID <= first.ID {
+		t.Fatalf("IDs are not assigned in increasing order: %d, %d", first.ID, second.ID)
+	}
+
+	got, err := r.Menu.Get(ctx, first.ID)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if got != first {
+		t.Errorf("Get = %+v, want %+v", got, first)
+	}
+
+	items, err := r.Menu.GetAll(ctx)
+	if err != nil {
+		t.Fatalf("GetAll: %v", err)
+	}
+	if !slices.IsSortedFunc(items, func(a, b model.MenuItem) int { return a.ID - b.ID }) {
+		t.Errorf("GetAll is not in the order of IDs")
+	}
+	if !slices.Contains(items, first) || !slices.Contains(items, second) {
+		t.Errorf("GetAll misses the created items")
+	}
+
+	update := model.MenuItem{Name: uniqueName(t, "flat white"), Description: "double shot", Category: "drinks", Price: 4, Version: first.Version}
+	if err := r.Menu.Update(ctx, first.ID, update); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	update.ID = first.ID
+	update.Version = 2
+	if got, _ := r.Menu.Get(ctx, first.ID); got != update {
+		t.Errorf("Get after Update = %+v, want %+v", got, update)
+	}
+	assertNotFound(t, "Update of a missing item", func() error {
+		return r.Menu.Update(ctx, missingID, update)
+	})
+	assertVersionMismatch(t, "Update with an old version", func() error {
+		return r.Menu.Update(ctx, first.ID, first)
+	})
+	assertVersionMismatch(t, "Delete with an old version", func() error {
+		return r.Menu.Delete(ctx, first.ID, first.Version)
+	})
+
+	if err := r.Menu.Delete(ctx, second.ID, 0); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	assertNotFound(t, "Get after Delete", func() error {
+		_, err := r.Menu.Get(ctx, second.ID)
+		return err
+	})
+	assertNotFound(t, "Delete of a missing item", func() error {
+		return r.Menu.Delete(ctx, missingID, 0)
+	})
+}
+
+func testMenuPatch(t *testing.T, ctx context.Context, r Repos) {
+	item := createMenuItem(t, ctx, r, "latte", 3.5)
+
+	price := 3.75
+	if err := r.Menu.Patch(ctx, item.ID, item.Version, model.MenuItemPatch{Price: &price}); err != nil {
+		t.Fatalf("Patch: %v", err)
+	}
+	want := item
+	want.Price = price
+	want.Version = item.Version + 1
+	if got, _ := r.Menu.Get(ctx, item.ID); got != want {
+		t.Errorf("Get after Patch = %+v, want %+v", got, want)
+	}
+
+	// An empty patch changes only the version, as it does when the recipe changes.
+	if err := r.Menu.Patch(ctx, item.ID, want.Version, model.MenuItemPatch{}); err != nil {
+		t.Fatalf("empty Patch: %v", err)
+	}
+	want.Version++
+	if got, _ := r.Menu.Get(ctx, item.ID); got != want {
+		t.Errorf("Get after an empty Patch = %+v, want %+v", got, want)
+	}
+
+	assertNotFound(t, "Patch of a missing item", func() error {
+		return r.Menu.Patch(ctx, missingID, 0, model.MenuItemPatch{Price: &price})
+	})
+	assertVersionMismatch(t, "Patch with an old version", func() error {
+		return r.Menu.Patch(ctx, item.ID, item.Version, model.MenuItemPatch{Price: &price})
+	})
+}
+
+func testMenuArchive(t *testing.T, ctx context.Context, r Repos) {
+	item := createMenuItem(t, ctx, r, "latte", 3.5)
+
+	if err := r.Menu.Archive(ctx, item.ID, item.Version, time.Now()); err != nil {
+		t.Fatalf("Archive: %v", err)
+	}
+	got, _ := r.Menu.Get(ctx, item.ID)
+	if !got.IsArchived() || got.Version != item.Version+1 || got.Price != item.Price {
+		t.Errorf("Get after Archive = %+v, want an archived item of version %d", got, item.Version+1)
+	}
+	assertVersionMismatch(t, "Archive with an old version", func() error {
+		return r.Menu.Archive(ctx, item.ID, item.Version, time.Now())
+	})
+	assertNotFound(t, "Archive of a missing item", func() error {
+		return r.Menu.Archive(ctx, missingID, 0, time.Now())
+	})
+
+	if err := r.Menu.Restore(ctx, item.ID, got.Version); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if got, _ := r.Menu.Get(ctx, item.ID); got.IsArchived() || got.Version != item.Version+2 {
+		t.Errorf("Get after Restore = %+v, want an item of version %d that is not archived", got, item.Version+2)
+	}
+	assertNotFound(t, "Restore of a missing item", func() error {
+		return r.Menu.Restore(ctx, missingID, 0)
+	})
+
+	refs, err := r.Menu.References(ctx, item.ID)
+	if err != nil || len(refs) != 0 {
+		t.Errorf("References of an item never ordered = %+v, %v, want none", refs, err)
+	}
+	order := createOrder(t, ctx, r, "Ann")
+	for range 2 {
+		if err := r.OrderItems.Create(ctx, model.OrderItems{OrderID: order.ID, ProductID: item.ID, Quantity: 1, Price: 3.5}); err != nil {
+			t.Fatalf("Create order item: %v", err)
+		}
+	}
+	if _, err := r.PriceHistory.Create(ctx, model.PriceHistory{MenuItemID: item.ID, OldPrice: 3.5, NewPrice: 4}); err != nil {
+		t.Fatalf("Create price change: %v", err)
+	}
+	refs, err = r.Menu.References(ctx, item.ID)
+	want := []model.Reference{
+		{EntityType: model.ReferenceOrderItems, Count: 2},
+		{EntityType: model.ReferencePriceHistory, Count: 1},
+	}
+	if err != nil || !slices.Equal(refs, want) {
+		t.Errorf("References = %+v, %v, want %+v", refs, err, want)
+	}
+}
+
+func testMenuIngredients(t *testing.T, ctx context.Context, r Repos) {
+	item := createMenuItem(t, ctx, r, "latte", 3.5)
+	milk := createInventory(t, ctx, r, model.Inventory{Name: uniqueName(t, "milk"), Quantity: 1000, Unit: "ml"})
+	beans := createInventory(t, ctx, r, model.Inventory{Name: uniqueName(t, "beans"), Quantity: 500, Unit: "g"})
+
+	// Created out of order to pin the order of GetAllWithID.
+	for _, ingredient := range []model.MenuItemIngredients{
+		{MenuID: item.ID, IngredientID: beans.IngredientID, Quantity: 18},
+		{MenuID: item.ID, IngredientID: milk.IngredientID, Quantity: 200},
+	} {
+		if err := r.MenuIngredients.Create(ctx, ingredient); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+	}
+
+	want := []model.MenuItemIngredients{
+		{MenuID: item.ID, IngredientID: milk.IngredientID, Quantity: 200},
+		{MenuID: item.ID, IngredientID: beans.IngredientID, Quantity: 18},
+	}
+	assertIngredients(t, ctx, r, item.ID, want)
+
+	want[1].Quantity = 20
+	if err := r.MenuIngredients.Update(ctx, item.ID, want[1]); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	assertIngredients(t, ctx, r, item.ID, want)
+
+	if err := r.MenuIngredients.DeleteIngredient(ctx, item.ID, milk.IngredientID); err != nil {
+		t.Fatalf("DeleteIngredient: %v", err)
+	}
+	assertIngredients(t, ctx, r, item.ID, want[1:])
+	assertNotFound(t, "DeleteIngredient of a missing ingredient", func() error {
+		return r.MenuIngredients.DeleteIngredient(ctx, item.ID, milk.IngredientID)
+	})
+	if err := r.MenuIngredients.Create(ctx, want[0]); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	if err := r.MenuIngredients.Delete(ctx, item.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	assertIngredients(t, ctx, r, item.ID, nil)
+	assertIngredients(t, ctx, r, missingID, nil)
+
+	if err := r.MenuIngredients.Create(ctx, want[0]); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if err := r.Menu.Delete(ctx, item.ID, 0); err != nil {
+		t.Fatalf("Delete of the menu item: %v", err)
+	}
+	assertIngredients(t, ctx, r, item.ID, nil)
+}
+
+func testPriceHistory(t *testing.T, ctx context.Context, r Repos) {
+	item := createMenuItem(t, ctx, r, "latte", 3.5)
+
+	changes := []model.PriceHistory{
+		{MenuItemID: item.ID, OldPrice: 3.5, NewPrice: 3.75},
+		{MenuItemID: item.ID, OldPrice: 3.75, NewPrice: 4},
+	}
+	for i := range changes {
+		id, err := r.PriceHistory.Create(ctx, changes[i])
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		if id <= 0 || i > 0 && id <= changes[i-1].HistoryID {
+			t.Errorf("Create returned ID %d, want an increasing positive ID", id)
+		}
+		changes[i].HistoryID = id
+	}
+
+	got, err := r.PriceHistory.GetAllWithID(ctx, item.ID)
+	if err != nil {
+		t.Fatalf("GetAllWithID: %v", err)
+	}
+	if len(got) != len(changes) {
+		t.Fatalf("GetAllWithID returned %d changes, want %d", len(got), len(changes))
+	}
+	for i := range got {
+		if got[i].ChangedAt.IsZero() {
+			t.Errorf("change %d has no time", got[i].HistoryID)
+		}
+		got[i].ChangedAt = changes[i].ChangedAt
+		if got[i] != changes[i] {
+			t.Errorf("GetAllWithID[%d] = %+v, want %+v", i, got[i], changes[i])
+		}
+	}
+
+	if got, err := r.PriceHistory.GetAllWithID(ctx, missingID); err != nil || len(got) != 0 {
+		t.Errorf("GetAllWithID of a missing item = %v, %v, want no changes", got, err)
+	}
+}
+
+func testOrders(t *testing.T, ctx context.Context, r Repos) {
+	first := createOrder(t, ctx, r, "Ann")
+	second := createOrder(t, ctx, r, "Bob")
+	if first.ID <= 0 || second.ID <= first.ID {
+		t.Fatalf("IDs are not assigned in increasing order: %d, %d", first.ID, second.ID)
+	}
+
+	got, err := r.Orders.Get(ctx, first.ID)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if got.CustomerName != "Ann" || got.Status != model.OrderStatusOpen || got.Notes != "no sugar" || got.DiscountTotal != 0.5 {
+		t.Errorf("Get = %+v, want the created order", got)
+	}
+	if got.CreateAt.IsZero() || !got.ClosedAt.IsZero() {
+		t.Errorf("Get: created at %v, closed at %v, want only the creation time", got.CreateAt, got.ClosedAt)
+	}
+
+	orders, err := r.Orders.GetAll(ctx)
+	if err != nil {
+		t.Fatalf("GetAll: %v", err)
+	}
+	if !slices.IsSortedFunc(orders, func(a, b model.Order) int { return a.ID - b.ID }) {
+		t.Errorf("GetAll is not in the order of IDs")
+	}
+	if !slices.ContainsFunc(orders, func(o model.Order) bool { return o.ID == second.ID }) {
+		t.Errorf("GetAll misses the created orders")
+	}
+
+	got.CustomerName = "Ann Lee"
+	got.Notes = "oat milk"
+	got.DiscountTotal = 1
+	if err := r.Orders.Update(ctx, first.ID, got); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	updated, _ := r.Orders.Get(ctx, first.ID)
+	if updated.CustomerName != "Ann Lee" || updated.Notes != "oat milk" || updated.DiscountTotal != 1 || updated.Version != got.Version+1 {
+		t.Errorf("Get after Update = %+v", updated)
+	}
+	assertNotFound(t, "Update of a missing order", func() error {
+		return r.Orders.Update(ctx, missingID, got)
+	})
+	assertVersionMismatch(t, "Update with an old version", func() error {
+		return r.Orders.Update(ctx, first.ID, got)
+	})
+	assertVersionMismatch(t, "Delete with an old version", func() error {
+		return r.Orders.Delete(ctx, second.ID, second.Version+1)
+	})
+
+	if err := r.Orders.Delete(ctx, second.ID, second.Version); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	assertNotFound(t, "Get after Delete", func() error {
+		_, err := r.Orders.Get(ctx, second.ID)
+		return err
+	})
+	assertNotFound(t, "Get of a missing order", func() error {
+		_, err := r.Orders.Get(ctx, missingID)
+		return err
+	})
+	assertNotFound(t, "Delete of a missing order", func() error {
+		return r.Orders.Delete(ctx, missingID, 0)
+	})
+
+	err = r.Tx.WithinTx(ctx, func(ctx context.Context) error {
+		return r.Orders.Lock(ctx, first.ID)
+	})
+	if err != nil {
+		t.Errorf("Lock: %v", err)
+	}
+	assertNotFound(t, "Lock of a missing order", func() error {
+		return r.Tx.WithinTx(ctx, func(ctx context.Context) error {
+			return r.Orders.Lock(ctx, missingID)
+		})
+	})
+}
+
+func testOrderClose(t *testing.T, ctx context.Context, r Repos) {
+	order := createOrder(t, ctx, r, "Ann")
+	order.OrderTotals = model.OrderTotals{Subtotal: 7, DiscountTotal: 0.5, TaxTotal: 0.65, Total: 7.15}
+	order.ClosedAt = order.CreateAt.Add(time.Minute)
+
+	closed, err := r.Orders.Close(ctx, order)
+	if err != nil || !closed {
+		t.Fatalf("Close = %v, %v, want true", closed, err)
+	}
+
+	got, _ := r.Orders.Get(ctx, order.ID)
+	if got.Status != model.OrderStatusClosed || got.Subtotal != order.Subtotal || got.DiscountTotal != order.DiscountTotal ||
+		got.TaxTotal != order.TaxTotal || got.Total != order.Total || got.ClosedAt.IsZero() || got.Version != order.Version+1 {
+		t.Errorf("Get after Close = %+v", got)
+	}
+
+	if closed, err := r.Orders.Close(ctx, order); err != nil || closed {
+		t.Errorf("second Close = %v, %v, want false", closed, err)
+	}
+	order.ID = missingID
+	if closed, err := r.Orders.Close(ctx, order); err != nil || closed {
+		t.Errorf("Close of a missing order = %v, %v, want false", closed, err)
+	}
+}
+
+func testOrderItems(t *testing.T, ctx context.Context, r Repos) {
+	order := createOrder(t, ctx, r, "Ann")
+	latte := createMenuItem(t, ctx, r, "latte", 3.5)
+	croissant := createMenuItem(t, ctx, r, "croissant", 2.25)
+
+	want := []model.OrderItems{
+		{OrderID: order.ID, ProductID: latte.ID, Quantity: 2, Price: 3.5, Modifiers: []string{"oat milk", "extra shot"}},
+		{OrderID: order.ID, ProductID: croissant.ID, Quantity: 1, Price: 2.25},
+	}
+	for _, item := range slices.Backward(want) {
+		if err := r.OrderItems.Create(ctx, item); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+	}
+
+	got, err := r.OrderItems.GetAllWithID(ctx, order.ID)
+	if err != nil {
+		t.Fatalf("GetAllWithID: %v", err)
+	}
+	// Compared as text, since backends may return no modifiers as nil or as an empty slice.
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("GetAllWithID = %+v, want %+v", got, want)
+	}
+
+	if err := r.OrderItems.Delete(ctx, order.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if got, err := r.OrderItems.GetAllWithID(ctx, order.ID); err != nil || len(got) != 0 {
+		t.Errorf("GetAllWithID after Delete = %v, %v, want no items", got, err)
+	}
+	if err := r.Orders.Delete(ctx, order.ID, 0); err != nil {
+		t.Errorf("Delete of the order without items: %v", err)
+	}
+}
+
+func testOrderStatusHistory(t *testing.T, ctx context.Context, r Repos) {
+	order := createOrder(t, ctx, r, "Ann")
+
+	if err := r.OrderStatusHistory.Create(ctx, model.OrderStatusHistory{OrderID: order.ID}); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if err := r.OrderStatusHistory.Close(ctx, order.ID); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if err := r.OrderStatusHistory.Close(ctx, missingID); err != nil {
+		t.Errorf("Close of a missing order: %v, want nil", err)
+	}
+	if err := r.OrderStatusHistory.DeleteByOrder(ctx, order.ID); err != nil {
+		t.Fatalf("DeleteByOrder: %v", err)
+	}
+	if err := r.Orders.Delete(ctx, order.ID, 0); err != nil {
+		t.Errorf("Delete of the order without history: %v", err)
+	}
+}
+
+func testStaff(t *testing.T, ctx context.Context, r Repos) {
+	staff := model.Staff{Username: uniqueName(t, "alice"), Role: model.RoleBarista, PasswordHash: "password hash"}
+	id, err := r.Staff.Create(ctx, staff)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	staff.ID = id
+
+	got, err := r.Staff.Get(ctx, id)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if got.CreatedAt.IsZero() {
+		t.Errorf("created staff member has no time")
+	}
+	staff.CreatedAt = got.CreatedAt
+	if got != staff {
+		t.Errorf("Get = %+v, want %+v", got, staff)
+	}
+	if got, err := r.Staff.GetByUsername(ctx, staff.Username); err != nil || got.ID != id {
+		t.Errorf("GetByUsername = %+v, %v, want the member %d", got, err, id)
+	}
+
+	staff.Role = model.RoleManager
+	staff.APIKeyHash = uniqueName(t, "key hash")
+	if err := r.Staff.Update(ctx, id, staff); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if got, err := r.Staff.GetByAPIKey(ctx, staff.APIKeyHash); err != nil || got != staff {
+		t.Errorf("GetByAPIKey = %+v, %v, want %+v", got, err, staff)
+	}
+
+	all, err := r.Staff.GetAll(ctx)
+	if err != nil {
+		t.Fatalf("GetAll: %v", err)
+	}
+	if !slices.Contains(all, staff) {
+		t.Errorf("GetAll does not return %+v", staff)
+	}
+
+	assertNotFound(t, "Get of a missing member", func() error { _, err := r.Staff.Get(ctx, missingID); return err })
+	assertNotFound(t, "GetByUsername of a missing member", func() error { _, err := r.Staff.GetByUsername(ctx, uniqueName(t, "nobody")); return err })
+	assertNotFound(t, "GetByAPIKey of an unknown key", func() error { _, err := r.Staff.GetByAPIKey(ctx, uniqueName(t, "unknown")); return err })
+	assertNotFound(t, "Update of a missing member", func() error { return r.Staff.Update(ctx, missingID, staff) })
+
+	// A database aborts the transaction on the error, so it is checked last.
+	duplicate := model.Staff{Username: staff.Username, Role: model.RoleAdmin, PasswordHash: "other hash"}
+	if _, err := r.Staff.Create(ctx, duplicate); !errors.Is(err, model.ErrDuplicate) {
+		t.Errorf("Create with a taken username = %v, want model.ErrDuplicate", err)
+	}
+}
+
+func testAudit(t *testing.T, ctx context.Context, r Repos) {
+	// The entries refer to an entity no other test changes, so that the query
+	// returns only them.
+	created := model.AuditEntry{
+		ActorID:    1,
+		Actor:      "alice",
+		Action:     model.AuditCreate,
+		EntityType: model.AuditInventory,
+		EntityID:   missingID,
+		After:      json.RawMessage(`{"name":"milk","quantity":10}`),
+		RequestID:  uniqueName(t, "request"),
+		IP:         "192.0.2.1",
+	}
+	updated := created
+	updated.Action = model.AuditUpdate
+	updated.Before = created.After
+	updated.After = json.RawMessage(`{"name":"milk","quantity":5}`)
+	deleted := model.AuditEntry{Action: model.AuditDelete, EntityType: model.AuditInventory, EntityID: missingID, Before: updated.After}
+
+	for _, entry := range []*model.AuditEntry{&created, &updated, &deleted} {
+		id, err := r.Audit.Create(ctx, *entry)
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		entry.ID = id
+	}
+
+	q := model.AuditQuery{EntityType: model.AuditInventory, EntityID: missingID, Limit: model.MaxAuditEntries}
+	entries, err := r.Audit.Find(ctx, q)
+	if err != nil {
+		t.Fatalf("Find: %v", err)
+	}
+	want := []model.AuditEntry{deleted, updated, created}
+	if len(entries) != len(want) {
+		t.Fatalf("Find returned %d entries, want %d", len(entries), len(want))
+	}
+	for i, got := range entries {
+		if got.CreatedAt.IsZero() {
+			t.Errorf("entry %d has no time", got.ID)
+		}
+		if !sameJSON(got.Before, want[i].Before) || !sameJSON(got.After, want[i].After) {
+			t.Errorf("entry %d has before %s and after %s, want %s and %s", got.ID, got.Before, got.After, want[i].Before, want[i].After)
+		}
+		if !equalEntries(got, want[i]) {
+			t.Errorf("Find()[%d] = %+v, want %+v", i, got, want[i])
+		}
+	}
+
+	q.Limit = 1
+	if entries, err := r.Audit.Find(ctx, q); err != nil || len(entries) != 1 || entries[0].ID != deleted.ID {
+		t.Errorf("Find with limit 1 = %+v, %v, want the entry %d", entries, err, deleted.ID)
+	}
+
+	q.Limit = model.MaxAuditEntries
+	q.Period.From = entries[0].CreatedAt.Add(time.Hour)
+	if entries, err := r.Audit.Find(ctx, q); err != nil || len(entries) != 0 {
+		t.Errorf("Find of a later period = %+v, %v, want no entries", entries, err)
+	}
+}
+
+func testIdempotencyKeys(t *testing.T, ctx context.Context, r Repos) {
+	now := time.Now()
+	record := model.IdempotencyRecord{
+		Key:         uniqueName(t, "key"),
+		RequestHash: "hash",
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(time.Hour),
+	}
+	if _, reserved, err := r.IdempotencyKeys.Reserve(ctx, record, now); err != nil || !reserved {
+		t.Fatalf("Reserve = %v, %v, want a reserved key", reserved, err)
+	}
+
+	retry := record
+	retry.RequestHash = "other hash"
+	existing, reserved, err := r.IdempotencyKeys.Reserve(ctx, retry, now)
+	if err != nil || reserved {
+		t.Fatalf("Reserve of a taken key = %v, %v, want the existing record", reserved, err)
+	}
+	if existing.RequestHash != record.RequestHash || existing.Completed {
+		t.Errorf("Reserve of a taken key returned %+v, want the request in progress", existing)
+	}
+
+	record.Completed = true
+	record.Status = 201
+	record.Header = map[string][]string{"Content-Type": {"application/json"}}
+	record.Body = []byte(`{"id":1}`)
+	if err := r.IdempotencyKeys.Complete(ctx, record); err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+	existing, _, err = r.IdempotencyKeys.Reserve(ctx, retry, now)
+	if err != nil {
+		t.Fatalf("Reserve of a completed key: %v", err)
+	}
+	if !existing.Completed || existing.Status != record.Status || string(existing.Body) != string(record.Body) ||
+		!slices.Equal(existing.Header["Content-Type"], record.Header["Content-Type"]) {
+		t.Errorf("Reserve of a completed key returned %+v, want %+v", existing, record)
+	}
+
+	later := record.ExpiresAt.Add(time.Second)
+	retry.ExpiresAt = later.Add(time.Hour)
+	if _, reserved, err := r.IdempotencyKeys.Reserve(ctx, retry, later); err != nil || !reserved {
+		t.Errorf("Reserve of an expired key = %v, %v, want a reserved key", reserved, err)
+	}
+
+	if err := r.IdempotencyKeys.Delete(ctx, record.Key); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	assertNotFound(t, "Delete of a released key", func() error { return r.IdempotencyKeys.Delete(ctx, record.Key) })
+	assertNotFound(t, "Complete of a released key", func() error { return r.IdempotencyKeys.Complete(ctx, record) })
+}
+
+// testMissingReference checks that records referring to missing records are rejected.
+// A database aborts the transaction on the first such error, so only one is checked.
+func testMissingReference(t *testing.T, ctx context.Context, r Repos) {
+	item := createMenuItem(t, ctx, r, "latte", 3.5)
+
+	err := r.OrderItems.Create(ctx, model.OrderItems{OrderID: missingID, ProductID: item.ID, Quantity: 1, Price: 3.5})
+	if err == nil {
+		t.Errorf("Create of an item of a missing order succeeded")
+	}
+}
+
+// testReferencedDelete checks that records still referred to cannot be deleted.
+func testReferencedDelete(t *testing.T, ctx context.Context, r Repos) {
+	item := createMenuItem(t, ctx, r, "latte", 3.5)
+	order := createOrder(t, ctx, r, "Ann")
+	if err := r.OrderItems.Create(ctx, model.OrderItems{OrderID: order.ID, ProductID: item.ID, Quantity: 1, Price: 3.5}); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	if err := r.Menu.Delete(ctx, item.ID, 0); err == nil {
+		t.Errorf("Delete of an ordered menu item succeeded")
+	}
+}
+
+func testRollback(t *testing.T, ctx context.Context, r Repos) {
+	if r.Shared {
+		t.Skip("the test already runs in a transaction")
+	}
+
+	var id int
+	err := r.Tx.WithinTx(ctx, func(ctx context.Context) error {
+		id = createMenuItem(t, ctx, r, "latte", 3.5).ID
+		return errRollback
+	})
+	if !errors.Is(err, errRollback) {
+		t.Fatalf("WithinTx = %v, want the error of fn", err)
+	}
+	assertNotFound(t, "Get after rollback", func() error {
+		_, err := r.Menu.Get(ctx, id)
+		return err
+	})
+
+	err = r.Tx.WithinTx(ctx, func(ctx context.Context) error {
+		id = createMenuItem(t, ctx, r, "mocha", 4).ID
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WithinTx: %v", err)
+	}
+	if _, err := r.Menu.Get(ctx, id); err != nil {
+		t.Errorf("Get after commit: %v", err)
+	}
+}
+
+func testConcurrency(t *testing.T, ctx context.Context, r Repos) {
+	if r.Shared {
+		t.Skip("a transaction is not safe for concurrent use")
+	}
+
+	const workers, perWorker = 8, 25
+	item := createInventory(t, ctx, r, model.Inventory{Name: uniqueName(t, "milk"), Quantity: 0, Unit: "ml"})
+
+	var wg sync.WaitGroup
+	ids := make(chan int, workers*perWorker)
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range perWorker {
+				id, err := r.Menu.Create(ctx, model.MenuItem{Name: "latte", Description: "milk coffee", Category: "drinks", Price: 3.5})
+				if err != nil {
+					t.Errorf("Create: %v", err)
+					return
+				}
+				ids <- id
+				if _, err := r.Inventory.AdjustQuantity(ctx, item.IngredientID, 1); err != nil {
+					t.Errorf("AdjustQuantity: %v", err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(ids)
+
+	seen := make(map[int]bool)
+	for id := range ids {
+		if seen[id] {
+			t.Errorf("ID %d was assigned twice", id)
+		}
+		seen[id] = true
+	}
+	if got, _ := r.Inventory.Get(ctx, item.IngredientID); got.Quantity != workers*perWorker {
+		t.Errorf("quantity = %d, want %d", got.Quantity, workers*perWorker)
+	}
+}
+
+// createInventory creates the item and returns it with its ID.
+func createInventory(t *testing.T, ctx context.Context, r Repos, item model.Inventory) model.Inventory {
+	t.Helper()
+	id, err := r.Inventory.Create(ctx, item)
+	if err != nil {
+		t.Fatalf("Create inventory item: %v", err)
+	}
+
+	item, err = r.Inventory.Get(ctx, id)
+	if err != nil {
+		t.Fatalf("Get inventory item: %v", err)
+	}
+	return item
+}
+
+func createMenuItem(t *testing.T, ctx context.Context, r Repos, name string, price float64) model.MenuItem {
+	t.Helper()
+	item := model.MenuItem{Name: uniqueName(t, name), Description: "test item", Category: "drinks", Price: price}
+
+	id, err := r.Menu.Create(ctx, item)
+	if err != nil {
+		t.Fatalf("Create menu item: %v", err)
+	}
+	item.ID = id
+	item.Version = 1
+	return item
+}
+
+func createOrder(t *testing.T, ctx context.Context, r Repos, customer string) model.Order {
+	t.Helper()
+	order := model.Order{CustomerName: customer, Status: model.OrderStatusOpen, Notes: "no sugar", OrderTotals: model.OrderTotals{DiscountTotal: 0.5}}
+
+	id, err := r.Orders.Create(ctx, order)
+	if err != nil {
+		t.Fatalf("Create order: %v", err)
+	}
+
+	order, err = r.Orders.Get(ctx, id)
+	if err != nil {
+		t.Fatalf("Get order: %v", err)
+	}
+	return order
+}
+
+// uniqueName makes names unique across tests, since shared backends keep the records of other tests.
+func uniqueName(t *testing.T, name string) string {
+	return fmt.Sprintf("%s (%s)", name, t.Name())
+}
+
+func assertIngredients(t *testing.T, ctx context.Context, r Repos, menuID int, want []model.MenuItemIngredients) {
+	t.Helper()
+	got, err := r.MenuIngredients.GetAllWithID(ctx, menuID)
+	if err != nil {
+		t.Fatalf("GetAllWithID: %v", err)
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("GetAllWithID(%d) = %+v, want %+v", menuID, got, want)
+	}
+}
+
+// assertNotFound checks that a missing record is reported with model.ErrNotFound, which the services rely on.
+// sameJSON reports whether a and b are the same JSON value. Databases may store JSON
+// reformatted, so the documents are not compared byte by byte.
+func sameJSON(a, b json.RawMessage) bool {
+	if len(a) == 0 || len(b) == 0 {
+		return len(a) == len(b)
+	}
+
+	var va, vb any
+	if json.Unmarshal(a, &va) != nil || json.Unmarshal(b, &vb) != nil {
+		return false
+	}
+	ja, _ := json.Marshal(va)
+	jb, _ := json.Marshal(vb)
+	return string(ja) == string(jb)
+}
+
+// equalEntries compares the entries without their JSON documents and times.
+func equalEntries(a, b model.AuditEntry) bool {
+	return a.ID == b.ID && a.ActorID == b.ActorID && a.Actor == b.Actor && a.Action == b.Action &&
+		a.EntityType == b.EntityType && a.EntityID == b.EntityID && a.RequestID == b.RequestID && a.IP == b.IP
+}
+
+func assertNotFound(t *testing.T, what string, fn func() error) {
+	t.Helper()
+	if err := fn(); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("%s = %v, want model.ErrNotFound", what, err)
+	}
+}
+
+func assertVersionMismatch(t *testing.T, what string, fn func() error) {
+	t.Helper()
+	if err := fn(); !errors.Is(err, model.ErrVersionMismatch) {
+		t.Errorf("%s = %v, want model.ErrVersionMismatch", what, err)
+	}
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+
+	"coffee-shop/internal/model"
+)
+
+type auditService struct {
+	AuditRepo AuditRepo
+}
+
+func NewAuditService(repo AuditRepo) *auditService {
+	return &auditService{AuditRepo: repo}
+}
+
+// RetrieveAuditLog returns the entries of the audit log selected by the query, newest first.
+// The following errors may be returned:
+// - model.ErrNotValidAuditEntity if the entity type is unknown or an ID is given without it.
+// - model.ErrNotValidPeriod if the period starts after it ends.
+// - model.ErrNotValidLimit if the limit is out of range.
+func (s *auditService) RetrieveAuditLog(ctx context.Context, q model.AuditQuery) ([]model.AuditEntry, error) {
+	if err := q.Validate(); err != nil {
+		return nil, err
+	}
+
+	return s.AuditRepo.Find(ctx, q)
+}
+
+// recordChange writes the change of a record to the audit log, with the origin of the
+// request in ctx. It is called in the transaction of the change, so the change and its
+// entry are saved together. before is nil for a created record and after for a deleted one.
+func recordChange(ctx context.Context, repo AuditRepo, action, entityType string, entityID int, before, after any) error {
+	origin := model.OriginFrom(ctx)
+	entry := model.AuditEntry{
+		ActorID:    origin.Principal.StaffID,
+		Actor:      origin.Principal.Username,
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   entityID,
+		RequestID:  origin.RequestID,
+		IP:         origin.IP,
+	}
+
+	var err error
+	if entry.Before, err = auditJSON(before); err != nil {
+		return err
+	}
+	if entry.After, err = auditJSON(after); err != nil {
+		return err
+	}
+
+	_, err = repo.Create(ctx, entry)
+	return err
+}
+
+func auditJSON(record any) (json.RawMessage, error) {
+	if record == nil {
+		return nil, nil
+	}
+	return json.Marshal(record)
+}
+
+// auditedMenuItem is the state of a menu item recorded in the audit log.
+type auditedMenuItem struct {
+	model.MenuItem
+	Ingredients []model.MenuItemIngredients
+}
+
+// menuState returns the menu item with the given ID and its recipe as they are recorded
+// in the audit log.
+func menuState(ctx context.Context, menuRepo MenuRepo, ingredientsRepo MenuItemIngredientsRepo, id int) (auditedMenuItem, error) {
+	item, err := menuRepo.Get(ctx, id)
+	if err != nil {
+		return auditedMenuItem{}, err
+	}
+
+	ingredients, err := ingredientsRepo.GetAllWithID(ctx, id)
+	if err != nil {
+		return auditedMenuItem{}, err
+	}
+
+	return auditedMenuItem{MenuItem: item, Ingredients: ingredients}, nil
+}
@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"unicode/utf8"
+
+	"coffee-shop/internal/model"
+)
+
+type authService struct {
+	StaffRepo   StaffRepo
+	Credentials CredentialKeeper
+}
+
+func NewAuthService(repo StaffRepo, credentials CredentialKeeper) *authService {
+	return &authService{
+		StaffRepo:   repo,
+		Credentials: credentials,
+	}
+}
+
+// Login checks the password of the staff member and issues an access token.
+// The following errors may be returned:
+// - model.ErrNotValidCredentials if there is no such staff member or the password does not match.
+func (s *authService) Login(ctx context.Context, username, password string) (*model.Token, error) {
+	staff, err := s.StaffRepo.GetByUsername(ctx, username)
+	if errors.Is(err, model.ErrNotFound) {
+		// The password is checked anyway, so that the time taken does not tell
+		// which usernames exist
+		s.Credentials.CheckPassword("", password)
+		return nil, model.ErrNotValidCredentials
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Members with an API key only have no password hash, which never matches
+	if !s.Credentials.CheckPassword(staff.PasswordHash, password) {
+		return nil, model.ErrNotValidCredentials
+	}
+
+	token, err := s.Credentials.Sign(staff.Principal())
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// Authenticate returns the principal of the access token. Tokens are not looked up in
+// the storage, so a changed role takes effect when the token expires.
+// The following errors may be returned:
+// - model.ErrNotValidToken if the token is malformed, expired or not signed by the shop.
+func (s *authService) Authenticate(ctx context.Context, token string) (model.Principal, error) {
+	return s.Credentials.Verify(token)
+}
+
+// AuthenticateAPIKey returns the principal of the staff member the API key was issued to.
+// The following errors may be returned:
+// - model.ErrNotValidAPIKey if the key was not issued or was replaced.
+func (s *authService) AuthenticateAPIKey(ctx context.Context, key string) (model.Principal, error) {
+	staff, err := s.StaffRepo.GetByAPIKey(ctx, s.Credentials.HashAPIKey(key))
+	if errors.Is(err, model.ErrNotFound) {
+		return model.Principal{}, model.ErrNotValidAPIKey
+	}
+	if err != nil {
+		return model.Principal{}, err
+	}
+
+	return staff.Principal(), nil
+}
+
+// AddStaff creates a staff member with the password, a new API key or both, and returns
+// the member with the API key. The key is not stored, so it cannot be shown again.
+// The following errors may be returned:
+// - model.ErrNotValidUsername, model.ErrNotValidRole if the member is not valid.
+// - model.ErrNotValidPassword if the password is too short.
+// - model.ErrNoCredentials if there is neither a password nor an API key.
+// - model.ErrNotUniqueStaff if the username is taken.
+func (s *authService) AddStaff(ctx context.Context, staff model.Staff, password string, withAPIKey bool) (*model.Staff, string, error) {
+	if err := staff.Validate(); err != nil {
+		return nil, "", err
+	}
+	if password == "" && !withAPIKey {
+		return nil, "", model.ErrNoCredentials
+	}
+
+	apiKey, err := s.setCredentials(&staff, password, withAPIKey)
+	if err != nil {
+		return nil, "", err
+	}
+
+	staff.ID, err = s.StaffRepo.Create(ctx, staff)
+	if errors.Is(err, model.ErrDuplicate) {
+		return nil, "", model.ErrNotUniqueStaff
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	created, err := s.StaffRepo.Get(ctx, staff.ID)
+	if err != nil {
+		return nil, "", err
+	}
+	return &created, apiKey, nil
+}
+
+// RetrieveStaff returns all staff members.
+func (s *authService) RetrieveStaff(ctx context.Context) ([]model.Staff, error) {
+	return s.StaffRepo.GetAll(ctx)
+}
+
+// UpdateStaff changes the role of the staff member if role is set, replaces the
+// password if password is set and issues a new API key if newAPIKey is set. It returns
+// the member with the new API key.
+// The following errors may be returned:
+// - model.ErrStaffNotFound if the member with the specified ID is not found.
+// - model.ErrNotValidRole, model.ErrNotValidPassword if a new value is not valid.
+func (s *authService) UpdateStaff(ctx context.Context, id int, role, password string, newAPIKey bool) (*model.Staff, string, error) {
+	staff, err := s.StaffRepo.Get(ctx, id)
+	if errors.Is(err, model.ErrNotFound) {
+		return nil, "", model.ErrStaffNotFound
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	if role != "" {
+		staff.Role = role
+	}
+	if err := staff.Validate(); err != nil {
+		return nil, "", err
+	}
+
+	apiKey, err := s.setCredentials(&staff, password, newAPIKey)
+	if err != nil {
+		return nil, "", err
+	}
+
+	err = s.StaffRepo.Update(ctx, id, staff)
+	if errors.Is(err, model.ErrNotFound) {
+		return nil, "", model.ErrStaffNotFound
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &staff, apiKey, nil
+}
+
+// setCredentials hashes the password if it is set and issues an API key if withAPIKey
+// is set. It returns the API key.
+func (s *authService) setCredentials(staff *model.Staff, password string, withAPIKey bool) (string, error) {
+	if password != "" {
+		if utf8.RuneCountInString(password) < model.MinPasswordLength {
+			return "", model.ErrNotValidPassword
+		}
+
+		hash, err := s.Credentials.HashPassword(password)
+		if err != nil {
+			return "", err
+		}
+		staff.PasswordHash = hash
+	}
+
+	if !withAPIKey {
+		return "", nil
+	}
+	key, hash := s.Credentials.NewAPIKey()
+	staff.APIKeyHash = hash
+	return key, nil
+}
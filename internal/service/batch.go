@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"coffee-shop/internal/model"
+)
+
+// ProcessBatch places the orders one by one in arrival order. Every order is validated,
+// priced and has the stock of its ingredients reserved in its own transaction, so a
+// rejected order does not affect the others, and an order fails once the stock taken by
+// the orders before it runs out. Accepted orders stay open until they are paid and closed.
+// The following errors may be returned:
+// - model.ErrNotValidBatch if the batch is empty or has more than model.MaxBatchOrders orders.
+func (s *orderService) ProcessBatch(ctx context.Context, orders []model.Order) (*model.BatchResult, error) {
+	if len(orders) == 0 || len(orders) > model.MaxBatchOrders {
+		return nil, model.ErrNotValidBatch
+	}
+
+	result := &model.BatchResult{}
+	consumed := make(map[int]int)
+	var ingredientIDs []int
+
+	for i, order := range orders {
+		res := model.BatchOrderResult{Index: i}
+
+		placed, used, err := s.reserveOrder(ctx, order)
+		if err != nil {
+			res.Err = err
+			result.Rejected++
+			result.Results = append(result.Results, res)
+			continue
+		}
+
+		res.OrderID = placed.ID
+		res.Total = placed.Total
+		result.Accepted++
+		result.Revenue = roundMoney(result.Revenue + placed.Total)
+		result.Results = append(result.Results, res)
+
+		for ingredientID, quantity := range used {
+			if _, ok := consumed[ingredientID]; !ok {
+				ingredientIDs = append(ingredientIDs, ingredientID)
+			}
+			consumed[ingredientID] += quantity
+		}
+
+		s.publish(ctx, model.OrderEventCreated, placed)
+	}
+
+	for _, ingredientID := range ingredientIDs {
+		usage := model.IngredientUsage{IngredientID: ingredientID, Quantity: consumed[ingredientID]}
+		if item, err := s.InventoryRepo.Get(ctx, ingredientID); err == nil {
+			usage.Name = item.Name
+			usage.Unit = item.Unit
+		}
+		result.Consumed = append(result.Consumed, usage)
+	}
+
+	return result, nil
+}
+
+// reserveOrder creates the order and writes off the stock of its ingredients in one
+// transaction. It returns the order with its totals and the quantity used per ingredient ID.
+func (s *orderService) reserveOrder(ctx context.Context, order model.Order) (model.Order, map[int]int, error) {
+	order.Status = model.OrderStatusOpen
+	order.Reserved = true
+	if err := order.Validate(); err != nil {
+		return model.Order{}, nil, err
+	}
+
+	var used map[int]int
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.priceItems(ctx, order.Items); err != nil {
+			return err
+		}
+
+		id, err := s.OrderRepo.Create(ctx, order)
+		if err != nil {
+			return err
+		}
+		order.ID = id
+
+		if err := s.createItems(ctx, id, order.Items); err != nil {
+			return err
+		}
+
+		if err := s.HistoryRepo.Create(ctx, model.OrderStatusHistory{OrderID: id}); err != nil {
+			return err
+		}
+
+		totals, err := s.calculateTotals(ctx, order)
+		if err != nil {
+			return err
+		}
+		order.Total = totals.Total
+
+		used, err = s.stock().consume(ctx, id, order.Items, fmt.Sprintf("order #%d reserved", id))
+		if err != nil {
+			return err
+		}
+
+		return s.recordOrder(ctx, model.AuditCreate, id, nil)
+	})
+	if err != nil {
+		return model.Order{}, nil, err
+	}
+
+	return order, used, nil
+}
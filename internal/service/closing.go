@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"coffee-shop/internal/model"
+)
+
+type closingService struct {
+	ReportRepo  ReportRepo
+	ClosingRepo ClosingRepo
+	Renderer    ClosingRenderer
+	tx          Transactor
+	location    *time.Location
+}
+
+// NewClosingService creates the end-of-day service. Business days follow the
+// calendar of the shop location.
+func NewClosingService(reportRepo ReportRepo, closingRepo ClosingRepo, renderer ClosingRenderer, tx Transactor, location *time.Location) *closingService {
+	return &closingService{
+		ReportRepo:  reportRepo,
+		ClosingRepo: closingRepo,
+		Renderer:    renderer,
+		tx:          tx,
+		location:    location,
+	}
+}
+
+// CloseDay closes the business day and stores its end-of-day report. The report covers
+// the time since the previous closing, or since the start of the day for the first one,
+// up to the end of the day or now if the day is not over yet. The business date defaults
+// to today. The expected cash is the opening float plus the cash payments and tips less
+// the cash refunds. Orders left open are listed and carry over to the next day.
+// The following errors may be returned:
+// - model.ErrNotValidCashCount if the opening float or the counted cash is negative.
+// - model.ErrNotValidBusinessDate if the business date is in the future.
+// - model.ErrDayClosed if the business date or a later one is already closed.
+func (s *closingService) CloseDay(ctx context.Context, req model.ClosingRequest) (*model.DailyClosing, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().In(s.location)
+	date := req.BusinessDate
+	if date.IsZero() {
+		date = now
+	}
+	day := businessDay(date, s.location)
+
+	period := model.Period{From: day, To: day.AddDate(0, 0, 1)}
+	if period.To.After(now) {
+		period.To = now
+	}
+	if err := period.Validate(); err != nil {
+		return nil, model.ErrNotValidBusinessDate
+	}
+
+	var closing *model.DailyClosing
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		last, err := s.ClosingRepo.Last(ctx)
+		switch {
+		case errors.Is(err, model.ErrNotFound):
+		case err != nil:
+			return err
+		case !last.BusinessDate.Before(day):
+			return model.ErrDayClosed
+		default:
+			period.From = last.To
+		}
+
+		sales, err := s.ReportRepo.ClosingSales(ctx, period)
+		if err != nil {
+			return err
+		}
+
+		taxes, err := s.ReportRepo.TaxSummary(ctx, period)
+		if err != nil {
+			return err
+		}
+
+		tenders, err := s.ReportRepo.PaymentSummary(ctx, period)
+		if err != nil {
+			return err
+		}
+
+		open, err := s.ReportRepo.OpenOrders(ctx, period.To)
+		if err != nil {
+			return err
+		}
+
+		closing = &sales
+		closing.BusinessDate = day
+		closing.Period = period
+		closing.OpenOrders = open
+		closing.TaxLines = taxes
+		closing.Tenders = tenders
+		closing.OpeningFloat = roundMoney(req.OpeningFloat)
+		closing.CountedCash = roundMoney(req.CountedCash)
+		closing.Notes = req.Notes
+		closing.CreatedAt = now
+		summarizeClosing(closing)
+
+		closing.ID, err = s.ClosingRepo.Create(ctx, *closing)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return closing, nil
+}
+
+// RetrieveClosing returns the end-of-day report of the business date.
+// The following errors may be returned:
+// - model.ErrClosingNotFound if the business day is not closed.
+func (s *closingService) RetrieveClosing(ctx context.Context, date time.Time) (*model.DailyClosing, error) {
+	closing, err := s.ClosingRepo.Get(ctx, businessDay(date, s.location))
+	if errors.Is(err, model.ErrNotFound) {
+		return nil, model.ErrClosingNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &closing, nil
+}
+
+// RetrieveClosings returns the end-of-day reports of all closed days, the latest first.
+func (s *closingService) RetrieveClosings(ctx context.Context) ([]model.DailyClosing, error) {
+	return s.ClosingRepo.GetAll(ctx)
+}
+
+// RenderClosing renders the end-of-day report of the business date for printing or export
+// and returns it with its content type.
+// The following errors may be returned:
+// - model.ErrNotValidReceiptFormat if the format is not one of text, html, escpos.
+// - model.ErrClosingNotFound if the business day is not closed.
+func (s *closingService) RenderClosing(ctx context.Context, date time.Time, format string) ([]byte, string, error) {
+	if !model.ValidReceiptFormat(format) {
+		return nil, "", model.ErrNotValidReceiptFormat
+	}
+
+	closing, err := s.RetrieveClosing(ctx, date)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return s.Renderer.Closing(format, *closing)
+}
+
+// summarizeClosing rounds the sales of the closing and derives the net sales,
+// the tip and refund totals and the cash reconciliation.
+func summarizeClosing(c *model.DailyClosing) {
+	c.GrossSales = roundMoney(c.GrossSales)
+	c.Discounts = roundMoney(c.Discounts)
+	c.Taxes = roundMoney(c.Taxes)
+	c.Total = roundMoney(c.Total)
+	c.NetSales = roundMoney(c.Total - c.Taxes)
+
+	for _, t := range c.Tenders {
+		c.Tips += t.Tips
+		c.Refunds += t.Refunds
+		c.Refunded += t.Refunded
+	}
+	c.Tips = roundMoney(c.Tips)
+	c.Refunded = roundMoney(c.Refunded)
+
+	cash := c.Tender(model.TenderCash)
+	c.ExpectedCash = roundMoney(c.OpeningFloat + cash.Amount + cash.Tips - cash.Refunded)
+	c.CashDifference = roundMoney(c.CountedCash - c.ExpectedCash)
+}
+
+// businessDay returns the midnight starting the day of t in the location.
+func businessDay(t time.Time, location *time.Location) time.Time {
+	y, m, d := t.In(location).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, location)
+}
+
+// checkOrderUnlocked returns model.ErrOrderLocked if the order belongs to a closed business day.
+func checkOrderUnlocked(ctx context.Context, closings ClosingRepo, orderID int) error {
+	locked, err := closings.OrderLocked(ctx, orderID)
+	if err != nil {
+		return err
+	}
+	if locked {
+		return model.ErrOrderLocked
+	}
+	return nil
+}
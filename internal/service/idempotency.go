@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"coffee-shop/internal/model"
+)
+
+type idempotencyService struct {
+	IdempotencyRepo IdempotencyRepo
+	ttl             time.Duration
+	now             func() time.Time
+}
+
+// NewIdempotencyService creates the service that keeps the responses of requests made
+// with idempotency keys for ttl.
+func NewIdempotencyService(repo IdempotencyRepo, ttl time.Duration) *idempotencyService {
+	return &idempotencyService{IdempotencyRepo: repo, ttl: ttl, now: time.Now}
+}
+
+// BeginRequest claims the key for the request with the hash and returns nil. If the key
+// was claimed within the TTL, it returns the record of the request that claimed it.
+func (s *idempotencyService) BeginRequest(ctx context.Context, key, requestHash string) (*model.IdempotencyRecord, error) {
+	now := s.now()
+	record := model.IdempotencyRecord{
+		Key:         key,
+		RequestHash: requestHash,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(s.ttl),
+	}
+
+	// The record found may be released before it is read; the key is then claimed again.
+	for range 2 {
+		existing, reserved, err := s.IdempotencyRepo.Reserve(ctx, record, now)
+		if errors.Is(err, model.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if reserved {
+			return nil, nil
+		}
+		return &existing, nil
+	}
+
+	return nil, model.ErrIdempotencyInProgress
+}
+
+// CompleteRequest stores the response of the request that claimed the key.
+func (s *idempotencyService) CompleteRequest(ctx context.Context, record model.IdempotencyRecord) error {
+	record.Completed = true
+	return s.IdempotencyRepo.Complete(ctx, record)
+}
+
+// ReleaseRequest frees the key of a request that failed, so that it can be retried.
+func (s *idempotencyService) ReleaseRequest(ctx context.Context, key string) error {
+	err := s.IdempotencyRepo.Delete(ctx, key)
+	if errors.Is(err, model.ErrNotFound) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,339 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"coffee-shop/internal/model"
+)
+
+type importService struct {
+	MenuRepo            MenuRepo
+	MenuIngredientsRepo MenuItemIngredientsRepo
+	PriceHistoryRepo    PriceHistoryRepo
+	InventoryRepo       InventoryRepo
+	AuditRepo           AuditRepo
+	tx                  Transactor
+}
+
+func NewImportService(menuRepo MenuRepo, menuIngRepo MenuItemIngredientsRepo, priceHistoryRepo PriceHistoryRepo, inventoryRepo InventoryRepo, auditRepo AuditRepo, tx Transactor) *importService {
+	return &importService{
+		MenuRepo:            menuRepo,
+		MenuIngredientsRepo: menuIngRepo,
+		PriceHistoryRepo:    priceHistoryRepo,
+		InventoryRepo:       inventoryRepo,
+		AuditRepo:           auditRepo,
+		tx:                  tx,
+	}
+}
+
+// menuImport is a checked menu row waiting to be applied.
+type menuImport struct {
+	result      int
+	item        model.MenuItem
+	ingredients []model.MenuItemIngredients
+}
+
+// ImportMenu creates or updates menu items and their recipes. Items are matched to the
+// existing ones by name, and recipe ingredients to inventory items by name. Every row is
+// checked before anything is written, and the changes are applied in one transaction only
+// when no row is rejected and it is not a dry run. The recipe of an updated item is replaced.
+// The following errors may be returned:
+// - model.ErrNotValidImport if there are no rows or more than model.MaxImportRows.
+func (s *importService) ImportMenu(ctx context.Context, rows []model.MenuImportRow, dryRun bool) (*model.ImportResult, error) {
+	if len(rows) == 0 || len(rows) > model.MaxImportRows {
+		return nil, model.ErrNotValidImport
+	}
+
+	var result *model.ImportResult
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		result = &model.ImportResult{DryRun: dryRun}
+
+		menu, err := s.MenuRepo.GetAll(ctx)
+		if err != nil {
+			return err
+		}
+		existing := make(map[string]int, len(menu))
+		for _, item := range menu {
+			existing[importKey(item.Name)] = item.ID
+		}
+
+		inventory, err := ingredientIDs(ctx, s.InventoryRepo, false)
+		if err != nil {
+			return err
+		}
+
+		seen := make(map[string]bool, len(rows))
+		var imports []menuImport
+		for _, row := range rows {
+			res := model.ImportRowResult{Row: row.Row, Name: row.Item.Name, Action: model.ImportActionCreate}
+			if id, ok := existing[importKey(row.Item.Name)]; ok {
+				res.Action = model.ImportActionUpdate
+				res.ID = id
+			}
+
+			ingredients, err := checkMenuRow(row, inventory, seen)
+			if err != nil {
+				res.Err = err
+				result.Rejected++
+			} else {
+				imports = append(imports, menuImport{result: len(result.Rows), item: row.Item, ingredients: ingredients})
+				countImport(result, res.Action)
+			}
+			result.Rows = append(result.Rows, res)
+		}
+
+		if dryRun || result.Rejected > 0 {
+			return nil
+		}
+
+		for _, imp := range imports {
+			res := &result.Rows[imp.result]
+			var before any
+			if res.ID != 0 {
+				if before, err = menuState(ctx, s.MenuRepo, s.MenuIngredientsRepo, res.ID); err != nil {
+					return err
+				}
+			}
+
+			id, err := s.saveMenuItem(ctx, res.ID, imp.item, imp.ingredients)
+			if err != nil {
+				return err
+			}
+			res.ID = id
+
+			after, err := menuState(ctx, s.MenuRepo, s.MenuIngredientsRepo, id)
+			if err != nil {
+				return err
+			}
+			if err := recordChange(ctx, s.AuditRepo, importAction(res.Action), model.AuditMenu, id, before, after); err != nil {
+				return err
+			}
+		}
+		result.Applied = true
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// ImportInventory creates or updates inventory items, matching them to the existing
+// ones by name. The quantity of an updated item is set to the imported one. Rows are
+// checked and applied the same way as by ImportMenu.
+// The following errors may be returned:
+// - model.ErrNotValidImport if there are no rows or more than model.MaxImportRows.
+func (s *importService) ImportInventory(ctx context.Context, rows []model.InventoryImportRow, dryRun bool) (*model.ImportResult, error) {
+	if len(rows) == 0 || len(rows) > model.MaxImportRows {
+		return nil, model.ErrNotValidImport
+	}
+
+	var result *model.ImportResult
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		result = &model.ImportResult{DryRun: dryRun}
+
+		existing, err := ingredientIDs(ctx, s.InventoryRepo, true)
+		if err != nil {
+			return err
+		}
+
+		seen := make(map[string]bool, len(rows))
+		var imports []int
+		for i, row := range rows {
+			res := model.ImportRowResult{Row: row.Row, Name: row.Item.Name, Action: model.ImportActionCreate}
+			if id, ok := existing[importKey(row.Item.Name)]; ok {
+				res.Action = model.ImportActionUpdate
+				res.ID = id
+			}
+
+			if err := checkInventoryRow(row, seen); err != nil {
+				res.Err = err
+				result.Rejected++
+			} else {
+				imports = append(imports, i)
+				countImport(result, res.Action)
+			}
+			result.Rows = append(result.Rows, res)
+		}
+
+		if dryRun || result.Rejected > 0 {
+			return nil
+		}
+
+		for _, i := range imports {
+			res := &result.Rows[i]
+			item := rows[i].Item
+			var before any
+			if res.Action == model.ImportActionUpdate {
+				old, err := s.InventoryRepo.Get(ctx, res.ID)
+				if err != nil {
+					return err
+				}
+				before = old
+
+				// The item is replaced at the version read above, in the same transaction.
+				item.Version = old.Version
+				if err := s.InventoryRepo.Update(ctx, res.ID, item); err != nil {
+					return err
+				}
+			} else {
+				if res.ID, err = s.InventoryRepo.Create(ctx, item); err != nil {
+					return err
+				}
+			}
+
+			after, err := s.InventoryRepo.Get(ctx, res.ID)
+			if err != nil {
+				return err
+			}
+			if err := recordChange(ctx, s.AuditRepo, importAction(res.Action), model.AuditInventory, res.ID, before, after); err != nil {
+				return err
+			}
+		}
+		result.Applied = true
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// saveMenuItem creates the menu item, or updates the one with the given ID and
+// replaces its recipe. The item is updated at the version it has when it is read
+// here, so the caller's transaction decides which version is replaced. A changed
+// price is added to the price history. It returns the ID of the saved item.
+func (s *importService) saveMenuItem(ctx context.Context, id int, item model.MenuItem, ingredients []model.MenuItemIngredients) (int, error) {
+	if id == 0 {
+		created, err := s.MenuRepo.Create(ctx, item)
+		if err != nil {
+			return 0, err
+		}
+		id = created
+	} else {
+		current, err := s.MenuRepo.Get(ctx, id)
+		if err != nil {
+			return 0, err
+		}
+		item.Version = current.Version
+		if err := s.MenuRepo.Update(ctx, id, item); err != nil {
+			return 0, err
+		}
+		if err := s.MenuIngredientsRepo.Delete(ctx, id); err != nil {
+			return 0, err
+		}
+		if err := recordPriceChange(ctx, s.PriceHistoryRepo, id, current.Price, item.Price); err != nil {
+			return 0, err
+		}
+	}
+
+	for _, ingredient := range ingredients {
+		ingredient.MenuID = id
+		if err := s.MenuIngredientsRepo.Create(ctx, ingredient); err != nil {
+			return 0, err
+		}
+	}
+
+	return id, nil
+}
+
+// ingredientIDs returns the IDs of the inventory items by their import key. Archived
+// items are left out unless includeArchived is set, so that recipes cannot use them.
+func ingredientIDs(ctx context.Context, repo InventoryRepo, includeArchived bool) (map[string]int, error) {
+	items, err := repo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make(map[string]int, len(items))
+	for _, item := range items {
+		if item.IsArchived() && !includeArchived {
+			continue
+		}
+		ids[importKey(item.Name)] = item.IngredientID
+	}
+	return ids, nil
+}
+
+// checkMenuRow validates the menu row and resolves its recipe to inventory items.
+func checkMenuRow(row model.MenuImportRow, inventory map[string]int, seen map[string]bool) ([]model.MenuItemIngredients, error) {
+	if row.Err != nil {
+		return nil, row.Err
+	}
+	if err := row.Item.Validate(); err != nil {
+		return nil, err
+	}
+	if err := checkDuplicate(row.Item.Name, seen); err != nil {
+		return nil, err
+	}
+	if len(row.Ingredients) == 0 {
+		return nil, model.ErrNotEnoughIngredients
+	}
+
+	ingredients := make([]model.MenuItemIngredients, 0, len(row.Ingredients))
+	used := make(map[int]bool, len(row.Ingredients))
+	for _, line := range row.Ingredients {
+		id, ok := inventory[importKey(line.Name)]
+		if !ok {
+			return nil, model.ErrImportIngredientNotFound
+		}
+		if used[id] {
+			return nil, model.ErrDuplicateMenuIngredients
+		}
+		used[id] = true
+
+		ingredient := model.MenuItemIngredients{IngredientID: id, Quantity: line.Quantity}
+		if err := ingredient.Validate(); err != nil {
+			return nil, err
+		}
+		ingredients = append(ingredients, ingredient)
+	}
+
+	return ingredients, nil
+}
+
+// checkInventoryRow validates the inventory row.
+func checkInventoryRow(row model.InventoryImportRow, seen map[string]bool) error {
+	if row.Err != nil {
+		return row.Err
+	}
+	if err := row.Item.Validate(); err != nil {
+		return err
+	}
+	return checkDuplicate(row.Item.Name, seen)
+}
+
+// checkDuplicate rejects a name that already appeared earlier in the file.
+func checkDuplicate(name string, seen map[string]bool) error {
+	key := importKey(name)
+	if seen[key] {
+		return model.ErrDuplicateImportRow
+	}
+	seen[key] = true
+	return nil
+}
+
+// importAction returns the audit action of an import row action.
+func importAction(action string) string {
+	if action == model.ImportActionUpdate {
+		return model.AuditUpdate
+	}
+	return model.AuditCreate
+}
+
+func countImport(result *model.ImportResult, action string) {
+	if action == model.ImportActionUpdate {
+		result.Updated++
+	} else {
+		result.Created++
+	}
+}
+
+// importKey is the key items are matched by: names are compared ignoring case
+// and surrounding spaces.
+func importKey(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
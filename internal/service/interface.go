@@ -2,37 +2,182 @@ package service
 
 import (
 	"context"
+	"time"
 
 	"coffee-shop/internal/model"
 )
 
 type InventoryRepo interface {
-	Create(ctx context.Context, item model.Inventory) error
+	Create(ctx context.Context, item model.Inventory) (int, error)
 	Get(ctx context.Context, id int) (model.Inventory, error)
 	GetAll(ctx context.Context) ([]model.Inventory, error)
+	Each(ctx context.Context, fn func(item model.Inventory) error) error
 	Update(ctx context.Context, id int, item model.Inventory) error
-	Delete(ctx context.Context, id int) error
+	Patch(ctx context.Context, id int, version int, patch model.InventoryPatch) error
+	AdjustQuantity(ctx context.Context, id int, delta int) (bool, error)
+	Archive(ctx context.Context, id int, version int, archivedAt time.Time) error
+	Restore(ctx context.Context, id int, version int) error
+	References(ctx context.Context, id int) ([]model.Reference, error)
+	Delete(ctx context.Context, id int, version int) error
+}
+
+type InventoryTransactionsRepo interface {
+	Create(ctx context.Context, transaction model.InventoryTransactions) error
+	GetAllWithID(ctx context.Context, id int) ([]model.InventoryTransactions, error)
 }
 
 type MenuRepo interface {
-	Create(ctx context.Context, menu model.MenuItem) error
+	Create(ctx context.Context, menu model.MenuItem) (int, error)
 	Get(ctx context.Context, id int) (model.MenuItem, error)
 	GetAll(ctx context.Context) ([]model.MenuItem, error)
 	Update(ctx context.Context, id int, menu model.MenuItem) error
-	Delete(ctx context.Context, id int) error
+	Patch(ctx context.Context, id int, version int, patch model.MenuItemPatch) error
+	Archive(ctx context.Context, id int, version int, archivedAt time.Time) error
+	Restore(ctx context.Context, id int, version int) error
+	References(ctx context.Context, id int) ([]model.Reference, error)
+	Delete(ctx context.Context, id int, version int) error
 }
 
 type MenuItemIngredientsRepo interface {
 	Create(ctx context.Context, menu_ingredients model.MenuItemIngredients) error
 	GetAllWithID(ctx context.Context, id int) ([]model.MenuItemIngredients, error)
 	Update(ctx context.Context, id int, menu_ingredients model.MenuItemIngredients) error
+	DeleteIngredient(ctx context.Context, id int, ingredientID int) error
 	Delete(ctx context.Context, id int) error
 }
 
+type PriceHistoryRepo interface {
+	Create(ctx context.Context, change model.PriceHistory) (int, error)
+	GetAllWithID(ctx context.Context, id int) ([]model.PriceHistory, error)
+}
+
 type OrderRepo interface {
-	Create(ctx context.Context, order model.Order) error
+	Create(ctx context.Context, order model.Order) (int, error)
 	Get(ctx context.Context, id int) (model.Order, error)
 	GetAll(ctx context.Context) ([]model.Order, error)
+	Each(ctx context.Context, fn func(order model.Order) error) error
+	// Lock locks the order until the end of the transaction of ctx, so that
+	// concurrent payments, refunds and closings of the order take turns.
+	Lock(ctx context.Context, id int) error
 	Update(ctx context.Context, id int, order model.Order) error
+	Close(ctx context.Context, order model.Order) (bool, error)
+	Delete(ctx context.Context, id int, version int) error
+}
+
+type OrderItemsRepo interface {
+	Create(ctx context.Context, order_items model.OrderItems) error
+	GetAllWithID(ctx context.Context, id int) ([]model.OrderItems, error)
 	Delete(ctx context.Context, id int) error
 }
+
+// OrderConsumptionRepo keeps the ingredients consumed per unit of the products of orders.
+type OrderConsumptionRepo interface {
+	// Save replaces the consumption recorded for the order with the given ID.
+	Save(ctx context.Context, id int, lines []model.OrderConsumption) error
+	GetAllWithID(ctx context.Context, id int) ([]model.OrderConsumption, error)
+}
+
+type OrderStatusHistoryRepo interface {
+	Create(ctx context.Context, order_history model.OrderStatusHistory) error
+	Close(ctx context.Context, orderID int) error
+	DeleteByOrder(ctx context.Context, orderID int) error
+}
+
+type TaxRateRepo interface {
+	Save(ctx context.Context, rate model.TaxRate) error
+	Get(ctx context.Context, category string) (model.TaxRate, error)
+	GetAll(ctx context.Context) ([]model.TaxRate, error)
+	Delete(ctx context.Context, category string) (bool, error)
+}
+
+type OrderTaxesRepo interface {
+	Create(ctx context.Context, line model.TaxLine) error
+	GetAllWithID(ctx context.Context, id int) ([]model.TaxLine, error)
+}
+
+type PaymentRepo interface {
+	Create(ctx context.Context, payment model.Payment) (int, error)
+	Get(ctx context.Context, id int) (model.Payment, error)
+	GetAllWithID(ctx context.Context, id int) ([]model.Payment, error)
+	CreateRefundItem(ctx context.Context, paymentID int, item model.OrderItems) error
+	RefundedItems(ctx context.Context, orderID int) ([]model.OrderItems, error)
+}
+
+type StaffRepo interface {
+	Create(ctx context.Context, staff model.Staff) (int, error)
+	Get(ctx context.Context, id int) (model.Staff, error)
+	GetByUsername(ctx context.Context, username string) (model.Staff, error)
+	GetByAPIKey(ctx context.Context, hash string) (model.Staff, error)
+	GetAll(ctx context.Context) ([]model.Staff, error)
+	Update(ctx context.Context, id int, staff model.Staff) error
+}
+
+// IdempotencyRepo keeps the requests made with idempotency keys and their responses.
+type IdempotencyRepo interface {
+	// Reserve stores the record unless a record with its key that expires after now
+	// exists, and reports whether it did. Otherwise it returns the existing record.
+	Reserve(ctx context.Context, record model.IdempotencyRecord, now time.Time) (model.IdempotencyRecord, bool, error)
+	// Complete stores the response of the record with the key of the given record.
+	Complete(ctx context.Context, record model.IdempotencyRecord) error
+	Delete(ctx context.Context, key string) error
+}
+
+// AuditRepo is an append-only log of the changes of records.
+type AuditRepo interface {
+	Create(ctx context.Context, entry model.AuditEntry) (int, error)
+	Find(ctx context.Context, q model.AuditQuery) ([]model.AuditEntry, error)
+}
+
+type ReportRepo interface {
+	TaxSummary(ctx context.Context, period model.Period) ([]model.TaxReportLine, error)
+	PaymentSummary(ctx context.Context, period model.Period) ([]model.PaymentReportLine, error)
+	SalesSummary(ctx context.Context, q model.SalesQuery) ([]model.SalesBucket, error)
+	PopularItems(ctx context.Context, q model.PopularItemsQuery) ([]model.PopularItem, error)
+	LedgerUsage(ctx context.Context, q model.IngredientUsageQuery) ([]model.IngredientDailyUsage, error)
+	RecipeUsage(ctx context.Context, q model.IngredientUsageQuery) ([]model.IngredientDailyUsage, error)
+	Heatmap(ctx context.Context, period model.Period, location *time.Location) ([]model.HeatmapCell, error)
+	ClosingSales(ctx context.Context, period model.Period) (model.DailyClosing, error)
+	OpenOrders(ctx context.Context, before time.Time) ([]model.OpenOrder, error)
+}
+
+type ClosingRepo interface {
+	Create(ctx context.Context, closing model.DailyClosing) (int, error)
+	Get(ctx context.Context, date time.Time) (model.DailyClosing, error)
+	GetAll(ctx context.Context) ([]model.DailyClosing, error)
+	Last(ctx context.Context) (model.DailyClosing, error)
+	OrderLocked(ctx context.Context, orderID int) (bool, error)
+}
+
+// Transactor runs fn in a single transaction. Repository calls made with the
+// context passed to fn take part in that transaction.
+type Transactor interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// OrderEventPublisher sends order events to the live order queue.
+type OrderEventPublisher interface {
+	Publish(event model.OrderEvent)
+}
+
+// ReceiptRenderer renders receipts and kitchen tickets in one of the receipt formats.
+type ReceiptRenderer interface {
+	Receipt(format string, receipt model.Receipt) ([]byte, string, error)
+	Ticket(format string, receipt model.Receipt) ([]byte, string, error)
+}
+
+// ClosingRenderer renders end-of-day reports in one of the receipt formats.
+type ClosingRenderer interface {
+	Closing(format string, closing model.DailyClosing) ([]byte, string, error)
+}
+
+// CredentialKeeper hashes the passwords and API keys of the staff, and signs and
+// verifies their access tokens. CheckPassword with an empty hash reports false, but
+// takes as long as checking a real hash.
+type CredentialKeeper interface {
+	HashPassword(password string) (string, error)
+	CheckPassword(hash, password string) bool
+	NewAPIKey() (key string, hash string)
+	HashAPIKey(key string) string
+	Sign(principal model.Principal) (model.Token, error)
+	Verify(token string) (model.Principal, error)
+}
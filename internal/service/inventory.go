@@ -2,22 +2,27 @@ package service
 
 import (
 	"context"
+	"errors"
+	"slices"
+	"time"
 
 	"coffee-shop/internal/model"
 )
 
 type inventoryService struct {
 	InventoryRepo InventoryRepo
+	AuditRepo     AuditRepo
+	tx            Transactor
 }
 
-func NewInventoryService(repo InventoryRepo) *inventoryService {
-	return &inventoryService{InventoryRepo: repo}
+func NewInventoryService(repo InventoryRepo, auditRepo AuditRepo, tx Transactor) *inventoryService {
+	return &inventoryService{InventoryRepo: repo, AuditRepo: auditRepo, tx: tx}
 }
 
 // AddInventoryItem adds a new inventory item to the repository.
 // Returns nil if the addition is successful.
 // The following errors may be returned:
-// - ErrNotUniqueID if the item with the same ID already exists.
+// - model.ErrNotUniqueID if the item with the same ID already exists.
 // - An error if there is a validation issue or a failure when adding the item to the repository.
 func (s *inventoryService) AddInventoryItem(ctx context.Context, item model.Inventory) error {
 	// Item validation
@@ -25,37 +30,70 @@ func (s *inventoryService) AddInventoryItem(ctx context.Context, item model.Inve
 		return err
 	}
 
-	err := s.InventoryRepo.Create(ctx, item)
-	if err != nil {
-		return err
-	}
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		id, err := s.InventoryRepo.Create(ctx, item)
+		if err != nil {
+			return err
+		}
 
-	return nil
+		item.IngredientID = id
+		item.Version = 1
+		return recordChange(ctx, s.AuditRepo, model.AuditCreate, model.AuditInventory, id, nil, item)
+	})
 }
 
 // RetrieveInventoryItems retrieves all inventory items from the repository.
-// Returns the items data in JSON format as a byte slice.
+// Archived items are left out unless includeArchived is set.
 // The following error may be returned:
-// - An error if there is a failure when retrieving items from the repository or when marshalling the data.
-func (s *inventoryService) RetrieveInventoryItems(ctx context.Context) ([]model.Inventory, error) {
-	return s.InventoryRepo.GetAll(ctx)
+// - An error if there is a failure when retrieving items from the repository.
+func (s *inventoryService) RetrieveInventoryItems(ctx context.Context, includeArchived bool) ([]model.Inventory, error) {
+	items, err := s.InventoryRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if includeArchived {
+		return items, nil
+	}
+
+	return slices.DeleteFunc(items, func(item model.Inventory) bool {
+		return item.IsArchived()
+	}), nil
+}
+
+// ExportInventoryItems calls fn for every inventory item, reading them one at a time.
+// Archived items are left out unless includeArchived is set.
+func (s *inventoryService) ExportInventoryItems(ctx context.Context, includeArchived bool, fn func(item model.Inventory) error) error {
+	return s.InventoryRepo.Each(ctx, func(item model.Inventory) error {
+		if item.IsArchived() && !includeArchived {
+			return nil
+		}
+		return fn(item)
+	})
 }
 
 // RetrieveInventoryItem retrieves a single inventory item by its ID.
 // Returns the item data in JSON format as a byte slice if found.
 // The following errors may be returned:
-// - ErrNoItem if the item with the specified ID is not found.
+// - model.ErrNoItem if the item with the specified ID is not found.
 // - An error if there is a failure when retrieving items from the repository or when marshalling the item data.
 func (s *inventoryService) RetrieveInventoryItem(ctx context.Context, id int) (*model.Inventory, error) {
 	item, err := s.InventoryRepo.Get(ctx, id)
-	return &item, err
+	if errors.Is(err, model.ErrNotFound) {
+		return nil, model.ErrNoItem
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &item, nil
 }
 
 // UpdateInventoryItem updates the old inventory item with the new one.
 // Returns nil if the update is successful.
 // The following errors may be returned:
-// - ErrNoItem if the old item is not found by id.
-// - ErrNotUniqueID if new item id not unique.
+// - model.ErrNoItem if the old item is not found by id.
+// - model.ErrNotUniqueID if new item id not unique.
+// - model.ErrVersionMismatch if the version of item is set and the stored item has another.
 // - An error if there is a validation issue or a failure when updating the repository.
 func (s *inventoryService) UpdateInventoryItem(ctx context.Context, id int, item model.Inventory) error {
 	// New item validation
@@ -63,20 +101,190 @@ func (s *inventoryService) UpdateInventoryItem(ctx context.Context, id int, item
 		return err
 	}
 
-	// Rewriting old item in repo
-	err := s.InventoryRepo.Update(ctx, id, item)
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		old, err := s.RetrieveInventoryItem(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		// Rewriting old item in repo
+		err = s.InventoryRepo.Update(ctx, id, item)
+		if errors.Is(err, model.ErrNotFound) {
+			return model.ErrNoItem
+		}
+		if err != nil {
+			return err
+		}
+
+		item.IngredientID = id
+		item.Version = old.Version + 1
+		return recordChange(ctx, s.AuditRepo, model.AuditUpdate, model.AuditInventory, id, old, item)
+	})
+}
+
+// PatchInventoryItem stores a partial update of an inventory item. item is the stored
+// item with the patch applied, and its version is the version it was patched from.
+// Only the changed fields are written. Returns the stored item.
+// The following errors may be returned:
+// - model.ErrNoItem if the item is not found by id.
+// - model.ErrVersionMismatch if the item changed since it was patched.
+// - An error if there is a validation issue or a failure when updating the repository.
+func (s *inventoryService) PatchInventoryItem(ctx context.Context, id int, item model.Inventory) (*model.Inventory, error) {
+	if err := item.Validate(); err != nil {
+		return nil, err
+	}
+
+	var patched *model.Inventory
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		old, err := s.RetrieveInventoryItem(ctx, id)
+		if err != nil {
+			return err
+		}
+		if old.Version != item.Version {
+			return model.ErrVersionMismatch
+		}
+
+		patch := old.Changes(item)
+		if patch.IsEmpty() {
+			patched = old
+			return nil
+		}
+
+		err = s.InventoryRepo.Patch(ctx, id, item.Version, patch)
+		if errors.Is(err, model.ErrNotFound) {
+			return model.ErrNoItem
+		}
+		if err != nil {
+			return err
+		}
+
+		patched, err = s.RetrieveInventoryItem(ctx, id)
+		if err != nil {
+			return err
+		}
+		return recordChange(ctx, s.AuditRepo, model.AuditUpdate, model.AuditInventory, id, old, patched)
+	})
 	if err != nil {
-		return nil
+		return nil, err
 	}
 
-	return nil
+	return patched, nil
 }
 
-// DeleteInventoryItem deletes an inventory item by its ID.
+// ArchiveInventoryItem archives an inventory item by its ID, which hides it from the
+// lists of items. Recipes and the ledger keep referring to it. Archiving an archived
+// item changes nothing. If version is set, only the item with that version is archived.
+// The following errors may be returned:
+// - model.ErrNoItem if the item with the specified ID is not found.
+// - model.ErrVersionMismatch if the item has another version.
+// - An error if there is a failure when retrieving or saving items in the repository.
+func (s *inventoryService) ArchiveInventoryItem(ctx context.Context, id int, version int) error {
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		old, err := s.RetrieveInventoryItem(ctx, id)
+		if err != nil {
+			return err
+		}
+		if version != 0 && old.Version != version {
+			return model.ErrVersionMismatch
+		}
+		if old.IsArchived() {
+			return nil
+		}
+
+		err = s.InventoryRepo.Archive(ctx, id, version, time.Now())
+		if errors.Is(err, model.ErrNotFound) {
+			return model.ErrNoItem
+		}
+		if err != nil {
+			return err
+		}
+
+		archived, err := s.RetrieveInventoryItem(ctx, id)
+		if err != nil {
+			return err
+		}
+		return recordChange(ctx, s.AuditRepo, model.AuditArchive, model.AuditInventory, id, old, archived)
+	})
+}
+
+// RestoreInventoryItem brings an archived inventory item back to the lists of items.
+// Restoring an item that is not archived changes nothing. If version is set, only the
+// item with that version is restored. Returns the restored item.
+// The following errors may be returned:
+// - model.ErrNoItem if the item with the specified ID is not found.
+// - model.ErrVersionMismatch if the item has another version.
+// - An error if there is a failure when retrieving or saving items in the repository.
+func (s *inventoryService) RestoreInventoryItem(ctx context.Context, id int, version int) (*model.Inventory, error) {
+	var restored *model.Inventory
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		old, err := s.RetrieveInventoryItem(ctx, id)
+		if err != nil {
+			return err
+		}
+		if version != 0 && old.Version != version {
+			return model.ErrVersionMismatch
+		}
+		if !old.IsArchived() {
+			restored = old
+			return nil
+		}
+
+		err = s.InventoryRepo.Restore(ctx, id, version)
+		if errors.Is(err, model.ErrNotFound) {
+			return model.ErrNoItem
+		}
+		if err != nil {
+			return err
+		}
+
+		restored, err = s.RetrieveInventoryItem(ctx, id)
+		if err != nil {
+			return err
+		}
+		return recordChange(ctx, s.AuditRepo, model.AuditRestore, model.AuditInventory, id, old, restored)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return restored, nil
+}
+
+// DeleteInventoryItem deletes an inventory item by its ID for good. Items that are
+// used by recipes or recorded in the ledger cannot be deleted, but can be archived.
+// If version is set, only the item with that version is deleted.
 // Returns nil if the deletion is successful.
 // The following errors may be returned:
-// - ErrNoItem if the item with the specified ID is not found.
+// - model.ErrNoItem if the item with the specified ID is not found.
+// - model.ErrVersionMismatch if the item has another version.
+// - *model.ReferencedError, which wraps model.ErrReferenced, if other records refer to the item.
 // - An error if there is a failure when retrieving or saving items in the repository.
-func (s *inventoryService) DeleteInventoryItem(ctx context.Context, id int) error {
-	return s.InventoryRepo.Delete(ctx, id)
+func (s *inventoryService) DeleteInventoryItem(ctx context.Context, id int, version int) error {
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		old, err := s.RetrieveInventoryItem(ctx, id)
+		if err != nil {
+			return err
+		}
+		if version != 0 && old.Version != version {
+			return model.ErrVersionMismatch
+		}
+
+		refs, err := s.InventoryRepo.References(ctx, id)
+		if err != nil {
+			return err
+		}
+		if len(refs) > 0 {
+			return &model.ReferencedError{References: refs}
+		}
+
+		err = s.InventoryRepo.Delete(ctx, id, version)
+		if errors.Is(err, model.ErrNotFound) {
+			return model.ErrNoItem
+		}
+		if err != nil {
+			return err
+		}
+
+		return recordChange(ctx, s.AuditRepo, model.AuditDelete, model.AuditInventory, id, old, nil)
+	})
 }
@@ -0,0 +1,317 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"coffee-shop/internal/model"
+)
+
+// legacyOrderNote marks the orders migrated from the legacy data, followed by the
+// legacy order ID. It keeps a second run from migrating the same orders again.
+const legacyOrderNote = "legacy order "
+
+type legacyService struct {
+	orders *orderService
+	tx     Transactor
+}
+
+// NewLegacyService creates the service migrating the data of the JSON version of the
+// shop. It works with the repositories of the order service and prices the orders the same way.
+func NewLegacyService(orders *orderService, tx Transactor) *legacyService {
+	return &legacyService{orders: orders, tx: tx}
+}
+
+// MigrateLegacy recreates the legacy inventory, menu and orders in one transaction and
+// returns the reconciliation report. Inventory and menu items are matched to the existing
+// ones by name and updated, the others are created with new IDs. Legacy references are
+// resolved to the new IDs. A record that is not valid, or refers to one that was skipped,
+// is skipped and reported. Closed orders keep their creation time as the closing time,
+// get their totals calculated with the current tax rates and do not consume the stock,
+// since the legacy inventory already reflects it.
+func (s *legacyService) MigrateLegacy(ctx context.Context, data model.LegacyData) (*model.LegacyReport, error) {
+	var report *model.LegacyReport
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		report = &model.LegacyReport{}
+
+		ingredients, err := s.migrateInventory(ctx, data.Inventory, report)
+		if err != nil {
+			return err
+		}
+
+		products, err := s.migrateMenu(ctx, data.Menu, ingredients, report)
+		if err != nil {
+			return err
+		}
+
+		return s.migrateOrders(ctx, data.Orders, products, report)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// migrateInventory returns the new IDs of the migrated inventory items by their legacy IDs.
+func (s *legacyService) migrateInventory(ctx context.Context, items []model.LegacyInventoryItem, report *model.LegacyReport) (map[string]int, error) {
+	existing, err := ingredientIDs(ctx, s.orders.InventoryRepo, true)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make(map[string]int, len(items))
+	seen := make(map[string]bool, len(items))
+	legacy := make(legacyIDs, len(items))
+	for _, item := range items {
+		report.Inventory.Read++
+
+		if err := legacy.check(item.ID); err != nil {
+			skipLegacy(report, &report.Inventory, model.LegacyInventory, item.ID, err)
+			continue
+		}
+		if err := checkInventoryRow(model.InventoryImportRow{Item: item.Item}, seen); err != nil {
+			skipLegacy(report, &report.Inventory, model.LegacyInventory, item.ID, err)
+			continue
+		}
+
+		id, ok := existing[importKey(item.Item.Name)]
+		if ok {
+			err = s.orders.InventoryRepo.Update(ctx, id, item.Item)
+			report.Inventory.Updated++
+		} else {
+			id, err = s.orders.InventoryRepo.Create(ctx, item.Item)
+			report.Inventory.Created++
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		ids[item.ID] = id
+		report.IDs = append(report.IDs, model.LegacyID{Kind: model.LegacyInventory, LegacyID: item.ID, ID: id})
+	}
+
+	return ids, nil
+}
+
+// migrateMenu returns the new IDs of the migrated menu items by their legacy IDs.
+// The recipe of an updated item is replaced.
+func (s *legacyService) migrateMenu(ctx context.Context, items []model.LegacyMenuItem, ingredientIDs map[string]int, report *model.LegacyReport) (map[string]int, error) {
+	menu, err := s.orders.MenuRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	existing := make(map[string]int, len(menu))
+	for _, item := range menu {
+		existing[importKey(item.Name)] = item.ID
+	}
+
+	imports := importService{
+		MenuRepo:            s.orders.MenuRepo,
+		MenuIngredientsRepo: s.orders.MenuIngredientsRepo,
+	}
+
+	ids := make(map[string]int, len(items))
+	seen := make(map[string]bool, len(items))
+	legacy := make(legacyIDs, len(items))
+	for _, item := range items {
+		report.Menu.Read++
+
+		if item.Item.Category == "" {
+			item.Item.Category = model.DefaultMenuCategory
+		}
+
+		if err := legacy.check(item.ID); err != nil {
+			skipLegacy(report, &report.Menu, model.LegacyMenu, item.ID, err)
+			continue
+		}
+
+		recipe, err := checkLegacyMenuItem(item, ingredientIDs, seen)
+		if err != nil {
+			skipLegacy(report, &report.Menu, model.LegacyMenu, item.ID, err)
+			continue
+		}
+
+		id, ok := existing[importKey(item.Item.Name)]
+		id, err = imports.saveMenuItem(ctx, id, item.Item, recipe)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			report.Menu.Updated++
+		} else {
+			report.Menu.Created++
+		}
+
+		ids[item.ID] = id
+		report.IDs = append(report.IDs, model.LegacyID{Kind: model.LegacyMenu, LegacyID: item.ID, ID: id})
+	}
+
+	return ids, nil
+}
+
+func (s *legacyService) migrateOrders(ctx context.Context, orders []model.LegacyOrder, productIDs map[string]int, report *model.LegacyReport) error {
+	migrated := make(map[string]bool)
+	err := s.orders.OrderRepo.Each(ctx, func(order model.Order) error {
+		if id, ok := strings.CutPrefix(order.Notes, legacyOrderNote); ok {
+			migrated[id] = true
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	legacy := make(legacyIDs, len(orders))
+	for _, legacyOrder := range orders {
+		report.Orders.Read++
+
+		if err := legacy.check(legacyOrder.ID); err != nil {
+			skipLegacy(report, &report.Orders, model.LegacyOrders, legacyOrder.ID, err)
+			continue
+		}
+		if migrated[legacyOrder.ID] {
+			skipLegacy(report, &report.Orders, model.LegacyOrders, legacyOrder.ID, model.ErrLegacyOrderExists)
+			continue
+		}
+
+		order, err := legacyOrderItems(legacyOrder, productIDs)
+		if err == nil {
+			err = order.Validate()
+		}
+		if err != nil {
+			skipLegacy(report, &report.Orders, model.LegacyOrders, legacyOrder.ID, err)
+			continue
+		}
+
+		saved, err := s.createOrder(ctx, order)
+		if err != nil {
+			return err
+		}
+
+		report.Orders.Created++
+		report.IDs = append(report.IDs, model.LegacyID{Kind: model.LegacyOrders, LegacyID: legacyOrder.ID, ID: saved.ID})
+		if saved.Status == model.OrderStatusClosed {
+			report.ClosedOrders++
+			report.Sales = roundMoney(report.Sales + saved.Total)
+		} else {
+			report.OpenOrders++
+		}
+	}
+
+	return nil
+}
+
+// createOrder saves the order with its items at the current menu prices, and closes it
+// with its totals and taxes if it is a closed one. It returns the saved order.
+func (s *legacyService) createOrder(ctx context.Context, order model.Order) (model.Order, error) {
+	status := order.Status
+	order.Status = model.OrderStatusOpen
+
+	if err := s.orders.priceItems(ctx, order.Items); err != nil {
+		return model.Order{}, err
+	}
+
+	id, err := s.orders.OrderRepo.Create(ctx, order)
+	if err != nil {
+		return model.Order{}, err
+	}
+	order.ID = id
+
+	if err := s.orders.createItems(ctx, id, order.Items); err != nil {
+		return model.Order{}, err
+	}
+
+	if status != model.OrderStatusClosed {
+		return order, nil
+	}
+
+	totals, err := s.orders.calculateTotals(ctx, order)
+	if err != nil {
+		return model.Order{}, err
+	}
+	order.OrderTotals = totals
+	order.Status = model.OrderStatusClosed
+	// Closing times are stored as the wall clock time of the server, as CloseOrder does.
+	order.ClosedAt = order.CreateAt.Local()
+
+	if _, err := s.orders.OrderRepo.Close(ctx, order); err != nil {
+		return model.Order{}, err
+	}
+
+	for _, line := range order.Taxes {
+		line.OrderID = id
+		if err := s.orders.OrderTaxesRepo.Create(ctx, line); err != nil {
+			return model.Order{}, err
+		}
+	}
+
+	return order, nil
+}
+
+// checkLegacyMenuItem validates the legacy menu item and resolves its recipe to the
+// migrated inventory items.
+func checkLegacyMenuItem(item model.LegacyMenuItem, ingredientIDs map[string]int, seen map[string]bool) ([]model.MenuItemIngredients, error) {
+	if err := item.Item.Validate(); err != nil {
+		return nil, err
+	}
+	if err := checkDuplicate(item.Item.Name, seen); err != nil {
+		return nil, err
+	}
+	if len(item.Ingredients) == 0 {
+		return nil, model.ErrNotEnoughIngredients
+	}
+
+	recipe := make([]model.MenuItemIngredients, 0, len(item.Ingredients))
+	used := make(map[int]bool, len(item.Ingredients))
+	for _, line := range item.Ingredients {
+		id, ok := ingredientIDs[line.IngredientID]
+		if !ok {
+			return nil, model.ErrLegacyReference
+		}
+		if used[id] {
+			return nil, model.ErrDuplicateMenuIngredients
+		}
+		used[id] = true
+
+		ingredient := model.MenuItemIngredients{IngredientID: id, Quantity: line.Quantity}
+		if err := ingredient.Validate(); err != nil {
+			return nil, err
+		}
+		recipe = append(recipe, ingredient)
+	}
+
+	return recipe, nil
+}
+
+// legacyOrderItems returns the legacy order with its items referring to the migrated menu items.
+func legacyOrderItems(legacyOrder model.LegacyOrder, productIDs map[string]int) (model.Order, error) {
+	order := legacyOrder.Order
+	order.Notes = legacyOrderNote + legacyOrder.ID
+	order.Items = make([]model.OrderItems, 0, len(legacyOrder.Items))
+	for _, item := range legacyOrder.Items {
+		id, ok := productIDs[item.ProductID]
+		if !ok {
+			return model.Order{}, model.ErrLegacyReference
+		}
+		order.Items = append(order.Items, model.OrderItems{ProductID: id, Quantity: item.Quantity})
+	}
+	return order, nil
+}
+
+// legacyIDs counts the records seen with each legacy ID.
+type legacyIDs map[string]int
+
+// check rejects an empty ID and any but the first record with the same ID.
+func (l legacyIDs) check(id string) error {
+	l[id]++
+	if id == "" || l[id] > 1 {
+		return model.ErrNotValidLegacyID
+	}
+	return nil
+}
+
+func skipLegacy(report *model.LegacyReport, count *model.LegacyCount, kind, id string, err error) {
+	count.Skipped++
+	report.Skipped = append(report.Skipped, model.LegacySkip{Kind: kind, LegacyID: id, Err: err})
+}
@@ -1,26 +1,38 @@
 package service
 
 import (
-	"coffee-shop/internal/model"
-	"coffee-shop/internal/repository/postgres"
 	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"coffee-shop/internal/model"
 )
 
 type menuService struct {
-	MenuRepo            postgres.Menu
-	MenuIngredientsRepo postgres.MenuItemIngredients
+	MenuRepo            MenuRepo
+	MenuIngredientsRepo MenuItemIngredientsRepo
+	PriceHistoryRepo    PriceHistoryRepo
+	InventoryRepo       InventoryRepo
+	AuditRepo           AuditRepo
+	tx                  Transactor
 }
 
-func NewMenuService(menuRepo postgres.Menu, menuIngRepo postgres.MenuItemIngredients) *menuService {
+func NewMenuService(menuRepo MenuRepo, menuIngRepo MenuItemIngredientsRepo, priceHistoryRepo PriceHistoryRepo, inventoryRepo InventoryRepo, auditRepo AuditRepo, tx Transactor) *menuService {
 	return &menuService{
 		MenuRepo:            menuRepo,
-		MenuIngredientsRepo: menuIngRepo}
+		MenuIngredientsRepo: menuIngRepo,
+		PriceHistoryRepo:    priceHistoryRepo,
+		InventoryRepo:       inventoryRepo,
+		AuditRepo:           auditRepo,
+		tx:                  tx}
 }
 
 // AddMenuItem adds a new menu item to the repository.
 // Returns nil if the addition is successful.
 // The following errors may be returned:
-// - ErrNotUniqueID if the item with the same ID already exists.
+// - model.ErrNotUniqueID if the item with the same ID already exists.
 // - An error if there is a validation issue or a failure when adding the item to the repository.
 func (s *menuService) AddMenuItem(ctx context.Context, menu model.MenuItem, ingredients []model.MenuItemIngredients) error {
 	// Item validation
@@ -34,33 +46,59 @@ func (s *menuService) AddMenuItem(ctx context.Context, menu model.MenuItem, ingr
 		}
 	}
 
-	err := s.MenuRepo.Create(ctx, menu)
-	if err != nil {
-		return err
-	}
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.checkIngredients(ctx, ingredients); err != nil {
+			return err
+		}
 
-	for _, i := range ingredients {
-		err := s.MenuIngredientsRepo.Create(ctx, i)
+		id, err := s.MenuRepo.Create(ctx, menu)
 		if err != nil {
 			return err
 		}
-	}
 
-	return nil
+		for n := range ingredients {
+			ingredients[n].MenuID = id
+			err := s.MenuIngredientsRepo.Create(ctx, ingredients[n])
+			if err != nil {
+				return err
+			}
+		}
+
+		menu.ID = id
+		after := auditedMenuItem{MenuItem: menu, Ingredients: ingredients}
+		return recordChange(ctx, s.AuditRepo, model.AuditCreate, model.AuditMenu, id, nil, after)
+	})
 }
 
-// rewrite for psql
-func (s *menuService) RetrieveMenuItems(ctx context.Context) ([]model.MenuItem, error) {
-	return s.MenuRepo.GetAll(ctx)
+// RetrieveMenuItems returns the items on the menu. Archived items are left out
+// unless includeArchived is set.
+func (s *menuService) RetrieveMenuItems(ctx context.Context, includeArchived bool) ([]model.MenuItem, error) {
+	items, err := s.MenuRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if includeArchived {
+		return items, nil
+	}
+
+	return slices.DeleteFunc(items, func(item model.MenuItem) bool {
+		return item.IsArchived()
+	}), nil
 }
 
 func (s *menuService) RetrieveMenuItemWithId(ctx context.Context, id int) (*model.MenuItem, []model.MenuItemIngredients, error) {
 	menuItem, err := s.MenuRepo.Get(ctx, id)
+	if errors.Is(err, model.ErrNotFound) {
+		return nil, nil, model.ErrMenuItemNotFound
+	}
 	if err != nil {
 		return nil, nil, err
 	}
 
 	menuItemIngredients, err := s.MenuIngredientsRepo.GetAllWithID(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	return &menuItem, menuItemIngredients, nil
 }
@@ -79,32 +117,309 @@ func (s *menuService) UpdateMenuItem(ctx context.Context, id int, item model.Men
 		}
 	}
 
-	// Rewriting old item in repo
-	err = s.MenuRepo.Update(ctx, id, item)
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		old, oldIngredients, err := s.RetrieveMenuItemWithId(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		// Ingredients already in the recipe stay in it after they are archived.
+		added := slices.DeleteFunc(slices.Clone(ingredients), func(i model.MenuItemIngredients) bool {
+			return slices.ContainsFunc(oldIngredients, func(old model.MenuItemIngredients) bool {
+				return old.IngredientID == i.IngredientID
+			})
+		})
+		if err := s.checkIngredients(ctx, added); err != nil {
+			return err
+		}
+
+		// Rewriting old item in repo
+		err = s.MenuRepo.Update(ctx, id, item)
+		if errors.Is(err, model.ErrNotFound) {
+			return model.ErrMenuItemNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		for _, i := range ingredients {
+			err := s.MenuIngredientsRepo.Update(ctx, id, i)
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := recordPriceChange(ctx, s.PriceHistoryRepo, id, old.Price, item.Price); err != nil {
+			return err
+		}
+
+		updated, newIngredients, err := s.RetrieveMenuItemWithId(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		before := auditedMenuItem{MenuItem: *old, Ingredients: oldIngredients}
+		after := auditedMenuItem{MenuItem: *updated, Ingredients: newIngredients}
+		return recordChange(ctx, s.AuditRepo, model.AuditUpdate, model.AuditMenu, id, before, after)
+	})
+}
+
+// PatchMenuItem stores a partial update of a menu item. item and ingredients are the
+// stored item and recipe with the patch applied, and the version of item is the
+// version it was patched from. Only the changed fields and ingredient rows are
+// written. Returns the stored item and recipe.
+// The following errors may be returned:
+// - model.ErrMenuItemNotFound if the item is not found by id.
+// - model.ErrVersionMismatch if the item changed since it was patched.
+// - An error if there is a validation issue or a failure when updating the repository.
+func (s *menuService) PatchMenuItem(ctx context.Context, id int, item model.MenuItem, ingredients []model.MenuItemIngredients) (*model.MenuItem, []model.MenuItemIngredients, error) {
+	if err := item.Validate(); err != nil {
+		return nil, nil, err
+	}
+
+	quantities := make(map[int]int, len(ingredients))
+	for _, i := range ingredients {
+		if err := i.Validate(); err != nil {
+			return nil, nil, err
+		}
+		if _, ok := quantities[i.IngredientID]; ok {
+			return nil, nil, model.ErrNotValidIngredientID.WithMessage(fmt.Sprintf("ingredient %d is listed more than once", i.IngredientID))
+		}
+		quantities[i.IngredientID] = i.Quantity
+	}
+
+	var patched *model.MenuItem
+	var patchedIngredients []model.MenuItemIngredients
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		old, oldIngredients, err := s.RetrieveMenuItemWithId(ctx, id)
+		if err != nil {
+			return err
+		}
+		if old.Version != item.Version {
+			return model.ErrVersionMismatch
+		}
+
+		// The recipe is compared row by row, so that unchanged rows are kept.
+		var created, updated, deleted []model.MenuItemIngredients
+		for _, i := range oldIngredients {
+			quantity, ok := quantities[i.IngredientID]
+			switch {
+			case !ok:
+				deleted = append(deleted, i)
+			case quantity != i.Quantity:
+				i.Quantity = quantity
+				updated = append(updated, i)
+			}
+			delete(quantities, i.IngredientID)
+		}
+		for _, i := range ingredients {
+			if _, ok := quantities[i.IngredientID]; ok {
+				i.MenuID = id
+				created = append(created, i)
+			}
+		}
+
+		// Ingredients already in the recipe stay in it after they are archived.
+		if err := s.checkIngredients(ctx, created); err != nil {
+			return err
+		}
+
+		patch := old.Changes(item)
+		if patch.IsEmpty() && len(created)+len(updated)+len(deleted) == 0 {
+			patched, patchedIngredients = old, oldIngredients
+			return nil
+		}
+
+		// The version of the item changes with its recipe as well.
+		err = s.MenuRepo.Patch(ctx, id, item.Version, patch)
+		if errors.Is(err, model.ErrNotFound) {
+			return model.ErrMenuItemNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		for _, i := range deleted {
+			if err := s.MenuIngredientsRepo.DeleteIngredient(ctx, id, i.IngredientID); err != nil {
+				return err
+			}
+		}
+		for _, i := range updated {
+			if err := s.MenuIngredientsRepo.Update(ctx, id, i); err != nil {
+				return err
+			}
+		}
+		for _, i := range created {
+			if err := s.MenuIngredientsRepo.Create(ctx, i); err != nil {
+				return err
+			}
+		}
+
+		if err := recordPriceChange(ctx, s.PriceHistoryRepo, id, old.Price, item.Price); err != nil {
+			return err
+		}
+
+		patched, patchedIngredients, err = s.RetrieveMenuItemWithId(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		before := auditedMenuItem{MenuItem: *old, Ingredients: oldIngredients}
+		after := auditedMenuItem{MenuItem: *patched, Ingredients: patchedIngredients}
+		return recordChange(ctx, s.AuditRepo, model.AuditUpdate, model.AuditMenu, id, before, after)
+	})
 	if err != nil {
+		return nil, nil, err
+	}
+
+	return patched, patchedIngredients, nil
+}
+
+// recordPriceChange adds the change of the price of the menu item to its price
+// history. Nothing is recorded if the price stays the same.
+func recordPriceChange(ctx context.Context, repo PriceHistoryRepo, id int, oldPrice, newPrice float64) error {
+	if oldPrice == newPrice {
 		return nil
 	}
+	_, err := repo.Create(ctx, model.PriceHistory{MenuItemID: id, OldPrice: oldPrice, NewPrice: newPrice})
+	return err
+}
 
+// checkIngredients returns model.ErrIngredientArchived if one of the ingredients is
+// archived. Missing ingredients are reported by the repositories when they are saved.
+func (s *menuService) checkIngredients(ctx context.Context, ingredients []model.MenuItemIngredients) error {
 	for _, i := range ingredients {
-		err := s.MenuIngredientsRepo.Update(ctx, id, i)
+		item, err := s.InventoryRepo.Get(ctx, i.IngredientID)
+		if errors.Is(err, model.ErrNotFound) {
+			continue
+		}
 		if err != nil {
 			return err
 		}
+		if item.IsArchived() {
+			return model.ErrIngredientArchived.WithMessage(fmt.Sprintf("ingredient %d is archived", i.IngredientID))
+		}
 	}
 
 	return nil
 }
 
-func (s *menuService) DeleteMenuItem(ctx context.Context, id int) error {
-	err := s.MenuRepo.Delete(ctx, id)
-	if err != nil {
-		return err
-	}
+// ArchiveMenuItem takes the menu item off the menu: it is hidden from the lists of
+// items and cannot be ordered, but past orders and reports keep referring to it.
+// Archiving an archived item changes nothing. If version is set, only the item with
+// that version is archived.
+func (s *menuService) ArchiveMenuItem(ctx context.Context, id int, version int) error {
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		old, ingredients, err := s.RetrieveMenuItemWithId(ctx, id)
+		if err != nil {
+			return err
+		}
+		if version != 0 && old.Version != version {
+			return model.ErrVersionMismatch
+		}
+		if old.IsArchived() {
+			return nil
+		}
 
-	err = s.MenuIngredientsRepo.Delete(ctx, id)
+		err = s.MenuRepo.Archive(ctx, id, version, time.Now())
+		if errors.Is(err, model.ErrNotFound) {
+			return model.ErrMenuItemNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		archived, _, err := s.RetrieveMenuItemWithId(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		before := auditedMenuItem{MenuItem: *old, Ingredients: ingredients}
+		after := auditedMenuItem{MenuItem: *archived, Ingredients: ingredients}
+		return recordChange(ctx, s.AuditRepo, model.AuditArchive, model.AuditMenu, id, before, after)
+	})
+}
+
+// RestoreMenuItem puts an archived menu item back on the menu. Restoring an item that
+// is not archived changes nothing. If version is set, only the item with that version
+// is restored. Returns the restored item and its recipe.
+func (s *menuService) RestoreMenuItem(ctx context.Context, id int, version int) (*model.MenuItem, []model.MenuItemIngredients, error) {
+	var restored *model.MenuItem
+	var ingredients []model.MenuItemIngredients
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		old, oldIngredients, err := s.RetrieveMenuItemWithId(ctx, id)
+		if err != nil {
+			return err
+		}
+		if version != 0 && old.Version != version {
+			return model.ErrVersionMismatch
+		}
+		if !old.IsArchived() {
+			restored, ingredients = old, oldIngredients
+			return nil
+		}
+
+		err = s.MenuRepo.Restore(ctx, id, version)
+		if errors.Is(err, model.ErrNotFound) {
+			return model.ErrMenuItemNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		restored, ingredients, err = s.RetrieveMenuItemWithId(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		before := auditedMenuItem{MenuItem: *old, Ingredients: oldIngredients}
+		after := auditedMenuItem{MenuItem: *restored, Ingredients: ingredients}
+		return recordChange(ctx, s.AuditRepo, model.AuditRestore, model.AuditMenu, id, before, after)
+	})
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 
-	return nil
+	return restored, ingredients, nil
+}
+
+// DeleteMenuItem deletes the menu item with its recipe for good. Items that were
+// ordered, refunded or repriced cannot be deleted, but can be archived; the error
+// is a *model.ReferencedError that lists the records referring to the item. If
+// version is set, only the item with that version is deleted.
+func (s *menuService) DeleteMenuItem(ctx context.Context, id int, version int) error {
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		old, oldIngredients, err := s.RetrieveMenuItemWithId(ctx, id)
+		if err != nil {
+			return err
+		}
+		if version != 0 && old.Version != version {
+			return model.ErrVersionMismatch
+		}
+
+		refs, err := s.MenuRepo.References(ctx, id)
+		if err != nil {
+			return err
+		}
+		if len(refs) > 0 {
+			return &model.ReferencedError{References: refs}
+		}
+
+		// The recipe refers to the item, so it is deleted first.
+		err = s.MenuIngredientsRepo.Delete(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		err = s.MenuRepo.Delete(ctx, id, version)
+		if errors.Is(err, model.ErrNotFound) {
+			return model.ErrMenuItemNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		before := auditedMenuItem{MenuItem: *old, Ingredients: oldIngredients}
+		return recordChange(ctx, s.AuditRepo, model.AuditDelete, model.AuditMenu, id, before, nil)
+	})
 }
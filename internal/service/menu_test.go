@@ -0,0 +1,302 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"coffee-shop/internal/model"
+	"coffee-shop/internal/repository/memory"
+)
+
+// menuFixture is a menu with a latte made of milk, made with memory repositories.
+type menuFixture struct {
+	menu    *menuService
+	imports *importService
+	latte   int
+	milk    int
+}
+
+func newMenuFixture(t *testing.T) *menuFixture {
+	t.Helper()
+	ctx := context.Background()
+	db := memory.NewDB()
+
+	menu, ingredients, history := memory.NewMenu(db), memory.NewMenuItemIngredients(db), memory.NewPriceHistory(db)
+	inventory, audit := memory.NewInventory(db), memory.NewAudit(db)
+	f := &menuFixture{
+		menu:    NewMenuService(menu, ingredients, history, inventory, audit, db),
+		imports: NewImportService(menu, ingredients, history, inventory, audit, db),
+	}
+
+	var err error
+	if f.milk, err = f.menu.InventoryRepo.Create(ctx, model.Inventory{Name: "Milk", Quantity: 100, Unit: "ml"}); err != nil {
+		t.Fatalf("Create inventory: %v", err)
+	}
+	latte := model.MenuItem{Name: "Latte", Description: "milk coffee", Category: "coffee", Price: 4.5}
+	if err := f.menu.AddMenuItem(ctx, latte, []model.MenuItemIngredients{{IngredientID: f.milk, Quantity: 2}}); err != nil {
+		t.Fatalf("AddMenuItem: %v", err)
+	}
+
+	items, err := f.menu.RetrieveMenuItems(ctx, false)
+	if err != nil || len(items) != 1 {
+		t.Fatalf("RetrieveMenuItems = %v, %v, want the latte", items, err)
+	}
+	f.latte = items[0].ID
+
+	return f
+}
+
+func (f *menuFixture) version(t *testing.T) int {
+	t.Helper()
+	item, _, err := f.menu.RetrieveMenuItemWithId(context.Background(), f.latte)
+	if err != nil {
+		t.Fatalf("RetrieveMenuItemWithId: %v", err)
+	}
+	return item.Version
+}
+
+func TestArchiveMenuItem(t *testing.T) {
+	ctx := context.Background()
+	f := newMenuFixture(t)
+	version := f.version(t)
+
+	if err := f.menu.ArchiveMenuItem(ctx, f.latte, version+1); !errors.Is(err, model.ErrVersionMismatch) {
+		t.Fatalf("ArchiveMenuItem of another version: %v, want %v", err, model.ErrVersionMismatch)
+	}
+	if err := f.menu.ArchiveMenuItem(ctx, f.latte+100, 0); !errors.Is(err, model.ErrMenuItemNotFound) {
+		t.Fatalf("ArchiveMenuItem of a missing item: %v, want %v", err, model.ErrMenuItemNotFound)
+	}
+
+	if err := f.menu.ArchiveMenuItem(ctx, f.latte, version); err != nil {
+		t.Fatalf("ArchiveMenuItem: %v", err)
+	}
+	archived, ingredients, err := f.menu.RetrieveMenuItemWithId(ctx, f.latte)
+	if err != nil {
+		t.Fatalf("RetrieveMenuItemWithId: %v", err)
+	}
+	if !archived.IsArchived() || archived.Version == version {
+		t.Errorf("archived item = %+v, want ArchivedAt set and a new version", archived)
+	}
+	if len(ingredients) != 1 {
+		t.Errorf("recipe of the archived item = %+v, want it kept", ingredients)
+	}
+
+	// Archived items are left out of the menu unless they are asked for
+	if items, _ := f.menu.RetrieveMenuItems(ctx, false); len(items) != 0 {
+		t.Errorf("menu = %+v, want no items", items)
+	}
+	if items, _ := f.menu.RetrieveMenuItems(ctx, true); len(items) != 1 {
+		t.Errorf("menu with archived items = %+v, want the latte", items)
+	}
+
+	// Archiving again changes nothing
+	if err := f.menu.ArchiveMenuItem(ctx, f.latte, 0); err != nil {
+		t.Fatalf("ArchiveMenuItem of an archived item: %v", err)
+	}
+	if again, _, _ := f.menu.RetrieveMenuItemWithId(ctx, f.latte); !reflect.DeepEqual(again, archived) {
+		t.Errorf("item archived again = %+v, want %+v", again, archived)
+	}
+}
+
+func TestRestoreMenuItem(t *testing.T) {
+	ctx := context.Background()
+	f := newMenuFixture(t)
+
+	// Restoring an item on the menu changes nothing
+	restored, _, err := f.menu.RestoreMenuItem(ctx, f.latte, 0)
+	if err != nil {
+		t.Fatalf("RestoreMenuItem: %v", err)
+	}
+	if version := f.version(t); restored.IsArchived() || restored.Version != version {
+		t.Errorf("restored item = %+v, want it unchanged at version %d", restored, version)
+	}
+
+	if err := f.menu.ArchiveMenuItem(ctx, f.latte, 0); err != nil {
+		t.Fatalf("ArchiveMenuItem: %v", err)
+	}
+	version := f.version(t)
+
+	if _, _, err := f.menu.RestoreMenuItem(ctx, f.latte, version+1); !errors.Is(err, model.ErrVersionMismatch) {
+		t.Fatalf("RestoreMenuItem of another version: %v, want %v", err, model.ErrVersionMismatch)
+	}
+
+	restored, ingredients, err := f.menu.RestoreMenuItem(ctx, f.latte, version)
+	if err != nil {
+		t.Fatalf("RestoreMenuItem: %v", err)
+	}
+	if restored.IsArchived() || restored.Version == version {
+		t.Errorf("restored item = %+v, want ArchivedAt cleared and a new version", restored)
+	}
+	if len(ingredients) != 1 || ingredients[0].IngredientID != f.milk {
+		t.Errorf("recipe of the restored item = %+v, want the milk", ingredients)
+	}
+	if items, _ := f.menu.RetrieveMenuItems(ctx, false); len(items) != 1 {
+		t.Errorf("menu = %+v, want the latte", items)
+	}
+}
+
+func TestDeleteMenuItem(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("referenced item", func(t *testing.T) {
+		f := newMenuFixture(t)
+		latte := model.MenuItem{Name: "Latte", Description: "milk coffee", Category: "coffee", Price: 4.75}
+		if err := f.menu.UpdateMenuItem(ctx, f.latte, latte, nil); err != nil {
+			t.Fatalf("UpdateMenuItem: %v", err)
+		}
+
+		err := f.menu.DeleteMenuItem(ctx, f.latte, 0)
+		var referenced *model.ReferencedError
+		if !errors.As(err, &referenced) {
+			t.Fatalf("DeleteMenuItem: %v, want a *model.ReferencedError", err)
+		}
+		want := []model.Reference{{EntityType: model.ReferencePriceHistory, Count: 1}}
+		if !reflect.DeepEqual(referenced.References, want) {
+			t.Errorf("references = %+v, want %+v", referenced.References, want)
+		}
+
+		// Neither the item nor its recipe are deleted
+		if _, ingredients, err := f.menu.RetrieveMenuItemWithId(ctx, f.latte); err != nil || len(ingredients) != 1 {
+			t.Errorf("RetrieveMenuItemWithId = %+v, %v, want the item with its recipe", ingredients, err)
+		}
+	})
+
+	t.Run("unreferenced item", func(t *testing.T) {
+		f := newMenuFixture(t)
+		version := f.version(t)
+
+		if err := f.menu.DeleteMenuItem(ctx, f.latte, version+1); !errors.Is(err, model.ErrVersionMismatch) {
+			t.Fatalf("DeleteMenuItem of another version: %v, want %v", err, model.ErrVersionMismatch)
+		}
+		if err := f.menu.DeleteMenuItem(ctx, f.latte, version); err != nil {
+			t.Fatalf("DeleteMenuItem: %v", err)
+		}
+		if _, _, err := f.menu.RetrieveMenuItemWithId(ctx, f.latte); !errors.Is(err, model.ErrMenuItemNotFound) {
+			t.Errorf("RetrieveMenuItemWithId of the deleted item: %v, want %v", err, model.ErrMenuItemNotFound)
+		}
+		if ingredients, err := f.menu.MenuIngredientsRepo.GetAllWithID(ctx, f.latte); err != nil || len(ingredients) != 0 {
+			t.Errorf("recipe of the deleted item = %+v, %v, want none", ingredients, err)
+		}
+		if err := f.menu.DeleteMenuItem(ctx, f.latte, 0); !errors.Is(err, model.ErrMenuItemNotFound) {
+			t.Errorf("DeleteMenuItem of a deleted item: %v, want %v", err, model.ErrMenuItemNotFound)
+		}
+	})
+}
+
+func TestMenuItemArchivedIngredients(t *testing.T) {
+	ctx := context.Background()
+	f := newMenuFixture(t)
+
+	sugar, err := f.menu.InventoryRepo.Create(ctx, model.Inventory{Name: "Sugar", Quantity: 100, Unit: "g"})
+	if err != nil {
+		t.Fatalf("Create inventory: %v", err)
+	}
+	for _, id := range []int{f.milk, sugar} {
+		if err := f.menu.InventoryRepo.Archive(ctx, id, 0, time.Now()); err != nil {
+			t.Fatalf("Archive inventory: %v", err)
+		}
+	}
+
+	mocha := model.MenuItem{Name: "Mocha", Description: "chocolate coffee", Category: "coffee", Price: 5}
+	err = f.menu.AddMenuItem(ctx, mocha, []model.MenuItemIngredients{{IngredientID: sugar, Quantity: 1}})
+	if !errors.Is(err, model.ErrIngredientArchived) {
+		t.Errorf("AddMenuItem with an archived ingredient: %v, want %v", err, model.ErrIngredientArchived)
+	}
+
+	latte := model.MenuItem{Name: "Latte", Description: "milk coffee", Category: "coffee", Price: 4.5}
+
+	// The milk was in the recipe before it was archived, so it stays in it
+	if err := f.menu.UpdateMenuItem(ctx, f.latte, latte, []model.MenuItemIngredients{{IngredientID: f.milk, Quantity: 3}}); err != nil {
+		t.Errorf("UpdateMenuItem with an archived ingredient of the recipe: %v", err)
+	}
+
+	err = f.menu.UpdateMenuItem(ctx, f.latte, latte, []model.MenuItemIngredients{{IngredientID: f.milk, Quantity: 3}, {IngredientID: sugar, Quantity: 1}})
+	if !errors.Is(err, model.ErrIngredientArchived) {
+		t.Errorf("UpdateMenuItem adding an archived ingredient: %v, want %v", err, model.ErrIngredientArchived)
+	}
+}
+
+func TestMenuItemPriceHistory(t *testing.T) {
+	ctx := context.Background()
+	f := newMenuFixture(t)
+
+	type change struct{ old, new float64 }
+	history := func() []change {
+		t.Helper()
+		changes, err := f.menu.PriceHistoryRepo.GetAllWithID(ctx, f.latte)
+		if err != nil {
+			t.Fatalf("GetAllWithID: %v", err)
+		}
+		var got []change
+		for _, c := range changes {
+			got = append(got, change{c.OldPrice, c.NewPrice})
+		}
+		return got
+	}
+	patch := func(item model.MenuItem) {
+		t.Helper()
+		item.Version = f.version(t)
+		if _, _, err := f.menu.PatchMenuItem(ctx, f.latte, item, []model.MenuItemIngredients{{IngredientID: f.milk, Quantity: 2}}); err != nil {
+			t.Fatalf("PatchMenuItem: %v", err)
+		}
+	}
+	latte := model.MenuItem{Name: "Latte", Description: "milk coffee", Category: "coffee", Price: 4.5}
+
+	steps := []struct {
+		name   string
+		change func()
+		want   []change
+	}{
+		{
+			name: "update keeping the price",
+			change: func() {
+				latte.Description = "coffee with milk"
+				if err := f.menu.UpdateMenuItem(ctx, f.latte, latte, nil); err != nil {
+					t.Fatalf("UpdateMenuItem: %v", err)
+				}
+			},
+		},
+		{
+			name: "update of the price",
+			change: func() {
+				latte.Price = 4.75
+				if err := f.menu.UpdateMenuItem(ctx, f.latte, latte, nil); err != nil {
+					t.Fatalf("UpdateMenuItem: %v", err)
+				}
+			},
+			want: []change{{4.5, 4.75}},
+		},
+		{
+			name:   "patch keeping the price",
+			change: func() { latte.Category = "hot coffee"; patch(latte) },
+			want:   []change{{4.5, 4.75}},
+		},
+		{
+			name:   "patch of the price",
+			change: func() { latte.Price = 5; patch(latte) },
+			want:   []change{{4.5, 4.75}, {4.75, 5}},
+		},
+		{
+			name: "import of the price",
+			change: func() {
+				latte.Price = 5.25
+				rows := []model.MenuImportRow{{Row: 1, Item: latte, Ingredients: []model.ImportIngredient{{Name: "Milk", Quantity: 2}}}}
+				result, err := f.imports.ImportMenu(ctx, rows, false)
+				if err != nil || !result.Applied {
+					t.Fatalf("ImportMenu = %+v, %v, want it applied", result, err)
+				}
+			},
+			want: []change{{4.5, 4.75}, {4.75, 5}, {5, 5.25}},
+		},
+	}
+
+	for _, step := range steps {
+		step.change()
+		if got := history(); !reflect.DeepEqual(got, step.want) {
+			t.Errorf("%s: price history = %v, want %v", step.name, got, step.want)
+		}
+	}
+}
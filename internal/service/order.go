@@ -2,91 +2,439 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"time"
 
 	"coffee-shop/internal/model"
-	"coffee-shop/internal/repository/postgres"
 )
 
+// OrderRepos groups the repositories the order service works with.
+type OrderRepos struct {
+	OrderRepo           OrderRepo
+	OrderItemsRepo      OrderItemsRepo
+	HistoryRepo         OrderStatusHistoryRepo
+	OrderTaxesRepo      OrderTaxesRepo
+	MenuRepo            MenuRepo
+	MenuIngredientsRepo MenuItemIngredientsRepo
+	InventoryRepo       InventoryRepo
+	LedgerRepo          InventoryTransactionsRepo
+	TaxRepo             TaxRateRepo
+	PaymentRepo         PaymentRepo
+	ConsumptionRepo     OrderConsumptionRepo
+	ClosingRepo         ClosingRepo
+	AuditRepo           AuditRepo
+}
+
 type orderService struct {
-	OrderRepo     postgres.Order
-	MenuRepo      postgres.Menu
-	InventoryRepo postgres.Inventory
-	ReportRepo    any
+	OrderRepos
+	tx     Transactor
+	events OrderEventPublisher
+}
+
+func NewOrderService(repos OrderRepos, tx Transactor, events OrderEventPublisher) *orderService {
+	return &orderService{OrderRepos: repos, tx: tx, events: events}
 }
 
-func NewOrderService(or postgres.Order, menu postgres.Menu, ir postgres.Inventory, re any) *orderService {
-	return &orderService{OrderRepo: or, MenuRepo: menu, InventoryRepo: ir, ReportRepo: re}
+func (s *orderService) stock() stock {
+	return stock{
+		InventoryRepo:       s.InventoryRepo,
+		LedgerRepo:          s.LedgerRepo,
+		MenuIngredientsRepo: s.MenuIngredientsRepo,
+		ConsumptionRepo:     s.ConsumptionRepo,
+	}
 }
 
-func (s *orderService) AddOrder(ctx context.Context, order model.Order) error {
+// AddOrder creates a new open order with its items and returns the order ID.
+// The current menu price of every product is stored with the item.
+// The following errors may be returned:
+// - model.ErrProductNotFound if an item refers to a product that is not on the menu.
+// - model.ErrProductArchived if an item refers to a product that was taken off the menu.
+// - An error if there is a validation issue or a failure when saving the order.
+func (s *orderService) AddOrder(ctx context.Context, order model.Order) (int, error) {
+	order.Status = model.OrderStatusOpen
 	if err := order.Validate(); err != nil {
-		return err
+		return 0, err
 	}
 
-	if err := s.OrderRepo.Create(ctx, order); err != nil {
-		return err
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.priceItems(ctx, order.Items); err != nil {
+			return err
+		}
+
+		id, err := s.OrderRepo.Create(ctx, order)
+		if err != nil {
+			return err
+		}
+		order.ID = id
+
+		if err := s.createItems(ctx, id, order.Items); err != nil {
+			return err
+		}
+
+		if err := s.HistoryRepo.Create(ctx, model.OrderStatusHistory{OrderID: id}); err != nil {
+			return err
+		}
+
+		return s.recordOrder(ctx, model.AuditCreate, id, nil)
+	})
+	if err != nil {
+		return 0, err
 	}
 
-	return nil
+	s.publish(ctx, model.OrderEventCreated, order)
+	return order.ID, nil
 }
 
+// RetrieveOrders returns all orders with their items.
 func (s *orderService) RetrieveOrders(ctx context.Context) ([]model.Order, error) {
-	return s.OrderRepo.GetAll(ctx)
+	orders, err := s.OrderRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range orders {
+		orders[i].Items, err = s.OrderItemsRepo.GetAllWithID(ctx, orders[i].ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return orders, nil
 }
 
+// ExportOrders calls fn for every order without its items, reading them one at a time,
+// so that all orders can be exported without holding them in memory.
+func (s *orderService) ExportOrders(ctx context.Context, fn func(order model.Order) error) error {
+	return s.OrderRepo.Each(ctx, fn)
+}
+
+// LockOrder locks the order until the end of the transaction of ctx. Payments,
+// refunds and closings lock the order first, so that they check the balance of
+// the order one at a time.
+// The following errors may be returned:
+// - model.ErrNoOrder if the order with the specified ID is not found.
+func (s *orderService) LockOrder(ctx context.Context, id int) error {
+	err := s.OrderRepo.Lock(ctx, id)
+	if errors.Is(err, model.ErrNotFound) {
+		return model.ErrNoOrder
+	}
+	return err
+}
+
+// RetrieveOrder returns the order with its items and, for closed orders, its tax lines.
+// The following errors may be returned:
+// - model.ErrNoOrder if the order with the specified ID is not found.
 func (s *orderService) RetrieveOrder(ctx context.Context, id int) (*model.Order, error) {
 	order, err := s.OrderRepo.Get(ctx, id)
+	if errors.Is(err, model.ErrNotFound) {
+		return nil, model.ErrNoOrder
+	}
 	if err != nil {
 		return nil, err
 	}
 
+	order.Items, err = s.OrderItemsRepo.GetAllWithID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if order.Status == model.OrderStatusClosed {
+		order.Taxes, err = s.OrderTaxesRepo.GetAllWithID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return &order, nil
 }
 
+// UpdateOrder replaces the customer, notes, discount and items of an open order.
+// Items are repriced with the current menu prices. For a reserved order the
+// stock of the old items is given back and the new items are reserved instead.
+// The following errors may be returned:
+// - model.ErrNoOrder if the order with the specified ID is not found.
+// - model.ErrOrderClosed if the order is already closed.
+// - model.ErrVersionMismatch if the version of order is set and the stored order has another.
+// - model.ErrNotEnoughInventoryQuantity if the stock for the new items of a reserved order is insufficient.
 func (s *orderService) UpdateOrder(ctx context.Context, id int, order model.Order) error {
+	order.Status = model.OrderStatusOpen
 	if err := order.Validate(); err != nil {
 		return err
 	}
 
-	err := s.OrderRepo.Update(ctx, id, order)
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		old, err := s.RetrieveOrder(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if old.Status != model.OrderStatusOpen {
+			return model.ErrOrderClosed
+		}
+
+		if err := s.priceItems(ctx, order.Items); err != nil {
+			return err
+		}
+
+		if err := s.OrderRepo.Update(ctx, id, order); err != nil {
+			return err
+		}
+
+		if err := s.OrderItemsRepo.Delete(ctx, id); err != nil {
+			return err
+		}
+
+		if old.Reserved {
+			order.Reserved = true
+			if err := s.stock().restock(ctx, id, old.Items, fmt.Sprintf("order #%d updated", id)); err != nil {
+				return err
+			}
+			if _, err := s.stock().consume(ctx, id, order.Items, fmt.Sprintf("order #%d reserved", id)); err != nil {
+				return err
+			}
+		}
+
+		if err := s.createItems(ctx, id, order.Items); err != nil {
+			return err
+		}
+
+		return s.recordOrder(ctx, model.AuditUpdate, id, old)
+	})
 	if err != nil {
 		return err
 	}
 
+	order.ID = id
+	s.publish(ctx, model.OrderEventUpdated, order)
 	return nil
 }
 
-func (s *orderService) DeleteOrder(ctx context.Context, id int) error {
-	return s.OrderRepo.Delete(ctx, id)
+// DeleteOrder deletes the order together with its items and status history.
+// The stock reserved for the order is given back. If version is set, only the order
+// with that version is deleted.
+// The following errors may be returned:
+// - model.ErrNoOrder if the order with the specified ID is not found.
+// - model.ErrVersionMismatch if the order has another version.
+// - model.ErrOrderHasPayments if payments were made for the order.
+// - model.ErrOrderLocked if the order was closed within a closed business day.
+func (s *orderService) DeleteOrder(ctx context.Context, id int, version int) error {
+	var order *model.Order
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		order, err = s.RetrieveOrder(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if err := checkOrderUnlocked(ctx, s.ClosingRepo, id); err != nil {
+			return err
+		}
+
+		payments, err := s.PaymentRepo.GetAllWithID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if len(payments) > 0 {
+			return model.ErrOrderHasPayments
+		}
+
+		if order.Reserved {
+			if err := s.stock().restock(ctx, id, order.Items, fmt.Sprintf("order #%d cancelled", id)); err != nil {
+				return err
+			}
+		}
+
+		if err := s.OrderItemsRepo.Delete(ctx, id); err != nil {
+			return err
+		}
+
+		if err := s.HistoryRepo.DeleteByOrder(ctx, id); err != nil {
+			return err
+		}
+
+		if err := s.OrderRepo.Delete(ctx, id, version); err != nil {
+			return err
+		}
+
+		return recordChange(ctx, s.AuditRepo, model.AuditDelete, model.AuditOrder, id, order, nil)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.publish(ctx, model.OrderEventCancelled, *order)
+	return nil
 }
 
-func (s *orderService) CloseOrder(ctx context.Context, id int) error {
+// CloseOrder closes a fully paid open order. The ingredients of its items are written off
+// the inventory through the ledger, and the totals and tax lines are stored on the order.
+// The following errors may be returned:
+// - model.ErrNoOrder if the order with the specified ID is not found.
+// - model.ErrOrderClosed if the order is already closed.
+// - model.ErrOrderNotPaid if the payments do not cover the order total.
+// - model.ErrNotEnoughInventoryQuantity if the stock of an ingredient is insufficient.
+func (s *orderService) CloseOrder(ctx context.Context, id int) (*model.Order, error) {
+	var order *model.Order
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.LockOrder(ctx, id); err != nil {
+			return err
+		}
+
+		var err error
+		order, err = s.RetrieveOrder(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if order.Status != model.OrderStatusOpen {
+			return model.ErrOrderClosed
+		}
+
+		totals, err := s.calculateTotals(ctx, *order)
+		if err != nil {
+			return err
+		}
+
+		payments, err := s.PaymentRepo.GetAllWithID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if balance := paymentBalance(totals.Total, payments); balance.Balance > 0 {
+			return model.ErrOrderNotPaid
+		}
+
+		before := *order
+		if !order.Reserved {
+			_, err = s.stock().consume(ctx, id, order.Items, fmt.Sprintf("order #%d closed", id))
+			if err != nil {
+				return err
+			}
+		}
+
+		order.OrderTotals = totals
+		order.Status = model.OrderStatusClosed
+		order.ClosedAt = time.Now()
+
+		closed, err := s.OrderRepo.Close(ctx, *order)
+		if err != nil {
+			return err
+		}
+		if !closed {
+			return model.ErrOrderClosed
+		}
+
+		for _, line := range order.Taxes {
+			line.OrderID = id
+			if err := s.OrderTaxesRepo.Create(ctx, line); err != nil {
+				return err
+			}
+		}
+
+		if err := s.HistoryRepo.Close(ctx, id); err != nil {
+			return err
+		}
+
+		return s.recordOrder(ctx, model.AuditClose, id, &before)
+	})
+	if err != nil {
+		return nil, err
+	}
 
-	// if order.Status != "open" {
-	// 	return ErrOrderClosed
-	// }
+	s.publish(ctx, model.OrderEventClosed, *order)
+	return order, nil
+}
 
-	// err = s.ReduceIngredients(order.Items)
-	// if err != nil {
-	// 	return err
-	// }
+// RetrieveOrderTotals returns the receipt totals of the order.
+// Totals of closed orders are the ones stored at closing time, totals of
+// open orders are calculated with the current tax rates.
+// The following errors may be returned:
+// - model.ErrNoOrder if the order with the specified ID is not found.
+func (s *orderService) RetrieveOrderTotals(ctx context.Context, id int) (*model.OrderTotals, error) {
+	order, err := s.RetrieveOrder(ctx, id)
+	if err != nil {
+		return nil, err
+	}
 
-	// totalOrderPrice, err := s.CalculateTotalSales()
-	// if err != nil {
-	// 	return err
-	// }
+	if order.Status == model.OrderStatusClosed {
+		return &order.OrderTotals, nil
+	}
 
-	// err = s.ReportRepo.UpdateTotalSales(totalOrderPrice)
-	// if err != nil {
-	// 	return err
-	// }
+	totals, err := s.calculateTotals(ctx, *order)
+	if err != nil {
+		return nil, err
+	}
+
+	return &totals, nil
+}
+
+// recordOrder writes the change of the order to the audit log with the order as it is
+// stored after the change. before is nil for a created order.
+func (s *orderService) recordOrder(ctx context.Context, action string, id int, before *model.Order) error {
+	after, err := s.RetrieveOrder(ctx, id)
+	if err != nil {
+		return err
+	}
 
-	// order.Status = "closed"
+	if before == nil {
+		return recordChange(ctx, s.AuditRepo, action, model.AuditOrder, id, nil, after)
+	}
+	return recordChange(ctx, s.AuditRepo, action, model.AuditOrder, id, before, after)
+}
 
-	// err = s.OrderRepo.RewriteOrder(id, order)
-	// if err != nil {
-	// 	return err
-	// }
+// calculateTotals groups the order items by menu category and applies the tax rates.
+func (s *orderService) calculateTotals(ctx context.Context, order model.Order) (model.OrderTotals, error) {
+	rates, err := s.TaxRepo.GetAll(ctx)
+	if err != nil {
+		return model.OrderTotals{}, err
+	}
+
+	lines := make([]taxableLine, 0, len(order.Items))
+	for _, item := range order.Items {
+		menuItem, err := s.MenuRepo.Get(ctx, item.ProductID)
+		if errors.Is(err, model.ErrNotFound) {
+			return model.OrderTotals{}, model.ErrProductNotFound
+		}
+		if err != nil {
+			return model.OrderTotals{}, err
+		}
+
+		lines = append(lines, taxableLine{
+			Category: menuItem.Category,
+			Amount:   item.Price * float64(item.Quantity),
+		})
+	}
+
+	return calculateTotals(lines, order.DiscountTotal, rates), nil
+}
+
+// priceItems sets the current menu price on every item. Archived products cannot be ordered.
+func (s *orderService) priceItems(ctx context.Context, items []model.OrderItems) error {
+	for i, item := range items {
+		menuItem, err := s.MenuRepo.Get(ctx, item.ProductID)
+		if errors.Is(err, model.ErrNotFound) {
+			return model.ErrProductNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if menuItem.IsArchived() {
+			return model.ErrProductArchived
+		}
+
+		items[i].Price = menuItem.Price
+	}
+
+	return nil
+}
+
+func (s *orderService) createItems(ctx context.Context, orderID int, items []model.OrderItems) error {
+	for _, item := range items {
+		item.OrderID = orderID
+		if err := s.OrderItemsRepo.Create(ctx, item); err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
@@ -139,18 +487,18 @@ func (s *orderService) CloseOrder(ctx context.Context, id int) error {
 // 	for _, orderItem := range orderItems {
 // 		menuItem, exists := menuMap[orderItem.ProductID]
 // 		if !exists {
-// 			return false, ErrOrderProductNotFound
+// 			return false, model.ErrOrderProductNotFound
 // 		}
 
 // 		for _, ingredient := range menuItem.Ingredients {
 // 			inventoryItem, exists := inventoryMap[ingredient.IngredientID]
 // 			if !exists {
-// 				return false, ErrInventoryItemNotFound
+// 				return false, model.ErrInventoryItemNotFound
 // 			}
 
 // 			requiredQuantity := ingredient.Quantity * float64(orderItem.Quantity)
 // 			if requiredQuantity > inventoryItem.Quantity {
-// 				return false, ErrNotEnoughInventoryQuantity
+// 				return false, model.ErrNotEnoughInventoryQuantity
 // 			}
 // 		}
 // 	}
@@ -158,79 +506,36 @@ func (s *orderService) CloseOrder(ctx context.Context, id int) error {
 // 	return true, nil
 // }
 
-// func (s *orderService) ReduceIngredients(ctx context.Context, orderItems []model.OrderItem) error {
-// 	inventoryMap := make(map[string]models.InventoryItem)
-// 	inventoryItems, err := s.InventoryRepo.GetAllItems()
-// 	if err != nil {
-// 		return err
-// 	}
-
-// 	for _, item := range inventoryItems {
-// 		inventoryMap[item.IngredientID] = item
-// 	}
-
-// 	menuMap := make(map[string]models.MenuItem)
-// 	menuItems, err := s.MenuRepo.GetAllMenuItems()
-// 	if err != nil {
-// 		return err
-// 	}
-// 	for _, item := range menuItems {
-// 		menuMap[item.ID] = item
-// 	}
-
-// 	for _, orderItem := range orderItems {
-// 		menuItem, exists := menuMap[orderItem.ProductID]
-// 		if !exists {
-// 			return ErrOrderProductNotFound
-// 		}
-
-// 		for _, ingredient := range menuItem.Ingredients {
-// 			inventoryItem, exists := inventoryMap[ingredient.IngredientID]
-// 			if !exists {
-// 				return ErrInventoryItemNotFound
-// 			}
-
-// 			requiredQuantity := ingredient.Quantity * float64(orderItem.Quantity)
-// 			if requiredQuantity > inventoryItem.Quantity {
-// 				return ErrNotEnoughInventoryQuantity
-// 			}
-
-// 			inventoryItem.Quantity -= requiredQuantity
-// 			inventoryMap[ingredient.IngredientID] = inventoryItem
-// 		}
-// 	}
-
-// 	var updatedItems []models.InventoryItem
-// 	for _, item := range inventoryMap {
-// 		updatedItems = append(updatedItems, item)
-// 	}
-
-// 	if err := s.InventoryRepo.SaveItems(updatedItems); err != nil {
-// 		return err
-// 	}
-
-// 	return nil
-// }
+// publish sends the event of the order to the live order queue. It is called once the
+// transaction is committed, so subscribers never see changes that were rolled back.
+// Items whose product cannot be looked up are sent without a name and category.
+func (s *orderService) publish(ctx context.Context, eventType string, order model.Order) {
+	if s.events == nil {
+		return
+	}
 
-// func (s *orderService) CalculateTotalSales() (float64, error) {
-// 	totalSales := 0.0
+	event := model.OrderEvent{
+		Type:         eventType,
+		OrderID:      order.ID,
+		Status:       order.Status,
+		CustomerName: order.CustomerName,
+		Notes:        order.Notes,
+	}
 
-// 	orders, err := s.OrderRepo.GetAllOrders()
-// 	if err != nil {
-// 		return 0.0, err
-// 	}
+	for _, item := range order.Items {
+		eventItem := model.OrderEventItem{
+			ProductID: item.ProductID,
+			Quantity:  item.Quantity,
+			Modifiers: item.Modifiers,
+		}
 
-// 	for _, order := range orders {
-// 		for _, orderItem := range order.Items {
-// 			menuItem, err := s.MenuRepo.GetMenuItemById(orderItem.ProductID)
-// 			if err != nil {
-// 				return 0.0, err
-// 			}
+		if menuItem, err := s.MenuRepo.Get(ctx, item.ProductID); err == nil {
+			eventItem.Name = menuItem.Name
+			eventItem.Category = menuItem.Category
+		}
 
-// 			itemTotal := menuItem.Price * float64(orderItem.Quantity)
-// 			totalSales += itemTotal
-// 		}
-// 	}
+		event.Items = append(event.Items, eventItem)
+	}
 
-// 	return totalSales, nil
-// }
+	s.events.Publish(event)
+}
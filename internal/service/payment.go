@@ -0,0 +1,497 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"coffee-shop/internal/model"
+)
+
+// OrderReader is the part of the order service used to look up and lock orders and their totals.
+type OrderReader interface {
+	LockOrder(ctx context.Context, id int) error
+	RetrieveOrder(ctx context.Context, id int) (*model.Order, error)
+	RetrieveOrderTotals(ctx context.Context, id int) (*model.OrderTotals, error)
+}
+
+type paymentService struct {
+	PaymentRepo PaymentRepo
+	Orders      OrderReader
+	ClosingRepo ClosingRepo
+	stock       stock
+	tx          Transactor
+}
+
+func NewPaymentService(repo PaymentRepo, orders OrderReader, ir InventoryRepo, ledger InventoryTransactionsRepo, mi MenuItemIngredientsRepo, consumption OrderConsumptionRepo, closings ClosingRepo, tx Transactor) *paymentService {
+	return &paymentService{
+		PaymentRepo: repo,
+		Orders:      orders,
+		ClosingRepo: closings,
+		stock:       stock{InventoryRepo: ir, LedgerRepo: ledger, MenuIngredientsRepo: mi, ConsumptionRepo: consumption},
+		tx:          tx,
+	}
+}
+
+// AddPayment records a payment for an open order. Several payments with different
+// tenders may be made until the order total is covered. The order is locked while
+// the payment is checked against its balance. For cash payments the change
+// is calculated from the tendered amount; if it is not given, the exact amount is assumed.
+// The following errors may be returned:
+// - model.ErrNoOrder if the order with the specified ID is not found.
+// - model.ErrOrderClosed if the order is already closed.
+// - model.ErrOverpayment if the amount exceeds the order balance.
+// - model.ErrNotValidTendered if the tendered cash does not cover the amount and the tip.
+func (s *paymentService) AddPayment(ctx context.Context, orderID int, payment model.Payment) (*model.Payment, error) {
+	if err := payment.Validate(); err != nil {
+		return nil, err
+	}
+
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.Orders.LockOrder(ctx, orderID); err != nil {
+			return err
+		}
+
+		order, err := s.Orders.RetrieveOrder(ctx, orderID)
+		if err != nil {
+			return err
+		}
+		if order.Status != model.OrderStatusOpen {
+			return model.ErrOrderClosed
+		}
+
+		balance, err := s.balance(ctx, orderID)
+		if err != nil {
+			return err
+		}
+		if roundMoney(payment.Amount) > balance.Balance {
+			return model.ErrOverpayment
+		}
+
+		payment.OrderID = orderID
+		payment.Amount = roundMoney(payment.Amount)
+		payment.Tip = roundMoney(payment.Tip)
+		charged := roundMoney(payment.Amount + payment.Tip)
+
+		switch {
+		case payment.Tender != model.TenderCash || payment.Tendered == 0:
+			payment.Tendered = charged
+		case roundMoney(payment.Tendered) < charged:
+			return model.ErrNotValidTendered
+		}
+		payment.Change = roundMoney(payment.Tendered - charged)
+
+		payment.ID, err = s.PaymentRepo.Create(ctx, payment)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &payment, nil
+}
+
+// RetrievePayments returns the payments and refunds of the order and its balance.
+// The following errors may be returned:
+// - model.ErrNoOrder if the order with the specified ID is not found.
+func (s *paymentService) RetrievePayments(ctx context.Context, orderID int) ([]model.Payment, *model.PaymentBalance, error) {
+	payments, err := s.PaymentRepo.GetAllWithID(ctx, orderID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	balance, err := s.balance(ctx, orderID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return payments, balance, nil
+}
+
+// SplitBill divides the bill of the order between guests without recording any payment.
+// An even split divides the outstanding balance into equal parts, the first parts
+// taking the remaining cents. An item split must assign every ordered item to a guest;
+// every guest pays the share of the order total that their items make up.
+// The following errors may be returned:
+// - model.ErrNoOrder if the order with the specified ID is not found.
+// - model.ErrNotValidSplit if the split does not cover the order.
+func (s *paymentService) SplitBill(ctx context.Context, orderID int, req model.SplitRequest) ([]model.SplitShare, error) {
+	order, err := s.Orders.RetrieveOrder(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch req.Mode {
+	case model.SplitEven:
+		if req.Parts < 2 {
+			return nil, model.ErrNotValidSplit
+		}
+
+		balance, err := s.balance(ctx, orderID)
+		if err != nil {
+			return nil, err
+		}
+
+		return splitEven(balance.Balance, req.Parts), nil
+	case model.SplitItems:
+		totals, err := s.Orders.RetrieveOrderTotals(ctx, orderID)
+		if err != nil {
+			return nil, err
+		}
+
+		return splitItems(*order, totals.Total, req.Groups)
+	default:
+		return nil, model.ErrNotValidSplit
+	}
+}
+
+// RefundOrder refunds a closed order fully or partially. Refunds are recorded as
+// negative payments, spread over the refunded payments from the newest one, or
+// taken from a single payment if req.PaymentID is set. The order is locked while
+// the refund is checked against what was paid. With req.Restock the
+// ingredients that the refunded items consumed are put back into the inventory
+// through the ledger.
+// The following errors may be returned:
+// - model.ErrNoOrder if the order with the specified ID is not found.
+// - model.ErrOrderNotClosed if the order is still open.
+// - model.ErrOrderLocked if the order was closed within a closed business day.
+// - model.ErrPaymentNotFound if req.PaymentID is not a payment of the order.
+// - model.ErrNotValidRestock if req.Restock is set without the returned items.
+// - model.ErrRefundItemsExceeded if more items are refunded than were ordered.
+// - model.ErrRefundExceedsPaid if the amount exceeds what is left to refund.
+func (s *paymentService) RefundOrder(ctx context.Context, orderID int, req model.RefundRequest) ([]model.Payment, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	var refunds []model.Payment
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.Orders.LockOrder(ctx, orderID); err != nil {
+			return err
+		}
+
+		order, err := s.Orders.RetrieveOrder(ctx, orderID)
+		if err != nil {
+			return err
+		}
+		if order.Status != model.OrderStatusClosed {
+			return model.ErrOrderNotClosed
+		}
+
+		if err := checkOrderUnlocked(ctx, s.ClosingRepo, orderID); err != nil {
+			return err
+		}
+
+		payments, err := s.PaymentRepo.GetAllWithID(ctx, orderID)
+		if err != nil {
+			return err
+		}
+
+		refundable, err := refundablePayments(payments, req.PaymentID)
+		if err != nil {
+			return err
+		}
+
+		refunded, err := s.PaymentRepo.RefundedItems(ctx, orderID)
+		if err != nil {
+			return err
+		}
+		remaining := subtractItems(order.Items, refunded)
+
+		items := req.Items
+		amount := roundMoney(req.Amount)
+		switch {
+		case len(items) > 0:
+			if !containsItems(remaining, items) {
+				return model.ErrRefundItemsExceeded
+			}
+			amount = itemsShare(*order, order.Total, takeItems(remaining, items))
+		case amount == 0:
+			// A full refund of the order returns all items that are not refunded yet
+			amount = sumRefundable(refundable)
+			if req.PaymentID == 0 {
+				items = remaining
+			}
+		}
+
+		if amount <= 0 || amount > sumRefundable(refundable) {
+			return model.ErrRefundExceedsPaid
+		}
+
+		refunds, err = s.createRefunds(ctx, orderID, refundable, amount, req.Reason)
+		if err != nil {
+			return err
+		}
+
+		for _, item := range items {
+			if err := s.PaymentRepo.CreateRefundItem(ctx, refunds[0].ID, item); err != nil {
+				return err
+			}
+		}
+
+		if req.Restock && len(items) > 0 {
+			return s.stock.restock(ctx, orderID, items, fmt.Sprintf("refund of order #%d", orderID))
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return refunds, nil
+}
+
+// createRefunds takes the amount from the refundable payments in turn and records a refund for each of them.
+func (s *paymentService) createRefunds(ctx context.Context, orderID int, refundable []model.Payment, amount float64, reason string) ([]model.Payment, error) {
+	var refunds []model.Payment
+	for _, p := range refundable {
+		if amount <= 0 {
+			break
+		}
+
+		part := p.Amount
+		if part > amount {
+			part = amount
+		}
+		amount = roundMoney(amount - part)
+
+		refund := model.Payment{
+			OrderID:   orderID,
+			Tender:    p.Tender,
+			Amount:    -part,
+			RefundOf:  p.ID,
+			Reference: p.Reference,
+			Reason:    reason,
+		}
+
+		id, err := s.PaymentRepo.Create(ctx, refund)
+		if err != nil {
+			return nil, err
+		}
+		refund.ID = id
+
+		refunds = append(refunds, refund)
+	}
+
+	return refunds, nil
+}
+
+func (s *paymentService) balance(ctx context.Context, orderID int) (*model.PaymentBalance, error) {
+	totals, err := s.Orders.RetrieveOrderTotals(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	payments, err := s.PaymentRepo.GetAllWithID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	balance := paymentBalance(totals.Total, payments)
+	return &balance, nil
+}
+
+// paymentBalance sums up the payments made against the amount due.
+func paymentBalance(due float64, payments []model.Payment) model.PaymentBalance {
+	balance := model.PaymentBalance{Due: due}
+	for _, p := range payments {
+		if p.IsRefund() {
+			balance.Refunded -= p.Amount
+		} else {
+			balance.Paid += p.Amount
+			balance.Tips += p.Tip
+		}
+	}
+
+	balance.Paid = roundMoney(balance.Paid)
+	balance.Refunded = roundMoney(balance.Refunded)
+	balance.Tips = roundMoney(balance.Tips)
+	balance.Balance = roundMoney(due - balance.Paid + balance.Refunded)
+
+	return balance
+}
+
+// refundablePayments returns the payments that can still be refunded, newest first,
+// with Amount reduced by the refunds already made against them.
+// If paymentID is set only that payment is returned.
+func refundablePayments(payments []model.Payment, paymentID int) ([]model.Payment, error) {
+	refundedByPayment := make(map[int]float64)
+	for _, p := range payments {
+		if p.IsRefund() {
+			refundedByPayment[p.RefundOf] -= p.Amount
+		}
+	}
+
+	var refundable []model.Payment
+	found := false
+	for i := len(payments) - 1; i >= 0; i-- {
+		p := payments[i]
+		if p.IsRefund() || (paymentID > 0 && p.ID != paymentID) {
+			continue
+		}
+		found = true
+
+		p.Amount = roundMoney(p.Amount - refundedByPayment[p.ID])
+		if p.Amount > 0 {
+			refundable = append(refundable, p)
+		}
+	}
+
+	if paymentID > 0 && !found {
+		return nil, model.ErrPaymentNotFound
+	}
+
+	return refundable, nil
+}
+
+func sumRefundable(payments []model.Payment) float64 {
+	var sum float64
+	for _, p := range payments {
+		sum += p.Amount
+	}
+	return roundMoney(sum)
+}
+
+// splitEven divides the amount into equal parts. The first parts take the remaining cents.
+func splitEven(amount float64, parts int) []model.SplitShare {
+	cents := int(math.Round(amount * 100))
+	if cents < 0 {
+		cents = 0
+	}
+
+	shares := make([]model.SplitShare, parts)
+	for i := range shares {
+		share := cents / parts
+		if i < cents%parts {
+			share++
+		}
+		shares[i] = model.SplitShare{Part: i + 1, Amount: float64(share) / 100}
+	}
+
+	return shares
+}
+
+// splitItems builds a share for every group of items. The groups together must
+// contain exactly the ordered items. The last group takes the rounding remainder.
+func splitItems(order model.Order, total float64, groups [][]model.OrderItems) ([]model.SplitShare, error) {
+	if len(groups) < 2 {
+		return nil, model.ErrNotValidSplit
+	}
+
+	var all []model.OrderItems
+	for _, group := range groups {
+		if len(group) == 0 {
+			return nil, model.ErrNotValidSplit
+		}
+		all = append(all, group...)
+	}
+	if !sameItems(order.Items, all) {
+		return nil, model.ErrNotValidSplit
+	}
+
+	shares := make([]model.SplitShare, len(groups))
+	available := order.Items
+	remaining := total
+	for i, group := range groups {
+		amount := remaining
+		if i < len(groups)-1 {
+			amount = itemsShare(order, total, takeItems(available, group))
+			available = subtractItems(available, group)
+		}
+		remaining = roundMoney(remaining - amount)
+
+		shares[i] = model.SplitShare{Part: i + 1, Items: group, Amount: amount}
+	}
+
+	return shares, nil
+}
+
+// itemsShare returns the part of the total that the items make up, so that
+// discounts and taxes are spread over the items in proportion to their price.
+// Every item is priced at its own Price, as returned by takeItems.
+func itemsShare(order model.Order, total float64, items []model.OrderItems) float64 {
+	var subtotal float64
+	for _, item := range order.Items {
+		subtotal += item.Price * float64(item.Quantity)
+	}
+	if subtotal == 0 {
+		return 0
+	}
+
+	var amount float64
+	for _, item := range items {
+		amount += item.Price * float64(item.Quantity)
+	}
+
+	return roundMoney(total * amount / subtotal)
+}
+
+func itemQuantities(items []model.OrderItems) map[int]int {
+	quantities := make(map[int]int, len(items))
+	for _, item := range items {
+		quantities[item.ProductID] += item.Quantity
+	}
+	return quantities
+}
+
+// sameItems reports whether both lists contain the same products in the same quantities.
+func sameItems(a, b []model.OrderItems) bool {
+	qa, qb := itemQuantities(a), itemQuantities(b)
+	if len(qa) != len(qb) {
+		return false
+	}
+	for product, quantity := range qa {
+		if qb[product] != quantity {
+			return false
+		}
+	}
+	return true
+}
+
+// containsItems reports whether every product of items is available in the given quantity.
+func containsItems(available, items []model.OrderItems) bool {
+	qa := itemQuantities(available)
+	for product, quantity := range itemQuantities(items) {
+		if qa[product] < quantity {
+			return false
+		}
+	}
+	return true
+}
+
+// takeItems returns the lines of available that the given quantities are taken
+// from. A product may be ordered on several lines, e.g. with different
+// modifiers or prices, so its quantity is taken from the lines in turn.
+func takeItems(available, items []model.OrderItems) []model.OrderItems {
+	wanted := itemQuantities(items)
+	var taken []model.OrderItems
+	for _, item := range available {
+		quantity := min(item.Quantity, wanted[item.ProductID])
+		if quantity <= 0 {
+			continue
+		}
+		wanted[item.ProductID] -= quantity
+
+		item.Quantity = quantity
+		taken = append(taken, item)
+	}
+	return taken
+}
+
+// subtractItems returns the items left after removing the given quantities.
+// The quantity of a product is removed from its lines in turn.
+func subtractItems(items, removed []model.OrderItems) []model.OrderItems {
+	left := itemQuantities(removed)
+	var remaining []model.OrderItems
+	for _, item := range items {
+		quantity := min(item.Quantity, left[item.ProductID])
+		left[item.ProductID] -= quantity
+
+		item.Quantity -= quantity
+		if item.Quantity > 0 {
+			remaining = append(remaining, item)
+		}
+	}
+	return remaining
+}
@@ -0,0 +1,473 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"coffee-shop/internal/model"
+	"coffee-shop/internal/repository/memory"
+)
+
+func TestSplitEven(t *testing.T) {
+	tests := []struct {
+		name   string
+		amount float64
+		parts  int
+		want   []float64
+	}{
+		{"even", 9, 3, []float64{3, 3, 3}},
+		{"first part takes the remaining cent", 10, 3, []float64{3.34, 3.33, 3.33}},
+		{"first parts take the remaining cents", 10.01, 4, []float64{2.51, 2.5, 2.5, 2.5}},
+		{"amount not exact in binary", 0.29, 2, []float64{0.15, 0.14}},
+		{"fewer cents than parts", 0.01, 2, []float64{0.01, 0}},
+		{"nothing left to pay", -1, 2, []float64{0, 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shares := splitEven(tt.amount, tt.parts)
+			var got []float64
+			for i, share := range shares {
+				if share.Part != i+1 {
+					t.Errorf("share %d has part %d", i, share.Part)
+				}
+				got = append(got, share.Amount)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitEven(%v, %d) = %v, want %v", tt.amount, tt.parts, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitItems(t *testing.T) {
+	order := model.Order{Items: []model.OrderItems{
+		{ProductID: 1, Quantity: 2, Price: 4.5},
+		{ProductID: 2, Quantity: 1, Price: 3},
+	}}
+	three := model.Order{Items: []model.OrderItems{
+		{ProductID: 1, Quantity: 1, Price: 1},
+		{ProductID: 2, Quantity: 1, Price: 1},
+		{ProductID: 3, Quantity: 1, Price: 1},
+	}}
+
+	tests := []struct {
+		name    string
+		order   model.Order
+		total   float64
+		groups  [][]model.OrderItems
+		want    []float64
+		wantErr error
+	}{
+		{
+			name:   "shares follow the item prices",
+			order:  order,
+			total:  12,
+			groups: [][]model.OrderItems{{{ProductID: 1, Quantity: 2}}, {{ProductID: 2, Quantity: 1}}},
+			want:   []float64{9, 3},
+		},
+		{
+			name:   "discount and taxes are spread in proportion",
+			order:  order,
+			total:  10,
+			groups: [][]model.OrderItems{{{ProductID: 1, Quantity: 1}}, {{ProductID: 1, Quantity: 1}}, {{ProductID: 2, Quantity: 1}}},
+			want:   []float64{3.75, 3.75, 2.5},
+		},
+		{
+			name:   "last group takes the rounding remainder",
+			order:  three,
+			total:  10,
+			groups: [][]model.OrderItems{{{ProductID: 1, Quantity: 1}}, {{ProductID: 2, Quantity: 1}}, {{ProductID: 3, Quantity: 1}}},
+			want:   []float64{3.33, 3.33, 3.34},
+		},
+		{
+			name:    "single group",
+			order:   order,
+			total:   12,
+			groups:  [][]model.OrderItems{{{ProductID: 1, Quantity: 2}, {ProductID: 2, Quantity: 1}}},
+			wantErr: model.ErrNotValidSplit,
+		},
+		{
+			name:    "empty group",
+			order:   order,
+			total:   12,
+			groups:  [][]model.OrderItems{{{ProductID: 1, Quantity: 2}, {ProductID: 2, Quantity: 1}}, {}},
+			wantErr: model.ErrNotValidSplit,
+		},
+		{
+			name:    "items missing",
+			order:   order,
+			total:   12,
+			groups:  [][]model.OrderItems{{{ProductID: 1, Quantity: 1}}, {{ProductID: 2, Quantity: 1}}},
+			wantErr: model.ErrNotValidSplit,
+		},
+		{
+			name:    "items not ordered",
+			order:   order,
+			total:   12,
+			groups:  [][]model.OrderItems{{{ProductID: 1, Quantity: 2}}, {{ProductID: 2, Quantity: 1}, {ProductID: 3, Quantity: 1}}},
+			wantErr: model.ErrNotValidSplit,
+		},
+		{
+			name: "product on several lines is priced per line",
+			order: model.Order{Items: []model.OrderItems{
+				{ProductID: 1, Quantity: 1, Price: 4},
+				{ProductID: 1, Quantity: 1, Price: 6},
+			}},
+			total:  10,
+			groups: [][]model.OrderItems{{{ProductID: 1, Quantity: 1}}, {{ProductID: 1, Quantity: 1}}},
+			want:   []float64{4, 6},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shares, err := splitItems(tt.order, tt.total, tt.groups)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("splitItems: %v, want %v", err, tt.wantErr)
+			}
+
+			var got []float64
+			for _, share := range shares {
+				got = append(got, share.Amount)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitItems = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOrderLines(t *testing.T) {
+	// The latte is ordered on two lines, e.g. one of them with an extra shot
+	lines := []model.OrderItems{
+		{ProductID: 1, Quantity: 2, Price: 4.5},
+		{ProductID: 2, Quantity: 1, Price: 3},
+		{ProductID: 1, Quantity: 2, Price: 5, Modifiers: []string{"extra shot"}},
+	}
+	order := model.Order{Items: lines}
+
+	tests := []struct {
+		name      string
+		items     []model.OrderItems
+		taken     []model.OrderItems
+		remaining []model.OrderItems
+		share     float64
+	}{
+		{
+			name:      "first line",
+			items:     []model.OrderItems{{ProductID: 1, Quantity: 1}},
+			taken:     []model.OrderItems{{ProductID: 1, Quantity: 1, Price: 4.5}},
+			remaining: []model.OrderItems{{ProductID: 1, Quantity: 1, Price: 4.5}, lines[1], lines[2]},
+			share:     4.5,
+		},
+		{
+			name:  "quantity spans the lines",
+			items: []model.OrderItems{{ProductID: 1, Quantity: 3}},
+			taken: []model.OrderItems{
+				{ProductID: 1, Quantity: 2, Price: 4.5},
+				{ProductID: 1, Quantity: 1, Price: 5, Modifiers: []string{"extra shot"}},
+			},
+			remaining: []model.OrderItems{lines[1], {ProductID: 1, Quantity: 1, Price: 5, Modifiers: []string{"extra shot"}}},
+			share:     14,
+		},
+		{
+			name:      "all items",
+			items:     []model.OrderItems{{ProductID: 2, Quantity: 1}, {ProductID: 1, Quantity: 4}},
+			taken:     lines,
+			remaining: nil,
+			share:     22,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			taken := takeItems(lines, tt.items)
+			if !reflect.DeepEqual(taken, tt.taken) {
+				t.Errorf("takeItems = %+v, want %+v", taken, tt.taken)
+			}
+			if share := itemsShare(order, 22, taken); share != tt.share {
+				t.Errorf("itemsShare = %v, want %v", share, tt.share)
+			}
+			if remaining := subtractItems(lines, tt.items); !reflect.DeepEqual(remaining, tt.remaining) {
+				t.Errorf("subtractItems = %+v, want %+v", remaining, tt.remaining)
+			}
+		})
+	}
+
+	// Refunding the lines one after another uses up the ordered quantity
+	remaining := lines
+	for _, refund := range [][]model.OrderItems{{{ProductID: 1, Quantity: 3}}, {{ProductID: 1, Quantity: 1}}} {
+		if !containsItems(remaining, refund) {
+			t.Fatalf("containsItems(%+v, %+v) = false, want true", remaining, refund)
+		}
+		remaining = subtractItems(remaining, refund)
+	}
+	if want := []model.OrderItems{lines[1]}; !reflect.DeepEqual(remaining, want) {
+		t.Errorf("remaining = %+v, want %+v", remaining, want)
+	}
+	if containsItems(remaining, []model.OrderItems{{ProductID: 1, Quantity: 1}}) {
+		t.Errorf("containsItems after all lattes are refunded = true, want false")
+	}
+}
+
+// paymentFixture is an order of two lattes and a muffin, 12.00 in total, made with
+// memory repositories. Every latte takes 2 of the milk in the inventory.
+type paymentFixture struct {
+	orders    *orderService
+	payments  *paymentService
+	inventory InventoryRepo
+	recipes   MenuItemIngredientsRepo
+	orderID   int
+	latte     int
+	muffin    int
+	milk      int
+}
+
+func newPaymentFixture(t *testing.T) *paymentFixture {
+	t.Helper()
+	ctx := context.Background()
+	db := memory.NewDB()
+	repos := OrderRepos{
+		OrderRepo:           memory.NewOrder(db),
+		OrderItemsRepo:      memory.NewOrderItems(db),
+		HistoryRepo:         memory.NewOrderStatusHistory(db),
+		OrderTaxesRepo:      memory.NewOrderTaxes(db),
+		MenuRepo:            memory.NewMenu(db),
+		MenuIngredientsRepo: memory.NewMenuItemIngredients(db),
+		InventoryRepo:       memory.NewInventory(db),
+		LedgerRepo:          memory.NewInventoryTransactions(db),
+		TaxRepo:             memory.NewTaxRate(db),
+		PaymentRepo:         memory.NewPayment(db),
+		ConsumptionRepo:     memory.NewOrderConsumption(db),
+		ClosingRepo:         memory.NewDailyClosing(db),
+		AuditRepo:           memory.NewAudit(db),
+	}
+
+	f := &paymentFixture{
+		orders:    NewOrderService(repos, db, nil),
+		inventory: repos.InventoryRepo,
+		recipes:   repos.MenuIngredientsRepo,
+	}
+	f.payments = NewPaymentService(repos.PaymentRepo, f.orders, repos.InventoryRepo, repos.LedgerRepo,
+		repos.MenuIngredientsRepo, repos.ConsumptionRepo, repos.ClosingRepo, db)
+
+	var err error
+	if f.milk, err = repos.InventoryRepo.Create(ctx, model.Inventory{Name: "Milk", Quantity: 100, Unit: "ml"}); err != nil {
+		t.Fatalf("Create inventory: %v", err)
+	}
+	if f.latte, err = repos.MenuRepo.Create(ctx, model.MenuItem{Name: "Latte", Description: "milk coffee", Category: "coffee", Price: 4.5}); err != nil {
+		t.Fatalf("Create menu item: %v", err)
+	}
+	if f.muffin, err = repos.MenuRepo.Create(ctx, model.MenuItem{Name: "Muffin", Description: "blueberry", Category: "food", Price: 3}); err != nil {
+		t.Fatalf("Create menu item: %v", err)
+	}
+	if err := repos.MenuIngredientsRepo.Create(ctx, model.MenuItemIngredients{MenuID: f.latte, IngredientID: f.milk, Quantity: 2}); err != nil {
+		t.Fatalf("Create recipe: %v", err)
+	}
+
+	f.orderID, err = f.orders.AddOrder(ctx, model.Order{
+		CustomerName: "Ann",
+		Items:        []model.OrderItems{{ProductID: f.latte, Quantity: 2}, {ProductID: f.muffin, Quantity: 1}},
+	})
+	if err != nil {
+		t.Fatalf("AddOrder: %v", err)
+	}
+
+	return f
+}
+
+func (f *paymentFixture) milkQuantity(t *testing.T) int {
+	t.Helper()
+	item, err := f.inventory.Get(context.Background(), f.milk)
+	if err != nil {
+		t.Fatalf("Get inventory: %v", err)
+	}
+	return item.Quantity
+}
+
+func TestAddPaymentChange(t *testing.T) {
+	tests := []struct {
+		name         string
+		payment      model.Payment
+		wantTendered float64
+		wantChange   float64
+		wantErr      error
+	}{
+		{
+			name:         "cash change covers amount and tip",
+			payment:      model.Payment{Tender: model.TenderCash, Amount: 5, Tip: 0.5, Tendered: 10},
+			wantTendered: 10,
+			wantChange:   4.5,
+		},
+		{
+			name:         "exact cash is assumed without tendered amount",
+			payment:      model.Payment{Tender: model.TenderCash, Amount: 5, Tip: 0.5},
+			wantTendered: 5.5,
+		},
+		{
+			name:         "card is charged exactly",
+			payment:      model.Payment{Tender: model.TenderCard, Amount: 12, Tendered: 20},
+			wantTendered: 12,
+		},
+		{
+			name:         "amounts are rounded to cents",
+			payment:      model.Payment{Tender: model.TenderCash, Amount: 3.333, Tip: 0.004, Tendered: 5},
+			wantTendered: 5,
+			wantChange:   1.67,
+		},
+		{
+			name:    "tendered cash does not cover the tip",
+			payment: model.Payment{Tender: model.TenderCash, Amount: 5, Tip: 1, Tendered: 5.5},
+			wantErr: model.ErrNotValidTendered,
+		},
+		{
+			name:    "amount exceeds the balance",
+			payment: model.Payment{Tender: model.TenderCard, Amount: 12.01},
+			wantErr: model.ErrOverpayment,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newPaymentFixture(t)
+
+			got, err := f.payments.AddPayment(context.Background(), f.orderID, tt.payment)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("AddPayment: %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got.Tendered != tt.wantTendered || got.Change != tt.wantChange {
+				t.Errorf("tendered %v, change %v, want %v, %v", got.Tendered, got.Change, tt.wantTendered, tt.wantChange)
+			}
+		})
+	}
+}
+
+func TestRefundOrderPartial(t *testing.T) {
+	ctx := context.Background()
+	f := newPaymentFixture(t)
+
+	card, err := f.payments.AddPayment(ctx, f.orderID, model.Payment{Tender: model.TenderCard, Amount: 7})
+	if err != nil {
+		t.Fatalf("AddPayment: %v", err)
+	}
+	cash, err := f.payments.AddPayment(ctx, f.orderID, model.Payment{Tender: model.TenderCash, Amount: 5})
+	if err != nil {
+		t.Fatalf("AddPayment: %v", err)
+	}
+
+	_, err = f.payments.RefundOrder(ctx, f.orderID, model.RefundRequest{Amount: 1, Reason: "open"})
+	if !errors.Is(err, model.ErrOrderNotClosed) {
+		t.Fatalf("RefundOrder of an open order: %v, want %v", err, model.ErrOrderNotClosed)
+	}
+
+	if _, err := f.orders.CloseOrder(ctx, f.orderID); err != nil {
+		t.Fatalf("CloseOrder: %v", err)
+	}
+	if got := f.milkQuantity(t); got != 96 {
+		t.Fatalf("milk after closing = %d, want 96", got)
+	}
+
+	// The recipe changes after the order consumed its milk
+	if err := f.recipes.Update(ctx, f.latte, model.MenuItemIngredients{MenuID: f.latte, IngredientID: f.milk, Quantity: 5}); err != nil {
+		t.Fatalf("Update recipe: %v", err)
+	}
+
+	type refund struct {
+		amount   float64
+		refundOf int
+	}
+	steps := []struct {
+		name    string
+		req     model.RefundRequest
+		want    []refund
+		wantErr error
+		milk    int
+	}{
+		{
+			name: "item share is taken from the newest payment and restocked as consumed",
+			req:  model.RefundRequest{Items: []model.OrderItems{{ProductID: f.latte, Quantity: 1}}, Restock: true, Reason: "spilled"},
+			want: []refund{{-4.5, cash.ID}},
+			milk: 98,
+		},
+		{
+			name: "amount is spread over the payments",
+			req:  model.RefundRequest{Amount: 3, Reason: "late"},
+			want: []refund{{-0.5, cash.ID}, {-2.5, card.ID}},
+			milk: 98,
+		},
+		{
+			name:    "refunded items cannot be refunded again",
+			req:     model.RefundRequest{Items: []model.OrderItems{{ProductID: f.latte, Quantity: 2}}, Reason: "again"},
+			wantErr: model.ErrRefundItemsExceeded,
+			milk:    98,
+		},
+		{
+			name:    "amount exceeds what is left",
+			req:     model.RefundRequest{Amount: 4.51, Reason: "too much"},
+			wantErr: model.ErrRefundExceedsPaid,
+			milk:    98,
+		},
+		{
+			name:    "payment of another order",
+			req:     model.RefundRequest{PaymentID: card.ID + 100, Reason: "unknown"},
+			wantErr: model.ErrPaymentNotFound,
+			milk:    98,
+		},
+		{
+			name:    "restock of a payment refund without items",
+			req:     model.RefundRequest{PaymentID: card.ID, Restock: true, Reason: "card refund"},
+			wantErr: model.ErrNotValidRestock,
+			milk:    98,
+		},
+		{
+			name:    "restock of an amount",
+			req:     model.RefundRequest{Amount: 1, Restock: true, Reason: "partial"},
+			wantErr: model.ErrNotValidRestock,
+			milk:    98,
+		},
+		{
+			name: "full refund returns the rest",
+			req:  model.RefundRequest{Restock: true, Reason: "closed by mistake"},
+			want: []refund{{-4.5, card.ID}},
+			milk: 100,
+		},
+		{
+			name:    "nothing is left to refund",
+			req:     model.RefundRequest{Reason: "again"},
+			wantErr: model.ErrRefundExceedsPaid,
+			milk:    100,
+		},
+	}
+
+	for _, step := range steps {
+		refunds, err := f.payments.RefundOrder(ctx, f.orderID, step.req)
+		if !errors.Is(err, step.wantErr) {
+			t.Fatalf("%s: RefundOrder: %v, want %v", step.name, err, step.wantErr)
+		}
+
+		var got []refund
+		for _, r := range refunds {
+			got = append(got, refund{r.Amount, r.RefundOf})
+		}
+		if !reflect.DeepEqual(got, step.want) {
+			t.Errorf("%s: refunds = %v, want %v", step.name, got, step.want)
+		}
+		if milk := f.milkQuantity(t); milk != step.milk {
+			t.Errorf("%s: milk = %d, want %d", step.name, milk, step.milk)
+		}
+	}
+
+	_, balance, err := f.payments.RetrievePayments(ctx, f.orderID)
+	if err != nil {
+		t.Fatalf("RetrievePayments: %v", err)
+	}
+	if balance.Paid != 12 || balance.Refunded != 12 || balance.Balance != 12 {
+		t.Errorf("balance = %+v, want 12 paid and refunded", balance)
+	}
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"coffee-shop/internal/model"
+)
+
+// PaymentReader is the part of the payment service used to look up the payments of an order.
+type PaymentReader interface {
+	RetrievePayments(ctx context.Context, orderID int) ([]model.Payment, *model.PaymentBalance, error)
+}
+
+type receiptService struct {
+	Orders   OrderReader
+	Payments PaymentReader
+	MenuRepo MenuRepo
+	Renderer ReceiptRenderer
+}
+
+func NewReceiptService(orders OrderReader, payments PaymentReader, menuRepo MenuRepo, renderer ReceiptRenderer) *receiptService {
+	return &receiptService{
+		Orders:   orders,
+		Payments: payments,
+		MenuRepo: menuRepo,
+		Renderer: renderer,
+	}
+}
+
+// RenderReceipt renders the customer receipt of the order and returns it with its content type.
+// Receipts of open orders are printed as pre-bills with the totals calculated with the current tax rates.
+// The following errors may be returned:
+// - model.ErrNotValidReceiptFormat if the format is not one of text, html, escpos.
+// - model.ErrNoOrder if the order with the specified ID is not found.
+func (s *receiptService) RenderReceipt(ctx context.Context, orderID int, format string) ([]byte, string, error) {
+	if !model.ValidReceiptFormat(format) {
+		return nil, "", model.ErrNotValidReceiptFormat
+	}
+
+	receipt, err := s.receipt(ctx, orderID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return s.Renderer.Receipt(format, *receipt)
+}
+
+// RenderTicket renders the kitchen ticket of the order, which lists the items without prices.
+// The following errors may be returned:
+// - model.ErrNotValidReceiptFormat if the format is not one of text, html, escpos.
+// - model.ErrNoOrder if the order with the specified ID is not found.
+func (s *receiptService) RenderTicket(ctx context.Context, orderID int, format string) ([]byte, string, error) {
+	if !model.ValidReceiptFormat(format) {
+		return nil, "", model.ErrNotValidReceiptFormat
+	}
+
+	receipt, err := s.receipt(ctx, orderID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return s.Renderer.Ticket(format, *receipt)
+}
+
+// receipt collects the order, its items with menu names, totals and payments.
+func (s *receiptService) receipt(ctx context.Context, orderID int) (*model.Receipt, error) {
+	order, err := s.Orders.RetrieveOrder(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	totals, err := s.Orders.RetrieveOrderTotals(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	payments, balance, err := s.Payments.RetrievePayments(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	receipt := &model.Receipt{
+		Order:     *order,
+		Totals:    *totals,
+		Payments:  payments,
+		Balance:   *balance,
+		PrintedAt: time.Now(),
+	}
+
+	for _, item := range order.Items {
+		menuItem, err := s.MenuRepo.Get(ctx, item.ProductID)
+		if errors.Is(err, model.ErrNotFound) {
+			return nil, model.ErrProductNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		receipt.Lines = append(receipt.Lines, model.ReceiptLine{
+			ProductID: item.ProductID,
+			Name:      menuItem.Name,
+			Category:  menuItem.Category,
+			Quantity:  item.Quantity,
+			Modifiers: item.Modifiers,
+			Price:     item.Price,
+			Amount:    roundMoney(item.Price * float64(item.Quantity)),
+		})
+	}
+
+	return receipt, nil
+}
@@ -1,75 +1,323 @@
 package service
 
-// import (
-// 	"sort"
-
-// 	"coffee-shop/internal/repository"
-// 	"coffee-shop/models"
-// )
-
-// type ReportService interface {
-// 	GetTotalSales() (models.TotalSales, error)
-// 	GetPopularItems() ([]models.MenuItem, error)
-// }
-
-// type reportService struct {
-// 	orderRepository     repository.OrderRepository
-// 	menuReposipory      repository.MenuRepository
-// 	inventoryRepository repository.InventoryRepository
-// 	reportRepository    repository.ReportRepository
-// }
-
-// func NewReportService(o repository.OrderRepository, m repository.MenuRepository, i repository.InventoryRepository, r repository.ReportRepository) *reportService {
-// 	if o == nil || m == nil || i == nil || r == nil {
-// 		return nil
-// 	}
-// 	return &reportService{orderRepository: o, menuReposipory: m, inventoryRepository: i, reportRepository: r}
-// }
-
-// func (rs *reportService) GetTotalSales() (models.TotalSales, error) {
-// 	return rs.reportRepository.GetTotalSales()
-// }
-
-// func (rs *reportService) GetPopularItems() ([]models.MenuItem, error) {
-// 	orders, err := rs.orderRepository.GetClosedOrders()
-// 	if err != nil {
-// 		return nil, err
-// 	}
-
-// 	frequencyMap := make(map[string]int)
-// 	for _, order := range orders {
-// 		for _, item := range order.Items {
-// 			frequencyMap[item.ProductID] += item.Quantity
-// 		}
-// 	}
-
-// 	type menuItemCount struct {
-// 		ID    string
-// 		Count int
-// 	}
-
-// 	menuItemsCount := []menuItemCount{}
-// 	for id, count := range frequencyMap {
-// 		menuItemsCount = append(menuItemsCount, menuItemCount{ID: id, Count: count})
-// 	}
-
-// 	sort.Slice(menuItemsCount, func(i, j int) bool {
-// 		return menuItemsCount[i].Count > menuItemsCount[j].Count
-// 	})
-
-// 	topMenuItemsCount := menuItemsCount
-// 	if len(menuItemsCount) > 10 {
-// 		topMenuItemsCount = menuItemsCount[:10]
-// 	}
-
-// 	popularItems := []models.MenuItem{}
-// 	for _, menuItem := range topMenuItemsCount {
-// 		item, err := rs.menuReposipory.GetMenuItemById(menuItem.ID)
-// 		if err != nil {
-// 			return nil, err
-// 		}
-// 		popularItems = append(popularItems, item)
-// 	}
-
-// 	return popularItems, nil
-// }
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"coffee-shop/internal/model"
+)
+
+// maxReportBuckets limits the number of buckets a grouped report may return.
+const maxReportBuckets = 10000
+
+type reportService struct {
+	ReportRepo    ReportRepo
+	InventoryRepo InventoryRepo
+	location      *time.Location
+}
+
+// NewReportService creates the report service. Reports are bucketed by the
+// calendar of the shop location.
+func NewReportService(repo ReportRepo, inventoryRepo InventoryRepo, location *time.Location) *reportService {
+	return &reportService{ReportRepo: repo, InventoryRepo: inventoryRepo, location: location}
+}
+
+// GetTaxReport returns the tax collected within the period, broken down by category and rate.
+// The following errors may be returned:
+// - model.ErrNotValidPeriod if the period start is not before its end.
+func (s *reportService) GetTaxReport(ctx context.Context, period model.Period) ([]model.TaxReportLine, error) {
+	if err := period.Validate(); err != nil {
+		return nil, model.ErrNotValidPeriod
+	}
+
+	return s.ReportRepo.TaxSummary(ctx, period)
+}
+
+// GetPaymentReport returns the payments, tips and refunds made within the period by tender.
+// The following errors may be returned:
+// - model.ErrNotValidPeriod if the period start is not before its end.
+func (s *reportService) GetPaymentReport(ctx context.Context, period model.Period) ([]model.PaymentReportLine, error) {
+	if err := period.Validate(); err != nil {
+		return nil, model.ErrNotValidPeriod
+	}
+
+	return s.ReportRepo.PaymentSummary(ctx, period)
+}
+
+// GetSalesReport returns the revenue, order count, average ticket and item count
+// of the orders sold within the period, per hour, day, week or month.
+// The following errors may be returned:
+// - model.ErrNotValidPeriod if the period start is not before its end.
+// - model.ErrNotValidGroupBy if the grouping is not one of hour, day, week, month.
+// - model.ErrNotValidOrderStatus if the status is not one of open, closed, all.
+// - model.ErrTooManyBuckets if the period is too long for the grouping.
+func (s *reportService) GetSalesReport(ctx context.Context, q model.SalesQuery) ([]model.SalesBucket, error) {
+	q.Location = s.location
+	if err := q.Validate(); err != nil {
+		return nil, err
+	}
+
+	if bucketCount(q.Period, q.GroupBy) > maxReportBuckets {
+		return nil, model.ErrTooManyBuckets
+	}
+
+	buckets, err := s.ReportRepo.SalesSummary(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range buckets {
+		buckets[i].Revenue = roundMoney(buckets[i].Revenue)
+		buckets[i].Refunds = roundMoney(buckets[i].Refunds)
+		buckets[i].NetRevenue = roundMoney(buckets[i].Revenue - buckets[i].Refunds)
+		if buckets[i].Orders > 0 {
+			buckets[i].AverageTicket = roundMoney(buckets[i].Revenue / float64(buckets[i].Orders))
+		}
+	}
+
+	return buckets, nil
+}
+
+// GetPopularItems ranks the menu items sold in the orders closed within the period
+// by quantity or revenue, with their share and trend against the previous period.
+// The following errors may be returned:
+// - model.ErrNotValidPeriod if the period start is not before its end.
+// - model.ErrNotValidLimit if the limit is not between 1 and model.MaxPopularItems.
+// - model.ErrNotValidRankBy if the ranking is not one of quantity, revenue.
+func (s *reportService) GetPopularItems(ctx context.Context, q model.PopularItemsQuery) ([]model.PopularItem, error) {
+	if err := q.Validate(); err != nil {
+		return nil, err
+	}
+
+	items, err := s.ReportRepo.PopularItems(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range items {
+		item := &items[i]
+		item.Revenue = roundMoney(item.Revenue)
+		item.PreviousRevenue = roundMoney(item.PreviousRevenue)
+		item.Share = roundMoney(item.Share)
+
+		current, previous := float64(item.Quantity), float64(item.PreviousQuantity)
+		if q.RankBy == model.RankByRevenue {
+			current, previous = item.Revenue, item.PreviousRevenue
+		}
+		if previous > 0 {
+			trend := roundMoney((current - previous) * 100 / previous)
+			item.Trend = &trend
+		}
+	}
+
+	return items, nil
+}
+
+// GetIngredientUsage returns the usage of every ingredient within the period and per day.
+// The following errors may be returned:
+// - model.ErrNotValidPeriod if the period start is not before its end.
+// - model.ErrNotValidUsageSource if the source is not one of ledger, orders.
+func (s *reportService) GetIngredientUsage(ctx context.Context, q model.IngredientUsageQuery) ([]model.IngredientUsageSummary, error) {
+	q.Location = s.location
+	if err := q.Validate(); err != nil {
+		return nil, err
+	}
+
+	usage, err := s.dailyUsage(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	var summaries []model.IngredientUsageSummary
+	for _, u := range usage {
+		if len(summaries) == 0 || summaries[len(summaries)-1].IngredientID != u.IngredientID {
+			summaries = append(summaries, model.IngredientUsageSummary{
+				IngredientUsage: model.IngredientUsage{IngredientID: u.IngredientID, Name: u.Name, Unit: u.Unit},
+			})
+		}
+
+		summary := &summaries[len(summaries)-1]
+		summary.Quantity += u.Quantity
+		summary.Days = append(summary.Days, model.DailyUsage{Day: u.Day, Quantity: u.Quantity})
+	}
+
+	return summaries, nil
+}
+
+// GetInventoryForecast projects when every ingredient runs out from the moving average
+// of its daily usage in the ledger, and suggests the quantity to reorder so that the
+// stock lasts the delivery lead time and the cover days after it.
+// The following errors may be returned:
+// - model.ErrNotValidForecast if the window, lead time or cover is out of range.
+func (s *reportService) GetInventoryForecast(ctx context.Context, q model.ForecastQuery) ([]model.InventoryForecast, error) {
+	if err := q.Validate(); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().In(s.location)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, s.location)
+	window := model.IngredientUsageQuery{
+		Period:   model.Period{From: today.AddDate(0, 0, -q.Window), To: today},
+		Source:   model.UsageSourceLedger,
+		Location: s.location,
+	}
+
+	usage, err := s.dailyUsage(ctx, window)
+	if err != nil {
+		return nil, err
+	}
+
+	used := make(map[int]int)
+	for _, u := range usage {
+		used[u.IngredientID] += u.Quantity
+	}
+
+	items, err := s.InventoryRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	forecasts := make([]model.InventoryForecast, 0, len(items))
+	for _, item := range items {
+		forecasts = append(forecasts, forecast(item, float64(used[item.IngredientID])/float64(q.Window), q, now))
+	}
+
+	// Ingredients that run out first come first, unused ones last
+	sort.SliceStable(forecasts, func(i, j int) bool {
+		a, b := forecasts[i].DaysUntilStockout, forecasts[j].DaysUntilStockout
+		switch {
+		case a == nil:
+			return false
+		case b == nil:
+			return true
+		default:
+			return *a < *b
+		}
+	})
+
+	return forecasts, nil
+}
+
+// forecast projects the stockout of the inventory item with the given average daily usage.
+func forecast(item model.Inventory, average float64, q model.ForecastQuery, now time.Time) model.InventoryForecast {
+	f := model.InventoryForecast{
+		IngredientID:      item.IngredientID,
+		Name:              item.Name,
+		Unit:              item.Unit,
+		Quantity:          item.Quantity,
+		AverageDailyUsage: roundMoney(average),
+	}
+
+	if average <= 0 {
+		return f
+	}
+
+	days := roundMoney(float64(item.Quantity) / average)
+	stockout := now.Add(time.Duration(days * float64(24*time.Hour)))
+	f.DaysUntilStockout = &days
+	f.StockoutDate = &stockout
+
+	needed := int(math.Ceil(average * float64(q.LeadTime+q.Cover)))
+	if needed > item.Quantity {
+		f.ReorderQuantity = needed - item.Quantity
+	}
+
+	return f
+}
+
+// GetHeatmap returns the orders placed within the period on a weekday × hour grid of the
+// shop calendar, with the revenue, the average number of orders in such an hour and the
+// average preparation time, so that shifts can be planned around the rush hours.
+// The following errors may be returned:
+// - model.ErrNotValidPeriod if the period start is not before its end.
+func (s *reportService) GetHeatmap(ctx context.Context, period model.Period) (*model.Heatmap, error) {
+	if err := period.Validate(); err != nil {
+		return nil, model.ErrNotValidPeriod
+	}
+
+	cells, err := s.ReportRepo.Heatmap(ctx, period, s.location)
+	if err != nil {
+		return nil, err
+	}
+
+	found := make(map[[2]int]model.HeatmapCell, len(cells))
+	for _, c := range cells {
+		found[[2]int{int(c.Weekday), c.Hour}] = c
+	}
+
+	occurrences := weekdayCount(period, s.location)
+	heatmap := &model.Heatmap{Period: period}
+
+	var prepOrders int
+	var prepTime time.Duration
+	for i := 0; i < 7; i++ {
+		weekday := time.Weekday((i + 1) % 7) // Monday first
+		for hour := 0; hour < 24; hour++ {
+			cell, ok := found[[2]int{int(weekday), hour}]
+			if !ok {
+				cell = model.HeatmapCell{Weekday: weekday, Hour: hour}
+			}
+
+			cell.Revenue = roundMoney(cell.Revenue)
+			if occurrences[weekday] > 0 {
+				cell.AverageOrders = roundMoney(float64(cell.Orders) / float64(occurrences[weekday]))
+			}
+			if cell.PrepOrders > 0 {
+				cell.AveragePrepTime = (cell.PrepTime / time.Duration(cell.PrepOrders)).Round(time.Second)
+			}
+
+			heatmap.Orders += cell.Orders
+			heatmap.Revenue += cell.Revenue
+			prepOrders += cell.PrepOrders
+			prepTime += cell.PrepTime
+			heatmap.Cells = append(heatmap.Cells, cell)
+		}
+	}
+
+	heatmap.Revenue = roundMoney(heatmap.Revenue)
+	if prepOrders > 0 {
+		heatmap.AveragePrepTime = (prepTime / time.Duration(prepOrders)).Round(time.Second)
+	}
+
+	return heatmap, nil
+}
+
+// weekdayCount counts how many times every weekday starts within the period in the location.
+func weekdayCount(period model.Period, location *time.Location) map[time.Weekday]int {
+	count := make(map[time.Weekday]int, 7)
+
+	from := period.From.In(location)
+	day := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, location)
+	if day.Before(from) {
+		// A partial first day still counts, its hours belong to the period
+		count[day.Weekday()]++
+		day = day.AddDate(0, 0, 1)
+	}
+
+	for ; day.Before(period.To); day = day.AddDate(0, 0, 1) {
+		count[day.Weekday()]++
+	}
+
+	return count
+}
+
+func (s *reportService) dailyUsage(ctx context.Context, q model.IngredientUsageQuery) ([]model.IngredientDailyUsage, error) {
+	if q.Source == model.UsageSourceOrders {
+		return s.ReportRepo.RecipeUsage(ctx, q)
+	}
+	return s.ReportRepo.LedgerUsage(ctx, q)
+}
+
+// bucketCount estimates the number of buckets of the grouping within the period.
+func bucketCount(period model.Period, groupBy string) int {
+	size := map[string]time.Duration{
+		model.GroupByHour:  time.Hour,
+		model.GroupByDay:   24 * time.Hour,
+		model.GroupByWeek:  7 * 24 * time.Hour,
+		model.GroupByMonth: 28 * 24 * time.Hour,
+	}[groupBy]
+
+	return int(period.To.Sub(period.From)/size) + 1
+}
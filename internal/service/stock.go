@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+
+	"coffee-shop/internal/model"
+)
+
+// stock moves ingredients in and out of the inventory. Every change of the
+// quantity is recorded in the inventory ledger with the given reason.
+type stock struct {
+	InventoryRepo       InventoryRepo
+	LedgerRepo          InventoryTransactionsRepo
+	MenuIngredientsRepo MenuItemIngredientsRepo
+	ConsumptionRepo     OrderConsumptionRepo
+}
+
+// consume writes off the ingredients used by the items of the order and returns the
+// written off quantity per ingredient ID. The recipes of the items are recorded as
+// the consumption of the order, replacing what was recorded before.
+// The following errors may be returned:
+// - model.ErrNotEnoughInventoryQuantity if the stock of an ingredient is insufficient.
+func (s stock) consume(ctx context.Context, orderID int, items []model.OrderItems, reason string) (map[int]int, error) {
+	consumption, err := s.recipes(ctx, items)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.ConsumptionRepo.Save(ctx, orderID, consumption); err != nil {
+		return nil, err
+	}
+
+	return s.apply(ctx, items, consumption, -1, reason)
+}
+
+// restock puts the ingredients that the items of the order consumed back into the
+// inventory. Orders without recorded consumption, written off before it was
+// recorded, are restocked by the current recipes.
+func (s stock) restock(ctx context.Context, orderID int, items []model.OrderItems, reason string) error {
+	consumption, err := s.ConsumptionRepo.GetAllWithID(ctx, orderID)
+	if err != nil {
+		return err
+	}
+
+	if len(consumption) == 0 {
+		consumption, err = s.recipes(ctx, items)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = s.apply(ctx, items, consumption, 1, reason)
+	return err
+}
+
+// recipes returns the ingredients used per unit of every product of the items.
+func (s stock) recipes(ctx context.Context, items []model.OrderItems) ([]model.OrderConsumption, error) {
+	var consumption []model.OrderConsumption
+	seen := make(map[int]bool)
+	for _, item := range items {
+		if seen[item.ProductID] {
+			continue
+		}
+		seen[item.ProductID] = true
+
+		ingredients, err := s.MenuIngredientsRepo.GetAllWithID(ctx, item.ProductID)
+		if err != nil {
+			return nil, err
+		}
+
+		perUnit := make(map[int]int)
+		var ingredientIDs []int
+		for _, ingredient := range ingredients {
+			if ingredient.Quantity == 0 {
+				continue
+			}
+			if _, ok := perUnit[ingredient.IngredientID]; !ok {
+				ingredientIDs = append(ingredientIDs, ingredient.IngredientID)
+			}
+			perUnit[ingredient.IngredientID] += ingredient.Quantity
+		}
+
+		for _, ingredientID := range ingredientIDs {
+			consumption = append(consumption, model.OrderConsumption{
+				ProductID:    item.ProductID,
+				IngredientID: ingredientID,
+				Quantity:     perUnit[ingredientID],
+			})
+		}
+	}
+
+	return consumption, nil
+}
+
+func (s stock) apply(ctx context.Context, items []model.OrderItems, consumption []model.OrderConsumption, sign int, reason string) (map[int]int, error) {
+	required := make(map[int]int)
+	var ingredientIDs []int
+	for _, item := range items {
+		for _, c := range consumption {
+			if c.ProductID != item.ProductID {
+				continue
+			}
+			if _, ok := required[c.IngredientID]; !ok {
+				ingredientIDs = append(ingredientIDs, c.IngredientID)
+			}
+			required[c.IngredientID] += c.Quantity * item.Quantity
+		}
+	}
+
+	for _, ingredientID := range ingredientIDs {
+		delta := sign * required[ingredientID]
+		if delta == 0 {
+			continue
+		}
+
+		ok, err := s.InventoryRepo.AdjustQuantity(ctx, ingredientID, delta)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, model.ErrNotEnoughInventoryQuantity
+		}
+
+		err = s.LedgerRepo.Create(ctx, model.InventoryTransactions{
+			IngredientId:   ingredientID,
+			QuantityChange: delta,
+			Reason:         reason,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return required, nil
+}
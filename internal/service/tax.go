@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+
+	"coffee-shop/internal/model"
+)
+
+type taxService struct {
+	TaxRepo TaxRateRepo
+}
+
+func NewTaxService(repo TaxRateRepo) *taxService {
+	return &taxService{TaxRepo: repo}
+}
+
+// RetrieveTaxRates returns the tax rates of all categories.
+func (s *taxService) RetrieveTaxRates(ctx context.Context) ([]model.TaxRate, error) {
+	return s.TaxRepo.GetAll(ctx)
+}
+
+// RetrieveTaxRate returns the tax rate of the category.
+// The following errors may be returned:
+// - model.ErrTaxRateNotFound if the category has no tax rate.
+func (s *taxService) RetrieveTaxRate(ctx context.Context, category string) (*model.TaxRate, error) {
+	rate, err := s.TaxRepo.Get(ctx, category)
+	if errors.Is(err, model.ErrNotFound) {
+		return nil, model.ErrTaxRateNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &rate, nil
+}
+
+// SetTaxRate creates or replaces the tax rate of a category.
+// Orders closed before the change keep the tax lines computed at closing time.
+func (s *taxService) SetTaxRate(ctx context.Context, rate model.TaxRate) error {
+	if err := rate.Validate(); err != nil {
+		return err
+	}
+
+	return s.TaxRepo.Save(ctx, rate)
+}
+
+// DeleteTaxRate removes the tax rate of the category.
+// Items of that category are taxed with the default rate afterwards.
+func (s *taxService) DeleteTaxRate(ctx context.Context, category string) error {
+	deleted, err := s.TaxRepo.Delete(ctx, category)
+	if err != nil {
+		return err
+	}
+
+	if !deleted {
+		return model.ErrTaxRateNotFound
+	}
+
+	return nil
+}
+
+// taxableLine is the amount charged for the items of a single menu category.
+type taxableLine struct {
+	Category string
+	Amount   float64
+}
+
+// calculateTotals builds the receipt totals of the order lines.
+//
+// The discount is spread over the categories in proportion to their amount, so a
+// category with a lower tax rate takes its share of the discount. Categories without
+// their own rate use the default rate, or are not taxed if there is no default rate.
+// Inclusive taxes are extracted from the discounted amount, exclusive taxes are added on top.
+func calculateTotals(lines []taxableLine, discount float64, rates []model.TaxRate) model.OrderTotals {
+	rateByCategory := make(map[string]model.TaxRate, len(rates))
+	for _, r := range rates {
+		rateByCategory[r.Category] = r
+	}
+
+	amounts := make(map[string]float64)
+	var categories []string
+	var subtotal float64
+	for _, l := range lines {
+		if _, ok := amounts[l.Category]; !ok {
+			categories = append(categories, l.Category)
+		}
+		amounts[l.Category] += l.Amount
+		subtotal += l.Amount
+	}
+	sort.Strings(categories)
+
+	subtotal = roundMoney(subtotal)
+	discount = roundMoney(math.Min(math.Max(discount, 0), subtotal))
+
+	totals := model.OrderTotals{
+		Subtotal:      subtotal,
+		DiscountTotal: discount,
+	}
+
+	var exclusiveTax float64
+	remainingDiscount := discount
+	for i, category := range categories {
+		amount := roundMoney(amounts[category])
+
+		// The last category takes the rounding remainder of the discount
+		share := remainingDiscount
+		if i < len(categories)-1 && subtotal > 0 {
+			share = roundMoney(discount * amount / subtotal)
+		}
+		remainingDiscount = roundMoney(remainingDiscount - share)
+		net := roundMoney(amount - share)
+
+		rate, ok := rateByCategory[category]
+		if !ok {
+			rate, ok = rateByCategory[model.DefaultTaxCategory]
+		}
+		if !ok || rate.Rate == 0 {
+			continue
+		}
+
+		line := model.TaxLine{
+			Category:  category,
+			Rate:      rate.Rate,
+			Inclusive: rate.Inclusive,
+		}
+		if rate.Inclusive {
+			line.TaxAmount = roundMoney(net - net/(1+rate.Rate))
+			line.TaxableAmount = roundMoney(net - line.TaxAmount)
+		} else {
+			line.TaxableAmount = net
+			line.TaxAmount = roundMoney(net * rate.Rate)
+			exclusiveTax += line.TaxAmount
+		}
+
+		totals.TaxTotal += line.TaxAmount
+		totals.Taxes = append(totals.Taxes, line)
+	}
+
+	totals.TaxTotal = roundMoney(totals.TaxTotal)
+	totals.Total = roundMoney(subtotal - discount + exclusiveTax)
+
+	return totals
+}
+
+// roundMoney rounds the amount to cents, halves away from zero.
+func roundMoney(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
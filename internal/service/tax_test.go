@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"coffee-shop/internal/model"
+	"coffee-shop/internal/repository/memory"
+)
+
+func TestCalculateTotals(t *testing.T) {
+	exclusive := model.TaxRate{Category: model.DefaultTaxCategory, Rate: 0.1}
+	inclusiveFood := model.TaxRate{Category: "food", Rate: 0.2, Inclusive: true}
+
+	tests := []struct {
+		name     string
+		lines    []taxableLine
+		discount float64
+		rates    []model.TaxRate
+		want     model.OrderTotals
+	}{
+		{
+			name:  "no rates",
+			lines: []taxableLine{{Category: "coffee", Amount: 7}},
+			want:  model.OrderTotals{Subtotal: 7, Total: 7},
+		},
+		{
+			name:  "exclusive default rate",
+			lines: []taxableLine{{Category: "coffee", Amount: 3.5}, {Category: "coffee", Amount: 3.5}},
+			rates: []model.TaxRate{exclusive},
+			want: model.OrderTotals{
+				Subtotal: 7, TaxTotal: 0.7, Total: 7.7,
+				Taxes: []model.TaxLine{{Category: "coffee", Rate: 0.1, TaxableAmount: 7, TaxAmount: 0.7}},
+			},
+		},
+		{
+			name:  "inclusive rate is extracted",
+			lines: []taxableLine{{Category: "food", Amount: 12}},
+			rates: []model.TaxRate{inclusiveFood},
+			want: model.OrderTotals{
+				Subtotal: 12, TaxTotal: 2, Total: 12,
+				Taxes: []model.TaxLine{{Category: "food", Rate: 0.2, Inclusive: true, TaxableAmount: 10, TaxAmount: 2}},
+			},
+		},
+		{
+			name:     "category rate overrides the default and takes its share of the discount",
+			lines:    []taxableLine{{Category: "food", Amount: 5}, {Category: "coffee", Amount: 10}},
+			discount: 3,
+			rates:    []model.TaxRate{exclusive, inclusiveFood},
+			want: model.OrderTotals{
+				Subtotal: 15, DiscountTotal: 3, TaxTotal: 1.47, Total: 12.8,
+				Taxes: []model.TaxLine{
+					{Category: "coffee", Rate: 0.1, TaxableAmount: 8, TaxAmount: 0.8},
+					{Category: "food", Rate: 0.2, Inclusive: true, TaxableAmount: 3.33, TaxAmount: 0.67},
+				},
+			},
+		},
+		{
+			name:     "last category takes the rounding remainder of the discount",
+			lines:    []taxableLine{{Category: "a", Amount: 1}, {Category: "b", Amount: 1}, {Category: "c", Amount: 1}},
+			discount: 1,
+			rates:    []model.TaxRate{exclusive},
+			want: model.OrderTotals{
+				Subtotal: 3, DiscountTotal: 1, TaxTotal: 0.21, Total: 2.21,
+				Taxes: []model.TaxLine{
+					{Category: "a", Rate: 0.1, TaxableAmount: 0.67, TaxAmount: 0.07},
+					{Category: "b", Rate: 0.1, TaxableAmount: 0.67, TaxAmount: 0.07},
+					{Category: "c", Rate: 0.1, TaxableAmount: 0.66, TaxAmount: 0.07},
+				},
+			},
+		},
+		{
+			name:  "zero rate is not taxed",
+			lines: []taxableLine{{Category: "water", Amount: 2}, {Category: "coffee", Amount: 4}},
+			rates: []model.TaxRate{exclusive, {Category: "water"}},
+			want: model.OrderTotals{
+				Subtotal: 6, TaxTotal: 0.4, Total: 6.4,
+				Taxes: []model.TaxLine{{Category: "coffee", Rate: 0.1, TaxableAmount: 4, TaxAmount: 0.4}},
+			},
+		},
+		{
+			name:  "tax is rounded half away from zero",
+			lines: []taxableLine{{Category: "coffee", Amount: 0.5}},
+			rates: []model.TaxRate{{Category: model.DefaultTaxCategory, Rate: 0.05}},
+			want: model.OrderTotals{
+				Subtotal: 0.5, TaxTotal: 0.03, Total: 0.53,
+				Taxes: []model.TaxLine{{Category: "coffee", Rate: 0.05, TaxableAmount: 0.5, TaxAmount: 0.03}},
+			},
+		},
+		{
+			name:     "discount is capped at the subtotal",
+			lines:    []taxableLine{{Category: "coffee", Amount: 5}},
+			discount: 8,
+			rates:    []model.TaxRate{exclusive},
+			want: model.OrderTotals{
+				Subtotal: 5, DiscountTotal: 5,
+				Taxes: []model.TaxLine{{Category: "coffee", Rate: 0.1}},
+			},
+		},
+		{
+			name:     "negative discount is ignored",
+			lines:    []taxableLine{{Category: "coffee", Amount: 5}},
+			discount: -2,
+			want:     model.OrderTotals{Subtotal: 5, Total: 5},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := calculateTotals(tt.lines, tt.discount, tt.rates)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("calculateTotals =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRoundMoney(t *testing.T) {
+	tests := []struct {
+		amount float64
+		want   float64
+	}{
+		{1.004, 1},
+		{1.006, 1.01},
+		{0.125, 0.13},
+		{0.375, 0.38},
+		{-0.125, -0.13},
+	}
+
+	for _, tt := range tests {
+		if got := roundMoney(tt.amount); got != tt.want {
+			t.Errorf("roundMoney(%v) = %v, want %v", tt.amount, got, tt.want)
+		}
+	}
+}
+
+func TestOrderServiceCalculateTotals(t *testing.T) {
+	ctx := context.Background()
+	db := memory.NewDB()
+	s := &orderService{OrderRepos: OrderRepos{
+		MenuRepo: memory.NewMenu(db),
+		TaxRepo:  memory.NewTaxRate(db),
+	}}
+
+	latte, err := s.MenuRepo.Create(ctx, model.MenuItem{Name: "Latte", Description: "milk coffee", Category: "coffee", Price: 4.5})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	muffin, err := s.MenuRepo.Create(ctx, model.MenuItem{Name: "Muffin", Description: "blueberry", Category: "food", Price: 3})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	for _, rate := range []model.TaxRate{{Category: model.DefaultTaxCategory, Rate: 0.1}, {Category: "food", Rate: 0.2, Inclusive: true}} {
+		if err := s.TaxRepo.Save(ctx, rate); err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+	}
+
+	order := model.Order{
+		Items: []model.OrderItems{
+			{ProductID: latte, Quantity: 2, Price: 4.5},
+			{ProductID: muffin, Quantity: 2, Price: 3},
+		},
+	}
+	order.DiscountTotal = 1.5
+	got, err := s.calculateTotals(ctx, order)
+	if err != nil {
+		t.Fatalf("calculateTotals: %v", err)
+	}
+
+	// The items are priced at the price stored with them and grouped by the category of the product
+	want := calculateTotals([]taxableLine{{Category: "coffee", Amount: 9}, {Category: "food", Amount: 6}}, 1.5, []model.TaxRate{
+		{Category: model.DefaultTaxCategory, Rate: 0.1}, {Category: "food", Rate: 0.2, Inclusive: true},
+	})
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("calculateTotals =\n%+v\nwant\n%+v", got, want)
+	}
+	if got.Total != 14.31 {
+		t.Errorf("Total = %v, want 14.31", got.Total)
+	}
+
+	order.Items = append(order.Items, model.OrderItems{ProductID: muffin + 100, Quantity: 1, Price: 1})
+	if _, err := s.calculateTotals(ctx, order); !errors.Is(err, model.ErrProductNotFound) {
+		t.Errorf("calculateTotals with a missing product: %v, want %v", err, model.ErrProductNotFound)
+	}
+}
@@ -0,0 +1,14 @@
+package dto
+
+import "coffee-shop/internal/model"
+
+// AuditRequest holds the query parameters of the audit log besides the period.
+type AuditRequest struct {
+	Entity string `form:"entity"`
+	ID     int    `form:"id"`
+	Limit  int    `form:"limit,default=100"`
+}
+
+func (r AuditRequest) ToDomain(period model.Period) model.AuditQuery {
+	return model.AuditQuery{EntityType: r.Entity, EntityID: r.ID, Period: period, Limit: r.Limit}
+}
@@ -0,0 +1,48 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+
+	"coffee-shop/internal/model"
+)
+
+// AuditEntryResponse is an entry of the audit log. Before is left out for a created
+// record and After for a deleted one.
+type AuditEntryResponse struct {
+	ID         int             `json:"id"`
+	ActorID    int             `json:"actor_id,omitempty"`
+	Actor      string          `json:"actor"`
+	Action     string          `json:"action"`
+	EntityType string          `json:"entity"`
+	EntityID   int             `json:"entity_id"`
+	Before     json.RawMessage `json:"before,omitempty"`
+	After      json.RawMessage `json:"after,omitempty"`
+	RequestID  string          `json:"request_id,omitempty"`
+	IP         string          `json:"ip,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+func NewAuditEntryResponse(e model.AuditEntry) AuditEntryResponse {
+	return AuditEntryResponse{
+		ID:         e.ID,
+		ActorID:    e.ActorID,
+		Actor:      e.Actor,
+		Action:     e.Action,
+		EntityType: e.EntityType,
+		EntityID:   e.EntityID,
+		Before:     document(e.Before),
+		After:      document(e.After),
+		RequestID:  e.RequestID,
+		IP:         e.IP,
+		CreatedAt:  e.CreatedAt,
+	}
+}
+
+// document returns nil for a missing record, which file storages may keep as JSON null.
+func document(raw json.RawMessage) json.RawMessage {
+	if string(raw) == "null" {
+		return nil
+	}
+	return raw
+}
@@ -0,0 +1,31 @@
+package dto
+
+import "coffee-shop/internal/model"
+
+type LoginRequest struct {
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+// StaffRequest creates a staff member with a password, an API key or both.
+type StaffRequest struct {
+	Username string `json:"username" binding:"required,max=50"`
+	Role     string `json:"role" binding:"required,oneof=barista manager admin"`
+	Password string `json:"password" binding:"omitempty,min=8"`
+	APIKey   bool   `json:"api_key"`
+}
+
+func (r *StaffRequest) ToDomain() model.Staff {
+	return model.Staff{
+		Username: r.Username,
+		Role:     r.Role,
+	}
+}
+
+// StaffUpdateRequest changes the role or the credentials of a staff member. Fields
+// left empty are kept.
+type StaffUpdateRequest struct {
+	Role      string `json:"role" binding:"omitempty,oneof=barista manager admin"`
+	Password  string `json:"password" binding:"omitempty,min=8"`
+	NewAPIKey bool   `json:"new_api_key"`
+}
@@ -0,0 +1,57 @@
+package dto
+
+import (
+	"time"
+
+	"coffee-shop/internal/model"
+)
+
+type TokenResponse struct {
+	AccessToken string        `json:"access_token"`
+	TokenType   string        `json:"token_type"`
+	ExpiresAt   time.Time     `json:"expires_at"`
+	Staff       PrincipalInfo `json:"staff"`
+}
+
+type PrincipalInfo struct {
+	ID       int    `json:"staff_id"`
+	Username string `json:"username"`
+	Role     string `json:"role"`
+}
+
+func NewTokenResponse(t model.Token) TokenResponse {
+	return TokenResponse{
+		AccessToken: t.Value,
+		TokenType:   "Bearer",
+		ExpiresAt:   t.ExpiresAt,
+		Staff: PrincipalInfo{
+			ID:       t.Principal.StaffID,
+			Username: t.Principal.Username,
+			Role:     t.Principal.Role,
+		},
+	}
+}
+
+// StaffResponse describes a staff member without the hashes of its credentials.
+// APIKey is set only in the response that issued the key.
+type StaffResponse struct {
+	ID          int       `json:"staff_id"`
+	Username    string    `json:"username"`
+	Role        string    `json:"role"`
+	HasPassword bool      `json:"has_password"`
+	HasAPIKey   bool      `json:"has_api_key"`
+	APIKey      string    `json:"api_key,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func NewStaffResponse(s model.Staff, apiKey string) StaffResponse {
+	return StaffResponse{
+		ID:          s.ID,
+		Username:    s.Username,
+		Role:        s.Role,
+		HasPassword: s.PasswordHash != "",
+		HasAPIKey:   s.APIKeyHash != "",
+		APIKey:      apiKey,
+		CreatedAt:   s.CreatedAt,
+	}
+}
@@ -0,0 +1,25 @@
+package dto
+
+import (
+	"time"
+
+	"coffee-shop/internal/model"
+)
+
+type ClosingRequest struct {
+	BusinessDate string  `json:"business_date"`
+	OpeningFloat float64 `json:"opening_float"`
+	CountedCash  float64 `json:"counted_cash"`
+	Notes        string  `json:"notes"`
+}
+
+// ToDomain converts the request. The business date is parsed by the caller,
+// the zero time stands for today.
+func (r *ClosingRequest) ToDomain(date time.Time) model.ClosingRequest {
+	return model.ClosingRequest{
+		BusinessDate: date,
+		OpeningFloat: r.OpeningFloat,
+		CountedCash:  r.CountedCash,
+		Notes:        r.Notes,
+	}
+}
@@ -0,0 +1,123 @@
+package dto
+
+import (
+	"time"
+
+	report "coffee-shop/internal/transport/dto/report"
+
+	"coffee-shop/internal/model"
+)
+
+// Cash drawer states of the reconciliation
+const (
+	CashBalanced = "balanced"
+	CashOver     = "over"
+	CashShort    = "short"
+)
+
+type ClosingResponse struct {
+	ID           int                            `json:"id"`
+	BusinessDate string                         `json:"business_date"`
+	Period       report.PeriodResponse          `json:"period"`
+	Orders       ClosingOrdersResponse          `json:"orders"`
+	Sales        ClosingSalesResponse           `json:"sales"`
+	Taxes        []report.TaxReportLineItem     `json:"taxes"`
+	Payments     []report.PaymentReportLineItem `json:"payments"`
+	Cash         ClosingCashResponse            `json:"cash"`
+	Notes        string                         `json:"notes"`
+	ClosedAt     time.Time                      `json:"closed_at"`
+}
+
+type ClosingOrdersResponse struct {
+	Closed     int                 `json:"closed"`
+	Open       int                 `json:"open"`
+	OpenOrders []OpenOrderResponse `json:"open_orders"`
+}
+
+type OpenOrderResponse struct {
+	ID           int       `json:"id"`
+	CustomerName string    `json:"customer_name"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type ClosingSalesResponse struct {
+	Gross     float64 `json:"gross"`
+	Discounts float64 `json:"discounts"`
+	Net       float64 `json:"net"`
+	Taxes     float64 `json:"taxes"`
+	Total     float64 `json:"total"`
+	Tips      float64 `json:"tips"`
+	Refunds   int     `json:"refunds"`
+	Refunded  float64 `json:"refunded"`
+}
+
+type ClosingCashResponse struct {
+	OpeningFloat float64 `json:"opening_float"`
+	Expected     float64 `json:"expected"`
+	Counted      float64 `json:"counted"`
+	Difference   float64 `json:"difference"`
+	Status       string  `json:"status"`
+}
+
+func NewClosingResponse(c model.DailyClosing) ClosingResponse {
+	res := ClosingResponse{
+		ID:           c.ID,
+		BusinessDate: c.BusinessDate.Format(time.DateOnly),
+		Period:       report.NewPeriodResponse(c.Period),
+		Orders: ClosingOrdersResponse{
+			Closed:     c.ClosedOrders,
+			Open:       len(c.OpenOrders),
+			OpenOrders: []OpenOrderResponse{},
+		},
+		Sales: ClosingSalesResponse{
+			Gross:     c.GrossSales,
+			Discounts: c.Discounts,
+			Net:       c.NetSales,
+			Taxes:     c.Taxes,
+			Total:     c.Total,
+			Tips:      c.Tips,
+			Refunds:   c.Refunds,
+			Refunded:  c.Refunded,
+		},
+		Taxes:    report.NewTaxReportResponse(c.Period, c.TaxLines).Lines,
+		Payments: report.NewPaymentReportResponse(c.Period, c.Tenders).Lines,
+		Cash: ClosingCashResponse{
+			OpeningFloat: c.OpeningFloat,
+			Expected:     c.ExpectedCash,
+			Counted:      c.CountedCash,
+			Difference:   c.CashDifference,
+			Status:       cashStatus(c.CashDifference),
+		},
+		Notes:    c.Notes,
+		ClosedAt: c.CreatedAt,
+	}
+
+	for _, o := range c.OpenOrders {
+		res.Orders.OpenOrders = append(res.Orders.OpenOrders, OpenOrderResponse{
+			ID:           o.ID,
+			CustomerName: o.CustomerName,
+			CreatedAt:    o.CreatedAt,
+		})
+	}
+
+	return res
+}
+
+func NewClosingsResponse(closings []model.DailyClosing) []ClosingResponse {
+	res := []ClosingResponse{}
+	for _, c := range closings {
+		res = append(res, NewClosingResponse(c))
+	}
+	return res
+}
+
+func cashStatus(difference float64) string {
+	switch {
+	case difference > 0:
+		return CashOver
+	case difference < 0:
+		return CashShort
+	default:
+		return CashBalanced
+	}
+}
@@ -0,0 +1,273 @@
+package dto
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"slices"
+	"strconv"
+	"strings"
+
+	"coffee-shop/internal/model"
+)
+
+// Import file formats
+const (
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+)
+
+var (
+	ErrUnknownFormat   = errors.New("unknown import format")
+	ErrNotValidRecipe  = errors.New("recipe must be a list of name:quantity pairs separated by semicolons")
+	ErrNotValidRecord  = errors.New("record does not match the header")
+	ErrNotValidRowJSON = errors.New("row is not a valid item object")
+)
+
+// Columns of the import files. The menu recipe column holds name:quantity pairs
+// separated by semicolons, e.g. "Espresso beans:18; Milk:200".
+var (
+	MenuColumns      = []string{"name", "description", "category", "price", "ingredients"}
+	InventoryColumns = []string{"name", "quantity", "unit"}
+)
+
+// ImportRequest holds the parameters of an import. File is the "file" field of a
+// multipart form; otherwise the file is the request body.
+type ImportRequest struct {
+	DryRun bool                  `form:"dry_run"`
+	Format string                `form:"format"`
+	File   *multipart.FileHeader `form:"file"`
+}
+
+type MenuItemImport struct {
+	Name        string             `json:"name"`
+	Description string             `json:"description"`
+	Category    string             `json:"category"`
+	Price       float64            `json:"price"`
+	Ingredients []IngredientImport `json:"ingredients"`
+}
+
+type IngredientImport struct {
+	Name     string `json:"name"`
+	Quantity int    `json:"quantity"`
+}
+
+func (r *MenuItemImport) ToDomain(row int) model.MenuImportRow {
+	item := model.MenuImportRow{
+		Row: row,
+		Item: model.MenuItem{
+			Name:        strings.TrimSpace(r.Name),
+			Description: strings.TrimSpace(r.Description),
+			Category:    strings.TrimSpace(r.Category),
+			Price:       r.Price,
+		},
+	}
+
+	if item.Item.Category == "" {
+		item.Item.Category = model.DefaultMenuCategory
+	}
+
+	for _, i := range r.Ingredients {
+		item.Ingredients = append(item.Ingredients, model.ImportIngredient{
+			Name:     strings.TrimSpace(i.Name),
+			Quantity: i.Quantity,
+		})
+	}
+
+	return item
+}
+
+type InventoryImport struct {
+	Name     string `json:"name"`
+	Quantity int    `json:"quantity"`
+	Unit     string `json:"unit"`
+}
+
+func (r *InventoryImport) ToDomain(row int) model.InventoryImportRow {
+	return model.InventoryImportRow{
+		Row: row,
+		Item: model.Inventory{
+			Name:     strings.TrimSpace(r.Name),
+			Quantity: r.Quantity,
+			Unit:     strings.TrimSpace(r.Unit),
+		},
+	}
+}
+
+// ParseMenu reads the menu items of an import file. Rows are numbered from 1, the
+// CSV header not counted. A row that cannot be read is returned with its error, so
+// that it is reported along with the others; an error is returned only when the
+// file itself cannot be read.
+func ParseMenu(format string, r io.Reader) ([]model.MenuImportRow, error) {
+	var rows []model.MenuImportRow
+	err := parse(format, r, MenuColumns, []string{"name", "description", "price", "ingredients"},
+		func(row int, data json.RawMessage) {
+			var item MenuItemImport
+			if err := json.Unmarshal(data, &item); err != nil {
+				rows = append(rows, model.MenuImportRow{Row: row, Err: ErrNotValidRowJSON})
+				return
+			}
+			rows = append(rows, item.ToDomain(row))
+		},
+		func(row int, record map[string]string, err error) {
+			if err != nil {
+				rows = append(rows, model.MenuImportRow{Row: row, Item: model.MenuItem{Name: record["name"]}, Err: err})
+				return
+			}
+			rows = append(rows, menuRecord(row, record))
+		})
+	return rows, err
+}
+
+// ParseInventory reads the inventory items of an import file the same way as ParseMenu.
+func ParseInventory(format string, r io.Reader) ([]model.InventoryImportRow, error) {
+	var rows []model.InventoryImportRow
+	err := parse(format, r, InventoryColumns, InventoryColumns,
+		func(row int, data json.RawMessage) {
+			var item InventoryImport
+			if err := json.Unmarshal(data, &item); err != nil {
+				rows = append(rows, model.InventoryImportRow{Row: row, Err: ErrNotValidRowJSON})
+				return
+			}
+			rows = append(rows, item.ToDomain(row))
+		},
+		func(row int, record map[string]string, err error) {
+			if err != nil {
+				rows = append(rows, model.InventoryImportRow{Row: row, Item: model.Inventory{Name: record["name"]}, Err: err})
+				return
+			}
+			rows = append(rows, inventoryRecord(row, record))
+		})
+	return rows, err
+}
+
+func menuRecord(row int, record map[string]string) model.MenuImportRow {
+	item := MenuItemImport{
+		Name:        record["name"],
+		Description: record["description"],
+		Category:    record["category"],
+	}
+
+	price, err := strconv.ParseFloat(strings.TrimSpace(record["price"]), 64)
+	if err != nil {
+		return model.MenuImportRow{Row: row, Item: model.MenuItem{Name: item.Name}, Err: model.ErrNotValidPrice}
+	}
+	item.Price = price
+
+	for _, line := range strings.Split(record["ingredients"], ";") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		name, quantity, ok := strings.Cut(line, ":")
+		if !ok {
+			return model.MenuImportRow{Row: row, Item: model.MenuItem{Name: item.Name}, Err: ErrNotValidRecipe}
+		}
+		q, err := strconv.Atoi(strings.TrimSpace(quantity))
+		if err != nil {
+			return model.MenuImportRow{Row: row, Item: model.MenuItem{Name: item.Name}, Err: model.ErrNotValidQuantity}
+		}
+		item.Ingredients = append(item.Ingredients, IngredientImport{Name: name, Quantity: q})
+	}
+
+	return item.ToDomain(row)
+}
+
+func inventoryRecord(row int, record map[string]string) model.InventoryImportRow {
+	item := InventoryImport{
+		Name: record["name"],
+		Unit: record["unit"],
+	}
+
+	quantity, err := strconv.Atoi(strings.TrimSpace(record["quantity"]))
+	if err != nil {
+		return model.InventoryImportRow{Row: row, Item: model.Inventory{Name: item.Name}, Err: model.ErrNotValidQuantity}
+	}
+	item.Quantity = quantity
+
+	return item.ToDomain(row)
+}
+
+// parse calls jsonRow for every element of a JSON array, or csvRow for every CSV
+// record keyed by its column names. columns are the known columns of a CSV file, of
+// which the required ones must be present in the header.
+func parse(format string, r io.Reader, columns, required []string,
+	jsonRow func(row int, data json.RawMessage),
+	csvRow func(row int, record map[string]string, err error)) error {
+	switch format {
+	case FormatJSON:
+		var items []json.RawMessage
+		if err := json.NewDecoder(r).Decode(&items); err != nil {
+			return fmt.Errorf("import file must be a JSON array of items: %w", err)
+		}
+		for i, data := range items {
+			jsonRow(i+1, data)
+		}
+		return nil
+	case FormatCSV:
+		return parseCSV(r, columns, required, csvRow)
+	default:
+		return ErrUnknownFormat
+	}
+}
+
+func parseCSV(r io.Reader, columns, required []string, row func(row int, record map[string]string, err error)) error {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return errors.New("import file is empty")
+	}
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, len(header))
+	present := make(map[string]bool, len(header))
+	for i, name := range header {
+		if i == 0 {
+			name = strings.TrimPrefix(name, "\ufeff")
+		}
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !slices.Contains(columns, name) {
+			return fmt.Errorf("unknown column %q, expected %s", name, strings.Join(columns, ", "))
+		}
+		if present[name] {
+			return fmt.Errorf("duplicate column %q", name)
+		}
+		present[name] = true
+		names[i] = name
+	}
+	for _, name := range required {
+		if !present[name] {
+			return fmt.Errorf("missing column %q", name)
+		}
+	}
+
+	for n := 1; ; n++ {
+		fields, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		record := make(map[string]string, len(names))
+		for i, value := range fields {
+			if i < len(names) {
+				record[names[i]] = value
+			}
+		}
+
+		if len(fields) != len(names) {
+			row(n, record, ErrNotValidRecord)
+			continue
+		}
+		row(n, record, nil)
+	}
+}
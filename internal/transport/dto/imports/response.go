@@ -0,0 +1,66 @@
+package dto
+
+import "coffee-shop/internal/model"
+
+// Import row statuses
+const (
+	RowStatusAccepted = "accepted"
+	RowStatusRejected = "rejected"
+)
+
+type ImportResponse struct {
+	DryRun  bool                  `json:"dry_run"`
+	Applied bool                  `json:"applied"`
+	Summary ImportSummaryResponse `json:"summary"`
+	Rows    []ImportRowResponse   `json:"rows"`
+}
+
+type ImportSummaryResponse struct {
+	Rows     int `json:"rows"`
+	Created  int `json:"created"`
+	Updated  int `json:"updated"`
+	Rejected int `json:"rejected"`
+}
+
+type ImportRowResponse struct {
+	Row     int    `json:"row"`
+	Status  string `json:"status"`
+	Action  string `json:"action"`
+	ID      int    `json:"id,omitempty"`
+	Name    string `json:"name"`
+	Code    string `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// NewImportResponse builds the response of an import. reason describes the
+// error of a rejected row with the code and message fields.
+func NewImportResponse(r model.ImportResult, reason func(err error) (string, string)) ImportResponse {
+	res := ImportResponse{
+		DryRun:  r.DryRun,
+		Applied: r.Applied,
+		Summary: ImportSummaryResponse{
+			Rows:     len(r.Rows),
+			Created:  r.Created,
+			Updated:  r.Updated,
+			Rejected: r.Rejected,
+		},
+		Rows: []ImportRowResponse{},
+	}
+
+	for _, row := range r.Rows {
+		item := ImportRowResponse{
+			Row:    row.Row,
+			Status: RowStatusAccepted,
+			Action: row.Action,
+			ID:     row.ID,
+			Name:   row.Name,
+		}
+		if !row.Accepted() {
+			item.Status = RowStatusRejected
+			item.Code, item.Message = reason(row.Err)
+		}
+		res.Rows = append(res.Rows, item)
+	}
+
+	return res
+}
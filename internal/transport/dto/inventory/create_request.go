@@ -3,9 +3,19 @@ package dto
 import "coffee-shop/internal/model"
 
 type InventoryRequest struct {
-	Name     string `json:"name"`
-	Quantity int    `json:"quantity"`
-	Unit     string `json:"unit"`
+	Name     string `json:"name" binding:"required,max=50"`
+	Quantity int    `json:"quantity" binding:"min=1"`
+	Unit     string `json:"unit" binding:"required,oneof=g kg ml l pcs shots"`
+}
+
+// NewInventoryRequest returns the request that creates the item, which a patch of the
+// item is applied to.
+func NewInventoryRequest(item model.Inventory) InventoryRequest {
+	return InventoryRequest{
+		Name:     item.Name,
+		Quantity: item.Quantity,
+		Unit:     item.Unit,
+	}
 }
 
 func (r *InventoryRequest) ToDomain() model.Inventory {
@@ -15,18 +25,3 @@ func (r *InventoryRequest) ToDomain() model.Inventory {
 		Unit:     r.Unit,
 	}
 }
-
-func (r *InventoryRequest) Validate() error {
-	if r.Name == "" {
-		return nil
-	}
-
-	if r.Quantity < 0 {
-		return nil
-	}
-
-	if r.Unit == "" {
-
-	}
-	return nil
-}
@@ -0,0 +1,14 @@
+package dto
+
+import (
+	"coffee-shop/internal/export"
+	"coffee-shop/internal/model"
+)
+
+// InventoryColumns are the columns of the inventory export.
+var InventoryColumns = export.Columns[model.Inventory]{
+	{Key: "ingredient_id", Title: "Ingredient ID", Type: export.Int, Value: func(i model.Inventory) any { return i.IngredientID }},
+	{Key: "name", Title: "Name", Value: func(i model.Inventory) any { return i.Name }},
+	{Key: "quantity", Title: "Quantity", Type: export.Int, Value: func(i model.Inventory) any { return i.Quantity }},
+	{Key: "unit", Title: "Unit", Value: func(i model.Inventory) any { return i.Unit }},
+}
@@ -0,0 +1,12 @@
+package dto
+
+// ListRequest holds the query parameters of the list of inventory items.
+type ListRequest struct {
+	IncludeArchived bool `form:"include_archived"`
+}
+
+// DeleteRequest holds the query parameters of the deletion of an inventory item. Without
+// Permanent the item is archived.
+type DeleteRequest struct {
+	Permanent bool `form:"permanent"`
+}
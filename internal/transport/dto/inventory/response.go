@@ -1,19 +1,33 @@
 package dto
 
-import "coffee-shop/internal/model"
+import (
+	"time"
+
+	"coffee-shop/internal/model"
+)
 
 type InventoryResponse struct {
-	IngredientID int    `json:"ingredient_id"`
-	Name         string `json:"name"`
-	Quantity     int    `json:"quantity"`
-	Unit         string `json:"unit"`
+	IngredientID int        `json:"ingredient_id"`
+	Name         string     `json:"name"`
+	Quantity     int        `json:"quantity"`
+	Unit         string     `json:"unit"`
+	Version      int        `json:"version"`
+	ArchivedAt   *time.Time `json:"archived_at,omitempty"`
 }
 
 func NewInventoryResponse(i model.Inventory) InventoryResponse {
-	return InventoryResponse{
+	res := InventoryResponse{
 		IngredientID: i.IngredientID,
 		Name:         i.Name,
 		Quantity:     i.Quantity,
 		Unit:         i.Unit,
+		Version:      i.Version,
+	}
+
+	if i.IsArchived() {
+		archivedAt := i.ArchivedAt
+		res.ArchivedAt = &archivedAt
 	}
+
+	return res
 }
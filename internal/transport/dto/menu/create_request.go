@@ -1,47 +1,37 @@
 package dto
 
-import (
-	"coffee-shop/internal/model"
-	"coffee-shop/internal/transport/dto"
-)
+import "coffee-shop/internal/model"
 
 type MenuItemRequest struct {
-	Name        string               `json:"name"`
-	Description string               `json:"description"`
-	Price       float64              `json:"price"`
-	Ingredients []MenuItemIngredient `json:"ingredients"`
+	Name        string               `json:"name" binding:"required,max=50"`
+	Description string               `json:"description" binding:"required"`
+	Category    string               `json:"category" binding:"max=50"`
+	Price       float64              `json:"price" binding:"gt=0"`
+	Ingredients []MenuItemIngredient `json:"ingredients" binding:"required"`
 }
 
 type MenuItemIngredient struct {
-	IngredientID int `json:"ingredient_id"`
-	Quantity     int `json:"quantity"`
+	IngredientID int `json:"ingredient_id" binding:"min=1"`
+	Quantity     int `json:"quantity" binding:"min=1"`
 }
 
-func (r *MenuItemRequest) Validate() error {
-	switch {
-	case r.Name == "":
-		return dto.ErrNotValidMenuItemName
-	case r.Description == "":
-		return dto.ErrNotValidMenuDescription
-	case r.Price <= 0:
-		return dto.ErrNotValidMenuPrice
-	case len(r.Ingredients) == 0:
-		return dto.ErrNotValidMenuIngredients
-	}
-
-	for _, i := range r.Ingredients {
-		i.Validate()
+// NewMenuItemRequest returns the request that creates the item with its recipe, which
+// a patch of the item is applied to.
+func NewMenuItemRequest(m model.MenuItem, i []model.MenuItemIngredients) MenuItemRequest {
+	ingredients := []MenuItemIngredient{}
+	for _, ingredient := range i {
+		ingredients = append(ingredients, MenuItemIngredient{
+			IngredientID: ingredient.IngredientID,
+			Quantity:     ingredient.Quantity,
+		})
 	}
 
-	return nil
-}
-
-func (r *MenuItemIngredient) Validate() error {
-	switch {
-	case r.Quantity <= 0:
-		return dto.ErrNotValdidMenuIngredientsQuantity
-	default:
-		return nil
+	return MenuItemRequest{
+		Name:        m.Name,
+		Description: m.Description,
+		Category:    m.Category,
+		Price:       m.Price,
+		Ingredients: ingredients,
 	}
 }
 
@@ -50,9 +40,14 @@ func ToDomain(m MenuItemRequest) (model.MenuItem, []model.MenuItemIngredients) {
 		ID:          0,
 		Name:        m.Name,
 		Description: m.Description,
+		Category:    m.Category,
 		Price:       m.Price,
 	}
 
+	if menuItem.Category == "" {
+		menuItem.Category = model.DefaultMenuCategory
+	}
+
 	var ingredients []model.MenuItemIngredients
 	for _, ingredient := range m.Ingredients {
 		ingredients = append(ingredients, model.MenuItemIngredients{
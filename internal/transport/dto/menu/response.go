@@ -1,13 +1,20 @@
 package dto
 
-import "coffee-shop/internal/model"
+import (
+	"time"
+
+	"coffee-shop/internal/model"
+)
 
 type MenuItemResponse struct {
 	ID          int                   `json:"id"`
 	Name        string                `json:"name"`
 	Description string                `json:"description"`
+	Category    string                `json:"category"`
 	Ingredients []MenuItemIngredients `json:"ingredients"`
 	Price       float64               `json:"price"`
+	Version     int                   `json:"version"`
+	ArchivedAt  *time.Time            `json:"archived_at,omitempty"`
 }
 
 type MenuItemIngredients struct {
@@ -28,8 +35,15 @@ func NewMenuItemResponse(m *model.MenuItem, i []model.MenuItemIngredients) MenuI
 		ID:          m.ID,
 		Name:        m.Name,
 		Description: m.Description,
+		Category:    m.Category,
 		Ingredients: ingredients,
 		Price:       m.Price,
+		Version:     m.Version,
+	}
+
+	if m.IsArchived() {
+		archivedAt := m.ArchivedAt
+		menu.ArchivedAt = &archivedAt
 	}
 	return menu
 }
@@ -0,0 +1,89 @@
+package dto
+
+import "coffee-shop/internal/model"
+
+// Batch order result statuses
+const (
+	BatchStatusAccepted = "accepted"
+	BatchStatusRejected = "rejected"
+)
+
+type BatchRequest struct {
+	// Orders are not validated when the batch is bound: an invalid order is rejected
+	// in its result, and the other orders are still placed.
+	Orders []OrderRequest `json:"orders" binding:"-"`
+}
+
+func (r *BatchRequest) ToDomain() []model.Order {
+	orders := make([]model.Order, 0, len(r.Orders))
+	for _, o := range r.Orders {
+		orders = append(orders, o.ToDomain())
+	}
+	return orders
+}
+
+type BatchResponse struct {
+	Results []BatchResultResponse `json:"results"`
+	Summary BatchSummaryResponse  `json:"summary"`
+}
+
+type BatchResultResponse struct {
+	Index   int     `json:"index"`
+	Status  string  `json:"status"`
+	OrderID int     `json:"order_id,omitempty"`
+	Total   float64 `json:"total,omitempty"`
+	Code    string  `json:"code,omitempty"`
+	Message string  `json:"message,omitempty"`
+}
+
+type BatchSummaryResponse struct {
+	Accepted    int                       `json:"accepted"`
+	Rejected    int                       `json:"rejected"`
+	Revenue     float64                   `json:"revenue"`
+	Ingredients []IngredientUsageResponse `json:"ingredients_consumed"`
+}
+
+type IngredientUsageResponse struct {
+	IngredientID int    `json:"ingredient_id"`
+	Name         string `json:"name"`
+	Quantity     int    `json:"quantity"`
+	Unit         string `json:"unit"`
+}
+
+// NewBatchResponse builds the response of a processed batch. reason describes
+// the error of a rejected order with the code and message fields.
+func NewBatchResponse(r model.BatchResult, reason func(err error) (string, string)) BatchResponse {
+	res := BatchResponse{
+		Results: []BatchResultResponse{},
+		Summary: BatchSummaryResponse{
+			Accepted:    r.Accepted,
+			Rejected:    r.Rejected,
+			Revenue:     r.Revenue,
+			Ingredients: []IngredientUsageResponse{},
+		},
+	}
+
+	for _, result := range r.Results {
+		item := BatchResultResponse{Index: result.Index}
+		if result.Accepted() {
+			item.Status = BatchStatusAccepted
+			item.OrderID = result.OrderID
+			item.Total = result.Total
+		} else {
+			item.Status = BatchStatusRejected
+			item.Code, item.Message = reason(result.Err)
+		}
+		res.Results = append(res.Results, item)
+	}
+
+	for _, usage := range r.Consumed {
+		res.Summary.Ingredients = append(res.Summary.Ingredients, IngredientUsageResponse{
+			IngredientID: usage.IngredientID,
+			Name:         usage.Name,
+			Quantity:     usage.Quantity,
+			Unit:         usage.Unit,
+		})
+	}
+
+	return res
+}
@@ -1,34 +1,34 @@
 package dto
 
+import "coffee-shop/internal/model"
+
 type OrderRequest struct {
-	ID           string      `json:"order_id"`
-	CustomerName string      `json:"customer_name"`
-	Items        []OrderItem `json:"items"`
-	Status       string      `json:"status"`
-	CreatedAt    string      `json:"created_at"`
+	CustomerName string      `json:"customer_name" binding:"required,max=50"`
+	Notes        string      `json:"notes"`
+	Discount     float64     `json:"discount" binding:"min=0"`
+	Items        []OrderItem `json:"items" binding:"required"`
 }
 
 type OrderItem struct {
-	ProductID string `json:"product_id"`
-	Quantity  int    `json:"quantity"`
+	ProductID int      `json:"product_id" binding:"required,min=1"`
+	Quantity  int      `json:"quantity" binding:"required,min=1"`
+	Modifiers []string `json:"modifiers"`
 }
 
-func (r *OrderRequest) Validate() error {
-	// TODO: Write validation logic here
-	if r.ID == "" {
-		return nil
-	}
-
-	if r.CustomerName == "" {
-		return nil
+func (r *OrderRequest) ToDomain() model.Order {
+	order := model.Order{
+		CustomerName: r.CustomerName,
+		Notes:        r.Notes,
+		OrderTotals:  model.OrderTotals{DiscountTotal: r.Discount},
 	}
 
-	if r.Status == "" {
-		return nil
+	for _, item := range r.Items {
+		order.Items = append(order.Items, model.OrderItems{
+			ProductID: item.ProductID,
+			Quantity:  item.Quantity,
+			Modifiers: item.Modifiers,
+		})
 	}
 
-	if r.CreatedAt == "" {
-		return nil
-	}
-	return nil
+	return order
 }
@@ -0,0 +1,56 @@
+package dto
+
+import (
+	"time"
+
+	"coffee-shop/internal/model"
+)
+
+// OrderStreamRequest holds the query parameters of the live order queue. Category
+// takes comma-separated or repeated values.
+type OrderStreamRequest struct {
+	Station  string   `form:"station"`
+	Category []string `form:"category"`
+}
+
+type OrderEventResponse struct {
+	Type         string                   `json:"type"`
+	OrderID      int                      `json:"order_id"`
+	Status       string                   `json:"status"`
+	CustomerName string                   `json:"customer_name"`
+	Notes        string                   `json:"notes,omitempty"`
+	Items        []OrderEventItemResponse `json:"items"`
+	OccurredAt   time.Time                `json:"occurred_at"`
+}
+
+type OrderEventItemResponse struct {
+	ProductID int      `json:"product_id"`
+	Name      string   `json:"name"`
+	Category  string   `json:"category"`
+	Quantity  int      `json:"quantity"`
+	Modifiers []string `json:"modifiers,omitempty"`
+}
+
+func NewOrderEventResponse(e model.OrderEvent) OrderEventResponse {
+	res := OrderEventResponse{
+		Type:         e.Type,
+		OrderID:      e.OrderID,
+		Status:       e.Status,
+		CustomerName: e.CustomerName,
+		Notes:        e.Notes,
+		Items:        []OrderEventItemResponse{},
+		OccurredAt:   e.OccurredAt,
+	}
+
+	for _, item := range e.Items {
+		res.Items = append(res.Items, OrderEventItemResponse{
+			ProductID: item.ProductID,
+			Name:      item.Name,
+			Category:  item.Category,
+			Quantity:  item.Quantity,
+			Modifiers: item.Modifiers,
+		})
+	}
+
+	return res
+}
@@ -0,0 +1,30 @@
+package dto
+
+import (
+	"coffee-shop/internal/export"
+	"coffee-shop/internal/model"
+)
+
+// OrderColumns are the columns of the order export. The totals of open
+// orders are left empty, as they are only stored when an order is closed.
+var OrderColumns = export.Columns[model.Order]{
+	{Key: "id", Title: "Order ID", Type: export.Int, Value: func(o model.Order) any { return o.ID }},
+	{Key: "customer_name", Title: "Customer", Value: func(o model.Order) any { return o.CustomerName }},
+	{Key: "status", Title: "Status", Value: func(o model.Order) any { return o.Status }},
+	{Key: "notes", Title: "Notes", Value: func(o model.Order) any { return o.Notes }},
+	{Key: "subtotal", Title: "Subtotal", Type: export.Decimal, Value: closedTotal(func(t model.OrderTotals) float64 { return t.Subtotal })},
+	{Key: "discount", Title: "Discount", Type: export.Decimal, Value: func(o model.Order) any { return o.DiscountTotal }},
+	{Key: "tax", Title: "Tax", Type: export.Decimal, Value: closedTotal(func(t model.OrderTotals) float64 { return t.TaxTotal })},
+	{Key: "total", Title: "Total", Type: export.Decimal, Value: closedTotal(func(t model.OrderTotals) float64 { return t.Total })},
+	{Key: "created_at", Title: "Created at", Type: export.DateTime, Value: func(o model.Order) any { return o.CreateAt }},
+	{Key: "closed_at", Title: "Closed at", Type: export.DateTime, Value: func(o model.Order) any { return o.ClosedAt }},
+}
+
+func closedTotal(total func(t model.OrderTotals) float64) func(o model.Order) any {
+	return func(o model.Order) any {
+		if o.Status != model.OrderStatusClosed {
+			return nil
+		}
+		return total(o.OrderTotals)
+	}
+}
@@ -1 +1,96 @@
 package dto
+
+import (
+	"time"
+
+	"coffee-shop/internal/model"
+)
+
+type OrderResponse struct {
+	ID           int                 `json:"order_id"`
+	CustomerName string              `json:"customer_name"`
+	Status       string              `json:"status"`
+	Notes        string              `json:"notes"`
+	Items        []OrderItemResponse `json:"items"`
+	CreatedAt    time.Time           `json:"created_at"`
+	ClosedAt     *time.Time          `json:"closed_at,omitempty"`
+	Totals       *TotalsResponse     `json:"totals,omitempty"`
+	Version      int                 `json:"version"`
+}
+
+type OrderItemResponse struct {
+	ProductID int      `json:"product_id"`
+	Quantity  int      `json:"quantity"`
+	Price     float64  `json:"price"`
+	Modifiers []string `json:"modifiers,omitempty"`
+}
+
+type TotalsResponse struct {
+	Subtotal      float64           `json:"subtotal"`
+	DiscountTotal float64           `json:"discount_total"`
+	Taxes         []TaxLineResponse `json:"taxes"`
+	TaxTotal      float64           `json:"tax_total"`
+	Total         float64           `json:"total"`
+}
+
+type TaxLineResponse struct {
+	Category      string  `json:"category"`
+	Rate          float64 `json:"rate"`
+	Inclusive     bool    `json:"inclusive"`
+	TaxableAmount float64 `json:"taxable_amount"`
+	TaxAmount     float64 `json:"tax_amount"`
+}
+
+// NewOrderResponse builds the response of an order. Totals are included only
+// for closed orders, since they are stored when the order is closed.
+func NewOrderResponse(o model.Order) OrderResponse {
+	res := OrderResponse{
+		ID:           o.ID,
+		CustomerName: o.CustomerName,
+		Status:       o.Status,
+		Notes:        o.Notes,
+		Items:        []OrderItemResponse{},
+		CreatedAt:    o.CreateAt,
+		Version:      o.Version,
+	}
+
+	for _, item := range o.Items {
+		res.Items = append(res.Items, OrderItemResponse{
+			ProductID: item.ProductID,
+			Quantity:  item.Quantity,
+			Price:     item.Price,
+			Modifiers: item.Modifiers,
+		})
+	}
+
+	if o.Status == model.OrderStatusClosed {
+		closedAt := o.ClosedAt
+		totals := NewTotalsResponse(o.OrderTotals)
+		res.ClosedAt = &closedAt
+		res.Totals = &totals
+	}
+
+	return res
+}
+
+func NewTotalsResponse(t model.OrderTotals) TotalsResponse {
+	res := TotalsResponse{
+		Subtotal:      t.Subtotal,
+		DiscountTotal: t.DiscountTotal,
+		Taxes:         []TaxLineResponse{},
+		TaxTotal:      t.TaxTotal,
+		Total:         t.Total,
+	}
+
+	for _, line := range t.Taxes {
+		res.Taxes = append(res.Taxes, TaxLineResponse{
+			Category:      line.Category,
+			Rate:          line.Rate,
+			Inclusive:     line.Inclusive,
+			TaxableAmount: line.TaxableAmount,
+			TaxAmount:     line.TaxAmount,
+		})
+	}
+
+	return res
+}
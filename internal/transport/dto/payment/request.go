@@ -0,0 +1,73 @@
+package dto
+
+import (
+	dto "coffee-shop/internal/transport/dto/order"
+
+	"coffee-shop/internal/model"
+)
+
+type PaymentRequest struct {
+	Tender    string  `json:"tender"`
+	Amount    float64 `json:"amount"`
+	Tip       float64 `json:"tip"`
+	Tendered  float64 `json:"tendered"`
+	Reference string  `json:"reference"`
+}
+
+func (r *PaymentRequest) ToDomain() model.Payment {
+	return model.Payment{
+		Tender:    r.Tender,
+		Amount:    r.Amount,
+		Tip:       r.Tip,
+		Tendered:  r.Tendered,
+		Reference: r.Reference,
+	}
+}
+
+type SplitRequest struct {
+	Mode   string            `json:"mode"`
+	Parts  int               `json:"parts"`
+	Groups [][]dto.OrderItem `json:"groups"`
+}
+
+func (r *SplitRequest) ToDomain() model.SplitRequest {
+	split := model.SplitRequest{
+		Mode:  r.Mode,
+		Parts: r.Parts,
+	}
+
+	for _, group := range r.Groups {
+		split.Groups = append(split.Groups, toItems(group))
+	}
+
+	return split
+}
+
+type RefundRequest struct {
+	PaymentID int             `json:"payment_id"`
+	Amount    float64         `json:"amount"`
+	Items     []dto.OrderItem `json:"items"`
+	Restock   bool            `json:"restock"`
+	Reason    string          `json:"reason"`
+}
+
+func (r *RefundRequest) ToDomain() model.RefundRequest {
+	return model.RefundRequest{
+		PaymentID: r.PaymentID,
+		Amount:    r.Amount,
+		Items:     toItems(r.Items),
+		Restock:   r.Restock,
+		Reason:    r.Reason,
+	}
+}
+
+func toItems(items []dto.OrderItem) []model.OrderItems {
+	var res []model.OrderItems
+	for _, item := range items {
+		res = append(res, model.OrderItems{
+			ProductID: item.ProductID,
+			Quantity:  item.Quantity,
+		})
+	}
+	return res
+}
@@ -0,0 +1,92 @@
+package dto
+
+import (
+	"time"
+
+	"coffee-shop/internal/model"
+)
+
+type PaymentResponse struct {
+	ID        int       `json:"payment_id"`
+	OrderID   int       `json:"order_id"`
+	Tender    string    `json:"tender"`
+	Amount    float64   `json:"amount"`
+	Tip       float64   `json:"tip"`
+	Tendered  float64   `json:"tendered"`
+	Change    float64   `json:"change"`
+	RefundOf  int       `json:"refund_of,omitempty"`
+	Reference string    `json:"reference,omitempty"`
+	Reason    string    `json:"reason,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type BalanceResponse struct {
+	Due      float64 `json:"due"`
+	Paid     float64 `json:"paid"`
+	Refunded float64 `json:"refunded"`
+	Tips     float64 `json:"tips"`
+	Balance  float64 `json:"balance"`
+}
+
+type PaymentsResponse struct {
+	Payments []PaymentResponse `json:"payments"`
+	Balance  BalanceResponse   `json:"balance"`
+}
+
+type SplitShareResponse struct {
+	Part   int                 `json:"part"`
+	Items  []SplitItemResponse `json:"items,omitempty"`
+	Amount float64             `json:"amount"`
+}
+
+type SplitItemResponse struct {
+	ProductID int `json:"product_id"`
+	Quantity  int `json:"quantity"`
+}
+
+func NewPaymentResponse(p model.Payment) PaymentResponse {
+	return PaymentResponse{
+		ID:        p.ID,
+		OrderID:   p.OrderID,
+		Tender:    p.Tender,
+		Amount:    p.Amount,
+		Tip:       p.Tip,
+		Tendered:  p.Tendered,
+		Change:    p.Change,
+		RefundOf:  p.RefundOf,
+		Reference: p.Reference,
+		Reason:    p.Reason,
+		CreatedAt: p.CreatedAt,
+	}
+}
+
+func NewPaymentsResponse(payments []model.Payment, b model.PaymentBalance) PaymentsResponse {
+	res := PaymentsResponse{
+		Payments: []PaymentResponse{},
+		Balance: BalanceResponse{
+			Due:      b.Due,
+			Paid:     b.Paid,
+			Refunded: b.Refunded,
+			Tips:     b.Tips,
+			Balance:  b.Balance,
+		},
+	}
+
+	for _, p := range payments {
+		res.Payments = append(res.Payments, NewPaymentResponse(p))
+	}
+
+	return res
+}
+
+func NewSplitResponse(shares []model.SplitShare) []SplitShareResponse {
+	res := []SplitShareResponse{}
+	for _, s := range shares {
+		share := SplitShareResponse{Part: s.Part, Amount: s.Amount}
+		for _, item := range s.Items {
+			share.Items = append(share.Items, SplitItemResponse{ProductID: item.ProductID, Quantity: item.Quantity})
+		}
+		res = append(res, share)
+	}
+	return res
+}
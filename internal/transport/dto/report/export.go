@@ -0,0 +1,102 @@
+package dto
+
+import (
+	"strings"
+
+	"coffee-shop/internal/export"
+	"coffee-shop/internal/model"
+)
+
+// TaxReportColumns are the columns of the tax report export.
+var TaxReportColumns = export.Columns[model.TaxReportLine]{
+	{Key: "category", Title: "Category", Value: func(l model.TaxReportLine) any { return l.Category }},
+	{Key: "rate", Title: "Rate", Type: export.Decimal, Value: func(l model.TaxReportLine) any { return l.Rate * 100 }},
+	{Key: "inclusive", Title: "Inclusive", Value: func(l model.TaxReportLine) any { return l.Inclusive }},
+	{Key: "orders", Title: "Orders", Type: export.Int, Value: func(l model.TaxReportLine) any { return l.Orders }},
+	{Key: "taxable_amount", Title: "Taxable amount", Type: export.Decimal, Value: func(l model.TaxReportLine) any { return l.TaxableAmount }},
+	{Key: "tax_amount", Title: "Tax amount", Type: export.Decimal, Value: func(l model.TaxReportLine) any { return l.TaxAmount }},
+}
+
+// PaymentReportColumns are the columns of the payment report export.
+var PaymentReportColumns = export.Columns[model.PaymentReportLine]{
+	{Key: "tender", Title: "Tender", Value: func(l model.PaymentReportLine) any { return l.Tender }},
+	{Key: "payments", Title: "Payments", Type: export.Int, Value: func(l model.PaymentReportLine) any { return l.Payments }},
+	{Key: "amount", Title: "Amount", Type: export.Decimal, Value: func(l model.PaymentReportLine) any { return l.Amount }},
+	{Key: "tips", Title: "Tips", Type: export.Decimal, Value: func(l model.PaymentReportLine) any { return l.Tips }},
+	{Key: "refunds", Title: "Refunds", Type: export.Int, Value: func(l model.PaymentReportLine) any { return l.Refunds }},
+	{Key: "refunded", Title: "Refunded", Type: export.Decimal, Value: func(l model.PaymentReportLine) any { return l.Refunded }},
+}
+
+// SalesColumns are the columns of the sales report export.
+var SalesColumns = export.Columns[model.SalesBucket]{
+	{Key: "start", Title: "Start", Type: export.DateTime, Value: func(b model.SalesBucket) any { return b.Start }},
+	{Key: "orders", Title: "Orders", Type: export.Int, Value: func(b model.SalesBucket) any { return b.Orders }},
+	{Key: "revenue", Title: "Revenue", Type: export.Decimal, Value: func(b model.SalesBucket) any { return b.Revenue }},
+	{Key: "refunds", Title: "Refunds", Type: export.Decimal, Value: func(b model.SalesBucket) any { return b.Refunds }},
+	{Key: "net_revenue", Title: "Net revenue", Type: export.Decimal, Value: func(b model.SalesBucket) any { return b.NetRevenue }},
+	{Key: "average_ticket", Title: "Average ticket", Type: export.Decimal, Value: func(b model.SalesBucket) any { return b.AverageTicket }},
+	{Key: "items", Title: "Items", Type: export.Int, Value: func(b model.SalesBucket) any { return b.Items }},
+}
+
+// PopularItemColumns are the columns of the popular items export.
+var PopularItemColumns = export.Columns[model.PopularItem]{
+	{Key: "product_id", Title: "Product ID", Type: export.Int, Value: func(i model.PopularItem) any { return i.ProductID }},
+	{Key: "name", Title: "Name", Value: func(i model.PopularItem) any { return i.Name }},
+	{Key: "category", Title: "Category", Value: func(i model.PopularItem) any { return i.Category }},
+	{Key: "quantity", Title: "Quantity", Type: export.Int, Value: func(i model.PopularItem) any { return i.Quantity }},
+	{Key: "revenue", Title: "Revenue", Type: export.Decimal, Value: func(i model.PopularItem) any { return i.Revenue }},
+	{Key: "share", Title: "Share %", Type: export.Decimal, Value: func(i model.PopularItem) any { return i.Share }},
+	{Key: "previous_quantity", Title: "Previous quantity", Type: export.Int, Value: func(i model.PopularItem) any { return i.PreviousQuantity }},
+	{Key: "previous_revenue", Title: "Previous revenue", Type: export.Decimal, Value: func(i model.PopularItem) any { return i.PreviousRevenue }},
+	{Key: "trend", Title: "Trend %", Type: export.Decimal, Value: func(i model.PopularItem) any { return i.Trend }},
+}
+
+// IngredientUsageRow is a day of the usage of an ingredient, a row of the ingredient usage export.
+type IngredientUsageRow struct {
+	model.IngredientUsage
+	model.DailyUsage
+}
+
+// NewIngredientUsageRows flattens the usage of the ingredients into a row per ingredient and day.
+func NewIngredientUsageRows(usage []model.IngredientUsageSummary) []IngredientUsageRow {
+	var rows []IngredientUsageRow
+	for _, u := range usage {
+		for _, d := range u.Days {
+			rows = append(rows, IngredientUsageRow{IngredientUsage: u.IngredientUsage, DailyUsage: d})
+		}
+	}
+	return rows
+}
+
+// IngredientUsageColumns are the columns of the ingredient usage export.
+var IngredientUsageColumns = export.Columns[IngredientUsageRow]{
+	{Key: "date", Title: "Date", Type: export.Date, Value: func(r IngredientUsageRow) any { return r.Day }},
+	{Key: "ingredient_id", Title: "Ingredient ID", Type: export.Int, Value: func(r IngredientUsageRow) any { return r.IngredientID }},
+	{Key: "name", Title: "Name", Value: func(r IngredientUsageRow) any { return r.Name }},
+	{Key: "unit", Title: "Unit", Value: func(r IngredientUsageRow) any { return r.Unit }},
+	{Key: "quantity", Title: "Quantity", Type: export.Int, Value: func(r IngredientUsageRow) any { return r.DailyUsage.Quantity }},
+}
+
+// InventoryForecastColumns are the columns of the inventory forecast export.
+var InventoryForecastColumns = export.Columns[model.InventoryForecast]{
+	{Key: "ingredient_id", Title: "Ingredient ID", Type: export.Int, Value: func(f model.InventoryForecast) any { return f.IngredientID }},
+	{Key: "name", Title: "Name", Value: func(f model.InventoryForecast) any { return f.Name }},
+	{Key: "unit", Title: "Unit", Value: func(f model.InventoryForecast) any { return f.Unit }},
+	{Key: "quantity", Title: "Quantity", Type: export.Int, Value: func(f model.InventoryForecast) any { return f.Quantity }},
+	{Key: "average_daily_usage", Title: "Average daily usage", Type: export.Decimal, Value: func(f model.InventoryForecast) any { return f.AverageDailyUsage }},
+	{Key: "days_until_stockout", Title: "Days until stockout", Type: export.Decimal, Value: func(f model.InventoryForecast) any { return f.DaysUntilStockout }},
+	{Key: "stockout_date", Title: "Stockout date", Type: export.Date, Value: func(f model.InventoryForecast) any { return f.StockoutDate }},
+	{Key: "reorder_quantity", Title: "Reorder quantity", Type: export.Int, Value: func(f model.InventoryForecast) any { return f.ReorderQuantity }},
+}
+
+// HeatmapColumns are the columns of the heatmap export, a row per weekday and hour.
+var HeatmapColumns = export.Columns[model.HeatmapCell]{
+	{Key: "weekday", Title: "Weekday", Value: func(c model.HeatmapCell) any { return strings.ToLower(c.Weekday.String()) }},
+	{Key: "hour", Title: "Hour", Type: export.Int, Value: func(c model.HeatmapCell) any { return c.Hour }},
+	{Key: "orders", Title: "Orders", Type: export.Int, Value: func(c model.HeatmapCell) any { return c.Orders }},
+	{Key: "revenue", Title: "Revenue", Type: export.Decimal, Value: func(c model.HeatmapCell) any { return c.Revenue }},
+	{Key: "average_orders", Title: "Average orders", Type: export.Decimal, Value: func(c model.HeatmapCell) any { return c.AverageOrders }},
+	{Key: "average_prep_time_seconds", Title: "Average preparation time (s)", Type: export.Int, Value: func(c model.HeatmapCell) any {
+		return int(c.AveragePrepTime.Seconds())
+	}},
+}
@@ -0,0 +1,44 @@
+package dto
+
+import "coffee-shop/internal/model"
+
+// SalesRequest holds the query parameters of the sales report besides the period.
+type SalesRequest struct {
+	GroupBy string `form:"group_by,default=day"`
+	Status  string `form:"status,default=closed"`
+}
+
+func (r SalesRequest) ToDomain(period model.Period) model.SalesQuery {
+	return model.SalesQuery{Period: period, GroupBy: r.GroupBy, Status: r.Status}
+}
+
+// PopularItemsRequest holds the query parameters of the popular items report besides the period.
+type PopularItemsRequest struct {
+	Limit    int    `form:"limit,default=10"`
+	RankBy   string `form:"rank_by,default=quantity"`
+	Category string `form:"category"`
+}
+
+func (r PopularItemsRequest) ToDomain(period model.Period) model.PopularItemsQuery {
+	return model.PopularItemsQuery{Period: period, Limit: r.Limit, RankBy: r.RankBy, Category: r.Category}
+}
+
+// IngredientUsageRequest holds the query parameters of the ingredient usage report besides the period.
+type IngredientUsageRequest struct {
+	Source string `form:"source,default=ledger"`
+}
+
+func (r IngredientUsageRequest) ToDomain(period model.Period) model.IngredientUsageQuery {
+	return model.IngredientUsageQuery{Period: period, Source: r.Source}
+}
+
+// ForecastRequest holds the query parameters of the inventory forecast, in days.
+type ForecastRequest struct {
+	Window   int `form:"window,default=14"`
+	LeadTime int `form:"lead_time,default=3"`
+	Cover    int `form:"cover,default=7"`
+}
+
+func (r ForecastRequest) ToDomain() model.ForecastQuery {
+	return model.ForecastQuery{Window: r.Window, LeadTime: r.LeadTime, Cover: r.Cover}
+}
@@ -0,0 +1,341 @@
+package dto
+
+import (
+	"math"
+	"strings"
+	"time"
+
+	"coffee-shop/internal/model"
+)
+
+type PeriodResponse struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+}
+
+type TaxReportResponse struct {
+	Period        PeriodResponse      `json:"period"`
+	Lines         []TaxReportLineItem `json:"lines"`
+	TaxableAmount float64             `json:"taxable_amount"`
+	TaxAmount     float64             `json:"tax_amount"`
+}
+
+type TaxReportLineItem struct {
+	Category      string  `json:"category"`
+	Rate          float64 `json:"rate"`
+	Inclusive     bool    `json:"inclusive"`
+	Orders        int     `json:"orders"`
+	TaxableAmount float64 `json:"taxable_amount"`
+	TaxAmount     float64 `json:"tax_amount"`
+}
+
+func NewPeriodResponse(p model.Period) PeriodResponse {
+	return PeriodResponse{From: p.From, To: p.To}
+}
+
+func NewTaxReportResponse(p model.Period, lines []model.TaxReportLine) TaxReportResponse {
+	res := TaxReportResponse{
+		Period: NewPeriodResponse(p),
+		Lines:  []TaxReportLineItem{},
+	}
+
+	for _, l := range lines {
+		res.Lines = append(res.Lines, TaxReportLineItem{
+			Category:      l.Category,
+			Rate:          l.Rate,
+			Inclusive:     l.Inclusive,
+			Orders:        l.Orders,
+			TaxableAmount: l.TaxableAmount,
+			TaxAmount:     l.TaxAmount,
+		})
+		res.TaxableAmount += l.TaxableAmount
+		res.TaxAmount += l.TaxAmount
+	}
+
+	res.TaxableAmount = math.Round(res.TaxableAmount*100) / 100
+	res.TaxAmount = math.Round(res.TaxAmount*100) / 100
+
+	return res
+}
+
+type PaymentReportResponse struct {
+	Period   PeriodResponse          `json:"period"`
+	Lines    []PaymentReportLineItem `json:"lines"`
+	Amount   float64                 `json:"amount"`
+	Tips     float64                 `json:"tips"`
+	Refunded float64                 `json:"refunded"`
+}
+
+type PaymentReportLineItem struct {
+	Tender   string  `json:"tender"`
+	Payments int     `json:"payments"`
+	Amount   float64 `json:"amount"`
+	Tips     float64 `json:"tips"`
+	Refunds  int     `json:"refunds"`
+	Refunded float64 `json:"refunded"`
+}
+
+func NewPaymentReportResponse(p model.Period, lines []model.PaymentReportLine) PaymentReportResponse {
+	res := PaymentReportResponse{
+		Period: NewPeriodResponse(p),
+		Lines:  []PaymentReportLineItem{},
+	}
+
+	for _, l := range lines {
+		res.Lines = append(res.Lines, PaymentReportLineItem{
+			Tender:   l.Tender,
+			Payments: l.Payments,
+			Amount:   l.Amount,
+			Tips:     l.Tips,
+			Refunds:  l.Refunds,
+			Refunded: l.Refunded,
+		})
+		res.Amount += l.Amount
+		res.Tips += l.Tips
+		res.Refunded += l.Refunded
+	}
+
+	res.Amount = math.Round(res.Amount*100) / 100
+	res.Tips = math.Round(res.Tips*100) / 100
+	res.Refunded = math.Round(res.Refunded*100) / 100
+
+	return res
+}
+
+type SalesReportResponse struct {
+	Period   PeriodResponse        `json:"period"`
+	GroupBy  string                `json:"group_by"`
+	Status   string                `json:"status"`
+	Timezone string                `json:"timezone"`
+	Buckets  []SalesBucketResponse `json:"buckets"`
+	Totals   SalesTotalsResponse   `json:"totals"`
+}
+
+type SalesBucketResponse struct {
+	Start         time.Time `json:"start"`
+	Orders        int       `json:"orders"`
+	Revenue       float64   `json:"revenue"`
+	Refunds       float64   `json:"refunds"`
+	NetRevenue    float64   `json:"net_revenue"`
+	AverageTicket float64   `json:"average_ticket"`
+	Items         int       `json:"items"`
+}
+
+type SalesTotalsResponse struct {
+	Orders        int     `json:"orders"`
+	Revenue       float64 `json:"revenue"`
+	Refunds       float64 `json:"refunds"`
+	NetRevenue    float64 `json:"net_revenue"`
+	AverageTicket float64 `json:"average_ticket"`
+	Items         int     `json:"items"`
+}
+
+func NewSalesReportResponse(q model.SalesQuery, location *time.Location, buckets []model.SalesBucket) SalesReportResponse {
+	res := SalesReportResponse{
+		Period:   NewPeriodResponse(q.Period),
+		GroupBy:  q.GroupBy,
+		Status:   q.Status,
+		Timezone: location.String(),
+		Buckets:  []SalesBucketResponse{},
+	}
+
+	for _, b := range buckets {
+		res.Buckets = append(res.Buckets, SalesBucketResponse{
+			Start:         b.Start,
+			Orders:        b.Orders,
+			Revenue:       b.Revenue,
+			Refunds:       b.Refunds,
+			NetRevenue:    b.NetRevenue,
+			AverageTicket: b.AverageTicket,
+			Items:         b.Items,
+		})
+		res.Totals.Orders += b.Orders
+		res.Totals.Revenue += b.Revenue
+		res.Totals.Refunds += b.Refunds
+		res.Totals.Items += b.Items
+	}
+
+	res.Totals.Revenue = math.Round(res.Totals.Revenue*100) / 100
+	res.Totals.Refunds = math.Round(res.Totals.Refunds*100) / 100
+	res.Totals.NetRevenue = math.Round((res.Totals.Revenue-res.Totals.Refunds)*100) / 100
+	if res.Totals.Orders > 0 {
+		res.Totals.AverageTicket = math.Round(res.Totals.Revenue/float64(res.Totals.Orders)*100) / 100
+	}
+
+	return res
+}
+
+type PopularItemsResponse struct {
+	Period         PeriodResponse        `json:"period"`
+	PreviousPeriod PeriodResponse        `json:"previous_period"`
+	RankBy         string                `json:"rank_by"`
+	Category       string                `json:"category,omitempty"`
+	Items          []PopularItemResponse `json:"items"`
+}
+
+type PopularItemResponse struct {
+	Rank             int      `json:"rank"`
+	ProductID        int      `json:"product_id"`
+	Name             string   `json:"name"`
+	Category         string   `json:"category"`
+	Quantity         int      `json:"quantity"`
+	Revenue          float64  `json:"revenue"`
+	Share            float64  `json:"share_percent"`
+	PreviousQuantity int      `json:"previous_quantity"`
+	PreviousRevenue  float64  `json:"previous_revenue"`
+	Trend            *float64 `json:"trend_percent"`
+}
+
+func NewPopularItemsResponse(q model.PopularItemsQuery, items []model.PopularItem) PopularItemsResponse {
+	res := PopularItemsResponse{
+		Period:         NewPeriodResponse(q.Period),
+		PreviousPeriod: NewPeriodResponse(q.Previous()),
+		RankBy:         q.RankBy,
+		Category:       q.Category,
+		Items:          []PopularItemResponse{},
+	}
+
+	for i, item := range items {
+		res.Items = append(res.Items, PopularItemResponse{
+			Rank:             i + 1,
+			ProductID:        item.ProductID,
+			Name:             item.Name,
+			Category:         item.Category,
+			Quantity:         item.Quantity,
+			Revenue:          item.Revenue,
+			Share:            item.Share,
+			PreviousQuantity: item.PreviousQuantity,
+			PreviousRevenue:  item.PreviousRevenue,
+			Trend:            item.Trend,
+		})
+	}
+
+	return res
+}
+
+type IngredientUsageResponse struct {
+	Period      PeriodResponse                `json:"period"`
+	Source      string                        `json:"source"`
+	Ingredients []IngredientUsageItemResponse `json:"ingredients"`
+}
+
+type IngredientUsageItemResponse struct {
+	IngredientID int                  `json:"ingredient_id"`
+	Name         string               `json:"name"`
+	Unit         string               `json:"unit"`
+	Total        int                  `json:"total"`
+	Days         []DailyUsageResponse `json:"days"`
+}
+
+type DailyUsageResponse struct {
+	Date     string `json:"date"`
+	Quantity int    `json:"quantity"`
+}
+
+type InventoryForecastResponse struct {
+	WindowDays   int                          `json:"window_days"`
+	LeadTimeDays int                          `json:"lead_time_days"`
+	CoverDays    int                          `json:"cover_days"`
+	Ingredients  []IngredientForecastResponse `json:"ingredients"`
+}
+
+type IngredientForecastResponse struct {
+	IngredientID      int        `json:"ingredient_id"`
+	Name              string     `json:"name"`
+	Unit              string     `json:"unit"`
+	Quantity          int        `json:"quantity"`
+	AverageDailyUsage float64    `json:"average_daily_usage"`
+	DaysUntilStockout *float64   `json:"days_until_stockout"`
+	StockoutDate      *time.Time `json:"stockout_date"`
+	ReorderQuantity   int        `json:"reorder_quantity"`
+}
+
+func NewIngredientUsageResponse(q model.IngredientUsageQuery, usage []model.IngredientUsageSummary) IngredientUsageResponse {
+	res := IngredientUsageResponse{
+		Period:      NewPeriodResponse(q.Period),
+		Source:      q.Source,
+		Ingredients: []IngredientUsageItemResponse{},
+	}
+
+	for _, u := range usage {
+		item := IngredientUsageItemResponse{
+			IngredientID: u.IngredientID,
+			Name:         u.Name,
+			Unit:         u.Unit,
+			Total:        u.Quantity,
+			Days:         []DailyUsageResponse{},
+		}
+		for _, d := range u.Days {
+			item.Days = append(item.Days, DailyUsageResponse{Date: d.Day.Format(time.DateOnly), Quantity: d.Quantity})
+		}
+		res.Ingredients = append(res.Ingredients, item)
+	}
+
+	return res
+}
+
+func NewInventoryForecastResponse(q model.ForecastQuery, forecasts []model.InventoryForecast) InventoryForecastResponse {
+	res := InventoryForecastResponse{
+		WindowDays:   q.Window,
+		LeadTimeDays: q.LeadTime,
+		CoverDays:    q.Cover,
+		Ingredients:  []IngredientForecastResponse{},
+	}
+
+	for _, f := range forecasts {
+		res.Ingredients = append(res.Ingredients, IngredientForecastResponse{
+			IngredientID:      f.IngredientID,
+			Name:              f.Name,
+			Unit:              f.Unit,
+			Quantity:          f.Quantity,
+			AverageDailyUsage: f.AverageDailyUsage,
+			DaysUntilStockout: f.DaysUntilStockout,
+			StockoutDate:      f.StockoutDate,
+			ReorderQuantity:   f.ReorderQuantity,
+		})
+	}
+
+	return res
+}
+
+type HeatmapResponse struct {
+	Period                 PeriodResponse        `json:"period"`
+	Timezone               string                `json:"timezone"`
+	Orders                 int                   `json:"orders"`
+	Revenue                float64               `json:"revenue"`
+	AveragePrepTimeSeconds float64               `json:"average_prep_time_seconds"`
+	Cells                  []HeatmapCellResponse `json:"cells"`
+}
+
+type HeatmapCellResponse struct {
+	Weekday                string  `json:"weekday"`
+	Hour                   int     `json:"hour"`
+	Orders                 int     `json:"orders"`
+	Revenue                float64 `json:"revenue"`
+	AverageOrders          float64 `json:"average_orders"`
+	AveragePrepTimeSeconds float64 `json:"average_prep_time_seconds"`
+}
+
+func NewHeatmapResponse(heatmap *model.Heatmap, location *time.Location) HeatmapResponse {
+	res := HeatmapResponse{
+		Period:                 NewPeriodResponse(heatmap.Period),
+		Timezone:               location.String(),
+		Orders:                 heatmap.Orders,
+		Revenue:                heatmap.Revenue,
+		AveragePrepTimeSeconds: heatmap.AveragePrepTime.Seconds(),
+		Cells:                  []HeatmapCellResponse{},
+	}
+
+	for _, c := range heatmap.Cells {
+		res.Cells = append(res.Cells, HeatmapCellResponse{
+			Weekday:                strings.ToLower(c.Weekday.String()),
+			Hour:                   c.Hour,
+			Orders:                 c.Orders,
+			Revenue:                c.Revenue,
+			AverageOrders:          c.AverageOrders,
+			AveragePrepTimeSeconds: c.AveragePrepTime.Seconds(),
+		})
+	}
+
+	return res
+}
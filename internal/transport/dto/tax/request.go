@@ -0,0 +1,16 @@
+package dto
+
+import "coffee-shop/internal/model"
+
+type TaxRateRequest struct {
+	Rate      float64 `json:"rate"`
+	Inclusive bool    `json:"inclusive"`
+}
+
+func (r *TaxRateRequest) ToDomain(category string) model.TaxRate {
+	return model.TaxRate{
+		Category:  category,
+		Rate:      r.Rate,
+		Inclusive: r.Inclusive,
+	}
+}
@@ -0,0 +1,17 @@
+package dto
+
+import "coffee-shop/internal/model"
+
+type TaxRateResponse struct {
+	Category  string  `json:"category"`
+	Rate      float64 `json:"rate"`
+	Inclusive bool    `json:"inclusive"`
+}
+
+func NewTaxRateResponse(t model.TaxRate) TaxRateResponse {
+	return TaxRateResponse{
+		Category:  t.Category,
+		Rate:      t.Rate,
+		Inclusive: t.Inclusive,
+	}
+}
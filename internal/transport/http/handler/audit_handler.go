@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"god"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"coffee-shop/internal/model"
+	dto "coffee-shop/internal/transport/dto/audit"
+)
+
+type AuditHandler interface {
+	GetAuditLog(c *god.Context)
+}
+
+type auditHandler struct {
+	AuditService AuditService
+	location     *time.Location
+	log          *slog.Logger
+}
+
+// NewAuditHandler creates the audit log handler. Dates of the periods are taken
+// in the shop location.
+func NewAuditHandler(s AuditService, location *time.Location, l *slog.Logger) *auditHandler {
+	return &auditHandler{AuditService: s, location: location, log: l}
+}
+
+// GetAuditLog handles the HTTP request to retrieve the audit log, newest entries first.
+// The entries are selected with the "entity", "id", "from" and "to" query parameters,
+// which accept the same values as those of the reports. Without "from" or "to" the
+// period is not bounded on that side.
+func (h *auditHandler) GetAuditLog(c *god.Context) {
+	var req dto.AuditRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		handleBindError(c, model.ErrNotValidQuery, err)
+		return
+	}
+
+	var period model.Period
+	from, errFrom := parseTime(c.Query("from"), h.location, false)
+	to, errTo := parseTime(c.Query("to"), h.location, true)
+	if errFrom != nil || errTo != nil {
+		handleError(c, model.ErrNotValidPeriod)
+		return
+	}
+	period.From, period.To = from, to
+
+	entries, err := h.AuditService.RetrieveAuditLog(c.Request.Context(), req.ToDomain(period))
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	items := []dto.AuditEntryResponse{}
+	for _, e := range entries {
+		items = append(items, dto.NewAuditEntryResponse(e))
+	}
+
+	h.log.Debug("Retrieved audit log", slog.String("entity", req.Entity), slog.Int("entity_id", req.ID), slog.Int("entries", len(items)))
+	c.JSON(http.StatusOK, god.H{"body": items})
+}
@@ -0,0 +1,163 @@
+package handler
+
+import (
+	"god"
+	"log/slog"
+	"net/http"
+
+	"coffee-shop/internal/model"
+	dto "coffee-shop/internal/transport/dto/auth"
+)
+
+// apiKeyHeader is the request header that carries the API key of a staff member.
+const apiKeyHeader = "X-API-Key"
+
+type AuthHandler interface {
+	Login(c *god.Context)
+	AddStaff(c *god.Context)
+	GetAllStaff(c *god.Context)
+	UpdateStaff(c *god.Context)
+
+	// Authenticate is the middleware that stores the principal of every request
+	// that carries an access token or an API key.
+	Authenticate(c *god.Context)
+}
+
+type authHandler struct {
+	service      AuthService
+	log          *slog.Logger
+	authenticate god.HandlerFunc
+}
+
+func NewAuthHandler(s AuthService, l *slog.Logger) *authHandler {
+	h := &authHandler{service: s, log: l}
+	h.authenticate = god.Auth(god.AuthConfig{
+		Authenticate: h.principal,
+		Unauthorized: handleError,
+	})
+	return h
+}
+
+func (h *authHandler) Authenticate(c *god.Context) {
+	h.authenticate(c)
+}
+
+// principal returns the principal of the bearer token or the API key of the request,
+// or nil if it has neither.
+func (h *authHandler) principal(c *god.Context) (any, error) {
+	if token := c.BearerToken(); token != "" {
+		return h.service.Authenticate(c.Request.Context(), token)
+	}
+	if key := c.GetHeader(apiKeyHeader); key != "" {
+		return h.service.AuthenticateAPIKey(c.Request.Context(), key)
+	}
+	return nil, nil
+}
+
+// RequireRole returns a middleware that lets through only the requests of staff members
+// with the role or a more privileged one. It runs after Authenticate.
+func RequireRole(role string) god.HandlerFunc {
+	return func(c *god.Context) {
+		principal, ok := Principal(c)
+		if !ok {
+			c.Abort()
+			handleError(c, model.ErrUnauthenticated)
+			return
+		}
+		if !principal.HasRole(role) {
+			c.Abort()
+			handleError(c, model.ErrForbidden.WithMessage("the request requires the "+role+" role"))
+			return
+		}
+		c.Next()
+	}
+}
+
+// Principal returns the staff member the request was authenticated as.
+func Principal(c *god.Context) (model.Principal, bool) {
+	value, ok := c.Principal()
+	if !ok {
+		return model.Principal{}, false
+	}
+	principal, ok := value.(model.Principal)
+	return principal, ok
+}
+
+// Login handles the HTTP request to sign in with a username and a password.
+func (h *authHandler) Login(c *god.Context) {
+	var req dto.LoginRequest
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		handleBindError(c, model.ErrNotValidBody, err)
+		return
+	}
+
+	token, err := h.service.Login(c.Request.Context(), req.Username, req.Password)
+	if err != nil {
+		h.log.Warn("Failed sign in", slog.String("username", req.Username), slog.String("error", err.Error()))
+		handleError(c, err)
+		return
+	}
+
+	h.log.Info("Staff member signed in", slog.String("username", token.Principal.Username), slog.String("role", token.Principal.Role))
+	c.JSON(http.StatusOK, god.H{"body": dto.NewTokenResponse(*token)})
+}
+
+// AddStaff handles the HTTP request to create a staff member.
+func (h *authHandler) AddStaff(c *god.Context) {
+	var req dto.StaffRequest
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		handleBindError(c, model.ErrNotValidBody, err)
+		return
+	}
+
+	staff, apiKey, err := h.service.AddStaff(c.Request.Context(), req.ToDomain(), req.Password, req.APIKey)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	h.log.Info("Staff member is created", slog.Int("staff_id", staff.ID), slog.String("username", staff.Username), slog.String("role", staff.Role))
+	c.JSON(http.StatusCreated, god.H{"body": dto.NewStaffResponse(*staff, apiKey)})
+}
+
+// GetAllStaff handles the HTTP request to retrieve all staff members.
+func (h *authHandler) GetAllStaff(c *god.Context) {
+	staff, err := h.service.RetrieveStaff(c.Request.Context())
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	items := []dto.StaffResponse{}
+	for _, s := range staff {
+		items = append(items, dto.NewStaffResponse(s, ""))
+	}
+
+	c.JSON(http.StatusOK, god.H{"body": items})
+}
+
+// UpdateStaff handles the HTTP request to change the role or the credentials of a staff member.
+func (h *authHandler) UpdateStaff(c *god.Context) {
+	id, ok := pathID(c, model.ErrNotValidStaffID)
+	if !ok {
+		return
+	}
+
+	var req dto.StaffUpdateRequest
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		handleBindError(c, model.ErrNotValidBody, err)
+		return
+	}
+
+	staff, apiKey, err := h.service.UpdateStaff(c.Request.Context(), id, req.Role, req.Password, req.NewAPIKey)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	h.log.Info("Staff member is updated", slog.Int("staff_id", staff.ID), slog.String("role", staff.Role), slog.Bool("new_api_key", apiKey != ""))
+	c.JSON(http.StatusOK, god.H{"body": dto.NewStaffResponse(*staff, apiKey)})
+}
@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"god"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"coffee-shop/internal/model"
+)
+
+func TestRequireRole(t *testing.T) {
+	barista := model.Principal{StaffID: 1, Username: "ann", Role: model.RoleBarista}
+	manager := model.Principal{StaffID: 2, Username: "bob", Role: model.RoleManager}
+	admin := model.Principal{StaffID: 3, Username: "eve", Role: model.RoleAdmin}
+	unknown := model.Principal{StaffID: 4, Username: "joe", Role: "owner"}
+
+	tests := []struct {
+		name      string
+		principal any
+		role      string
+		want      int
+	}{
+		{"no principal", nil, model.RoleBarista, http.StatusUnauthorized},
+		{"principal of another type", "ann", model.RoleBarista, http.StatusUnauthorized},
+		{"barista as barista", barista, model.RoleBarista, http.StatusOK},
+		{"barista as manager", barista, model.RoleManager, http.StatusForbidden},
+		{"barista as admin", barista, model.RoleAdmin, http.StatusForbidden},
+		{"manager as barista", manager, model.RoleBarista, http.StatusOK},
+		{"manager as manager", manager, model.RoleManager, http.StatusOK},
+		{"manager as admin", manager, model.RoleAdmin, http.StatusForbidden},
+		{"admin as barista", admin, model.RoleBarista, http.StatusOK},
+		{"admin as admin", admin, model.RoleAdmin, http.StatusOK},
+		{"unknown role", unknown, model.RoleBarista, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handled := false
+
+			r := god.Default()
+			r.Use(func(c *god.Context) {
+				if tt.principal != nil {
+					c.Set(god.PrincipalKey, tt.principal)
+				}
+				c.Next()
+			})
+			r.GET("/menu", RequireRole(tt.role), func(c *god.Context) {
+				handled = true
+				c.Status(http.StatusOK)
+			})
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/menu", nil))
+
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+			if handled != (tt.want == http.StatusOK) {
+				t.Errorf("handled = %v with status %d", handled, w.Code)
+			}
+			if challenged := w.Header().Get("WWW-Authenticate") != ""; challenged != (tt.want == http.StatusUnauthorized) {
+				t.Errorf("WWW-Authenticate = %q with status %d", w.Header().Get("WWW-Authenticate"), w.Code)
+			}
+		})
+	}
+}
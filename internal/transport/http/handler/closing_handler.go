@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"god"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"coffee-shop/internal/model"
+	dto "coffee-shop/internal/transport/dto/closing"
+)
+
+// closingFormatJSON is the default format of a stored end-of-day report; the
+// receipt formats render it for printing or export.
+const closingFormatJSON = "json"
+
+type ClosingHandler interface {
+	CloseDay(c *god.Context)
+	GetClosings(c *god.Context)
+	GetClosing(c *god.Context)
+}
+
+type closingHandler struct {
+	ClosingService ClosingService
+	location       *time.Location
+	log            *slog.Logger
+}
+
+// NewClosingHandler creates the end-of-day handler. Business dates are taken in the shop location.
+func NewClosingHandler(s ClosingService, location *time.Location, l *slog.Logger) *closingHandler {
+	return &closingHandler{ClosingService: s, location: location, log: l}
+}
+
+// CloseDay handles the HTTP request to close a business day and store its end-of-day report.
+// The "business_date" field of the body (2006-01-02) defaults to today.
+func (h *closingHandler) CloseDay(c *god.Context) {
+	var req dto.ClosingRequest
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		handleBindError(c, model.ErrNotValidBody, err)
+		return
+	}
+
+	date, err := parseTime(req.BusinessDate, h.location, false)
+	if err != nil {
+		handleError(c, model.ErrNotValidBusinessDate)
+		return
+	}
+
+	closing, err := h.ClosingService.CloseDay(c.Request.Context(), req.ToDomain(date))
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	h.log.Info("Business day is closed", slog.Int("ClosingId", closing.ID), slog.Time("BusinessDate", closing.BusinessDate),
+		slog.Int("OpenOrders", len(closing.OpenOrders)), slog.Float64("CashDifference", closing.CashDifference))
+	c.JSON(http.StatusCreated, god.H{"body": dto.NewClosingResponse(*closing)})
+}
+
+// GetClosings handles the HTTP request to retrieve the end-of-day reports of all closed days.
+func (h *closingHandler) GetClosings(c *god.Context) {
+	closings, err := h.ClosingService.RetrieveClosings(c.Request.Context())
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, god.H{"body": dto.NewClosingsResponse(closings)})
+}
+
+// GetClosing handles the HTTP request to retrieve the end-of-day report of a business date.
+// The format is given by the "format" query parameter: json (default), text, html or escpos.
+func (h *closingHandler) GetClosing(c *god.Context) {
+	date, err := time.ParseInLocation(dateLayout, c.PathValue("date"), h.location)
+	if err != nil {
+		handleError(c, model.ErrNotValidBusinessDate)
+		return
+	}
+
+	format := c.DefaultQuery("format", closingFormatJSON)
+	if format != closingFormatJSON {
+		data, contentType, err := h.ClosingService.RenderClosing(c.Request.Context(), date, format)
+		if err != nil {
+			handleError(c, err)
+			return
+		}
+
+		c.Data(http.StatusOK, contentType, data)
+		return
+	}
+
+	closing, err := h.ClosingService.RetrieveClosing(c.Request.Context(), date)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, god.H{"body": dto.NewClosingResponse(*closing)})
+}
@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"errors"
+	"god"
+	"god/binding"
+	"net/http"
+
+	"coffee-shop/internal/model"
+)
+
+// statuses maps the kinds of errors to the HTTP status codes.
+var statuses = map[model.Kind]int{
+	model.KindInternal:             http.StatusInternalServerError,
+	model.KindInvalid:              http.StatusBadRequest,
+	model.KindNotFound:             http.StatusNotFound,
+	model.KindConflict:             http.StatusConflict,
+	model.KindUnsupported:          http.StatusUnsupportedMediaType,
+	model.KindNotAcceptable:        http.StatusNotAcceptable,
+	model.KindTooLarge:             http.StatusRequestEntityTooLarge,
+	model.KindUnauthorized:         http.StatusUnauthorized,
+	model.KindForbidden:            http.StatusForbidden,
+	model.KindUnprocessable:        http.StatusUnprocessableEntity,
+	model.KindPreconditionFailed:   http.StatusPreconditionFailed,
+	model.KindPreconditionRequired: http.StatusPreconditionRequired,
+}
+
+// handleError responds with the error as an RFC 7807 problem whose code member
+// holds the stable code of the error. The error is added to the errors of the request,
+// which are logged after it. Errors that are not errors of the shop are reported as
+// internal errors, without their text. The records that keep a record from being
+// deleted are listed in the references member as {entity, count}.
+func handleError(c *god.Context, err error) {
+	c.Error(err)
+
+	var shopErr *model.Error
+	if !errors.As(err, &shopErr) {
+		shopErr = model.ErrInternal
+	}
+	if shopErr.Kind == model.KindUnauthorized {
+		c.Header("WWW-Authenticate", `Bearer realm="coffee-shop"`)
+	}
+
+	p := problem(shopErr)
+	var referenced *model.ReferencedError
+	if errors.As(err, &referenced) {
+		refs := make([]god.H, len(referenced.References))
+		for i, ref := range referenced.References {
+			refs[i] = god.H{"entity": ref.EntityType, "count": ref.Count}
+		}
+		p.Extensions["references"] = refs
+	}
+	c.Problem(p)
+}
+
+// handleBindError responds to a request that could not be bound with invalid, e.g.
+// model.ErrNotValidBody. Fields that broke their rules are listed in the errors
+// member as {field, rule, message}.
+func handleBindError(c *god.Context, invalid *model.Error, err error) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		handleError(c, model.ErrBodyTooLarge)
+		return
+	}
+
+	var fields binding.ValidationErrors
+	if !errors.As(err, &fields) {
+		handleError(c, invalid.WithMessage(err.Error()))
+		return
+	}
+
+	p := problem(invalid)
+	p.Extensions["errors"] = fields
+	c.Problem(p)
+}
+
+// handlePatchError responds to a request whose patch could not be applied to the
+// record. A malformed patch and a patched record that breaks the rules of the request
+// are reported as model.ErrNotValidBody.
+func handlePatchError(c *god.Context, err error) {
+	switch {
+	case errors.Is(err, binding.ErrPatchMediaType):
+		c.Header("Accept-Patch", binding.MIMEMergePatchJSON+", "+binding.MIMEJSONPatch)
+		handleError(c, model.ErrNotValidPatch)
+	case errors.Is(err, binding.ErrPatchFailed):
+		handleError(c, model.ErrPatchConflict.WithMessage(err.Error()))
+	default:
+		handleBindError(c, model.ErrNotValidBody, err)
+	}
+}
+
+func problem(err *model.Error) god.Problem {
+	return god.Problem{
+		Title:      err.Title,
+		Status:     statuses[err.Kind],
+		Detail:     err.Message,
+		Extensions: god.H{"code": err.Code},
+	}
+}
+
+// rejectionReason returns the function that reports the code and the message of an
+// error for a single row of a batch or an import. Errors that are not errors of the
+// shop are reported as internal errors, without their text, and are added to the
+// errors of the request to be logged after it.
+func rejectionReason(c *god.Context) func(error) (string, string) {
+	return func(err error) (string, string) {
+		var shopErr *model.Error
+		if errors.As(err, &shopErr) {
+			return shopErr.Code, shopErr.Message
+		}
+
+		c.Error(err)
+		return model.ErrInternal.Code, model.ErrInternal.Message
+	}
+}
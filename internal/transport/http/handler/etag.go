@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"god"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"coffee-shop/internal/model"
+)
+
+// etag returns the entity tag of a record with the version.
+func etag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// setETag sets the ETag header of the response to the tag of the version.
+func setETag(c *god.Context, version int) {
+	c.Header("ETag", etag(version))
+}
+
+// notModified sets the ETag of a record with the version and reports whether the
+// If-None-Match header of the request matches it. In that case it responds with
+// 304 Not Modified, and the record must not be sent.
+func notModified(c *god.Context, version int) bool {
+	setETag(c, version)
+
+	header := c.GetHeader("If-None-Match")
+	if header == "" {
+		return false
+	}
+
+	tag := etag(version)
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == tag {
+			c.Status(http.StatusNotModified)
+			return true
+		}
+	}
+	return false
+}
+
+// ifMatch returns the version in the If-Match header of a request that changes a
+// record, or 0 for "*", which matches any version. The header may list several tags;
+// then current is called for the version of the record, which is returned if it is
+// listed. It responds with model.ErrVersionRequired if the header is missing and with
+// model.ErrVersionMismatch if it cannot match a version, as weak and malformed tags
+// cannot.
+func ifMatch(c *god.Context, current func() (int, error)) (int, bool) {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" {
+		handleError(c, model.ErrVersionRequired)
+		return 0, false
+	}
+
+	var versions []int
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return 0, true
+		}
+
+		tag, ok := strings.CutPrefix(candidate, `"`)
+		if ok {
+			tag, ok = strings.CutSuffix(tag, `"`)
+		}
+		version, err := strconv.Atoi(tag)
+		if ok && err == nil && version > 0 {
+			versions = append(versions, version)
+		}
+	}
+
+	switch len(versions) {
+	case 0:
+		handleError(c, model.ErrVersionMismatch)
+		return 0, false
+	case 1:
+		// The version is checked when the record is changed
+		return versions[0], true
+	}
+
+	version, err := current()
+	if err != nil {
+		handleError(c, err)
+		return 0, false
+	}
+	if !slices.Contains(versions, version) {
+		handleError(c, model.ErrVersionMismatch)
+		return 0, false
+	}
+	return version, true
+}
+
+// setNextETag sets the ETag of a record changed under the version in the If-Match
+// header. For "*" the version is not known, so no ETag is set.
+func setNextETag(c *god.Context, version int) {
+	if version != 0 {
+		setETag(c, version+1)
+	}
+}
@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"god"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"coffee-shop/internal/model"
+)
+
+func TestIfMatch(t *testing.T) {
+	tests := []struct {
+		name        string
+		header      string
+		current     int
+		err         error
+		wantStatus  int
+		wantVersion int
+		wantLookup  bool
+	}{
+		{name: "missing", wantStatus: http.StatusPreconditionRequired},
+		{name: "any version", header: "*", wantStatus: http.StatusOK},
+		{name: "single tag", header: `"3"`, current: 4, wantStatus: http.StatusOK, wantVersion: 3},
+		{name: "weak tag", header: `W/"3"`, wantStatus: http.StatusPreconditionFailed},
+		{name: "malformed tag", header: `3`, wantStatus: http.StatusPreconditionFailed},
+		{name: "zero version", header: `"0"`, wantStatus: http.StatusPreconditionFailed},
+		{name: "list with the current version", header: `"2", "3"`, current: 3, wantStatus: http.StatusOK, wantVersion: 3, wantLookup: true},
+		{name: "list without the current version", header: `"1","2"`, current: 3, wantStatus: http.StatusPreconditionFailed, wantLookup: true},
+		{name: "list with a weak tag", header: `W/"4", "3"`, current: 4, wantStatus: http.StatusOK, wantVersion: 3},
+		{name: "list with any version", header: `"1", *`, wantStatus: http.StatusOK},
+		{name: "list of a missing record", header: `"1", "2"`, err: model.ErrMenuItemNotFound, wantStatus: http.StatusNotFound, wantLookup: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var version int
+			looked := false
+
+			r := god.Default()
+			r.PUT("/menu/1", func(c *god.Context) {
+				v, ok := ifMatch(c, func() (int, error) {
+					looked = true
+					return tt.current, tt.err
+				})
+				if !ok {
+					return
+				}
+				version = v
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodPut, "/menu/1", nil)
+			if tt.header != "" {
+				req.Header.Set("If-Match", tt.header)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if version != tt.wantVersion {
+				t.Errorf("version = %d, want %d", version, tt.wantVersion)
+			}
+			if looked != tt.wantLookup {
+				t.Errorf("current version read = %v, want %v", looked, tt.wantLookup)
+			}
+		})
+	}
+}
+
+func TestNotModified(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		want   int
+	}{
+		{"no header", "", http.StatusOK},
+		{"matching tag", `"3"`, http.StatusNotModified},
+		{"weak tag", `W/"3"`, http.StatusNotModified},
+		{"list with the tag", `"1", "3"`, http.StatusNotModified},
+		{"any tag", "*", http.StatusNotModified},
+		{"other tag", `"2"`, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := god.Default()
+			r.GET("/menu/1", func(c *god.Context) {
+				if notModified(c, 3) {
+					return
+				}
+				c.String(http.StatusOK, "latte")
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/menu/1", nil)
+			if tt.header != "" {
+				req.Header.Set("If-None-Match", tt.header)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+			if got := w.Header().Get("ETag"); got != `"3"` {
+				t.Errorf("ETag = %q, want %q", got, `"3"`)
+			}
+			if tt.want == http.StatusNotModified && w.Body.Len() != 0 {
+				t.Errorf("body of 304 = %q, want none", w.Body)
+			}
+		})
+	}
+}
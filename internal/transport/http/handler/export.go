@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"fmt"
+	"god"
+	"god/binding"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"coffee-shop/internal/export"
+	"coffee-shop/internal/model"
+)
+
+// exportFormats are the response formats of the list and report endpoints, the default first.
+var exportFormats = []string{binding.MIMEJSON, export.MIMECSV, export.MIMEXLSX}
+
+// Exporter streams lists and reports as CSV or XLSX files.
+type Exporter struct {
+	columns  map[string][]string
+	location *time.Location
+	log      *slog.Logger
+}
+
+// NewExporter creates the exporter. columns holds the default column keys by export
+// name; exports without defaults have all their columns. Times are written in the
+// shop location.
+func NewExporter(columns map[string][]string, location *time.Location, l *slog.Logger) *Exporter {
+	return &Exporter{columns: columns, location: location, log: l}
+}
+
+// responseFormat negotiates the response format of a list or report endpoint.
+// It responds with 406 and reports false if none of the formats is acceptable.
+func responseFormat(c *god.Context) (string, bool) {
+	format := c.NegotiateFormat(exportFormats...)
+	if format == "" {
+		handleError(c, model.ErrNotAcceptable)
+		return "", false
+	}
+	return format, true
+}
+
+// writeExport streams the rows as a CSV or XLSX attachment named after the export.
+// The columns are chosen by the "columns" query parameter, a comma separated list of
+// column keys, or else by the default columns of the export. As the response is sent
+// row by row, errors that occur after the first row can only be logged.
+func writeExport[T any](c *god.Context, e *Exporter, format, name string, columns export.Columns[T], each func(fn func(row T) error) error) {
+	keys := e.columns[name]
+	if value := c.Query("columns"); value != "" {
+		keys = strings.Split(value, ",")
+	}
+
+	selected, err := columns.Select(keys)
+	if err != nil {
+		handleError(c, model.ErrNotValidExportColumns)
+		return
+	}
+
+	filename := fmt.Sprintf("%s-%s%s", name, time.Now().In(e.location).Format(dateLayout), export.Extension(format))
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+
+	opts := export.Options{Sheet: name, Location: e.location}
+	err = c.DataStream(http.StatusOK, contentType(format), func(w io.Writer) error {
+		return export.Write(w, format, opts, selected, each)
+	})
+	if err != nil {
+		e.log.Error("Export is interrupted", slog.String("export", name), slog.String("error", err.Error()))
+		return
+	}
+
+	e.log.Debug("Exported", slog.String("export", name), slog.String("format", format))
+}
+
+// contentType adds the charset to the text formats.
+func contentType(format string) string {
+	if strings.HasPrefix(format, "text/") {
+		return format + "; charset=utf-8"
+	}
+	return format
+}
@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"god"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"coffee-shop/internal/model"
+)
+
+type IdempotencyHandler interface {
+	// Idempotent is the middleware that replays the stored response of a request retried
+	// with the same Idempotency-Key header. It runs after the role requirement of the route.
+	Idempotent(c *god.Context)
+}
+
+type idempotencyHandler struct {
+	service    IdempotencyService
+	log        *slog.Logger
+	idempotent god.HandlerFunc
+}
+
+func NewIdempotencyHandler(s IdempotencyService, l *slog.Logger) *idempotencyHandler {
+	h := &idempotencyHandler{service: s, log: l}
+	h.idempotent = god.Idempotency(god.IdempotencyConfig{
+		Store:  idempotencyStore{service: s},
+		Scope:  idempotencyScope,
+		Reject: h.reject,
+	})
+	return h
+}
+
+func (h *idempotencyHandler) Idempotent(c *god.Context) {
+	h.idempotent(c)
+}
+
+// idempotencyScope keeps the keys of every staff member apart, so that a key cannot
+// replay the response of a request made by someone else.
+func idempotencyScope(c *god.Context) string {
+	principal, ok := Principal(c)
+	if !ok {
+		return "anonymous"
+	}
+	return "staff-" + strconv.Itoa(principal.StaffID)
+}
+
+// reject responds to a request that cannot be handled with its idempotency key.
+func (h *idempotencyHandler) reject(c *god.Context, err error) {
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.Is(err, god.ErrIdempotencyKeyInvalid):
+		err = model.ErrNotValidIdempotencyKey
+	case errors.Is(err, god.ErrIdempotencyKeyReused):
+		err = model.ErrIdempotencyKeyReused
+	case errors.Is(err, god.ErrIdempotencyInProgress):
+		err = model.ErrIdempotencyInProgress
+	case errors.As(err, &tooLarge):
+		err = model.ErrBodyTooLarge
+	}
+
+	h.log.Warn("Rejected idempotent request", slog.String("path", c.Request.URL.Path), slog.String("error", err.Error()))
+	handleError(c, err)
+}
+
+// idempotencyStore keeps the idempotent requests of god with the idempotency service.
+type idempotencyStore struct {
+	service IdempotencyService
+}
+
+func (s idempotencyStore) Begin(ctx context.Context, key, requestHash string) (*god.IdempotentResponse, error) {
+	record, err := s.service.BeginRequest(ctx, key, requestHash)
+	if err != nil || record == nil {
+		return nil, err
+	}
+
+	return &god.IdempotentResponse{
+		RequestHash: record.RequestHash,
+		Completed:   record.Completed,
+		Status:      record.Status,
+		Header:      record.Header,
+		Body:        record.Body,
+	}, nil
+}
+
+func (s idempotencyStore) Complete(ctx context.Context, key string, response god.IdempotentResponse) error {
+	return s.service.CompleteRequest(ctx, model.IdempotencyRecord{
+		Key:         key,
+		RequestHash: response.RequestHash,
+		Status:      response.Status,
+		Header:      response.Header,
+		Body:        response.Body,
+	})
+}
+
+func (s idempotencyStore) Release(ctx context.Context, key string) error {
+	return s.service.ReleaseRequest(ctx, key)
+}
@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"god"
+	"god/binding"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"coffee-shop/internal/model"
+	dto "coffee-shop/internal/transport/dto/imports"
+)
+
+// maxImportSize limits the size of an import file.
+const maxImportSize = 10 << 20
+
+type ImportHandler interface {
+	ImportMenu(c *god.Context)
+	ImportInventory(c *god.Context)
+}
+
+type importHandler struct {
+	ImportService ImportService
+	log           *slog.Logger
+}
+
+func NewImportHandler(s ImportService, l *slog.Logger) *importHandler {
+	return &importHandler{ImportService: s, log: l}
+}
+
+// ImportMenu handles the HTTP request to create or update menu items and their recipes
+// from a CSV or JSON file. With "dry_run=true" the changes are only previewed. The request
+// is answered with 422 and the row errors if any row is rejected, and nothing is changed.
+func (h *importHandler) ImportMenu(c *god.Context) {
+	dryRun, format, file, ok := h.importRequest(c)
+	if !ok {
+		return
+	}
+	defer file.Close()
+
+	rows, err := dto.ParseMenu(format, file)
+	if err != nil {
+		handleError(c, model.ErrNotValidImportFile.WithMessage(err.Error()))
+		return
+	}
+
+	result, err := h.ImportService.ImportMenu(c.Request.Context(), rows, dryRun)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	h.respond(c, "menu", result)
+}
+
+// ImportInventory handles the HTTP request to create or update inventory items from
+// a CSV or JSON file. It takes the same parameters as ImportMenu.
+func (h *importHandler) ImportInventory(c *god.Context) {
+	dryRun, format, file, ok := h.importRequest(c)
+	if !ok {
+		return
+	}
+	defer file.Close()
+
+	rows, err := dto.ParseInventory(format, file)
+	if err != nil {
+		handleError(c, model.ErrNotValidImportFile.WithMessage(err.Error()))
+		return
+	}
+
+	result, err := h.ImportService.ImportInventory(c.Request.Context(), rows, dryRun)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	h.respond(c, "inventory", result)
+}
+
+func (h *importHandler) respond(c *god.Context, name string, result *model.ImportResult) {
+	status := http.StatusOK
+	if result.Rejected > 0 {
+		status = http.StatusUnprocessableEntity
+	}
+
+	h.log.Info("Imported "+name, slog.Bool("DryRun", result.DryRun), slog.Bool("Applied", result.Applied),
+		slog.Int("Created", result.Created), slog.Int("Updated", result.Updated), slog.Int("Rejected", result.Rejected))
+	c.JSON(status, god.H{"body": dto.NewImportResponse(*result, rejectionReason(c))})
+}
+
+// importRequest binds the "dry_run" parameter and opens the import file. The file is either
+// the request body or the "file" field of a multipart form, whose fields may carry the parameters too. Its format is taken from the
+// "format" parameter, the file name extension or the content type, in this order.
+func (h *importHandler) importRequest(c *god.Context) (bool, string, io.ReadCloser, bool) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize)
+
+	contentType, _, _ := mime.ParseMediaType(c.Request.Header.Get("Content-Type"))
+	multipart := contentType == "multipart/form-data"
+	bind := c.ShouldBindQuery
+	if multipart {
+		bind = c.ShouldBindForm
+	}
+
+	var req dto.ImportRequest
+	if err := bind(&req); err != nil {
+		handleBindError(c, model.ErrNotValidQuery, err)
+		return false, "", nil, false
+	}
+
+	format := strings.ToLower(req.Format)
+	var file io.ReadCloser = c.Request.Body
+	if multipart {
+		if req.File == nil {
+			handleError(c, model.ErrNotValidImportFile.WithMessage("import file must be sent in the file field"))
+			return false, "", nil, false
+		}
+		f, err := req.File.Open()
+		if err != nil {
+			handleError(c, err)
+			return false, "", nil, false
+		}
+		file = f
+		contentType, _, _ = mime.ParseMediaType(req.File.Header.Get("Content-Type"))
+		if format == "" {
+			format = strings.TrimPrefix(strings.ToLower(filepath.Ext(req.File.Filename)), ".")
+		}
+	}
+
+	if format == "" {
+		switch contentType {
+		case binding.MIMECSV, "application/csv":
+			format = dto.FormatCSV
+		case binding.MIMEJSON:
+			format = dto.FormatJSON
+		}
+	}
+
+	if format != dto.FormatCSV && format != dto.FormatJSON {
+		file.Close()
+		handleError(c, model.ErrNotValidImportFormat)
+		return false, "", nil, false
+	}
+
+	return req.DryRun, format, file, true
+}
@@ -3,28 +3,104 @@ package handler
 import (
 	"coffee-shop/internal/model"
 	"context"
+	"time"
 )
 
 type InventoryService interface {
 	AddInventoryItem(ctx context.Context, item model.Inventory) error
-	RetrieveInventoryItems(ctx context.Context) ([]model.Inventory, error)
+	RetrieveInventoryItems(ctx context.Context, includeArchived bool) ([]model.Inventory, error)
+	ExportInventoryItems(ctx context.Context, includeArchived bool, fn func(item model.Inventory) error) error
 	RetrieveInventoryItem(ctx context.Context, id int) (*model.Inventory, error)
 	UpdateInventoryItem(ctx context.Context, id int, item model.Inventory) error
-	DeleteInventoryItem(ctx context.Context, id int) error
+	PatchInventoryItem(ctx context.Context, id int, item model.Inventory) (*model.Inventory, error)
+	ArchiveInventoryItem(ctx context.Context, id int, version int) error
+	RestoreInventoryItem(ctx context.Context, id int, version int) (*model.Inventory, error)
+	DeleteInventoryItem(ctx context.Context, id int, version int) error
 }
 
 type MenuService interface {
 	AddMenuItem(ctx context.Context, menu model.MenuItem, ingredients []model.MenuItemIngredients) error
-	RetrieveMenuItems(ctx context.Context) ([]model.MenuItem, error)
+	RetrieveMenuItems(ctx context.Context, includeArchived bool) ([]model.MenuItem, error)
 	RetrieveMenuItemWithId(ctx context.Context, id int) (*model.MenuItem, []model.MenuItemIngredients, error)
 	UpdateMenuItem(ctx context.Context, id int, item model.MenuItem, ingredients []model.MenuItemIngredients) error
-	DeleteMenuItem(ctx context.Context, id int) error
+	PatchMenuItem(ctx context.Context, id int, item model.MenuItem, ingredients []model.MenuItemIngredients) (*model.MenuItem, []model.MenuItemIngredients, error)
+	ArchiveMenuItem(ctx context.Context, id int, version int) error
+	RestoreMenuItem(ctx context.Context, id int, version int) (*model.MenuItem, []model.MenuItemIngredients, error)
+	DeleteMenuItem(ctx context.Context, id int, version int) error
 }
 
 type OrderService interface {
-	AddOrder(ctx context.Context, order model.Order) error
+	AddOrder(ctx context.Context, order model.Order) (int, error)
 	RetrieveOrders(ctx context.Context) ([]model.Order, error)
+	ExportOrders(ctx context.Context, fn func(order model.Order) error) error
 	RetrieveOrder(ctx context.Context, id int) (*model.Order, error)
 	UpdateOrder(ctx context.Context, id int, order model.Order) error
-	DeleteOrder(ctx context.Context, id int) error
+	DeleteOrder(ctx context.Context, id int, version int) error
+	CloseOrder(ctx context.Context, id int) (*model.Order, error)
+	RetrieveOrderTotals(ctx context.Context, id int) (*model.OrderTotals, error)
+	ProcessBatch(ctx context.Context, orders []model.Order) (*model.BatchResult, error)
+}
+
+type ImportService interface {
+	ImportMenu(ctx context.Context, rows []model.MenuImportRow, dryRun bool) (*model.ImportResult, error)
+	ImportInventory(ctx context.Context, rows []model.InventoryImportRow, dryRun bool) (*model.ImportResult, error)
+}
+
+type TaxService interface {
+	RetrieveTaxRates(ctx context.Context) ([]model.TaxRate, error)
+	RetrieveTaxRate(ctx context.Context, category string) (*model.TaxRate, error)
+	SetTaxRate(ctx context.Context, rate model.TaxRate) error
+	DeleteTaxRate(ctx context.Context, category string) error
+}
+
+type ReportService interface {
+	GetTaxReport(ctx context.Context, period model.Period) ([]model.TaxReportLine, error)
+	GetPaymentReport(ctx context.Context, period model.Period) ([]model.PaymentReportLine, error)
+	GetSalesReport(ctx context.Context, q model.SalesQuery) ([]model.SalesBucket, error)
+	GetPopularItems(ctx context.Context, q model.PopularItemsQuery) ([]model.PopularItem, error)
+	GetIngredientUsage(ctx context.Context, q model.IngredientUsageQuery) ([]model.IngredientUsageSummary, error)
+	GetInventoryForecast(ctx context.Context, q model.ForecastQuery) ([]model.InventoryForecast, error)
+	GetHeatmap(ctx context.Context, period model.Period) (*model.Heatmap, error)
+}
+
+type PaymentService interface {
+	AddPayment(ctx context.Context, orderID int, payment model.Payment) (*model.Payment, error)
+	RetrievePayments(ctx context.Context, orderID int) ([]model.Payment, *model.PaymentBalance, error)
+	SplitBill(ctx context.Context, orderID int, req model.SplitRequest) ([]model.SplitShare, error)
+	RefundOrder(ctx context.Context, orderID int, req model.RefundRequest) ([]model.Payment, error)
+}
+
+type OrderEventSubscriber interface {
+	Subscribe(lastEventID uint64) ([]model.OrderEvent, <-chan model.OrderEvent, func())
+}
+
+type IdempotencyService interface {
+	BeginRequest(ctx context.Context, key, requestHash string) (*model.IdempotencyRecord, error)
+	CompleteRequest(ctx context.Context, record model.IdempotencyRecord) error
+	ReleaseRequest(ctx context.Context, key string) error
+}
+
+type AuditService interface {
+	RetrieveAuditLog(ctx context.Context, q model.AuditQuery) ([]model.AuditEntry, error)
+}
+
+type ClosingService interface {
+	CloseDay(ctx context.Context, req model.ClosingRequest) (*model.DailyClosing, error)
+	RetrieveClosing(ctx context.Context, date time.Time) (*model.DailyClosing, error)
+	RetrieveClosings(ctx context.Context) ([]model.DailyClosing, error)
+	RenderClosing(ctx context.Context, date time.Time, format string) ([]byte, string, error)
+}
+
+type ReceiptService interface {
+	RenderReceipt(ctx context.Context, orderID int, format string) ([]byte, string, error)
+	RenderTicket(ctx context.Context, orderID int, format string) ([]byte, string, error)
+}
+
+type AuthService interface {
+	Login(ctx context.Context, username, password string) (*model.Token, error)
+	Authenticate(ctx context.Context, token string) (model.Principal, error)
+	AuthenticateAPIKey(ctx context.Context, key string) (model.Principal, error)
+	AddStaff(ctx context.Context, staff model.Staff, password string, withAPIKey bool) (*model.Staff, string, error)
+	RetrieveStaff(ctx context.Context) ([]model.Staff, error)
+	UpdateStaff(ctx context.Context, id int, role, password string, newAPIKey bool) (*model.Staff, string, error)
 }
@@ -1,12 +1,12 @@
 package handler
 
 import (
-	"context"
 	"god"
+	"god/binding"
 	"log/slog"
 	"net/http"
-	"strconv"
 
+	"coffee-shop/internal/model"
 	dto "coffee-shop/internal/transport/dto/inventory"
 	"coffee-shop/internal/transport/dto/response"
 )
@@ -16,16 +16,19 @@ type InventoryHandler interface {
 	GetAllInventoryItems(c *god.Context)
 	GetInventoryItem(c *god.Context)
 	UpdateInventoryItem(c *god.Context)
+	PatchInventoryItem(c *god.Context)
 	DeleteInventoryItem(c *god.Context)
+	RestoreInventoryItem(c *god.Context)
 }
 
 type inventoryHandler struct {
 	service InventoryService
+	export  *Exporter
 	log     *slog.Logger
 }
 
-func NewInventoryHandler(s InventoryService, l *slog.Logger) *inventoryHandler {
-	return &inventoryHandler{service: s, log: l}
+func NewInventoryHandler(s InventoryService, e *Exporter, l *slog.Logger) *inventoryHandler {
+	return &inventoryHandler{service: s, export: e, log: l}
 }
 
 // AddInventoryItem handles the HTTP request to add a new inventory item.
@@ -35,18 +38,13 @@ func (h *inventoryHandler) AddInventoryItem(c *god.Context) {
 	var item dto.InventoryRequest
 	err := c.ShouldBindJSON(&item)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, god.H{"error": err.Error(), "message": "invalid request body"})
+		handleBindError(c, model.ErrNotValidBody, err)
 		return
 	}
 
-	err = item.Validate()
+	err = h.service.AddInventoryItem(c.Request.Context(), item.ToDomain())
 	if err != nil {
-		h.handleError(c, err, http.StatusBadRequest)
-	}
-
-	err = h.service.AddInventoryItem(context.TODO(), item.ToDomain())
-	if err != nil {
-		h.handleError(c, err, http.StatusBadRequest)
+		handleError(c, err)
 		return
 	}
 
@@ -60,10 +58,29 @@ func (h *inventoryHandler) AddInventoryItem(c *god.Context) {
 
 // GetInventoryItems handles the HTTP request to retrieve inventory items.
 // It calls the service layer to get the list of inventory items, handles errors, and returns the data in the response.
+// Archived items are listed only with ?include_archived=true.
 func (h *inventoryHandler) GetAllInventoryItems(c *god.Context) {
-	object, err := h.service.RetrieveInventoryItems(context.TODO())
+	var req dto.ListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		handleBindError(c, model.ErrNotValidQuery, err)
+		return
+	}
+
+	format, ok := responseFormat(c)
+	if !ok {
+		return
+	}
+
+	if format != binding.MIMEJSON {
+		writeExport(c, h.export, format, "inventory", dto.InventoryColumns, func(fn func(i model.Inventory) error) error {
+			return h.service.ExportInventoryItems(c.Request.Context(), req.IncludeArchived, fn)
+		})
+		return
+	}
+
+	object, err := h.service.RetrieveInventoryItems(c.Request.Context(), req.IncludeArchived)
 	if err != nil {
-		h.handleError(c, err, http.StatusBadRequest)
+		handleError(c, err)
 		return
 	}
 
@@ -82,21 +99,22 @@ func (h *inventoryHandler) GetAllInventoryItems(c *god.Context) {
 
 // GetInventoryItem handles the HTTP request to retrieve a specific inventory item by its ID.
 func (h *inventoryHandler) GetInventoryItem(c *god.Context) {
-	id := c.PathValue("id")
-	itemID, err := strconv.Atoi(id)
-	if err != nil {
-		h.handleError(c, err, http.StatusBadRequest)
+	itemID, ok := pathID(c, model.ErrNotValidIngredientID)
+	if !ok {
 		return
 	}
 
-	object, err := h.service.RetrieveInventoryItem(context.TODO(), itemID)
+	object, err := h.service.RetrieveInventoryItem(c.Request.Context(), itemID)
 	if err != nil {
-		h.handleError(c, err, http.StatusBadRequest)
+		handleError(c, err)
+		return
+	}
+	if notModified(c, object.Version) {
 		return
 	}
 
 	item := dto.NewInventoryResponse(*object)
-	h.log.Debug("Retrieved inventory item with ID:", slog.String("itemId", id))
+	h.log.Debug("Retrieved inventory item with ID:", slog.Int("itemId", itemID))
 	res := response.APIResponse{
 		Status: http.StatusOK,
 		Body:   god.H{"item": item},
@@ -105,49 +123,158 @@ func (h *inventoryHandler) GetInventoryItem(c *god.Context) {
 }
 
 // UpdateInventoryItem handles the HTTP request to update an existing inventory item by its ID.
+// The request must carry the ETag of the item in If-Match.
 func (h *inventoryHandler) UpdateInventoryItem(c *god.Context) {
-	id := c.PathValue("id")
-	itemID, err := strconv.Atoi(id)
-	if err != nil {
-		h.handleError(c, err, http.StatusBadRequest)
+	itemID, ok := pathID(c, model.ErrNotValidIngredientID)
+	if !ok {
+		return
+	}
+	version, ok := ifMatch(c, h.versionOf(c, itemID))
+	if !ok {
 		return
 	}
 
 	var item dto.InventoryRequest
-	err = c.ShouldBindJSON(&item)
+	err := c.ShouldBindJSON(&item)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, god.H{"error": err.Error(), "message": "invalid request body"})
+		handleBindError(c, model.ErrNotValidBody, err)
 		return
 	}
 
-	err = h.service.UpdateInventoryItem(context.TODO(), itemID, item.ToDomain())
+	object := item.ToDomain()
+	object.Version = version
+	err = h.service.UpdateInventoryItem(c.Request.Context(), itemID, object)
 	if err != nil {
-		h.handleError(c, err, http.StatusBadRequest)
+		handleError(c, err)
 		return
 	}
 
-	h.log.Debug("Successfully updated an inventory item with ID:", slog.String("itemId", id))
+	h.log.Debug("Successfully updated an inventory item with ID:", slog.Int("itemId", itemID))
+	setNextETag(c, version)
 	c.Status(http.StatusOK)
 }
 
-func (h *inventoryHandler) DeleteInventoryItem(c *god.Context) {
-	id := c.PathValue("id")
-	itemID, err := strconv.Atoi(id)
+// PatchInventoryItem handles the HTTP request to change some fields of an inventory item.
+// The body is a JSON Merge Patch or a JSON Patch of the item as it is sent to
+// AddInventoryItem. The request must carry the ETag of the item in If-Match.
+func (h *inventoryHandler) PatchInventoryItem(c *god.Context) {
+	itemID, ok := pathID(c, model.ErrNotValidIngredientID)
+	if !ok {
+		return
+	}
+	version, ok := ifMatch(c, h.versionOf(c, itemID))
+	if !ok {
+		return
+	}
+
+	current, err := h.service.RetrieveInventoryItem(c.Request.Context(), itemID)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	if version != 0 && version != current.Version {
+		handleError(c, model.ErrVersionMismatch)
+		return
+	}
+
+	item := dto.NewInventoryRequest(*current)
+	if err := c.ShouldBindPatch(&item); err != nil {
+		handlePatchError(c, err)
+		return
+	}
+
+	object := item.ToDomain()
+	object.Version = current.Version
+	patched, err := h.service.PatchInventoryItem(c.Request.Context(), itemID, object)
 	if err != nil {
-		h.handleError(c, err, http.StatusBadRequest)
+		handleError(c, err)
 		return
 	}
 
-	err = h.service.DeleteInventoryItem(context.TODO(), itemID)
+	h.log.Debug("Successfully patched an inventory item with ID:", slog.Int("itemId", itemID))
+	setETag(c, patched.Version)
+	res := response.APIResponse{
+		Status: http.StatusOK,
+		Body:   god.H{"item": dto.NewInventoryResponse(*patched)},
+	}
+	c.JSON(res.Status, res)
+}
+
+// DeleteInventoryItem handles the HTTP request to delete an inventory item by its ID.
+// The item is archived, or deleted for good with ?permanent=true, which fails while
+// recipes or the ledger refer to it. The request must carry the ETag of the item in If-Match.
+func (h *inventoryHandler) DeleteInventoryItem(c *god.Context) {
+	itemID, ok := pathID(c, model.ErrNotValidIngredientID)
+	if !ok {
+		return
+	}
+	var req dto.DeleteRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		handleBindError(c, model.ErrNotValidQuery, err)
+		return
+	}
+	version, ok := ifMatch(c, h.versionOf(c, itemID))
+	if !ok {
+		return
+	}
+
+	if !req.Permanent {
+		err := h.service.ArchiveInventoryItem(c.Request.Context(), itemID, version)
+		if err != nil {
+			handleError(c, err)
+			return
+		}
+
+		h.log.Debug("Successfully archived an inventory item with ID:", slog.Int("itemId", itemID))
+		c.Status(http.StatusNoContent)
+		return
+	}
+
+	err := h.service.DeleteInventoryItem(c.Request.Context(), itemID, version)
 	if err != nil {
-		h.handleError(c, err, http.StatusBadRequest)
+		handleError(c, err)
 		return
 	}
 
-	h.log.Debug("Successfully deleted an inventory item with ID:", slog.String("itemId", id))
+	h.log.Debug("Successfully deleted an inventory item with ID:", slog.Int("itemId", itemID))
 	c.Status(http.StatusNoContent)
 }
 
-func (h *inventoryHandler) handleError(c *god.Context, err error, code int) {
-	c.JSON(code, god.H{"error": err.Error()})
+// RestoreInventoryItem handles the HTTP request to bring an archived inventory item
+// back. The request must carry the ETag of the item in If-Match.
+func (h *inventoryHandler) RestoreInventoryItem(c *god.Context) {
+	itemID, ok := pathID(c, model.ErrNotValidIngredientID)
+	if !ok {
+		return
+	}
+	version, ok := ifMatch(c, h.versionOf(c, itemID))
+	if !ok {
+		return
+	}
+
+	restored, err := h.service.RestoreInventoryItem(c.Request.Context(), itemID, version)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	h.log.Debug("Successfully restored an inventory item with ID:", slog.Int("itemId", itemID))
+	setETag(c, restored.Version)
+	res := response.APIResponse{
+		Status: http.StatusOK,
+		Body:   god.H{"item": dto.NewInventoryResponse(*restored)},
+	}
+	c.JSON(res.Status, res)
+}
+
+// versionOf returns the function that reads the current version of the inventory
+// item, for an If-Match header that lists several tags.
+func (h *inventoryHandler) versionOf(c *god.Context, id int) func() (int, error) {
+	return func() (int, error) {
+		item, err := h.service.RetrieveInventoryItem(c.Request.Context(), id)
+		if err != nil {
+			return 0, err
+		}
+		return item.Version, nil
+	}
 }
@@ -1,21 +1,22 @@
 package handler
 
 import (
-	"context"
 	"god"
 	"log/slog"
 	"net/http"
-	"strconv"
 
+	"coffee-shop/internal/model"
 	dto "coffee-shop/internal/transport/dto/menu"
 )
 
 type MenuItem interface {
 	AddMenuItem(*god.Context)
 	UpdateMenuItem(*god.Context)
+	PatchMenuItem(*god.Context)
 	GetAllMenuItems(c *god.Context)
 	GetMenuItem(*god.Context)
 	DeleteMenuItem(*god.Context)
+	RestoreMenuItem(*god.Context)
 }
 
 type menuHandler struct {
@@ -33,15 +34,15 @@ func (h *menuHandler) AddMenuItem(c *god.Context) {
 	var menu dto.MenuItemRequest
 	err := c.ShouldBindJSON(&menu)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, god.H{"code": http.StatusBadRequest, "error": err.Error(), "message": "Invalid request body"})
+		handleBindError(c, model.ErrNotValidBody, err)
 		return
 	}
 	h.log.Debug("Adding new menu item", slog.Any("MenuItem", menu))
 
 	item, ingredients := dto.ToDomain(menu)
-	err = h.service.AddMenuItem(context.TODO(), item, ingredients)
+	err = h.service.AddMenuItem(c.Request.Context(), item, ingredients)
 	if err != nil {
-		h.handleError(c, err, 400)
+		handleError(c, err)
 		return
 	}
 
@@ -51,10 +52,17 @@ func (h *menuHandler) AddMenuItem(c *god.Context) {
 
 // GetMenuItems handles the HTTP request to retrieve all menu items.
 // It calls the service layer to fetch the data and returns it to the client.
+// Archived items are listed only with ?include_archived=true.
 func (h *menuHandler) GetAllMenuItems(c *god.Context) {
-	items, err := h.service.RetrieveMenuItems(context.TODO())
+	var req dto.ListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		handleBindError(c, model.ErrNotValidQuery, err)
+		return
+	}
+
+	items, err := h.service.RetrieveMenuItems(c.Request.Context(), req.IncludeArchived)
 	if err != nil {
-		h.handleError(c, err, 400)
+		handleError(c, err)
 		return
 	}
 	h.log.Debug("Retrieved Menu items")
@@ -65,72 +73,175 @@ func (h *menuHandler) GetAllMenuItems(c *god.Context) {
 // It checks if the item ID is valid, calls the service layer to fetch the menu item,
 // and returns the result to the client. In case of errors, it responds with the appropriate error message.
 func (h *menuHandler) GetMenuItem(c *god.Context) {
-	id := c.Request.PathValue("id")
-	itemID, err := strconv.Atoi(id)
-	if err != nil {
-		h.handleError(c, err, 400)
+	itemID, ok := pathID(c, model.ErrNotValidMenuID)
+	if !ok {
 		return
 	}
 
-	item, ingredient, err := h.service.RetrieveMenuItemWithId(context.TODO(), itemID)
+	item, ingredient, err := h.service.RetrieveMenuItemWithId(c.Request.Context(), itemID)
 	if err != nil {
-		h.handleError(c, err, 400)
+		handleError(c, err)
 		return
 	}
 
+	if notModified(c, item.Version) {
+		return
+	}
 	menu := dto.NewMenuItemResponse(item, ingredient)
 
-	h.log.Debug("Retrieved menu item with ID", slog.String("id", id))
+	h.log.Debug("Retrieved menu item with ID", slog.Int("id", itemID))
 	c.JSON(http.StatusOK, god.H{"code": http.StatusOK, "body": menu})
 }
 
 // UpdateMenuItem handles the HTTP request to update an existing menu item.
 // It checks if the request body is valid, decodes the new menu item data, and
 // calls the service layer to update the menu item. In case of errors, it responds
-// with the appropriate HTTP status and error message.
+// with the appropriate HTTP status and error message. The request must carry the
+// ETag of the item in If-Match.
 func (h *menuHandler) UpdateMenuItem(c *god.Context) {
 	var menu dto.MenuItemRequest
 	err := c.ShouldBindJSON(&menu)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, god.H{"code": http.StatusBadRequest, "error": err.Error(), "message": "Invalid request body"})
+		handleBindError(c, model.ErrNotValidBody, err)
 		return
 	}
 
-	id := c.Request.PathValue("id")
-	itemID, err := strconv.Atoi(id)
-	if err != nil {
-		h.handleError(c, err, 400)
+	itemID, ok := pathID(c, model.ErrNotValidMenuID)
+	if !ok {
+		return
+	}
+	version, ok := ifMatch(c, h.versionOf(c, itemID))
+	if !ok {
 		return
 	}
 
 	item, ingredients := dto.ToDomain(menu)
-	err = h.service.UpdateMenuItem(context.TODO(), itemID, item, ingredients)
+	item.Version = version
+	err = h.service.UpdateMenuItem(c.Request.Context(), itemID, item, ingredients)
 	if err != nil {
-		h.handleError(c, err, 400)
+		handleError(c, err)
+		return
 	}
 
+	setNextETag(c, version)
 	c.Status(http.StatusOK)
 }
 
+// PatchMenuItem handles the HTTP request to change some fields or ingredients of a
+// menu item. The body is a JSON Merge Patch or a JSON Patch of the item as it is sent
+// to AddMenuItem. The request must carry the ETag of the item in If-Match.
+func (h *menuHandler) PatchMenuItem(c *god.Context) {
+	itemID, ok := pathID(c, model.ErrNotValidMenuID)
+	if !ok {
+		return
+	}
+	version, ok := ifMatch(c, h.versionOf(c, itemID))
+	if !ok {
+		return
+	}
+
+	current, currentIngredients, err := h.service.RetrieveMenuItemWithId(c.Request.Context(), itemID)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	if version != 0 && version != current.Version {
+		handleError(c, model.ErrVersionMismatch)
+		return
+	}
+
+	menu := dto.NewMenuItemRequest(*current, currentIngredients)
+	if err := c.ShouldBindPatch(&menu); err != nil {
+		handlePatchError(c, err)
+		return
+	}
+
+	item, ingredients := dto.ToDomain(menu)
+	item.Version = current.Version
+	patched, patchedIngredients, err := h.service.PatchMenuItem(c.Request.Context(), itemID, item, ingredients)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	h.log.Debug("Successfully patched a menu item with ID", slog.Int("id", itemID))
+	setETag(c, patched.Version)
+	c.JSON(http.StatusOK, god.H{"code": http.StatusOK, "body": dto.NewMenuItemResponse(patched, patchedIngredients)})
+}
+
 // DeleteMenuItem handles the HTTP request to delete a menu item by its ID.
 // It validates the item ID, calls the service layer to delete the item, and
-// responds with the appropriate HTTP status and message.
+// responds with the appropriate HTTP status and message. The item is taken off the
+// menu, or deleted for good with ?permanent=true, which fails while orders, refunds
+// or price changes refer to it. The request must carry the ETag of the item in If-Match.
 func (h *menuHandler) DeleteMenuItem(c *god.Context) {
-	id := c.Request.PathValue("id")
-	itemID, err := strconv.Atoi(id)
-	if err != nil {
-		h.handleError(c, err, 400)
+	itemID, ok := pathID(c, model.ErrNotValidMenuID)
+	if !ok {
+		return
+	}
+	var req dto.DeleteRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		handleBindError(c, model.ErrNotValidQuery, err)
+		return
+	}
+	version, ok := ifMatch(c, h.versionOf(c, itemID))
+	if !ok {
+		return
+	}
+
+	if !req.Permanent {
+		err := h.service.ArchiveMenuItem(c.Request.Context(), itemID, version)
+		if err != nil {
+			handleError(c, err)
+			return
+		}
+
+		h.log.Debug("Successfully archived a menu item with ID", slog.Int("id", itemID))
+		c.Status(http.StatusNoContent)
+		return
 	}
 
-	err = h.service.DeleteMenuItem(context.TODO(), itemID)
+	err := h.service.DeleteMenuItem(c.Request.Context(), itemID, version)
 	if err != nil {
-		h.handleError(c, err, 400)
+		handleError(c, err)
+		return
 	}
 
-	h.log.Debug("Successfully deleted a menu item with ID ", slog.String("id", id))
+	h.log.Debug("Successfully deleted a menu item with ID ", slog.Int("id", itemID))
 	c.Status(http.StatusNoContent)
 }
 
-func (h *menuHandler) handleError(c *god.Context, err error, code int) {
-	c.JSON(code, god.H{"error": err.Error()})
+// RestoreMenuItem handles the HTTP request to put an archived menu item back on the
+// menu. The request must carry the ETag of the item in If-Match.
+func (h *menuHandler) RestoreMenuItem(c *god.Context) {
+	itemID, ok := pathID(c, model.ErrNotValidMenuID)
+	if !ok {
+		return
+	}
+	version, ok := ifMatch(c, h.versionOf(c, itemID))
+	if !ok {
+		return
+	}
+
+	restored, ingredients, err := h.service.RestoreMenuItem(c.Request.Context(), itemID, version)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	h.log.Debug("Successfully restored a menu item with ID", slog.Int("id", itemID))
+	setETag(c, restored.Version)
+	c.JSON(http.StatusOK, god.H{"code": http.StatusOK, "body": dto.NewMenuItemResponse(restored, ingredients)})
+}
+
+// versionOf returns the function that reads the current version of the menu item,
+// for an If-Match header that lists several tags.
+func (h *menuHandler) versionOf(c *god.Context, id int) func() (int, error) {
+	return func() (int, error) {
+		item, _, err := h.service.RetrieveMenuItemWithId(c.Request.Context(), id)
+		if err != nil {
+			return 0, err
+		}
+		return item.Version, nil
+	}
 }
@@ -1,121 +1,236 @@
 package handler
 
-// import (
-// 	"errors"
-// 	"god"
-// 	"log/slog"
-// 	"net/http"
-
-// 	"coffee-shop/internal/model"
-// 	"coffee-shop/internal/service"
-// )
-
-// type OrderWriter interface {
-// 	CreateOrder(*god.Context)
-// 	UpdateOrder(*god.Context)
-// 	DeleteOrder(*god.Context)
-// 	CloseOrder(*god.Context)
-// }
-
-// type OrderReader interface {
-// 	RetrieveOrders(*god.Context)
-// 	RetrieveOrder(*god.Context)
-// }
-
-// type orderHandler struct {
-// 	OrderService OrderService
-// 	log          *slog.Logger
-// }
-
-// func NewOrderHandler(s OrderService, l *slog.Logger) *orderHandler {
-// 	return &orderHandler{OrderService: s, log: l}
-// }
-
-// func (h *orderHandler) CreateOrder(c *god.Context) {
-// 	var order model.Order
-// 	err := c.ShouldBindJSON(&order)
-
-// 	if err != nil {
-// 		c.JSON(http.StatusBadRequest, god.H{"code": http.StatusBadRequest, "error": err.Error(), "message": "Invalid request body"})
-// 		return
-// 	}
-
-// 	h.log.Debug("Creating new order", slog.Any("Order", order))
-// 	err = h.OrderService.AddOrder(order)
-// 	if err != nil {
-// 		h.handleError(c, err)
-// 	}
-
-// 	h.log.Info("Successfully created new order", slog.Any("Order", order))
-// 	c.JSON(http.StatusCreated, god.H{"body": order})
-// }
-
-// func (h *orderHandler) RetrieveOrders(c *god.Context) {
-// 	// Retrieve the orders from the service layer
-// 	items, err := h.OrderService.RetrieveOrders()
-// 	if err != nil {
-// 		h.handleError(c, err)
-// 	}
-
-// 	h.log.Debug("Retrieved orders")
-
-// 	c.JSON(http.StatusOK, god.H{"body": items})
-// }
-
-// func (h *orderHandler) RetrieveOrder(c *god.Context) {
-// 	id := c.Request.PathValue("id")
-// 	item, err := h.OrderService.RetrieveOrder(id)
-// 	if err != nil {
-// 		h.handleError(c, err)
-// 	}
-
-// 	h.log.Debug("Retrieved order with ID", slog.String("OrderId", id))
-// 	c.JSON(http.StatusOK, item)
-// }
-
-// func (h *orderHandler) UpdateOrder(c *god.Context) {
-// 	id := c.Request.PathValue("id")
-// 	var order model.Order
-// 	err := c.ShouldBindJSON(&order)
-// 	if err != nil {
-// 		c.JSON(http.StatusBadRequest, god.H{"error": err.Error(), "message": "invalid request body"})
-// 		return
-// 	}
-
-// 	err = h.OrderService.UpdateOrder(id, order)
-// 	if err != nil {
-// 		h.handleError(c, err)
-// 	}
-
-// 	c.Status(http.StatusOK)
-// }
-
-// func (h *orderHandler) DeleteOrder(c *god.Context) {
-// 	id := c.Request.PathValue("id")
-// 	err := h.OrderService.DeleteOrder(id)
-// 	if err != nil {
-// 		h.handleError(c, err)
-// 	}
-
-// 	h.log.Debug("Successfully deleted order with ID ", slog.String("OrderId", id))
-// 	c.Status(http.StatusNoContent)
-// }
-
-// func (h *orderHandler) CloseOrder(c *god.Context) {
-// 	id := c.Request.PathValue("id")
-// 	err := h.OrderService.CloseOrder(id)
-// 	if err != nil {
-// 		h.handleError(c, err)
-// 	}
-// 	c.Status(http.StatusOK)
-// }
-
-// func (h *orderHandler) handleError(c *god.Context, err error) {
-// 	var serviceErr *service.ServiceError
-// 	if errors.As(err, &serviceErr) {
-// 		c.JSON(serviceErr.Code, serviceErr.Hash())
-// 	} else {
-// 		h.log.Error("Error of OrderHandler", slog.String("error", err.Error()))
-// 		c.JSON(http.StatusInternalServerError, god.H{"error": err.Error(), "message": "internal server error"})
-// 	}
-// }
+import (
+	"god"
+	"god/binding"
+	"log/slog"
+	"net/http"
+
+	"coffee-shop/internal/model"
+	dto "coffee-shop/internal/transport/dto/order"
+)
+
+type OrderWriter interface {
+	CreateOrder(*god.Context)
+	UpdateOrder(*god.Context)
+	DeleteOrder(*god.Context)
+	CloseOrder(*god.Context)
+	ProcessBatch(*god.Context)
+}
+
+type OrderReader interface {
+	RetrieveOrders(*god.Context)
+	RetrieveOrder(*god.Context)
+	RetrieveOrderTotals(*god.Context)
+}
+
+type OrderHandler interface {
+	OrderWriter
+	OrderReader
+}
+
+type orderHandler struct {
+	OrderService OrderService
+	export       *Exporter
+	log          *slog.Logger
+}
+
+func NewOrderHandler(s OrderService, e *Exporter, l *slog.Logger) *orderHandler {
+	return &orderHandler{OrderService: s, export: e, log: l}
+}
+
+// CreateOrder handles the HTTP request to create a new order with its items.
+func (h *orderHandler) CreateOrder(c *god.Context) {
+	var order dto.OrderRequest
+	err := c.ShouldBindJSON(&order)
+	if err != nil {
+		handleBindError(c, model.ErrNotValidBody, err)
+		return
+	}
+
+	h.log.Debug("Creating new order", slog.Any("Order", order))
+	id, err := h.OrderService.AddOrder(c.Request.Context(), order.ToDomain())
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	created, err := h.OrderService.RetrieveOrder(c.Request.Context(), id)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	h.log.Info("Successfully created new order", slog.Int("OrderId", id))
+	c.JSON(http.StatusCreated, god.H{"body": dto.NewOrderResponse(*created)})
+}
+
+// RetrieveOrders handles the HTTP request to retrieve all orders.
+func (h *orderHandler) RetrieveOrders(c *god.Context) {
+	format, ok := responseFormat(c)
+	if !ok {
+		return
+	}
+
+	if format != binding.MIMEJSON {
+		writeExport(c, h.export, format, "orders", dto.OrderColumns, func(fn func(o model.Order) error) error {
+			return h.OrderService.ExportOrders(c.Request.Context(), fn)
+		})
+		return
+	}
+
+	orders, err := h.OrderService.RetrieveOrders(c.Request.Context())
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	items := []dto.OrderResponse{}
+	for _, o := range orders {
+		items = append(items, dto.NewOrderResponse(o))
+	}
+
+	h.log.Debug("Retrieved orders")
+	c.JSON(http.StatusOK, god.H{"body": items})
+}
+
+// RetrieveOrder handles the HTTP request to retrieve an order by its ID.
+func (h *orderHandler) RetrieveOrder(c *god.Context) {
+	id, ok := pathID(c, model.ErrNotValidOrderID)
+	if !ok {
+		return
+	}
+
+	order, err := h.OrderService.RetrieveOrder(c.Request.Context(), id)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	if notModified(c, order.Version) {
+		return
+	}
+
+	h.log.Debug("Retrieved order with ID", slog.Int("OrderId", id))
+	c.JSON(http.StatusOK, god.H{"body": dto.NewOrderResponse(*order)})
+}
+
+// RetrieveOrderTotals handles the HTTP request to retrieve the receipt totals of an order.
+// Open orders are calculated with the current tax rates and are not stored.
+func (h *orderHandler) RetrieveOrderTotals(c *god.Context) {
+	id, ok := pathID(c, model.ErrNotValidOrderID)
+	if !ok {
+		return
+	}
+
+	totals, err := h.OrderService.RetrieveOrderTotals(c.Request.Context(), id)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, god.H{"body": dto.NewTotalsResponse(*totals)})
+}
+
+// UpdateOrder handles the HTTP request to replace the content of an open order.
+// The request must carry the ETag of the order in If-Match.
+func (h *orderHandler) UpdateOrder(c *god.Context) {
+	id, ok := pathID(c, model.ErrNotValidOrderID)
+	if !ok {
+		return
+	}
+	version, ok := ifMatch(c, h.versionOf(c, id))
+	if !ok {
+		return
+	}
+
+	var order dto.OrderRequest
+	err := c.ShouldBindJSON(&order)
+	if err != nil {
+		handleBindError(c, model.ErrNotValidBody, err)
+		return
+	}
+
+	object := order.ToDomain()
+	object.Version = version
+	err = h.OrderService.UpdateOrder(c.Request.Context(), id, object)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	h.log.Debug("Successfully updated order with ID", slog.Int("OrderId", id))
+	setNextETag(c, version)
+	c.Status(http.StatusOK)
+}
+
+// DeleteOrder handles the HTTP request to delete an order by its ID.
+// The request must carry the ETag of the order in If-Match.
+func (h *orderHandler) DeleteOrder(c *god.Context) {
+	id, ok := pathID(c, model.ErrNotValidOrderID)
+	if !ok {
+		return
+	}
+	version, ok := ifMatch(c, h.versionOf(c, id))
+	if !ok {
+		return
+	}
+
+	err := h.OrderService.DeleteOrder(c.Request.Context(), id, version)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	h.log.Debug("Successfully deleted order with ID", slog.Int("OrderId", id))
+	c.Status(http.StatusNoContent)
+}
+
+// CloseOrder handles the HTTP request to close an order.
+// The response contains the closed order with its stored totals.
+func (h *orderHandler) CloseOrder(c *god.Context) {
+	id, ok := pathID(c, model.ErrNotValidOrderID)
+	if !ok {
+		return
+	}
+
+	order, err := h.OrderService.CloseOrder(c.Request.Context(), id)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	h.log.Info("Successfully closed order with ID", slog.Int("OrderId", id), slog.Float64("Total", order.Total))
+	c.JSON(http.StatusOK, god.H{"body": dto.NewOrderResponse(*order)})
+}
+
+// ProcessBatch handles the HTTP request to place many orders at once. Orders are
+// processed in arrival order and the response lists the result of every order.
+func (h *orderHandler) ProcessBatch(c *god.Context) {
+	var batch dto.BatchRequest
+	err := c.ShouldBindJSON(&batch)
+	if err != nil {
+		handleBindError(c, model.ErrNotValidBody, err)
+		return
+	}
+
+	result, err := h.OrderService.ProcessBatch(c.Request.Context(), batch.ToDomain())
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	h.log.Info("Processed order batch", slog.Int("Accepted", result.Accepted), slog.Int("Rejected", result.Rejected), slog.Float64("Revenue", result.Revenue))
+	c.JSON(http.StatusOK, god.H{"body": dto.NewBatchResponse(*result, rejectionReason(c))})
+}
+
+// versionOf returns the function that reads the current version of the order, for
+// an If-Match header that lists several tags.
+func (h *orderHandler) versionOf(c *god.Context, id int) func() (int, error) {
+	return func() (int, error) {
+		order, err := h.OrderService.RetrieveOrder(c.Request.Context(), id)
+		if err != nil {
+			return 0, err
+		}
+		return order.Version, nil
+	}
+}
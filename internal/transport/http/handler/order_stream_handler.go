@@ -0,0 +1,121 @@
+package handler
+
+import (
+	"god"
+	"io"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"coffee-shop/internal/model"
+	dto "coffee-shop/internal/transport/dto/order"
+)
+
+const (
+	// heartbeatInterval keeps idle connections from being closed by proxies.
+	heartbeatInterval = 15 * time.Second
+	// retryInterval tells the client how long to wait before reconnecting, in milliseconds.
+	retryInterval = 3000
+)
+
+type OrderStreamHandler interface {
+	StreamOrders(c *god.Context)
+}
+
+type orderStreamHandler struct {
+	Events   OrderEventSubscriber
+	stations map[string][]string
+	log      *slog.Logger
+}
+
+// NewOrderStreamHandler creates the handler of the live order queue. Stations map
+// a station name, e.g. "bar", to the menu categories it prepares.
+func NewOrderStreamHandler(events OrderEventSubscriber, stations map[string][]string, l *slog.Logger) *orderStreamHandler {
+	return &orderStreamHandler{Events: events, stations: stations, log: l}
+}
+
+// StreamOrders handles the HTTP request to follow the order queue as server-sent events.
+// The queue can be narrowed to a station with the "station" query parameter or to
+// menu categories with the comma-separated "category" query parameter. A client that
+// reconnects with the Last-Event-ID header receives the events it missed.
+func (h *orderStreamHandler) StreamOrders(c *god.Context) {
+	categories, err := h.categories(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	lastEventID, _ := strconv.ParseUint(c.Request.Header.Get("Last-Event-ID"), 10, 64)
+
+	missed, events, unsubscribe := h.Events.Subscribe(lastEventID)
+	defer unsubscribe()
+
+	h.log.Debug("Order stream is opened", slog.Any("categories", categories), slog.Uint64("last_event_id", lastEventID))
+
+	// The first event sends the headers, so the client knows the stream is open
+	c.SSEvent(god.SSEvent{Retry: retryInterval})
+	for _, e := range missed {
+		h.send(c, e, categories)
+	}
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	done := c.Request.Context().Done()
+	clientGone := c.Stream(func(w io.Writer) bool {
+		select {
+		case <-done:
+			return false
+		case e, ok := <-events:
+			if !ok {
+				// Dropped for falling behind; the client resumes with Last-Event-ID
+				return false
+			}
+			h.send(c, e, categories)
+			return true
+		case <-heartbeat.C:
+			c.SSEvent(god.SSEvent{Comment: "heartbeat"})
+			return true
+		}
+	})
+
+	h.log.Debug("Order stream is closed", slog.Bool("client_gone", clientGone))
+}
+
+func (h *orderStreamHandler) send(c *god.Context, e model.OrderEvent, categories []string) {
+	e, ok := e.ForStation(categories)
+	if !ok {
+		return
+	}
+
+	c.SSEvent(god.SSEvent{
+		ID:    strconv.FormatUint(e.ID, 10),
+		Event: e.Type,
+		Data:  dto.NewOrderEventResponse(e),
+	})
+}
+
+// categories returns the menu categories requested with the "station" and "category" query parameters.
+func (h *orderStreamHandler) categories(c *god.Context) ([]string, error) {
+	var req dto.OrderStreamRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		return nil, model.ErrNotValidQuery.WithMessage(err.Error())
+	}
+
+	var categories []string
+	if req.Station != "" {
+		stationCategories, ok := h.stations[req.Station]
+		if !ok {
+			return nil, model.ErrNotValidStation
+		}
+		categories = append(categories, stationCategories...)
+	}
+
+	for _, category := range req.Category {
+		if category != "" {
+			categories = append(categories, category)
+		}
+	}
+
+	return categories, nil
+}
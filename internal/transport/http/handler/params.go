@@ -0,0 +1,22 @@
+package handler
+
+import (
+	"god"
+
+	"coffee-shop/internal/model"
+)
+
+// idParams are the path parameters of the routes of a single record.
+type idParams struct {
+	ID int `uri:"id" binding:"min=1"`
+}
+
+// pathID binds the "id" path parameter and responds with invalid if it is not a positive number.
+func pathID(c *god.Context, invalid *model.Error) (int, bool) {
+	var params idParams
+	if err := c.ShouldBindUri(&params); err != nil {
+		handleError(c, invalid)
+		return 0, false
+	}
+	return params.ID, true
+}
@@ -0,0 +1,118 @@
+package handler
+
+import (
+	"god"
+	"log/slog"
+	"net/http"
+
+	"coffee-shop/internal/model"
+	dto "coffee-shop/internal/transport/dto/payment"
+)
+
+type PaymentHandler interface {
+	AddPayment(c *god.Context)
+	GetPayments(c *god.Context)
+	SplitBill(c *god.Context)
+	RefundOrder(c *god.Context)
+}
+
+type paymentHandler struct {
+	PaymentService PaymentService
+	log            *slog.Logger
+}
+
+func NewPaymentHandler(s PaymentService, l *slog.Logger) *paymentHandler {
+	return &paymentHandler{PaymentService: s, log: l}
+}
+
+// AddPayment handles the HTTP request to pay an open order, fully or partially.
+func (h *paymentHandler) AddPayment(c *god.Context) {
+	id, ok := pathID(c, model.ErrNotValidOrderID)
+	if !ok {
+		return
+	}
+
+	var req dto.PaymentRequest
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		handleBindError(c, model.ErrNotValidBody, err)
+		return
+	}
+
+	payment, err := h.PaymentService.AddPayment(c.Request.Context(), id, req.ToDomain())
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	h.log.Info("Payment is added", slog.Int("OrderId", id), slog.String("Tender", payment.Tender), slog.Float64("Amount", payment.Amount))
+	c.JSON(http.StatusCreated, god.H{"body": dto.NewPaymentResponse(*payment)})
+}
+
+// GetPayments handles the HTTP request to retrieve the payments of an order with its balance.
+func (h *paymentHandler) GetPayments(c *god.Context) {
+	id, ok := pathID(c, model.ErrNotValidOrderID)
+	if !ok {
+		return
+	}
+
+	payments, balance, err := h.PaymentService.RetrievePayments(c.Request.Context(), id)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, god.H{"body": dto.NewPaymentsResponse(payments, *balance)})
+}
+
+// SplitBill handles the HTTP request to split the order bill evenly or by items.
+func (h *paymentHandler) SplitBill(c *god.Context) {
+	id, ok := pathID(c, model.ErrNotValidOrderID)
+	if !ok {
+		return
+	}
+
+	var req dto.SplitRequest
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		handleBindError(c, model.ErrNotValidBody, err)
+		return
+	}
+
+	shares, err := h.PaymentService.SplitBill(c.Request.Context(), id, req.ToDomain())
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, god.H{"body": dto.NewSplitResponse(shares)})
+}
+
+// RefundOrder handles the HTTP request to refund a closed order, fully or partially.
+func (h *paymentHandler) RefundOrder(c *god.Context) {
+	id, ok := pathID(c, model.ErrNotValidOrderID)
+	if !ok {
+		return
+	}
+
+	var req dto.RefundRequest
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		handleBindError(c, model.ErrNotValidBody, err)
+		return
+	}
+
+	refunds, err := h.PaymentService.RefundOrder(c.Request.Context(), id, req.ToDomain())
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	items := []dto.PaymentResponse{}
+	for _, r := range refunds {
+		items = append(items, dto.NewPaymentResponse(r))
+	}
+
+	h.log.Info("Order is refunded", slog.Int("OrderId", id), slog.Int("Refunds", len(refunds)))
+	c.JSON(http.StatusCreated, god.H{"body": items})
+}
@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"god"
+	"time"
+
+	"coffee-shop/internal/model"
+)
+
+const (
+	dateLayout = "2006-01-02"
+
+	// defaultReportDays is the length of the report period when "from" is not given.
+	defaultReportDays = 30
+)
+
+// parsePeriod reads the "from" and "to" query parameters of a report.
+// Both accept a date (2006-01-02), taken in the shop location, or an RFC 3339
+// timestamp. A date in "to" is inclusive, so from=2025-01-01&to=2025-01-31 covers
+// the whole of January. Without "to" the period ends now, without "from" it
+// starts 30 days before "to".
+func parsePeriod(c *god.Context, location *time.Location) (model.Period, error) {
+	var period model.Period
+
+	to, err := parseTime(c.Query("to"), location, true)
+	if err != nil {
+		return period, model.ErrNotValidPeriod
+	}
+	if to.IsZero() {
+		to = time.Now()
+	}
+
+	from, err := parseTime(c.Query("from"), location, false)
+	if err != nil {
+		return period, model.ErrNotValidPeriod
+	}
+	if from.IsZero() {
+		from = to.AddDate(0, 0, -defaultReportDays)
+	}
+
+	period.From, period.To = from, to
+	return period, nil
+}
+
+// parseTime parses a date or an RFC 3339 timestamp. An empty value returns the zero time.
+// If endOfDay is set, a date is moved to the start of the next day.
+func parseTime(value string, location *time.Location, endOfDay bool) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	t, err := time.ParseInLocation(dateLayout, value, location)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
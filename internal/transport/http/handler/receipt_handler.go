@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"god"
+	"log/slog"
+	"net/http"
+
+	"coffee-shop/internal/model"
+)
+
+type ReceiptHandler interface {
+	GetReceipt(c *god.Context)
+	GetTicket(c *god.Context)
+}
+
+type receiptHandler struct {
+	ReceiptService ReceiptService
+	log            *slog.Logger
+}
+
+func NewReceiptHandler(s ReceiptService, l *slog.Logger) *receiptHandler {
+	return &receiptHandler{ReceiptService: s, log: l}
+}
+
+// GetReceipt handles the HTTP request to print the receipt of an order.
+// The format is given by the "format" query parameter: text (default), html or escpos.
+func (h *receiptHandler) GetReceipt(c *god.Context) {
+	id, ok := pathID(c, model.ErrNotValidOrderID)
+	if !ok {
+		return
+	}
+
+	data, contentType, err := h.ReceiptService.RenderReceipt(c.Request.Context(), id, c.DefaultQuery("format", model.ReceiptFormatText))
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.Data(http.StatusOK, contentType, data)
+}
+
+// GetTicket handles the HTTP request to print the kitchen ticket of an order.
+// The format is given by the "format" query parameter: text (default), html or escpos.
+func (h *receiptHandler) GetTicket(c *god.Context) {
+	id, ok := pathID(c, model.ErrNotValidOrderID)
+	if !ok {
+		return
+	}
+
+	data, contentType, err := h.ReceiptService.RenderTicket(c.Request.Context(), id, c.DefaultQuery("format", model.ReceiptFormatText))
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.Data(http.StatusOK, contentType, data)
+}
@@ -1,32 +1,268 @@
 package handler
 
-// type ReportHandler interface {
-// 	GetTotalSales(w http.ResponseWriter, r *http.Request)
-// 	GetPopularItems(w http.ResponseWriter, r *http.Request)
-// }
-
-// type reportHandler struct {
-// 	ReportService service.ReportService
-// 	log           *slog.Logger
-// }
-
-// func NewReportHandler(rs service.ReportService, l *slog.Logger) *reportHandler {
-// 	return &reportHandler{ReportService: rs, log: l}
-// }
-
-// func (h *reportHandler) GetTotalSales(w http.ResponseWriter, r *http.Request) {
-// 	totalSales, err := h.ReportService.GetTotalSales()
-// 	if err != nil {
-// 		h.log.Error("Failed to get total sales: " + err.Error())
-// 		utils.WriteErrorResponse(http.StatusInternalServerError, err, w, r)
-// 		return
-// 	}
-// 	h.log.Debug("Successfully retrieved the total sales", slog.Any("TotalSales", totalSales))
-// 	utils.WriteJSONResponse(http.StatusOK, totalSales, w, r)
-// }
-
-// func (h *reportHandler) GetPopularItems(w http.ResponseWriter, r *http.Request) {
-// 	// TODO: implement logic to Retrieve popular items.
-// 	w.WriteHeader(http.StatusOK)
-// 	w.Write([]byte("There will be Retrieving popular items."))
-// }
+import (
+	"god"
+	"god/binding"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"coffee-shop/internal/export"
+	"coffee-shop/internal/model"
+	dto "coffee-shop/internal/transport/dto/report"
+)
+
+type ReportHandler interface {
+	GetTaxReport(c *god.Context)
+	GetPaymentReport(c *god.Context)
+	GetSalesReport(c *god.Context)
+	GetPopularItems(c *god.Context)
+	GetIngredientUsage(c *god.Context)
+	GetInventoryForecast(c *god.Context)
+	GetHeatmap(c *god.Context)
+}
+
+type reportHandler struct {
+	ReportService ReportService
+	export        *Exporter
+	location      *time.Location
+	log           *slog.Logger
+}
+
+// NewReportHandler creates the report handler. Dates of the report periods are
+// taken in the shop location. Reports are exported as CSV or XLSX when the client
+// asks for them with the Accept header or the "format" query parameter.
+func NewReportHandler(rs ReportService, e *Exporter, location *time.Location, l *slog.Logger) *reportHandler {
+	return &reportHandler{ReportService: rs, export: e, location: location, log: l}
+}
+
+// GetTaxReport handles the HTTP request to retrieve the taxes collected within a period.
+// The period is given by the "from" and "to" query parameters.
+func (h *reportHandler) GetTaxReport(c *god.Context) {
+	format, ok := responseFormat(c)
+	if !ok {
+		return
+	}
+
+	period, err := parsePeriod(c, h.location)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	lines, err := h.ReportService.GetTaxReport(c.Request.Context(), period)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	if format != binding.MIMEJSON {
+		writeExport(c, h.export, format, "taxes", dto.TaxReportColumns, export.Rows(lines))
+		return
+	}
+
+	h.log.Debug("Retrieved tax report", slog.Time("from", period.From), slog.Time("to", period.To))
+	c.JSON(http.StatusOK, god.H{"body": dto.NewTaxReportResponse(period, lines)})
+}
+
+// GetPaymentReport handles the HTTP request to retrieve the payments, tips and refunds
+// made within a period, grouped by tender.
+func (h *reportHandler) GetPaymentReport(c *god.Context) {
+	format, ok := responseFormat(c)
+	if !ok {
+		return
+	}
+
+	period, err := parsePeriod(c, h.location)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	lines, err := h.ReportService.GetPaymentReport(c.Request.Context(), period)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	if format != binding.MIMEJSON {
+		writeExport(c, h.export, format, "payments", dto.PaymentReportColumns, export.Rows(lines))
+		return
+	}
+
+	h.log.Debug("Retrieved payment report", slog.Time("from", period.From), slog.Time("to", period.To))
+	c.JSON(http.StatusOK, god.H{"body": dto.NewPaymentReportResponse(period, lines)})
+}
+
+// GetSalesReport handles the HTTP request to retrieve the sales within a period per bucket.
+// The buckets are given by the "group_by" query parameter (hour, day (default), week, month)
+// and the orders by the "status" query parameter (closed (default), open, all).
+func (h *reportHandler) GetSalesReport(c *god.Context) {
+	format, ok := responseFormat(c)
+	if !ok {
+		return
+	}
+
+	period, err := parsePeriod(c, h.location)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	var req dto.SalesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		handleBindError(c, model.ErrNotValidQuery, err)
+		return
+	}
+	q := req.ToDomain(period)
+
+	buckets, err := h.ReportService.GetSalesReport(c.Request.Context(), q)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	if format != binding.MIMEJSON {
+		writeExport(c, h.export, format, "sales", dto.SalesColumns, export.Rows(buckets))
+		return
+	}
+
+	h.log.Debug("Retrieved sales report", slog.Time("from", period.From), slog.Time("to", period.To), slog.String("group_by", q.GroupBy))
+	c.JSON(http.StatusOK, god.H{"body": dto.NewSalesReportResponse(q, h.location, buckets)})
+}
+
+// GetPopularItems handles the HTTP request to rank the menu items sold within a period.
+// The query parameters are "limit" (default 10), "rank_by" (quantity (default) or revenue)
+// and "category" to rank only the items of a menu category.
+func (h *reportHandler) GetPopularItems(c *god.Context) {
+	format, ok := responseFormat(c)
+	if !ok {
+		return
+	}
+
+	period, err := parsePeriod(c, h.location)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	var req dto.PopularItemsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		handleBindError(c, model.ErrNotValidLimit, err)
+		return
+	}
+	q := req.ToDomain(period)
+
+	items, err := h.ReportService.GetPopularItems(c.Request.Context(), q)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	if format != binding.MIMEJSON {
+		writeExport(c, h.export, format, "popular-items", dto.PopularItemColumns, export.Rows(items))
+		return
+	}
+
+	h.log.Debug("Retrieved popular items", slog.Time("from", period.From), slog.Time("to", period.To), slog.String("rank_by", q.RankBy))
+	c.JSON(http.StatusOK, god.H{"body": dto.NewPopularItemsResponse(q, items)})
+}
+
+// GetIngredientUsage handles the HTTP request to retrieve the ingredients used within a period,
+// per ingredient and day. The "source" query parameter selects the inventory ledger (default)
+// or a recomputation from the closed orders and the recipes ("orders").
+func (h *reportHandler) GetIngredientUsage(c *god.Context) {
+	format, ok := responseFormat(c)
+	if !ok {
+		return
+	}
+
+	period, err := parsePeriod(c, h.location)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	var req dto.IngredientUsageRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		handleBindError(c, model.ErrNotValidQuery, err)
+		return
+	}
+	q := req.ToDomain(period)
+
+	usage, err := h.ReportService.GetIngredientUsage(c.Request.Context(), q)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	if format != binding.MIMEJSON {
+		writeExport(c, h.export, format, "ingredient-usage", dto.IngredientUsageColumns, export.Rows(dto.NewIngredientUsageRows(usage)))
+		return
+	}
+
+	h.log.Debug("Retrieved ingredient usage", slog.Time("from", period.From), slog.Time("to", period.To), slog.String("source", q.Source))
+	c.JSON(http.StatusOK, god.H{"body": dto.NewIngredientUsageResponse(q, usage)})
+}
+
+// GetInventoryForecast handles the HTTP request to project when the ingredients run out.
+// The query parameters are "window", the days of the usage moving average (default 14),
+// "lead_time", the days a delivery takes (default 3), and "cover", the days a reorder
+// should last after the delivery (default 7).
+func (h *reportHandler) GetInventoryForecast(c *god.Context) {
+	format, ok := responseFormat(c)
+	if !ok {
+		return
+	}
+
+	var req dto.ForecastRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		handleBindError(c, model.ErrNotValidForecast, err)
+		return
+	}
+	q := req.ToDomain()
+
+	forecasts, err := h.ReportService.GetInventoryForecast(c.Request.Context(), q)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	if format != binding.MIMEJSON {
+		writeExport(c, h.export, format, "inventory-forecast", dto.InventoryForecastColumns, export.Rows(forecasts))
+		return
+	}
+
+	h.log.Debug("Retrieved inventory forecast", slog.Int("window", q.Window))
+	c.JSON(http.StatusOK, god.H{"body": dto.NewInventoryForecastResponse(q, forecasts)})
+}
+
+// GetHeatmap handles the HTTP request to retrieve the orders, revenue and preparation times
+// within a period on a weekday × hour grid of the shop location.
+// The period is given by the "from" and "to" query parameters.
+func (h *reportHandler) GetHeatmap(c *god.Context) {
+	format, ok := responseFormat(c)
+	if !ok {
+		return
+	}
+
+	period, err := parsePeriod(c, h.location)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	heatmap, err := h.ReportService.GetHeatmap(c.Request.Context(), period)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	if format != binding.MIMEJSON {
+		writeExport(c, h.export, format, "heatmap", dto.HeatmapColumns, export.Rows(heatmap.Cells))
+		return
+	}
+
+	h.log.Debug("Retrieved order heatmap", slog.Time("from", period.From), slog.Time("to", period.To))
+	c.JSON(http.StatusOK, god.H{"body": dto.NewHeatmapResponse(heatmap, h.location)})
+}
@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"god"
+	"log/slog"
+	"net/http"
+
+	"coffee-shop/internal/model"
+	dto "coffee-shop/internal/transport/dto/tax"
+)
+
+type TaxHandler interface {
+	GetAllTaxRates(c *god.Context)
+	GetTaxRate(c *god.Context)
+	SetTaxRate(c *god.Context)
+	DeleteTaxRate(c *god.Context)
+}
+
+type taxHandler struct {
+	service TaxService
+	log     *slog.Logger
+}
+
+func NewTaxHandler(s TaxService, l *slog.Logger) *taxHandler {
+	return &taxHandler{service: s, log: l}
+}
+
+// GetAllTaxRates handles the HTTP request to retrieve the tax rates of all categories.
+func (h *taxHandler) GetAllTaxRates(c *god.Context) {
+	rates, err := h.service.RetrieveTaxRates(c.Request.Context())
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	items := []dto.TaxRateResponse{}
+	for _, r := range rates {
+		items = append(items, dto.NewTaxRateResponse(r))
+	}
+
+	c.JSON(http.StatusOK, god.H{"body": items})
+}
+
+// GetTaxRate handles the HTTP request to retrieve the tax rate of a category.
+func (h *taxHandler) GetTaxRate(c *god.Context) {
+	rate, err := h.service.RetrieveTaxRate(c.Request.Context(), c.PathValue("category"))
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, god.H{"body": dto.NewTaxRateResponse(*rate)})
+}
+
+// SetTaxRate handles the HTTP request to create or replace the tax rate of a category.
+func (h *taxHandler) SetTaxRate(c *god.Context) {
+	var req dto.TaxRateRequest
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		handleBindError(c, model.ErrNotValidBody, err)
+		return
+	}
+
+	rate := req.ToDomain(c.PathValue("category"))
+	err = h.service.SetTaxRate(c.Request.Context(), rate)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	h.log.Info("Tax rate is set", slog.String("category", rate.Category), slog.Float64("rate", rate.Rate), slog.Bool("inclusive", rate.Inclusive))
+	c.JSON(http.StatusOK, god.H{"body": dto.NewTaxRateResponse(rate)})
+}
+
+// DeleteTaxRate handles the HTTP request to delete the tax rate of a category.
+func (h *taxHandler) DeleteTaxRate(c *god.Context) {
+	category := c.PathValue("category")
+	err := h.service.DeleteTaxRate(c.Request.Context(), category)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	h.log.Info("Tax rate is deleted", slog.String("category", category))
+	c.Status(http.StatusNoContent)
+}
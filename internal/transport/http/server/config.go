@@ -1,5 +1,15 @@
 package server
 
+import (
+	"os"
+	"time"
+
+	"coffee-shop/internal/receipt"
+)
+
+// AuthSecretEnv is the environment variable holding the secret that signs access tokens.
+const AuthSecretEnv = "COFFEE_SHOP_AUTH_SECRET"
+
 type Config struct {
 	Env            string
 	port           string
@@ -18,6 +28,45 @@ type Config struct {
 	cfg_file string
 
 	allow_overwrite bool
+
+	// Receipt holds the shop header and the templates of receipts and kitchen tickets.
+	Receipt receipt.Config
+
+	// Timezone is the IANA time zone of the shop. Report days, weeks and months follow its calendar.
+	Timezone string
+
+	// Stations map a station of the live order queue to the menu categories it prepares.
+	Stations map[string][]string
+
+	// Export holds the default columns of the CSV and XLSX exports by export name
+	// (orders, inventory, sales, ...). Exports without defaults have all their columns.
+	Export map[string][]string
+
+	// Storage selects where the records are kept: postgres, json (a file in the data
+	// directory) or memory (lost on restart). Reports and end-of-day closings need postgres.
+	Storage string
+
+	// MaxBodySize limits the size of JSON request bodies in bytes.
+	MaxBodySize int64
+
+	// DisallowUnknownFields makes requests with fields the endpoint does not expect fail.
+	DisallowUnknownFields bool
+
+	// Auth configures the access tokens of the staff.
+	Auth AuthConfig
+
+	// IdempotencyTTL is how long the response of a request made with an Idempotency-Key
+	// header is kept for its retries.
+	IdempotencyTTL time.Duration
+}
+
+type AuthConfig struct {
+	// Secret signs the access tokens, at least 32 bytes long. It is read from
+	// AuthSecretEnv; without it a random secret is used and tokens do not survive a restart.
+	Secret string
+
+	// TokenTTL is how long an access token is valid.
+	TokenTTL time.Duration
 }
 
 func NewConfig(configPath, port, dir string) *Config {
@@ -44,9 +93,43 @@ func NewConfig(configPath, port, dir string) *Config {
 		cfg_file: "./configs/server.yaml",
 
 		allow_overwrite: true,
+
+		Receipt: receipt.Config{
+			Shop: receipt.Shop{
+				Name:   "Frappuccino",
+				Footer: "Thank you for your visit!",
+			},
+			Width: 42,
+		},
+
+		Timezone: "UTC",
+
+		Stations: map[string][]string{
+			"bar":    {"drinks"},
+			"bakery": {"bakery"},
+		},
+
+		Export: map[string][]string{
+			"orders": {"id", "customer_name", "status", "subtotal", "discount", "tax", "total", "created_at", "closed_at"},
+		},
+
+		Storage: "postgres",
+
+		MaxBodySize: 1 << 20,
+
+		Auth: AuthConfig{
+			Secret:   os.Getenv(AuthSecretEnv),
+			TokenTTL: 12 * time.Hour,
+		},
+
+		IdempotencyTTL: 24 * time.Hour,
 	}
 }
 
 func (cfg *Config) GetPort() string {
 	return cfg.port
 }
+
+func (cfg *Config) GetDataDir() string {
+	return cfg.data_directory
+}
@@ -0,0 +1,93 @@
+package server
+
+import (
+	"crypto/rand"
+	"god"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"coffee-shop/internal/model"
+	"coffee-shop/internal/transport/http/handler"
+)
+
+const (
+	// requestIDHeader carries the ID of a request, set by the client or a proxy, or
+	// generated by the server. It is sent back with the response.
+	requestIDHeader = "X-Request-ID"
+	requestIDKey    = "request_id"
+
+	maxRequestIDLength = 128
+)
+
+// requestID gives every request an ID, taken from its X-Request-ID header if it has a
+// usable one, so that its log records and audit entries can be traced.
+func requestID() god.HandlerFunc {
+	return func(c *god.Context) {
+		id := c.GetHeader(requestIDHeader)
+		if !validRequestID(id) {
+			id = rand.Text()
+		}
+
+		c.Set(requestIDKey, id)
+		c.Header(requestIDHeader, id)
+		c.Next()
+	}
+}
+
+// validRequestID reports whether the ID is short and printable, so that it is safe
+// to log and to store.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < '!' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// requestOrigin stores the origin of the request in its context, so that the services
+// record who made the changes. It runs after the authentication.
+func requestOrigin() god.HandlerFunc {
+	return func(c *god.Context) {
+		origin := model.Origin{IP: c.ClientIP()}
+		origin.Principal, _ = handler.Principal(c)
+		if id, ok := c.Get(requestIDKey); ok {
+			origin.RequestID, _ = id.(string)
+		}
+
+		c.Request = c.Request.WithContext(model.WithOrigin(c.Request.Context(), origin))
+		c.Next()
+	}
+}
+
+// requestLogger logs every request once it is handled, with the errors its handlers
+// collected with c.Error. Requests that failed with a server error are logged as errors.
+func requestLogger(log *slog.Logger) god.HandlerFunc {
+	return func(c *god.Context) {
+		start := time.Now()
+		c.Next()
+
+		attrs := []any{
+			slog.String("method", c.Request.Method),
+			slog.String("path", c.Request.URL.Path),
+			slog.Int("status", c.Writer.Status()),
+			slog.Duration("latency", time.Since(start)),
+		}
+		if id, ok := c.Get(requestIDKey); ok {
+			attrs = append(attrs, slog.Any(requestIDKey, id))
+		}
+		if len(c.Errors) > 0 {
+			attrs = append(attrs, slog.String("error", c.Errors.Error()))
+		}
+
+		if c.Writer.Status() >= http.StatusInternalServerError {
+			log.Error("Request failed", attrs...)
+			return
+		}
+		log.Debug("Request handled", attrs...)
+	}
+}
@@ -1,12 +1,29 @@
 package server
 
-import "coffee-shop/internal/transport/http/handler"
+import (
+	"coffee-shop/internal/model"
+	"coffee-shop/internal/transport/http/handler"
+)
 
 // endpoint prefix patterns
 const (
 	inventoryPrefix = "/inventory"
 	menuPrefix      = "/menu"
-	orderPrefix     = "/order"
+	orderPrefix     = "/orders"
+	taxPrefix       = "/taxes"
+	reportPrefix    = "/reports"
+	importPrefix    = "/import"
+	authPrefix      = "/auth"
+	staffPrefix     = "/staff"
+	auditPrefix     = "/audit"
+)
+
+// Role requirements of the routes, declared at registration. A role includes the
+// roles below it; routes without a requirement are open to everyone.
+var (
+	barista = handler.RequireRole(model.RoleBarista)
+	manager = handler.RequireRole(model.RoleManager)
+	admin   = handler.RequireRole(model.RoleAdmin)
 )
 
 func (s *Server) registerRoutes() {
@@ -25,11 +42,100 @@ func (s *Server) registerRoutes() {
 }
 
 func (s *Server) SetupInventoryRoutes(handler handler.InventoryHandler) {
-	s.r.POST(inventoryPrefix, handler.AddInventoryItem)
-	s.r.GET(inventoryPrefix, handler.GetAllInventoryItems)
-	s.r.GET(inventoryPrefix+"/:id", handler.GetInventoryItem)
-	s.r.PUT(inventoryPrefix+"/:id", handler.UpdateInventoryItem)
-	s.r.DELETE(inventoryPrefix+"/:id", handler.DeleteInventoryItem)
+	s.r.POST(inventoryPrefix, manager, handler.AddInventoryItem)
+	s.r.GET(inventoryPrefix, barista, handler.GetAllInventoryItems)
+	s.r.GET(inventoryPrefix+"/:id", barista, handler.GetInventoryItem)
+	s.r.PUT(inventoryPrefix+"/:id", manager, handler.UpdateInventoryItem)
+	s.r.PATCH(inventoryPrefix+"/:id", manager, handler.PatchInventoryItem)
+	s.r.DELETE(inventoryPrefix+"/:id", manager, handler.DeleteInventoryItem)
+	s.r.POST(inventoryPrefix+"/:id/restore", manager, handler.RestoreInventoryItem)
+}
+
+func (s *Server) SetupImportRoutes(handler handler.ImportHandler) {
+	s.r.POST(importPrefix+"/menu", manager, handler.ImportMenu)
+	s.r.POST(importPrefix+"/inventory", manager, handler.ImportInventory)
+}
+
+func (s *Server) SetupMenuRoutes(handler handler.MenuItem) {
+	s.r.POST(menuPrefix, manager, handler.AddMenuItem)
+	s.r.GET(menuPrefix, handler.GetAllMenuItems)
+	s.r.GET(menuPrefix+"/:id", handler.GetMenuItem)
+	s.r.PUT(menuPrefix+"/:id", manager, handler.UpdateMenuItem)
+	s.r.PATCH(menuPrefix+"/:id", manager, handler.PatchMenuItem)
+	s.r.DELETE(menuPrefix+"/:id", manager, handler.DeleteMenuItem)
+	s.r.POST(menuPrefix+"/:id/restore", manager, handler.RestoreMenuItem)
+}
+
+func (s *Server) SetupOrderRoutes(handler handler.OrderHandler) {
+	s.r.POST(orderPrefix, barista, s.idempotent, handler.CreateOrder)
+	s.r.POST(orderPrefix+"/batch-process", barista, handler.ProcessBatch)
+	s.r.GET(orderPrefix, barista, handler.RetrieveOrders)
+	s.r.GET(orderPrefix+"/:id", barista, handler.RetrieveOrder)
+	s.r.PUT(orderPrefix+"/:id", barista, handler.UpdateOrder)
+	s.r.DELETE(orderPrefix+"/:id", manager, handler.DeleteOrder)
+	s.r.POST(orderPrefix+"/:id/close", barista, handler.CloseOrder)
+	s.r.GET(orderPrefix+"/:id/totals", barista, handler.RetrieveOrderTotals)
+}
+
+func (s *Server) SetupOrderStreamRoutes(handler handler.OrderStreamHandler) {
+	s.r.GET(orderPrefix+"/stream", barista, handler.StreamOrders)
+}
+
+func (s *Server) SetupReceiptRoutes(handler handler.ReceiptHandler) {
+	s.r.GET(orderPrefix+"/:id/receipt", barista, handler.GetReceipt)
+	s.r.GET(orderPrefix+"/:id/ticket", barista, handler.GetTicket)
+}
+
+func (s *Server) SetupTaxRoutes(handler handler.TaxHandler) {
+	s.r.GET(taxPrefix, barista, handler.GetAllTaxRates)
+	s.r.GET(taxPrefix+"/:category", barista, handler.GetTaxRate)
+	s.r.PUT(taxPrefix+"/:category", manager, handler.SetTaxRate)
+	s.r.DELETE(taxPrefix+"/:category", manager, handler.DeleteTaxRate)
+}
+
+func (s *Server) SetupReportRoutes(handler handler.ReportHandler) {
+	s.r.GET(reportPrefix+"/taxes", manager, handler.GetTaxReport)
+	s.r.GET(reportPrefix+"/payments", manager, handler.GetPaymentReport)
+	s.r.GET(reportPrefix+"/sales", manager, handler.GetSalesReport)
+	s.r.GET(reportPrefix+"/popular-items", manager, handler.GetPopularItems)
+	s.r.GET(reportPrefix+"/ingredient-usage", manager, handler.GetIngredientUsage)
+	s.r.GET(reportPrefix+"/inventory-forecast", manager, handler.GetInventoryForecast)
+	s.r.GET(reportPrefix+"/heatmap", manager, handler.GetHeatmap)
+}
+
+func (s *Server) SetupClosingRoutes(handler handler.ClosingHandler) {
+	s.r.POST(reportPrefix+"/end-of-day", manager, handler.CloseDay)
+	s.r.GET(reportPrefix+"/end-of-day", manager, handler.GetClosings)
+	s.r.GET(reportPrefix+"/end-of-day/:date", manager, handler.GetClosing)
+}
+
+func (s *Server) SetupPaymentRoutes(handler handler.PaymentHandler) {
+	s.r.POST(orderPrefix+"/:id/payments", barista, s.idempotent, handler.AddPayment)
+	s.r.GET(orderPrefix+"/:id/payments", barista, handler.GetPayments)
+	s.r.POST(orderPrefix+"/:id/split", barista, s.idempotent, handler.SplitBill)
+	s.r.POST(orderPrefix+"/:id/refunds", manager, s.idempotent, handler.RefundOrder)
+}
+
+// SetupIdempotency sets the middleware that replays the responses of orders and
+// payments retried with an Idempotency-Key header. It is called before the order and
+// payment routes are registered.
+func (s *Server) SetupIdempotency(handler handler.IdempotencyHandler) {
+	s.idempotent = handler.Idempotent
+}
+
+// SetupAuthRoutes registers the sign in and staff routes, and the middlewares that
+// authenticate the requests of all routes and pass their origin to the services.
+func (s *Server) SetupAuthRoutes(handler handler.AuthHandler) {
+	s.r.Use(handler.Authenticate, requestOrigin())
+
+	s.r.POST(authPrefix+"/login", handler.Login)
+	s.r.POST(staffPrefix, admin, handler.AddStaff)
+	s.r.GET(staffPrefix, admin, handler.GetAllStaff)
+	s.r.PUT(staffPrefix+"/:id", admin, handler.UpdateStaff)
+}
+
+func (s *Server) SetupAuditRoutes(handler handler.AuditHandler) {
+	s.r.GET(auditPrefix, manager, handler.GetAuditLog)
 }
 
 // func (s *Server) registerMenuRoutes() {
@@ -2,6 +2,7 @@ package server
 
 import (
 	"god"
+	"god/binding"
 	"log/slog"
 
 	"coffee-shop/internal/utils"
@@ -11,16 +12,26 @@ type Server struct {
 	config *Config
 	log    *slog.Logger
 	r      *god.Router
+
+	// idempotent makes retried orders and payments safe; it lets every request
+	// through until SetupIdempotency is called.
+	idempotent god.HandlerFunc
 }
 
 // New server
 func New(config *Config, logger *slog.Logger) *Server {
+	binding.MaxBodySize = config.MaxBodySize
+	binding.EnableDecoderDisallowUnknownFields = config.DisallowUnknownFields
+
 	s := &Server{
 		config: config,
 		log:    logger,
 		r:      god.Default(),
+
+		idempotent: func(c *god.Context) { c.Next() },
 	}
 
+	s.r.Use(requestID(), requestLogger(logger))
 	s.registerRoutes()
 	return s
 }
@@ -163,14 +163,23 @@ func CustomUsage() {
 	fmt.Println(`Coffee Shop Management System
 
 Usage:
-  hot-coffee [--port <N>] [--dir <S>] [--cfg <S>]
+  hot-coffee [--port <N>] [--dir <S>] [--cfg <S>] [--storage <S>]
+  hot-coffee import-legacy [--dir <S>]
+  hot-coffee create-staff --username <S> [--role <S>] [--api-key] [--dir <S>] [--storage <S>]
   hot-coffee --help
 
 Options:
   --help       Show this screen.
   --port N     Port number.
-  --dir S      Path to the data directory.
-  --cfg S      Path to the config file.`)
+  --dir S      Path to the data directory. For import-legacy, the directory
+               with the inventory.json, menu_items.json and orders.json files.
+  --cfg S      Path to the config file.
+  --storage S  Where the records are kept: postgres (default), json (store.json
+               in the data directory) or memory.
+  --username S For create-staff, the username of the new staff member. The
+               password is read from the standard input.
+  --role S     For create-staff, the role: barista (default), manager or admin.
+  --api-key    For create-staff, issue an API key and print it once.`)
 }
 
 // ValidatePort checks if the provided port string is a valid number
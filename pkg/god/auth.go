@@ -0,0 +1,62 @@
+package god
+
+import (
+	"net/http"
+	"strings"
+)
+
+// PrincipalKey is the key of Context.Keys under which Auth stores the principal of a request.
+const PrincipalKey = "god.principal"
+
+// AuthConfig configures the Auth middleware.
+type AuthConfig struct {
+	// Authenticate returns the principal of the request, or nil if the request carries
+	// no credentials. An error means the credentials were rejected.
+	Authenticate func(c *Context) (any, error)
+
+	// Unauthorized responds to a request whose credentials were rejected. By default
+	// it responds with a 401 problem.
+	Unauthorized func(c *Context, err error)
+}
+
+// Auth returns a middleware that authenticates requests and stores their principal in
+// Context.Keys under PrincipalKey. Requests without credentials go on without a
+// principal, so that routes open to everyone can be served; routes that need a principal
+// check it with a middleware of their own. Requests with rejected credentials are aborted.
+func Auth(config AuthConfig) HandlerFunc {
+	unauthorized := config.Unauthorized
+	if unauthorized == nil {
+		unauthorized = func(c *Context, err error) {
+			c.Problem(Problem{Status: http.StatusUnauthorized})
+		}
+	}
+
+	return func(c *Context) {
+		principal, err := config.Authenticate(c)
+		if err != nil {
+			c.Abort()
+			unauthorized(c, err)
+			return
+		}
+
+		if principal != nil {
+			c.Set(PrincipalKey, principal)
+		}
+		c.Next()
+	}
+}
+
+// Principal returns the principal stored by Auth.
+func (c *Context) Principal() (any, bool) {
+	return c.Get(PrincipalKey)
+}
+
+// BearerToken returns the token of an "Authorization: Bearer <token>" header, or an
+// empty string if the request has no such header.
+func (c *Context) BearerToken() string {
+	scheme, token, ok := strings.Cut(c.GetHeader("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
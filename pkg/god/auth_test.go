@@ -0,0 +1,73 @@
+package god
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAuth(t *testing.T) {
+	authenticate := func(c *Context) (any, error) {
+		switch token := c.BearerToken(); token {
+		case "":
+			return nil, nil
+		case "ann":
+			return "ann", nil
+		default:
+			return nil, errors.New("unknown token")
+		}
+	}
+
+	tests := []struct {
+		name          string
+		authorization string
+		unauthorized  func(c *Context, err error)
+		wantStatus    int
+		wantPrincipal any
+	}{
+		{name: "valid token", authorization: "Bearer ann", wantStatus: http.StatusOK, wantPrincipal: "ann"},
+		{name: "scheme is case-insensitive", authorization: "bearer  ann ", wantStatus: http.StatusOK, wantPrincipal: "ann"},
+		{name: "no credentials", wantStatus: http.StatusOK},
+		{name: "other scheme", authorization: "Basic YW5uOnB3", wantStatus: http.StatusOK},
+		{name: "rejected token", authorization: "Bearer bob", wantStatus: http.StatusUnauthorized},
+		{
+			name:          "custom response to a rejected token",
+			authorization: "Bearer bob",
+			unauthorized:  func(c *Context, err error) { c.String(http.StatusForbidden, err.Error()) },
+			wantStatus:    http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var principal any
+			handled := false
+
+			r := Default()
+			r.Use(Auth(AuthConfig{Authenticate: authenticate, Unauthorized: tt.unauthorized}))
+			r.GET("/orders", func(c *Context) {
+				handled = true
+				principal, _ = c.Principal()
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/orders", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if handled != (tt.wantStatus == http.StatusOK) {
+				t.Errorf("handled = %v with status %d", handled, w.Code)
+			}
+			if principal != tt.wantPrincipal {
+				t.Errorf("principal = %v, want %v", principal, tt.wantPrincipal)
+			}
+		})
+	}
+}
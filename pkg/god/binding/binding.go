@@ -9,6 +9,11 @@ const (
 	MIMEPlain = "text/plain"
 	MIMEYAML  = "application/x-yaml"
 	MIMETOML  = "application/toml"
+	MIMECSV   = "text/csv"
+	MIMEXLSX  = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+
+	MIMEMergePatchJSON = "application/merge-patch+json"
+	MIMEJSONPatch      = "application/json-patch+json"
 )
 
 type Binding interface {
@@ -23,4 +28,12 @@ type BindingBody interface {
 
 var JSON BindingBody = jsonBinding{}
 
-// TODO: Implement validator struct and interface
+var (
+	// EnableDecoderDisallowUnknownFields makes the JSON binding reject fields
+	// that the struct does not have.
+	EnableDecoderDisallowUnknownFields = false
+
+	// MaxBodySize limits the size of the request bodies read by god.Context when
+	// binding. Larger bodies fail with *http.MaxBytesError. Zero or less disables the limit.
+	MaxBodySize int64 = 1 << 20
+)
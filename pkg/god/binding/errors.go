@@ -0,0 +1,26 @@
+package binding
+
+import "strings"
+
+// FieldError reports a field of the request that broke a rule. Rule is "type" if
+// the value has the wrong type and "unknown" if the field is not expected.
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+func (e FieldError) Error() string {
+	return e.Field + " " + e.Message
+}
+
+// ValidationErrors are the fields of a request that broke their rules.
+type ValidationErrors []FieldError
+
+func (e ValidationErrors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, f := range e {
+		messages = append(messages, f.Error())
+	}
+	return strings.Join(messages, "; ")
+}
@@ -0,0 +1,279 @@
+package binding
+
+import (
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaxMultipartMemory is the part of a multipart form kept in memory by the Form
+// binding; larger files are stored in temporary files.
+var MaxMultipartMemory int64 = 32 << 20
+
+var (
+	Query BindingQuery = queryBinding{}
+	Form  Binding      = formBinding{}
+	Uri   BindingUri   = uriBinding{}
+)
+
+// BindingQuery is a binding of the URL query, which holds no request body.
+type BindingQuery interface {
+	Binding
+	BindQuery(values map[string][]string, obj any) error
+}
+
+// BindingUri binds the path parameters of a route.
+type BindingUri interface {
+	Name() string
+	BindUri(params map[string][]string, obj any) error
+}
+
+type queryBinding struct{}
+
+func (queryBinding) Name() string {
+	return "query"
+}
+
+func (b queryBinding) Bind(req *http.Request, obj any) error {
+	return b.BindQuery(req.URL.Query(), obj)
+}
+
+func (queryBinding) BindQuery(values map[string][]string, obj any) error {
+	return mapForm(obj, values, nil, "form")
+}
+
+type formBinding struct{}
+
+func (formBinding) Name() string {
+	return "form"
+}
+
+// Bind binds the URL query and the fields of an URL-encoded or a multipart form.
+// Files of a multipart form are bound to *multipart.FileHeader and
+// []*multipart.FileHeader fields.
+func (formBinding) Bind(req *http.Request, obj any) error {
+	if err := req.ParseMultipartForm(MaxMultipartMemory); err != nil && !errors.Is(err, http.ErrNotMultipart) {
+		return err
+	}
+
+	var files map[string][]*multipart.FileHeader
+	if req.MultipartForm != nil {
+		files = req.MultipartForm.File
+	}
+	return mapForm(obj, req.Form, files, "form")
+}
+
+type uriBinding struct{}
+
+func (uriBinding) Name() string {
+	return "uri"
+}
+
+func (uriBinding) BindUri(params map[string][]string, obj any) error {
+	return mapForm(obj, params, nil, "uri")
+}
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	durationType   = reflect.TypeOf(time.Duration(0))
+	fileHeaderType = reflect.TypeOf(&multipart.FileHeader{})
+)
+
+// mapForm sets the fields of obj, a pointer to a struct, from the values named in
+// their tags, e.g. `form:"limit,default=10"`. Without a tag the field name is used,
+// and "-" skips the field. Strings, bools, numbers, durations, times and slices of
+// them are supported; a slice takes repeated values and splits them on commas.
+// The default of a slice lists its values separated by spaces.
+//
+// Times are parsed with the layout in the time_format tag (RFC 3339 by default), or
+// as Unix seconds if it is "unix", in the location named in the time_location tag
+// (UTC by default).
+//
+// Values of the wrong type are reported as ValidationErrors, and the filled struct is
+// validated by Validator.
+func mapForm(obj any, values map[string][]string, files map[string][]*multipart.FileHeader, tag string) error {
+	v := reflect.ValueOf(obj)
+	if v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return errors.New("binding: obj must be a pointer to a struct")
+	}
+
+	var errs ValidationErrors
+	mapStruct(v.Elem(), values, files, tag, &errs)
+	if len(errs) > 0 {
+		return errs
+	}
+	return validate(obj)
+}
+
+func mapStruct(v reflect.Value, values map[string][]string, files map[string][]*multipart.FileHeader, tag string, errs *ValidationErrors) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		field := v.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(sf.Tag.Get(tag), ",")
+		if name == "-" {
+			continue
+		}
+		if sf.Anonymous && field.Kind() == reflect.Struct && name == "" {
+			mapStruct(field, values, files, tag, errs)
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+
+		if sf.Type == fileHeaderType {
+			if fh := files[name]; len(fh) > 0 {
+				field.Set(reflect.ValueOf(fh[0]))
+			}
+			continue
+		}
+		if sf.Type == reflect.SliceOf(fileHeaderType) {
+			if fh := files[name]; len(fh) > 0 {
+				field.Set(reflect.ValueOf(fh))
+			}
+			continue
+		}
+
+		vs, ok := values[name]
+		if !ok || len(vs) == 0 || (len(vs) == 1 && vs[0] == "") {
+			def, hasDefault := defaultValue(opts)
+			if !hasDefault {
+				continue
+			}
+			vs = []string{def}
+			if field.Kind() == reflect.Slice {
+				vs = strings.Fields(def)
+			}
+		}
+
+		if err := setField(field, sf, vs); err != nil {
+			*errs = append(*errs, FieldError{Field: name, Rule: "type", Message: err.Error()})
+		}
+	}
+}
+
+// defaultValue returns the value of the default option of a tag.
+func defaultValue(opts string) (string, bool) {
+	for _, opt := range strings.Split(opts, ",") {
+		if def, ok := strings.CutPrefix(opt, "default="); ok {
+			return def, true
+		}
+	}
+	return "", false
+}
+
+func setField(field reflect.Value, sf reflect.StructField, vs []string) error {
+	if field.Kind() == reflect.Slice && field.Type() != reflect.TypeOf([]byte(nil)) {
+		var items []string
+		for _, value := range vs {
+			items = append(items, strings.Split(value, ",")...)
+		}
+
+		slice := reflect.MakeSlice(field.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := setValue(slice.Index(i), sf, strings.TrimSpace(item)); err != nil {
+				return err
+			}
+		}
+		field.Set(slice)
+		return nil
+	}
+
+	return setValue(field, sf, vs[0])
+}
+
+// setValue parses the value into v. The error describes the expected value.
+func setValue(v reflect.Value, sf reflect.StructField, value string) error {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return setValue(v.Elem(), sf, value)
+	}
+
+	switch v.Type() {
+	case timeType:
+		t, err := parseTime(sf, value)
+		if err != nil {
+			return errors.New("must be a time in the format " + timeFormat(sf))
+		}
+		v.Set(reflect.ValueOf(t))
+		return nil
+	case durationType:
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return errors.New("must be a duration")
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return errors.New("must be true or false")
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, v.Type().Bits())
+		if err != nil {
+			return errors.New("must be " + typeName(v.Type()))
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, v.Type().Bits())
+		if err != nil {
+			return errors.New("must be a non-negative integer")
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(value, v.Type().Bits())
+		if err != nil {
+			return errors.New("must be " + typeName(v.Type()))
+		}
+		v.SetFloat(n)
+	default:
+		return errors.New("cannot be bound from " + value)
+	}
+	return nil
+}
+
+func timeFormat(sf reflect.StructField) string {
+	if format := sf.Tag.Get("time_format"); format != "" {
+		return format
+	}
+	return time.RFC3339
+}
+
+func parseTime(sf reflect.StructField, value string) (time.Time, error) {
+	location := time.UTC
+	if name := sf.Tag.Get("time_location"); name != "" {
+		l, err := time.LoadLocation(name)
+		if err != nil {
+			return time.Time{}, err
+		}
+		location = l
+	}
+
+	format := timeFormat(sf)
+	if format == "unix" {
+		seconds, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return time.Unix(seconds, 0).In(location), nil
+	}
+	return time.ParseInLocation(format, value, location)
+}
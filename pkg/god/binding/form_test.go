@@ -0,0 +1,314 @@
+package binding
+
+import (
+	"bytes"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type Paging struct {
+	Limit  int `form:"limit,default=10" binding:"min=1,max=100"`
+	Offset int `form:"offset"`
+}
+
+type search struct {
+	Paging
+	Query    string        `form:"q"`
+	Statuses []string      `form:"status,default=open closed"`
+	IDs      []int         `form:"id"`
+	Archived bool          `form:"archived"`
+	Min      *float64      `form:"min"`
+	Within   time.Duration `form:"within"`
+	From     time.Time     `form:"from"`
+	Day      time.Time     `form:"day" time_format:"2006-01-02" time_location:"Europe/Oslo"`
+	Since    time.Time     `form:"since" time_format:"unix"`
+	Secret   string        `form:"-"`
+	Untagged string
+}
+
+func TestBindQuery(t *testing.T) {
+	oslo, err := time.LoadLocation("Europe/Oslo")
+	if err != nil {
+		t.Fatalf("LoadLocation: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		query   string
+		want    search
+		wantErr ValidationErrors
+	}{
+		{
+			name:  "defaults",
+			query: "",
+			want:  search{Paging: Paging{Limit: 10}, Statuses: []string{"open", "closed"}},
+		},
+		{
+			name:  "empty value takes the default",
+			query: "limit=&status=",
+			want:  search{Paging: Paging{Limit: 10}, Statuses: []string{"open", "closed"}},
+		},
+		{
+			name:  "values of every kind",
+			query: "limit=5&offset=20&q=latte&status=closed&archived=true&min=2.5&within=1h30m&Untagged=x&Secret=s",
+			want: search{
+				Paging:   Paging{Limit: 5, Offset: 20},
+				Query:    "latte",
+				Statuses: []string{"closed"},
+				Archived: true,
+				Min:      ptr(2.5),
+				Within:   90 * time.Minute,
+				Untagged: "x",
+			},
+		},
+		{
+			name:  "slices take repeated and comma-separated values",
+			query: "id=1,2&id=3&id=%204",
+			want:  search{Paging: Paging{Limit: 10}, Statuses: []string{"open", "closed"}, IDs: []int{1, 2, 3, 4}},
+		},
+		{
+			name:  "times",
+			query: "from=2024-03-01T08:00:00%2B01:00&day=2024-03-01&since=1700000000",
+			want: search{
+				Paging:   Paging{Limit: 10},
+				Statuses: []string{"open", "closed"},
+				From:     time.Date(2024, 3, 1, 7, 0, 0, 0, time.UTC),
+				Day:      time.Date(2024, 3, 1, 0, 0, 0, 0, oslo),
+				Since:    time.Unix(1700000000, 0).UTC(),
+			},
+		},
+		{
+			name:  "values of the wrong type",
+			query: "offset=ten&archived=maybe&min=x&id=1,b&within=soon&from=2024-03-01&since=now",
+			wantErr: ValidationErrors{
+				{Field: "offset", Rule: "type", Message: "must be an integer"},
+				{Field: "id", Rule: "type", Message: "must be an integer"},
+				{Field: "archived", Rule: "type", Message: "must be true or false"},
+				{Field: "min", Rule: "type", Message: "must be a number"},
+				{Field: "within", Rule: "type", Message: "must be a duration"},
+				{Field: "from", Rule: "type", Message: "must be a time in the format " + time.RFC3339},
+				{Field: "since", Rule: "type", Message: "must be a time in the format unix"},
+			},
+		},
+		{
+			name:    "bound values are validated",
+			query:   "limit=500",
+			wantErr: ValidationErrors{{Field: "limit", Rule: "max", Message: "must be at most 100"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := url.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatalf("ParseQuery: %v", err)
+			}
+
+			var got search
+			err = Query.BindQuery(values, &got)
+			if tt.wantErr != nil {
+				if !reflect.DeepEqual(err, tt.wantErr) {
+					t.Errorf("BindQuery: %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("BindQuery: %v", err)
+			}
+
+			// Times are compared as instants and locations, as the monotonic clock differs
+			if !got.From.Equal(tt.want.From) || !got.Day.Equal(tt.want.Day) || got.Day.Location().String() != tt.want.Day.Location().String() || !got.Since.Equal(tt.want.Since) {
+				t.Errorf("times = %v, %v, %v, want %v, %v, %v", got.From, got.Day, got.Since, tt.want.From, tt.want.Day, tt.want.Since)
+			}
+			got.From, got.Day, got.Since = tt.want.From, tt.want.Day, tt.want.Since
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("BindQuery =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBindQueryTarget(t *testing.T) {
+	var s search
+	for _, obj := range []any{s, (*search)(nil), new(int)} {
+		if err := Query.BindQuery(url.Values{}, obj); err == nil {
+			t.Errorf("BindQuery(%T) succeeded, want an error", obj)
+		}
+	}
+}
+
+func TestBindUri(t *testing.T) {
+	type params struct {
+		ID   int    `uri:"id" binding:"min=1"`
+		Slug string `uri:"slug"`
+	}
+
+	tests := []struct {
+		name    string
+		params  map[string][]string
+		want    params
+		wantErr ValidationErrors
+	}{
+		{
+			name:   "path parameters",
+			params: map[string][]string{"id": {"7"}, "slug": {"latte"}},
+			want:   params{ID: 7, Slug: "latte"},
+		},
+		{
+			name:    "wrong type",
+			params:  map[string][]string{"id": {"seven"}},
+			wantErr: ValidationErrors{{Field: "id", Rule: "type", Message: "must be an integer"}},
+		},
+		{
+			name:    "validated",
+			params:  map[string][]string{"id": {"0"}},
+			wantErr: ValidationErrors{{Field: "id", Rule: "min", Message: "must be at least 1"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got params
+			err := Uri.BindUri(tt.params, &got)
+			if tt.wantErr != nil {
+				if !reflect.DeepEqual(err, tt.wantErr) {
+					t.Errorf("BindUri: %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("BindUri = %+v, %v, want %+v", got, err, tt.want)
+			}
+		})
+	}
+}
+
+type upload struct {
+	Name        string                  `form:"name" binding:"required"`
+	Tags        []string                `form:"tag"`
+	DryRun      bool                    `form:"dry_run"`
+	File        *multipart.FileHeader   `form:"file"`
+	Attachments []*multipart.FileHeader `form:"attachment"`
+}
+
+// multipartRequest builds a multipart form with the fields and a file for every
+// field of files, named after the content.
+func multipartRequest(t *testing.T, target string, fields map[string][]string, files map[string][]string) *http.Request {
+	t.Helper()
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	for name, values := range fields {
+		for _, value := range values {
+			if err := w.WriteField(name, value); err != nil {
+				t.Fatalf("WriteField: %v", err)
+			}
+		}
+	}
+	for name, contents := range files {
+		for _, content := range contents {
+			part, err := w.CreateFormFile(name, content+".csv")
+			if err != nil {
+				t.Fatalf("CreateFormFile: %v", err)
+			}
+			if _, err := io.WriteString(part, content); err != nil {
+				t.Fatalf("WriteString: %v", err)
+			}
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, target, &body)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	return req
+}
+
+func fileContent(t *testing.T, fh *multipart.FileHeader) string {
+	t.Helper()
+	f, err := fh.Open()
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	return string(data)
+}
+
+func TestBindForm(t *testing.T) {
+	t.Run("url-encoded form and query", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/import?dry_run=true", strings.NewReader("name=menu&tag=a,b&tag=c"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		var got upload
+		if err := Form.Bind(req, &got); err != nil {
+			t.Fatalf("Bind: %v", err)
+		}
+		want := upload{Name: "menu", Tags: []string{"a", "b", "c"}, DryRun: true}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Bind = %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("multipart form with files", func(t *testing.T) {
+		req := multipartRequest(t, "/import",
+			map[string][]string{"name": {"menu"}, "tag": {"a"}},
+			map[string][]string{"file": {"rows"}, "attachment": {"one", "two"}})
+
+		var got upload
+		if err := Form.Bind(req, &got); err != nil {
+			t.Fatalf("Bind: %v", err)
+		}
+		if got.Name != "menu" || !reflect.DeepEqual(got.Tags, []string{"a"}) {
+			t.Errorf("fields = %q, %v, want menu, [a]", got.Name, got.Tags)
+		}
+		if got.File == nil || got.File.Filename != "rows.csv" || fileContent(t, got.File) != "rows" {
+			t.Errorf("file = %+v, want rows.csv", got.File)
+		}
+		if len(got.Attachments) != 2 || fileContent(t, got.Attachments[0]) != "one" || fileContent(t, got.Attachments[1]) != "two" {
+			t.Errorf("attachments = %+v, want one and two", got.Attachments)
+		}
+	})
+
+	t.Run("multipart form without files", func(t *testing.T) {
+		req := multipartRequest(t, "/import", map[string][]string{"name": {"menu"}}, nil)
+
+		var got upload
+		if err := Form.Bind(req, &got); err != nil {
+			t.Fatalf("Bind: %v", err)
+		}
+		if got.File != nil || got.Attachments != nil {
+			t.Errorf("files = %+v, %+v, want none", got.File, got.Attachments)
+		}
+	})
+
+	t.Run("validated", func(t *testing.T) {
+		req := multipartRequest(t, "/import", nil, map[string][]string{"file": {"rows"}})
+
+		var got upload
+		want := ValidationErrors{{Field: "name", Rule: "required", Message: "is required"}}
+		if err := Form.Bind(req, &got); !reflect.DeepEqual(err, want) {
+			t.Errorf("Bind: %v, want %v", err, want)
+		}
+	})
+
+	t.Run("malformed multipart form", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/import", strings.NewReader("--x\r\nbroken"))
+		req.Header.Set("Content-Type", "multipart/form-data; boundary=x")
+
+		var got upload
+		if err := Form.Bind(req, &got); err == nil {
+			t.Errorf("Bind succeeded, want an error")
+		}
+	})
+}
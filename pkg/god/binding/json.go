@@ -3,8 +3,12 @@ package binding
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"io"
 	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
 )
 
 type jsonBinding struct{}
@@ -14,6 +18,9 @@ func (jsonBinding) Name() string {
 }
 
 func (jsonBinding) Bind(req *http.Request, obj any) error {
+	if req == nil || req.Body == nil {
+		return errors.New("invalid request")
+	}
 	return decodeJSON(req.Body, obj)
 }
 
@@ -21,8 +28,44 @@ func (jsonBinding) BindBody(body []byte, obj any) error {
 	return decodeJSON(bytes.NewReader(body), obj)
 }
 
-// TODO: Write the appropriate error messages
+// decodeJSON decodes the body into obj and validates it. A value of the wrong type
+// and, if they are disallowed, an unknown field are reported as ValidationErrors.
 func decodeJSON(r io.Reader, obj any) error {
 	dec := json.NewDecoder(r)
-	return dec.Decode(obj)
+	if EnableDecoderDisallowUnknownFields {
+		dec.DisallowUnknownFields()
+	}
+
+	err := dec.Decode(obj)
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &typeErr) && typeErr.Field != "":
+		return ValidationErrors{{Field: typeErr.Field, Rule: "type", Message: "must be " + typeName(typeErr.Type)}}
+	case err != nil && strings.HasPrefix(err.Error(), "json: unknown field "):
+		field, _ := strconv.Unquote(strings.TrimPrefix(err.Error(), "json: unknown field "))
+		return ValidationErrors{{Field: field, Rule: "unknown", Message: "is not expected"}}
+	case err != nil:
+		return err
+	}
+
+	return validate(obj)
+}
+
+// typeName describes the JSON type of values of t.
+func typeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "an integer"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	default:
+		return "an object"
+	}
 }
@@ -0,0 +1,410 @@
+package binding
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Errors of MergePatch and JSONPatch.
+var (
+	// ErrPatchInvalid is returned for a patch that is malformed.
+	ErrPatchInvalid = errors.New("binding: invalid patch")
+	// ErrPatchFailed is returned for a patch that cannot be applied to the document,
+	// e.g. because a path does not exist or a test operation failed.
+	ErrPatchFailed = errors.New("binding: patch cannot be applied")
+	// ErrPatchMediaType is returned for a patch of a media type other than
+	// MIMEMergePatchJSON and MIMEJSONPatch.
+	ErrPatchMediaType = errors.New("binding: patch must be " + MIMEMergePatchJSON + " or " + MIMEJSONPatch)
+)
+
+// MergePatch applies a JSON Merge Patch (RFC 7396) to the JSON document doc. Members
+// of the patch replace the members of the document, objects are merged recursively
+// and null removes a member.
+func MergePatch(doc, patch []byte) ([]byte, error) {
+	target, err := decodeDocument(doc)
+	if err != nil {
+		return nil, err
+	}
+	changes, err := decodeDocument(patch)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrPatchInvalid, err)
+	}
+
+	return json.Marshal(mergePatch(target, changes))
+}
+
+func mergePatch(target, patch any) any {
+	changes, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	object, ok := target.(map[string]any)
+	if !ok {
+		object = map[string]any{}
+	}
+
+	for name, value := range changes {
+		if value == nil {
+			delete(object, name)
+			continue
+		}
+		object[name] = mergePatch(object[name], value)
+	}
+	return object
+}
+
+// patchOperation is an operation of a JSON Patch.
+type patchOperation struct {
+	Op    string          `json:"op"`
+	Path  *string         `json:"path"`
+	From  *string         `json:"from"`
+	Value json.RawMessage `json:"value"`
+}
+
+// JSONPatch applies a JSON Patch (RFC 6902) to the JSON document doc. The operations
+// add, remove, replace, move, copy and test are applied in order, and the patch fails
+// as a whole if one of them fails.
+func JSONPatch(doc, patch []byte) ([]byte, error) {
+	target, err := decodeDocument(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	var operations []patchOperation
+	if err := json.Unmarshal(patch, &operations); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrPatchInvalid, err)
+	}
+
+	for i, op := range operations {
+		target, err = op.apply(target)
+		if err != nil {
+			return nil, fmt.Errorf("operation %d: %w", i, err)
+		}
+	}
+
+	return json.Marshal(target)
+}
+
+func (op patchOperation) apply(doc any) (any, error) {
+	if op.Path == nil {
+		return nil, fmt.Errorf("%w: %q has no path", ErrPatchInvalid, op.Op)
+	}
+	path, err := parsePointer(*op.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op.Op {
+	case "add", "replace", "test":
+		// A null value is kept as "null", so only a missing value is empty
+		if len(op.Value) == 0 {
+			return nil, fmt.Errorf("%w: %q has no value", ErrPatchInvalid, op.Op)
+		}
+		value, err := decodeDocument(op.Value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrPatchInvalid, err)
+		}
+
+		switch op.Op {
+		case "add":
+			return path.add(doc, value)
+		case "replace":
+			return path.replace(doc, value)
+		default:
+			current, err := path.get(doc)
+			if err != nil {
+				return nil, err
+			}
+			if !equalJSON(current, value) {
+				return nil, fmt.Errorf("%w: value at %q differs", ErrPatchFailed, *op.Path)
+			}
+			return doc, nil
+		}
+
+	case "remove":
+		return path.remove(doc)
+
+	case "move", "copy":
+		if op.From == nil {
+			return nil, fmt.Errorf("%w: %q has no from", ErrPatchInvalid, op.Op)
+		}
+		from, err := parsePointer(*op.From)
+		if err != nil {
+			return nil, err
+		}
+		value, err := from.get(doc)
+		if err != nil {
+			return nil, err
+		}
+
+		if op.Op == "copy" {
+			return path.add(doc, copyJSON(value))
+		}
+		if len(path) > len(from) && slices.Equal(path[:len(from)], from) {
+			return nil, fmt.Errorf("%w: cannot move %q into itself", ErrPatchInvalid, *op.From)
+		}
+		doc, err = from.remove(doc)
+		if err != nil {
+			return nil, err
+		}
+		return path.add(doc, value)
+
+	default:
+		return nil, fmt.Errorf("%w: unknown operation %q", ErrPatchInvalid, op.Op)
+	}
+}
+
+// pointer is a parsed JSON Pointer (RFC 6901). The empty pointer is the whole document.
+type pointer []string
+
+func parsePointer(s string) (pointer, error) {
+	if s == "" {
+		return pointer{}, nil
+	}
+	if !strings.HasPrefix(s, "/") {
+		return nil, fmt.Errorf("%w: path %q does not start with /", ErrPatchInvalid, s)
+	}
+
+	tokens := strings.Split(s[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+	}
+	return pointer(tokens), nil
+}
+
+func (p pointer) String() string {
+	var b strings.Builder
+	for _, token := range p {
+		b.WriteString("/" + strings.NewReplacer("~", "~0", "/", "~1").Replace(token))
+	}
+	return b.String()
+}
+
+// get returns the value the pointer refers to.
+func (p pointer) get(doc any) (any, error) {
+	for i, token := range p {
+		child, err := childOf(doc, token)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q does not exist", ErrPatchFailed, p[:i+1].String())
+		}
+		doc = child
+	}
+	return doc, nil
+}
+
+// add inserts the value at the pointer: it sets a member of an object, inserts an
+// element into an array ("-" appends) or, for the empty pointer, replaces the document.
+func (p pointer) add(doc, value any) (any, error) {
+	return p.update(doc, value, func(parent any, token string) (any, bool) {
+		switch parent := parent.(type) {
+		case map[string]any:
+			parent[token] = value
+			return parent, true
+		case []any:
+			i := len(parent)
+			if token != "-" {
+				var ok bool
+				if i, ok = arrayIndex(token, len(parent)+1); !ok {
+					return nil, false
+				}
+			}
+			return slices.Insert(parent, i, value), true
+		default:
+			return nil, false
+		}
+	})
+}
+
+// remove removes the value at the pointer, which must exist.
+func (p pointer) remove(doc any) (any, error) {
+	if len(p) == 0 {
+		return nil, fmt.Errorf("%w: the document cannot be removed", ErrPatchFailed)
+	}
+	return p.update(doc, nil, func(parent any, token string) (any, bool) {
+		switch parent := parent.(type) {
+		case map[string]any:
+			if _, ok := parent[token]; !ok {
+				return nil, false
+			}
+			delete(parent, token)
+			return parent, true
+		case []any:
+			i, ok := arrayIndex(token, len(parent))
+			if !ok {
+				return nil, false
+			}
+			return slices.Delete(parent, i, i+1), true
+		default:
+			return nil, false
+		}
+	})
+}
+
+// replace replaces the value at the pointer, which must exist.
+func (p pointer) replace(doc, value any) (any, error) {
+	return p.update(doc, value, func(parent any, token string) (any, bool) {
+		switch parent := parent.(type) {
+		case map[string]any:
+			if _, ok := parent[token]; !ok {
+				return nil, false
+			}
+			parent[token] = value
+			return parent, true
+		case []any:
+			i, ok := arrayIndex(token, len(parent))
+			if !ok {
+				return nil, false
+			}
+			parent[i] = value
+			return parent, true
+		default:
+			return nil, false
+		}
+	})
+}
+
+// update calls fn with the container of the value the pointer refers to and the
+// last token, and stores the container fn returns in its own parent. For the empty
+// pointer the document is replaced with value. fn reports false if the value cannot
+// be changed, e.g. because it does not exist.
+func (p pointer) update(doc, value any, fn func(parent any, token string) (any, bool)) (any, error) {
+	updated, ok := p.change(doc, value, fn)
+	if !ok {
+		return nil, fmt.Errorf("%w: %q does not exist", ErrPatchFailed, p)
+	}
+	return updated, nil
+}
+
+func (p pointer) change(doc, value any, fn func(parent any, token string) (any, bool)) (any, bool) {
+	switch len(p) {
+	case 0:
+		return value, true
+	case 1:
+		return fn(doc, p[0])
+	}
+
+	child, err := childOf(doc, p[0])
+	if err != nil {
+		return nil, false
+	}
+	child, ok := p[1:].change(child, value, fn)
+	if !ok {
+		return nil, false
+	}
+
+	switch parent := doc.(type) {
+	case map[string]any:
+		parent[p[0]] = child
+	case []any:
+		i, _ := arrayIndex(p[0], len(parent))
+		parent[i] = child
+	}
+	return doc, true
+}
+
+func childOf(doc any, token string) (any, error) {
+	switch doc := doc.(type) {
+	case map[string]any:
+		child, ok := doc[token]
+		if !ok {
+			return nil, ErrPatchFailed
+		}
+		return child, nil
+	case []any:
+		i, ok := arrayIndex(token, len(doc))
+		if !ok {
+			return nil, ErrPatchFailed
+		}
+		return doc[i], nil
+	default:
+		return nil, ErrPatchFailed
+	}
+}
+
+// arrayIndex parses an array index of a pointer, which must be below limit.
+func arrayIndex(token string, limit int) (int, bool) {
+	if token == "" || len(token) > 1 && token[0] == '0' {
+		return 0, false
+	}
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 || i >= limit {
+		return 0, false
+	}
+	return i, true
+}
+
+// decodeDocument decodes a single JSON value, keeping numbers as json.Number.
+func decodeDocument(data []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var doc any
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, errors.New("unexpected data after the JSON value")
+	}
+	return doc, nil
+}
+
+// equalJSON compares decoded JSON values. Numbers are equal if their values are.
+func equalJSON(a, b any) bool {
+	x, xNumber := a.(json.Number)
+	y, yNumber := b.(json.Number)
+	if xNumber || yNumber {
+		if !xNumber || !yNumber {
+			return false
+		}
+		fx, errX := x.Float64()
+		fy, errY := y.Float64()
+		return errX == nil && errY == nil && fx == fy
+	}
+
+	switch a := a.(type) {
+	case map[string]any:
+		b, ok := b.(map[string]any)
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for name, value := range a {
+			other, ok := b[name]
+			if !ok || !equalJSON(value, other) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		b, ok := b.([]any)
+		return ok && slices.EqualFunc(a, b, equalJSON)
+	default:
+		return reflect.DeepEqual(a, b)
+	}
+}
+
+// copyJSON returns a deep copy of a decoded JSON value.
+func copyJSON(value any) any {
+	switch value := value.(type) {
+	case map[string]any:
+		object := make(map[string]any, len(value))
+		for name, member := range value {
+			object[name] = copyJSON(member)
+		}
+		return object
+	case []any:
+		array := make([]any, len(value))
+		for i, element := range value {
+			array[i] = copyJSON(element)
+		}
+		return array
+	default:
+		return value
+	}
+}
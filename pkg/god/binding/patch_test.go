@@ -0,0 +1,365 @@
+package binding
+
+import (
+	"errors"
+	"testing"
+)
+
+// assertJSON fails the test if the JSON documents got and want differ.
+func assertJSON(t *testing.T, got []byte, want string) {
+	t.Helper()
+	x, err := decodeDocument(got)
+	if err != nil {
+		t.Fatalf("result %s: %v", got, err)
+	}
+	y, err := decodeDocument([]byte(want))
+	if err != nil {
+		t.Fatalf("expected %s: %v", want, err)
+	}
+	if !equalJSON(x, y) {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+// The examples of RFC 6901, section 5.
+func TestPointer(t *testing.T) {
+	doc, err := decodeDocument([]byte(`{
+		"foo": ["bar", "baz"],
+		"": 0,
+		"a/b": 1,
+		"c%d": 2,
+		"e^f": 3,
+		"g|h": 4,
+		"i\\j": 5,
+		"k\"l": 6,
+		" ": 7,
+		"m~n": 8
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		pointer string
+		want    string
+	}{
+		{"/foo", `["bar", "baz"]`},
+		{"/foo/0", `"bar"`},
+		{"/", `0`},
+		{"/a~1b", `1`},
+		{"/c%d", `2`},
+		{"/e^f", `3`},
+		{"/g|h", `4`},
+		{"/i\\j", `5`},
+		{"/k\"l", `6`},
+		{"/ ", `7`},
+		{"/m~0n", `8`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pointer, func(t *testing.T) {
+			p, err := parsePointer(tt.pointer)
+			if err != nil {
+				t.Fatalf("parsePointer: %v", err)
+			}
+			if p.String() != tt.pointer {
+				t.Errorf("String = %q, want %q", p.String(), tt.pointer)
+			}
+
+			got, err := p.get(doc)
+			if err != nil {
+				t.Fatalf("get: %v", err)
+			}
+			want, _ := decodeDocument([]byte(tt.want))
+			if !equalJSON(got, want) {
+				t.Errorf("get = %v, want %s", got, tt.want)
+			}
+		})
+	}
+
+	whole, err := parsePointer("")
+	if err != nil {
+		t.Fatalf("parsePointer of the whole document: %v", err)
+	}
+	if got, _ := whole.get(doc); !equalJSON(got, doc) {
+		t.Errorf("the empty pointer does not refer to the whole document")
+	}
+
+	for _, pointer := range []string{"/foo/2", "/foo/-", "/foo/01", "/missing", "/foo/0/bar"} {
+		p, _ := parsePointer(pointer)
+		if _, err := p.get(doc); !errors.Is(err, ErrPatchFailed) {
+			t.Errorf("get %q: %v, want %v", pointer, err, ErrPatchFailed)
+		}
+	}
+	if _, err := parsePointer("foo"); !errors.Is(err, ErrPatchInvalid) {
+		t.Errorf("parsePointer without a leading slash: %v, want %v", err, ErrPatchInvalid)
+	}
+}
+
+func TestJSONPatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		doc     string
+		patch   string
+		want    string
+		wantErr error
+	}{
+		// The examples of RFC 6902, appendix A
+		{
+			name:  "A.1 adding an object member",
+			doc:   `{"foo": "bar"}`,
+			patch: `[{"op": "add", "path": "/baz", "value": "qux"}]`,
+			want:  `{"baz": "qux", "foo": "bar"}`,
+		},
+		{
+			name:  "A.2 adding an array element",
+			doc:   `{"foo": ["bar", "baz"]}`,
+			patch: `[{"op": "add", "path": "/foo/1", "value": "qux"}]`,
+			want:  `{"foo": ["bar", "qux", "baz"]}`,
+		},
+		{
+			name:  "A.3 removing an object member",
+			doc:   `{"baz": "qux", "foo": "bar"}`,
+			patch: `[{"op": "remove", "path": "/baz"}]`,
+			want:  `{"foo": "bar"}`,
+		},
+		{
+			name:  "A.4 removing an array element",
+			doc:   `{"foo": ["bar", "qux", "baz"]}`,
+			patch: `[{"op": "remove", "path": "/foo/1"}]`,
+			want:  `{"foo": ["bar", "baz"]}`,
+		},
+		{
+			name:  "A.5 replacing a value",
+			doc:   `{"baz": "qux", "foo": "bar"}`,
+			patch: `[{"op": "replace", "path": "/baz", "value": "boo"}]`,
+			want:  `{"baz": "boo", "foo": "bar"}`,
+		},
+		{
+			name:  "A.6 moving a value",
+			doc:   `{"foo": {"bar": "baz", "waldo": "fred"}, "qux": {"corge": "grault"}}`,
+			patch: `[{"op": "move", "from": "/foo/waldo", "path": "/qux/thud"}]`,
+			want:  `{"foo": {"bar": "baz"}, "qux": {"corge": "grault", "thud": "fred"}}`,
+		},
+		{
+			name:  "A.7 moving an array element",
+			doc:   `{"foo": ["all", "grass", "cows", "eat"]}`,
+			patch: `[{"op": "move", "from": "/foo/1", "path": "/foo/3"}]`,
+			want:  `{"foo": ["all", "cows", "eat", "grass"]}`,
+		},
+		{
+			name:  "A.8 testing a value: success",
+			doc:   `{"baz": "qux", "foo": ["a", 2, "c"]}`,
+			patch: `[{"op": "test", "path": "/baz", "value": "qux"}, {"op": "test", "path": "/foo/1", "value": 2}]`,
+			want:  `{"baz": "qux", "foo": ["a", 2, "c"]}`,
+		},
+		{
+			name:    "A.9 testing a value: error",
+			doc:     `{"baz": "qux"}`,
+			patch:   `[{"op": "test", "path": "/baz", "value": "bar"}]`,
+			wantErr: ErrPatchFailed,
+		},
+		{
+			name:  "A.10 adding a nested member object",
+			doc:   `{"foo": "bar"}`,
+			patch: `[{"op": "add", "path": "/child", "value": {"grandchild": {}}}]`,
+			want:  `{"foo": "bar", "child": {"grandchild": {}}}`,
+		},
+		{
+			name:  "A.11 ignoring unrecognized elements",
+			doc:   `{"foo": "bar"}`,
+			patch: `[{"op": "add", "path": "/baz", "value": "qux", "xyz": 123}]`,
+			want:  `{"foo": "bar", "baz": "qux"}`,
+		},
+		{
+			name:    "A.12 adding to a nonexistent target",
+			doc:     `{"foo": "bar"}`,
+			patch:   `[{"op": "add", "path": "/baz/bat", "value": "qux"}]`,
+			wantErr: ErrPatchFailed,
+		},
+		{
+			name:  "A.14 ~ escape ordering",
+			doc:   `{"/": 9, "~1": 10}`,
+			patch: `[{"op": "test", "path": "/~01", "value": 10}]`,
+			want:  `{"/": 9, "~1": 10}`,
+		},
+		{
+			name:    "A.15 comparing strings and numbers",
+			doc:     `{"/": 9, "~1": 10}`,
+			patch:   `[{"op": "test", "path": "/~01", "value": "10"}]`,
+			wantErr: ErrPatchFailed,
+		},
+		{
+			name:  "A.16 adding an array value",
+			doc:   `{"foo": ["bar"]}`,
+			patch: `[{"op": "add", "path": "/foo/-", "value": ["abc", "def"]}]`,
+			want:  `{"foo": ["bar", ["abc", "def"]]}`,
+		},
+
+		{
+			name:  "add replaces an existing member",
+			doc:   `{"foo": "bar"}`,
+			patch: `[{"op": "add", "path": "/foo", "value": 1}]`,
+			want:  `{"foo": 1}`,
+		},
+		{
+			name:  "add with the empty path replaces the document",
+			doc:   `{"foo": "bar"}`,
+			patch: `[{"op": "add", "path": "", "value": [1]}]`,
+			want:  `[1]`,
+		},
+		{
+			name:    "add beyond the end of an array",
+			doc:     `{"foo": ["bar"]}`,
+			patch:   `[{"op": "add", "path": "/foo/2", "value": "qux"}]`,
+			wantErr: ErrPatchFailed,
+		},
+		{
+			name:    "array index with a leading zero",
+			doc:     `{"foo": ["bar", "baz"]}`,
+			patch:   `[{"op": "replace", "path": "/foo/01", "value": "qux"}]`,
+			wantErr: ErrPatchFailed,
+		},
+		{
+			name:    "replace of a missing member",
+			doc:     `{"foo": "bar"}`,
+			patch:   `[{"op": "replace", "path": "/baz", "value": "qux"}]`,
+			wantErr: ErrPatchFailed,
+		},
+		{
+			name:    "remove of the document",
+			doc:     `{"foo": "bar"}`,
+			patch:   `[{"op": "remove", "path": ""}]`,
+			wantErr: ErrPatchFailed,
+		},
+		{
+			name:  "copy is independent of its source",
+			doc:   `{"foo": {"bar": 1}}`,
+			patch: `[{"op": "copy", "from": "/foo", "path": "/baz"}, {"op": "replace", "path": "/baz/bar", "value": 2}]`,
+			want:  `{"foo": {"bar": 1}, "baz": {"bar": 2}}`,
+		},
+		{
+			name:  "copy into an array",
+			doc:   `{"foo": ["a", "b"]}`,
+			patch: `[{"op": "copy", "from": "/foo/1", "path": "/foo/0"}]`,
+			want:  `{"foo": ["b", "a", "b"]}`,
+		},
+		{
+			name:    "copy of a missing value",
+			doc:     `{"foo": "bar"}`,
+			patch:   `[{"op": "copy", "from": "/baz", "path": "/qux"}]`,
+			wantErr: ErrPatchFailed,
+		},
+		{
+			name:    "move into a child of itself",
+			doc:     `{"foo": {"bar": 1}}`,
+			patch:   `[{"op": "move", "from": "/foo", "path": "/foo/baz"}]`,
+			wantErr: ErrPatchInvalid,
+		},
+		{
+			name:  "test compares numbers by value and objects by members",
+			doc:   `{"foo": {"a": 1, "b": [1.0, "x"]}}`,
+			patch: `[{"op": "test", "path": "/foo", "value": {"b": [1, "x"], "a": 1.00}}]`,
+			want:  `{"foo": {"a": 1, "b": [1.0, "x"]}}`,
+		},
+		{
+			name:  "null is a value",
+			doc:   `{"foo": "bar"}`,
+			patch: `[{"op": "add", "path": "/baz", "value": null}, {"op": "test", "path": "/baz", "value": null}]`,
+			want:  `{"foo": "bar", "baz": null}`,
+		},
+		{
+			name:    "test of a missing value",
+			doc:     `{"foo": "bar"}`,
+			patch:   `[{"op": "test", "path": "/baz", "value": null}]`,
+			wantErr: ErrPatchFailed,
+		},
+		{
+			name:    "a failing operation fails the patch",
+			doc:     `{"foo": "bar"}`,
+			patch:   `[{"op": "add", "path": "/baz", "value": 1}, {"op": "test", "path": "/foo", "value": "qux"}]`,
+			wantErr: ErrPatchFailed,
+		},
+		{
+			name:    "unknown operation",
+			doc:     `{"foo": "bar"}`,
+			patch:   `[{"op": "merge", "path": "/foo", "value": 1}]`,
+			wantErr: ErrPatchInvalid,
+		},
+		{
+			name:    "operation without a path",
+			doc:     `{"foo": "bar"}`,
+			patch:   `[{"op": "remove"}]`,
+			wantErr: ErrPatchInvalid,
+		},
+		{
+			name:    "operation without a value",
+			doc:     `{"foo": "bar"}`,
+			patch:   `[{"op": "add", "path": "/baz"}]`,
+			wantErr: ErrPatchInvalid,
+		},
+		{
+			name:    "move without from",
+			doc:     `{"foo": "bar"}`,
+			patch:   `[{"op": "move", "path": "/baz"}]`,
+			wantErr: ErrPatchInvalid,
+		},
+		{
+			name:    "patch that is not an array",
+			doc:     `{"foo": "bar"}`,
+			patch:   `{"op": "remove", "path": "/foo"}`,
+			wantErr: ErrPatchInvalid,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := JSONPatch([]byte(tt.doc), []byte(tt.patch))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("JSONPatch: %v, want %v", err, tt.wantErr)
+			}
+			if err == nil {
+				assertJSON(t, got, tt.want)
+			}
+		})
+	}
+}
+
+// The examples of RFC 7396, appendix A.
+func TestMergePatch(t *testing.T) {
+	tests := []struct {
+		doc   string
+		patch string
+		want  string
+	}{
+		{`{"a": "b"}`, `{"a": "c"}`, `{"a": "c"}`},
+		{`{"a": "b"}`, `{"b": "c"}`, `{"a": "b", "b": "c"}`},
+		{`{"a": "b"}`, `{"a": null}`, `{}`},
+		{`{"a": "b", "b": "c"}`, `{"a": null}`, `{"b": "c"}`},
+		{`{"a": ["b"]}`, `{"a": "c"}`, `{"a": "c"}`},
+		{`{"a": "c"}`, `{"a": ["b"]}`, `{"a": ["b"]}`},
+		{`{"a": {"b": "c"}}`, `{"a": {"b": "d", "c": null}}`, `{"a": {"b": "d"}}`},
+		{`{"a": [{"b": "c"}]}`, `{"a": [1]}`, `{"a": [1]}`},
+		{`["a", "b"]`, `["c", "d"]`, `["c", "d"]`},
+		{`{"a": "b"}`, `["c"]`, `["c"]`},
+		{`{"a": "foo"}`, `null`, `null`},
+		{`{"a": "foo"}`, `"bar"`, `"bar"`},
+		{`{"e": null}`, `{"a": 1}`, `{"e": null, "a": 1}`},
+		{`[1, 2]`, `{"a": "b", "c": null}`, `{"a": "b"}`},
+		{`{}`, `{"a": {"bb": {"ccc": null}}}`, `{"a": {"bb": {}}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.doc+" "+tt.patch, func(t *testing.T) {
+			got, err := MergePatch([]byte(tt.doc), []byte(tt.patch))
+			if err != nil {
+				t.Fatalf("MergePatch: %v", err)
+			}
+			assertJSON(t, got, tt.want)
+		})
+	}
+
+	if _, err := MergePatch([]byte(`{"a": "b"}`), []byte(`{"a": `)); !errors.Is(err, ErrPatchInvalid) {
+		t.Errorf("MergePatch with malformed JSON: %v, want %v", err, ErrPatchInvalid)
+	}
+}
@@ -0,0 +1,291 @@
+package binding
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// StructValidator validates the structs filled by the bindings.
+type StructValidator interface {
+	// ValidateStruct validates obj, a struct or a pointer to one. It returns
+	// ValidationErrors if fields break their rules.
+	ValidateStruct(obj any) error
+}
+
+// Validator is the validator run by the bindings after decoding. Setting it to nil
+// turns validation off.
+var Validator StructValidator = &defaultValidator{}
+
+func validate(obj any) error {
+	if Validator == nil {
+		return nil
+	}
+	return Validator.ValidateStruct(obj)
+}
+
+// defaultValidator checks the rules in the "binding" tags of struct fields, e.g.
+// `binding:"required,max=50"`. Rules are separated by commas:
+//
+//   - required: the value is not zero; strings, slices and maps are not empty.
+//   - omitempty: the other rules are skipped if the value is zero.
+//   - min=n, max=n, len=n: the length of strings (in characters), slices and maps,
+//     or the value of numbers, is at least, at most or exactly n.
+//   - gt=n, gte=n, lt=n, lte=n: the same as min and max with strict comparisons.
+//   - oneof=a b c: the value is one of the space-separated values.
+//
+// The rules apply to the value a pointer field points to. A nil pointer is not a
+// value, so it breaks only required and passes the other rules.
+//
+// Fields are named after their JSON names, or the names they are bound from. Nested
+// structs, and structs in slices, are validated too; the fields of embedded structs
+// are named as fields of the outer struct.
+type defaultValidator struct {
+	rules sync.Map // reflect.Type -> []fieldRules
+}
+
+type fieldRules struct {
+	index     int
+	name      string
+	inline    bool
+	omitempty bool
+	rules     []rule
+}
+
+type rule struct {
+	name  string
+	param string
+	check func(v reflect.Value, param string) bool
+}
+
+// checks are the rules the validator knows, except omitempty.
+var checks = map[string]func(v reflect.Value, param string) bool{
+	"required": func(v reflect.Value, _ string) bool { return !isEmpty(v) },
+	"min":      func(v reflect.Value, p string) bool { return compare(v, p) >= 0 },
+	"max":      func(v reflect.Value, p string) bool { return compare(v, p) <= 0 },
+	"len":      func(v reflect.Value, p string) bool { return compare(v, p) == 0 },
+	"gt":       func(v reflect.Value, p string) bool { return compare(v, p) > 0 },
+	"gte":      func(v reflect.Value, p string) bool { return compare(v, p) >= 0 },
+	"lt":       func(v reflect.Value, p string) bool { return compare(v, p) < 0 },
+	"lte":      func(v reflect.Value, p string) bool { return compare(v, p) <= 0 },
+	"oneof": func(v reflect.Value, p string) bool {
+		s := fmt.Sprint(v.Interface())
+		for _, option := range strings.Fields(p) {
+			if s == option {
+				return true
+			}
+		}
+		return false
+	},
+}
+
+func (d *defaultValidator) ValidateStruct(obj any) error {
+	v := reflect.ValueOf(obj)
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var errs ValidationErrors
+	d.validateStruct(v, "", &errs)
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func (d *defaultValidator) validateStruct(v reflect.Value, prefix string, errs *ValidationErrors) {
+	for _, f := range d.fields(v.Type()) {
+		field := v.Field(f.index)
+		name := prefix + f.name
+
+		if !f.omitempty || !isEmpty(field) {
+			value, ok := indirect(field)
+			for _, r := range f.rules {
+				v := value
+				switch {
+				case r.name == "required":
+					// A pointer to a zero value is present
+					v = field
+				case !ok:
+					continue
+				}
+
+				if !r.check(v, r.param) {
+					*errs = append(*errs, FieldError{Field: name, Rule: r.name, Message: message(v, r)})
+					break
+				}
+			}
+		}
+
+		if f.inline {
+			if embedded, ok := indirect(field); ok && embedded.Kind() == reflect.Struct {
+				d.validateStruct(embedded, prefix, errs)
+			}
+			continue
+		}
+		d.validateNested(field, name, errs)
+	}
+}
+
+// validateNested validates the structs held by a field, directly or in a slice.
+func (d *defaultValidator) validateNested(v reflect.Value, name string, errs *ValidationErrors) {
+	v, ok := indirect(v)
+	if !ok {
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		d.validateStruct(v, name+".", errs)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			d.validateNested(v.Index(i), name+"["+strconv.Itoa(i)+"]", errs)
+		}
+	}
+}
+
+// fields returns the rules of the exported fields of the struct type. They are
+// parsed once per type; an unknown rule is a programming error and panics.
+func (d *defaultValidator) fields(t reflect.Type) []fieldRules {
+	if cached, ok := d.rules.Load(t); ok {
+		return cached.([]fieldRules)
+	}
+
+	var fields []fieldRules
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("binding")
+		if !sf.IsExported() || tag == "-" {
+			continue
+		}
+
+		name, tagged := fieldName(sf)
+		f := fieldRules{index: i, name: name, inline: sf.Anonymous && !tagged}
+		for _, part := range strings.Split(tag, ",") {
+			if part == "" {
+				continue
+			}
+			if part == "omitempty" {
+				f.omitempty = true
+				continue
+			}
+
+			name, param, _ := strings.Cut(part, "=")
+			check, ok := checks[name]
+			if !ok {
+				panic(fmt.Sprintf("binding: unknown rule %q on field %s.%s", name, t.Name(), sf.Name))
+			}
+			f.rules = append(f.rules, rule{name: name, param: param, check: check})
+		}
+		fields = append(fields, f)
+	}
+
+	d.rules.Store(t, fields)
+	return fields
+}
+
+// fieldName returns the JSON name of the field, or for fields bound from a form or
+// a path, the name they are bound from. It reports false if no tag names the field.
+func fieldName(sf reflect.StructField) (string, bool) {
+	for _, tag := range []string{"json", "form", "uri"} {
+		name, _, _ := strings.Cut(sf.Tag.Get(tag), ",")
+		if name != "" && name != "-" {
+			return name, true
+		}
+	}
+	return sf.Name, false
+}
+
+// indirect returns the value v points to, through any number of pointers. It
+// reports false if one of the pointers is nil.
+func indirect(v reflect.Value) (reflect.Value, bool) {
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return v, false
+		}
+		v = v.Elem()
+	}
+	return v, true
+}
+
+func isEmpty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		return v.Len() == 0
+	default:
+		return v.IsZero()
+	}
+}
+
+// compare compares the length or the value of v with the parameter of a rule.
+func compare(v reflect.Value, param string) int {
+	n, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		panic(fmt.Sprintf("binding: rule parameter %q is not a number", param))
+	}
+
+	var got float64
+	switch v.Kind() {
+	case reflect.String:
+		got = float64(utf8.RuneCountInString(v.String()))
+	case reflect.Slice, reflect.Map, reflect.Array:
+		got = float64(v.Len())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		got = float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		got = float64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		got = v.Float()
+	default:
+		panic(fmt.Sprintf("binding: rule cannot be applied to %s", v.Type()))
+	}
+
+	switch {
+	case got < n:
+		return -1
+	case got > n:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// message explains the broken rule to the client.
+func message(v reflect.Value, r rule) string {
+	if r.name == "required" {
+		return "is required"
+	}
+	if r.name == "oneof" {
+		return "must be one of " + strings.Join(strings.Fields(r.param), ", ")
+	}
+
+	subject := "must be"
+	switch v.Kind() {
+	case reflect.String:
+		subject, r.param = "length must be", r.param+" characters"
+	case reflect.Slice, reflect.Map, reflect.Array:
+		subject, r.param = "must contain", r.param+" items"
+	}
+
+	switch r.name {
+	case "min", "gte":
+		return subject + " at least " + r.param
+	case "max", "lte":
+		return subject + " at most " + r.param
+	case "gt":
+		return subject + " greater than " + r.param
+	case "lt":
+		return subject + " less than " + r.param
+	default:
+		return subject + " exactly " + r.param
+	}
+}
@@ -0,0 +1,211 @@
+package binding
+
+import (
+	"reflect"
+	"testing"
+)
+
+type address struct {
+	City string `json:"city" binding:"required"`
+}
+
+type line struct {
+	Product  int `json:"product_id" binding:"gt=0"`
+	Quantity int `json:"quantity" binding:"min=1,max=10"`
+}
+
+type order struct {
+	Name     string   `json:"name" binding:"required,max=5"`
+	Code     string   `json:"code" binding:"omitempty,len=3"`
+	Status   string   `json:"status" binding:"omitempty,oneof=open closed"`
+	Tags     []string `json:"tags" binding:"omitempty,min=1,max=2"`
+	Total    float64  `json:"total" binding:"gte=0,lt=100"`
+	Discount *float64 `json:"discount" binding:"min=0,lte=50"`
+	Tip      *float64 `json:"tip" binding:"required,min=0"`
+	Table    *int     `json:"table" binding:"omitempty,oneof=1 2 3"`
+	Lines    []line   `json:"lines" binding:"required"`
+	Address  *address `json:"address"`
+	Billing  address
+	internal string
+	Ignored  string `binding:"-"`
+}
+
+func ptr[T any](v T) *T { return &v }
+
+// valid returns an order that keeps all the rules.
+func valid() order {
+	return order{
+		Name:    "Ann",
+		Tip:     ptr(0.0),
+		Lines:   []line{{Product: 1, Quantity: 1}},
+		Billing: address{City: "Oslo"},
+	}
+}
+
+func TestValidateStruct(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(o *order)
+		want   ValidationErrors
+	}{
+		{name: "valid", change: func(o *order) {}},
+		{
+			name:   "required",
+			change: func(o *order) { o.Name = ""; o.Lines = nil },
+			want: ValidationErrors{
+				{Field: "name", Rule: "required", Message: "is required"},
+				{Field: "lines", Rule: "required", Message: "is required"},
+			},
+		},
+		{
+			name:   "max counts characters",
+			change: func(o *order) { o.Name = "Åsa Ö" },
+		},
+		{
+			name:   "max of a string",
+			change: func(o *order) { o.Name = "Annika" },
+			want:   ValidationErrors{{Field: "name", Rule: "max", Message: "length must be at most 5 characters"}},
+		},
+		{
+			name:   "omitempty skips a zero value",
+			change: func(o *order) { o.Code, o.Status, o.Tags = "", "", []string{} },
+		},
+		{
+			name:   "len",
+			change: func(o *order) { o.Code = "AB" },
+			want:   ValidationErrors{{Field: "code", Rule: "len", Message: "length must be exactly 3 characters"}},
+		},
+		{
+			name:   "oneof",
+			change: func(o *order) { o.Status = "paid" },
+			want:   ValidationErrors{{Field: "status", Rule: "oneof", Message: "must be one of open, closed"}},
+		},
+		{
+			name:   "max of a slice",
+			change: func(o *order) { o.Tags = []string{"a", "b", "c"} },
+			want:   ValidationErrors{{Field: "tags", Rule: "max", Message: "must contain at most 2 items"}},
+		},
+		{
+			name:   "gte and lt of a number",
+			change: func(o *order) { o.Total = 100 },
+			want:   ValidationErrors{{Field: "total", Rule: "lt", Message: "must be less than 100"}},
+		},
+		{
+			name:   "only the first broken rule of a field is reported",
+			change: func(o *order) { o.Total = -1 },
+			want:   ValidationErrors{{Field: "total", Rule: "gte", Message: "must be at least 0"}},
+		},
+		{
+			name:   "rules apply to the value of a pointer",
+			change: func(o *order) { o.Discount = ptr(-1.0); o.Table = ptr(4) },
+			want: ValidationErrors{
+				{Field: "discount", Rule: "min", Message: "must be at least 0"},
+				{Field: "table", Rule: "oneof", Message: "must be one of 1, 2, 3"},
+			},
+		},
+		{
+			name:   "nil pointer passes rules other than required",
+			change: func(o *order) { o.Discount, o.Table = nil, nil },
+		},
+		{
+			name:   "nil pointer breaks required",
+			change: func(o *order) { o.Tip = nil },
+			want:   ValidationErrors{{Field: "tip", Rule: "required", Message: "is required"}},
+		},
+		{
+			name:   "pointer to a zero value is present",
+			change: func(o *order) { o.Tip, o.Discount = ptr(0.0), ptr(0.0) },
+		},
+		{
+			name:   "structs in slices are named by their index",
+			change: func(o *order) { o.Lines = append(o.Lines, line{Product: 0, Quantity: 11}) },
+			want: ValidationErrors{
+				{Field: "lines[1].product_id", Rule: "gt", Message: "must be greater than 0"},
+				{Field: "lines[1].quantity", Rule: "max", Message: "must be at most 10"},
+			},
+		},
+		{
+			name:   "nested structs are named by their path",
+			change: func(o *order) { o.Address = &address{}; o.Billing.City = "" },
+			want: ValidationErrors{
+				{Field: "address.city", Rule: "required", Message: "is required"},
+				{Field: "Billing.city", Rule: "required", Message: "is required"},
+			},
+		},
+		{
+			name:   "unexported and ignored fields are not validated",
+			change: func(o *order) { o.internal, o.Ignored = "", "" },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := valid()
+			tt.change(&o)
+
+			err := Validator.ValidateStruct(&o)
+			if tt.want == nil {
+				if err != nil {
+					t.Fatalf("ValidateStruct: %v, want no error", err)
+				}
+				return
+			}
+
+			got, ok := err.(ValidationErrors)
+			if !ok {
+				t.Fatalf("ValidateStruct: %v, want ValidationErrors", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ValidateStruct =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateStructValues(t *testing.T) {
+	var nilOrder *order
+	tests := []struct {
+		name string
+		obj  any
+	}{
+		{"nil pointer", nilOrder},
+		{"not a struct", 5},
+		{"struct value", valid()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Validator.ValidateStruct(tt.obj); err != nil {
+				t.Errorf("ValidateStruct: %v, want no error", err)
+			}
+		})
+	}
+}
+
+func TestValidateStructPanics(t *testing.T) {
+	tests := []struct {
+		name string
+		obj  any
+	}{
+		{"unknown rule", &struct {
+			Name string `binding:"requried"`
+		}{}},
+		{"parameter not a number", &struct {
+			Name string `binding:"max=ten"`
+		}{Name: "Ann"}},
+		{"rule on a bool", &struct {
+			Open bool `binding:"min=1"`
+		}{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("ValidateStruct did not panic")
+				}
+			}()
+			_ = Validator.ValidateStruct(tt.obj)
+		})
+	}
+}
@@ -1,16 +1,28 @@
 package god
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
+	"math"
+	"mime"
+	"net"
 	"net/http"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"sync"
+	"time"
 
 	"god/binding"
 )
 
+// abortIndex is the handler index of an aborted chain.
+const abortIndex = math.MaxInt >> 1
+
 type Context struct {
 	Request *http.Request
-	Writer  http.ResponseWriter
+	Writer  ResponseWriter
 
 	Params   map[string]string
 	handlers HandlersChain
@@ -22,6 +34,9 @@ type Context struct {
 	// This mutex protects Keys map.
 	mu sync.RWMutex
 
+	// Errors are the errors collected with Error by the handlers of the request.
+	Errors Errors
+
 	fullPath string
 }
 
@@ -33,14 +48,15 @@ type (
 func NewContext(w http.ResponseWriter, r *http.Request) *Context {
 	return &Context{
 		Request: r,
-		Writer:  w,
+		Writer:  newResponseWriter(w),
 		Params:  make(map[string]string),
 		index:   -1,
 		Keys:    make(map[string]any),
 	}
 }
 
-// Next calls the next handler in the chain.
+// Next calls the next handler in the chain. A middleware calls it to run the
+// handlers after it and continue once they return.
 func (c *Context) Next() {
 	c.index++
 	for c.index < len(c.handlers) {
@@ -49,6 +65,39 @@ func (c *Context) Next() {
 	}
 }
 
+// Abort stops the chain: the handlers after the current one are not called.
+// It does not stop the current handler.
+func (c *Context) Abort() {
+	c.index = abortIndex
+}
+
+// IsAborted reports whether the chain was aborted.
+func (c *Context) IsAborted() bool {
+	return c.index >= abortIndex
+}
+
+// AbortWithStatus aborts the chain and sends the headers with the status code.
+func (c *Context) AbortWithStatus(code int) {
+	c.Status(code)
+	c.Writer.WriteHeaderNow()
+	c.Abort()
+}
+
+// AbortWithStatusJSON aborts the chain and sends a JSON response.
+func (c *Context) AbortWithStatusJSON(code int, obj any) {
+	c.Abort()
+	c.JSON(code, obj)
+}
+
+// Error adds the error to the errors of the request, for a middleware that reports
+// them after the chain. It returns err.
+func (c *Context) Error(err error) error {
+	if err != nil {
+		c.Errors = append(c.Errors, err)
+	}
+	return err
+}
+
 // JSON sends a JSON response.
 func (c *Context) JSON(code int, obj any) {
 	json := &JSON{Data: obj}
@@ -58,11 +107,150 @@ func (c *Context) JSON(code int, obj any) {
 	}
 }
 
+// Problem sends an RFC 7807 problem response with the status of the problem.
+// The instance of the problem is the request path unless it is set.
+func (c *Context) Problem(p Problem) {
+	if p.Instance == "" {
+		p.Instance = c.Request.URL.Path
+	}
+
+	err := p.Render(p.Status, c.Writer)
+	if err != nil {
+		fmt.Println("Error of rendering problem response:", err)
+	}
+}
+
+// Data sends raw bytes with the given content type.
+func (c *Context) Data(code int, contentType string, data []byte) {
+	r := &Data{ContentType: contentType, Data: data}
+	err := r.Render(code, c.Writer)
+	if err != nil {
+		fmt.Println("Error of rendering data response:", err)
+	}
+}
+
+// DataStream sends a response with the given content type whose body is produced by
+// write, piece by piece. The write deadline of the server is lifted for the connection,
+// so long downloads are not cut off. The status and headers are sent before write is
+// called, so an error returned by write can only be logged by the caller.
+func (c *Context) DataStream(code int, contentType string, write func(w io.Writer) error) error {
+	_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+
+	writeContentType(contentType, c.Writer)
+	c.Writer.WriteHeader(code)
+	c.Writer.WriteHeaderNow()
+	return write(c.Writer)
+}
+
+// SSEvent writes a server-sent event to the response and flushes it to the client.
+func (c *Context) SSEvent(event SSEvent) {
+	err := event.Render(c.Writer)
+	if err != nil {
+		fmt.Println("Error of rendering server-sent event:", err)
+		return
+	}
+	c.Flush()
+}
+
+// Stream calls step until it returns false or the client disconnects, flushing
+// the response after every call. The write deadline of the server is lifted for
+// the connection, so the stream may outlive it. Stream reports whether the
+// client disconnected.
+func (c *Context) Stream(step func(w io.Writer) bool) bool {
+	_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+
+	done := c.Request.Context().Done()
+	for {
+		select {
+		case <-done:
+			return true
+		default:
+			keepOpen := step(c.Writer)
+			c.Flush()
+			if !keepOpen {
+				return c.Request.Context().Err() != nil
+			}
+		}
+	}
+}
+
+// Flush sends the buffered response data to the client.
+func (c *Context) Flush() {
+	c.Writer.Flush()
+}
+
+// Status sets the status code of the response. The headers are sent with the
+// body, or after the chain if there is no body.
 func (c *Context) Status(code int) {
 	c.Writer.WriteHeader(code)
 }
 
-// Get is used to store a new key/value pair for this context.
+// Header sets a header of the response, or deletes it if value is empty.
+func (c *Context) Header(key, value string) {
+	if value == "" {
+		c.Writer.Header().Del(key)
+		return
+	}
+	c.Writer.Header().Set(key, value)
+}
+
+// GetHeader returns a header of the request.
+func (c *Context) GetHeader(key string) string {
+	return c.Request.Header.Get(key)
+}
+
+// ClientIP returns the IP address the request came from. Forwarding headers are not
+// trusted, so behind a proxy it is the address of the proxy.
+func (c *Context) ClientIP() string {
+	host, _, err := net.SplitHostPort(strings.TrimSpace(c.Request.RemoteAddr))
+	if err != nil {
+		return c.Request.RemoteAddr
+	}
+	return host
+}
+
+// String sends a plain text response, formatted with fmt.Sprintf if values are given.
+func (c *Context) String(code int, format string, values ...any) {
+	r := &String{Format: format, Data: values}
+	err := r.Render(code, c.Writer)
+	if err != nil {
+		fmt.Println("Error of rendering text response:", err)
+	}
+}
+
+// XML sends an XML response.
+func (c *Context) XML(code int, obj any) {
+	r := &XML{Data: obj}
+	err := r.Render(code, c.Writer)
+	if err != nil {
+		fmt.Println("Error of rendering XML response:", err)
+	}
+}
+
+// Redirect redirects the client to location with a 3xx status code, or 201 Created.
+func (c *Context) Redirect(code int, location string) {
+	r := &Redirect{Code: code, Request: c.Request, Location: location}
+	err := r.Render(c.Writer)
+	if err != nil {
+		fmt.Println("Error of rendering redirect response:", err)
+	}
+}
+
+// File sends the file. Range and conditional requests are served by http.ServeFile.
+func (c *Context) File(path string) {
+	http.ServeFile(c.Writer, c.Request, path)
+}
+
+// FileAttachment sends the file as a download saved under filename.
+func (c *Context) FileAttachment(path, filename string) {
+	if filename == "" {
+		filename = filepath.Base(path)
+	}
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", strings.ReplaceAll(filename, `"`, "")))
+	http.ServeFile(c.Writer, c.Request, path)
+}
+
+// Set is used to store a new key/value pair for this context.
 func (c *Context) Set(key string, value any) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -78,7 +266,7 @@ func (c *Context) Set(key string, value any) {
 // If the value does not exist it returns (nil, false)
 func (c *Context) Get(key string) (value any, exists bool) {
 	c.mu.RLock()
-	defer c.mu.Unlock()
+	defer c.mu.RUnlock()
 
 	value, exists = c.Keys[key]
 
@@ -90,16 +278,96 @@ func (c *Context) FullPath() (fullPath string) {
 	return c.fullPath
 }
 
-// ShouldBindWith binds a struct to the custom binder.
+// ShouldBindWith binds a struct to the custom binder. Bodies read by body bindings,
+// such as JSON, are limited to binding.MaxBodySize bytes.
 func (c *Context) ShouldBindWith(obj any, b binding.Binding) error {
+	if _, ok := b.(binding.BindingBody); ok && binding.MaxBodySize > 0 && c.Request.Body != nil {
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, binding.MaxBodySize)
+	}
 	return b.Bind(c.Request, obj)
 }
 
-// ShouldBindJSON is a shortcut for ShouldBindWith for JSON binding.
+// ShouldBindJSON is a shortcut for ShouldBindWith for JSON binding. The decoded
+// struct is validated by binding.Validator; broken rules are returned as binding.ValidationErrors.
 func (c *Context) ShouldBindJSON(obj any) error {
 	return c.ShouldBindWith(obj, binding.JSON)
 }
 
+// ShouldBindQuery binds the URL query parameters to the struct using the "form" tags.
+func (c *Context) ShouldBindQuery(obj any) error {
+	return c.ShouldBindWith(obj, binding.Query)
+}
+
+// ShouldBindUri binds the path parameters to the struct using the "uri" tags.
+func (c *Context) ShouldBindUri(obj any) error {
+	params := make(map[string][]string, len(c.Params))
+	for key, value := range c.Params {
+		params[key] = []string{value}
+	}
+	return binding.Uri.BindUri(params, obj)
+}
+
+// ShouldBindForm binds the URL query and the form fields to the struct using the
+// "form" tags. Files of a multipart form are bound to *multipart.FileHeader fields.
+func (c *Context) ShouldBindForm(obj any) error {
+	return c.ShouldBindWith(obj, binding.Form)
+}
+
+// ShouldBindPatch applies the patch in the request body to obj, a pointer to a struct
+// holding the current state of the resource, and validates the result like
+// ShouldBindJSON. The Content-Type header tells a JSON Merge Patch from a JSON Patch;
+// other types fail with binding.ErrPatchMediaType. A malformed patch fails with
+// binding.ErrPatchInvalid and one that does not apply with binding.ErrPatchFailed.
+func (c *Context) ShouldBindPatch(obj any) error {
+	mediaType, _, _ := mime.ParseMediaType(c.GetHeader("Content-Type"))
+	apply := binding.MergePatch
+	switch mediaType {
+	case binding.MIMEMergePatchJSON:
+	case binding.MIMEJSONPatch:
+		apply = binding.JSONPatch
+	default:
+		return binding.ErrPatchMediaType
+	}
+
+	if c.Request.Body == nil {
+		return fmt.Errorf("%w: empty body", binding.ErrPatchInvalid)
+	}
+	if binding.MaxBodySize > 0 {
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, binding.MaxBodySize)
+	}
+	patch, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return err
+	}
+
+	current, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	patched, err := apply(current, patch)
+	if err != nil {
+		return err
+	}
+
+	// Members removed by the patch must not keep their current values.
+	reflect.ValueOf(obj).Elem().SetZero()
+	return binding.JSON.BindBody(patched, obj)
+}
+
+// PathValue returns the value of the named path parameter.
 func (c *Context) PathValue(key string) string {
 	return c.Params[key]
 }
+
+// Query returns the first value of the URL query parameter, or "" if it is absent.
+func (c *Context) Query(key string) string {
+	return c.Request.URL.Query().Get(key)
+}
+
+// DefaultQuery returns the URL query parameter, or defaultValue if it is absent or empty.
+func (c *Context) DefaultQuery(key, defaultValue string) string {
+	if value := c.Query(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
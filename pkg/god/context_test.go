@@ -0,0 +1,204 @@
+package god
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestAbort(t *testing.T) {
+	tests := []struct {
+		name       string
+		abort      func(c *Context)
+		wantCalls  []string
+		wantStatus int
+	}{
+		{
+			name:       "not aborted",
+			abort:      func(c *Context) {},
+			wantCalls:  []string{"outer", "inner", "handler", "outer after: false"},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "abort",
+			abort:      func(c *Context) { c.Abort() },
+			wantCalls:  []string{"outer", "inner", "inner after abort", "outer after: true"},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "abort with status",
+			abort:      func(c *Context) { c.AbortWithStatus(http.StatusForbidden) },
+			wantCalls:  []string{"outer", "inner", "inner after abort", "outer after: true"},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name: "abort with JSON",
+			abort: func(c *Context) {
+				c.AbortWithStatusJSON(http.StatusTooManyRequests, map[string]string{"error": "slow down"})
+			},
+			wantCalls:  []string{"outer", "inner", "inner after abort", "outer after: true"},
+			wantStatus: http.StatusTooManyRequests,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls []string
+
+			r := Default()
+			r.Use(func(c *Context) {
+				calls = append(calls, "outer")
+				c.Next()
+				calls = append(calls, "outer after: "+strconv.FormatBool(c.IsAborted()))
+			})
+			r.Use(func(c *Context) {
+				calls = append(calls, "inner")
+				tt.abort(c)
+				if c.IsAborted() {
+					calls = append(calls, "inner after abort")
+				}
+			})
+			r.GET("/orders", func(c *Context) {
+				calls = append(calls, "handler")
+				c.Status(http.StatusOK)
+			})
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/orders", nil))
+
+			if !reflect.DeepEqual(calls, tt.wantCalls) {
+				t.Errorf("calls = %q, want %q", calls, tt.wantCalls)
+			}
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestRenderers(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "report.csv")
+	if err := os.WriteFile(path, []byte("id,total\n1,4.5\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	type item struct {
+		ID   int    `xml:"id,attr"`
+		Name string `xml:"name"`
+	}
+
+	tests := []struct {
+		name            string
+		render          func(c *Context)
+		wantStatus      int
+		wantContentType string
+		wantBody        string
+		wantHeader      map[string]string
+	}{
+		{
+			name:            "string",
+			render:          func(c *Context) { c.String(http.StatusOK, "100% latte") },
+			wantStatus:      http.StatusOK,
+			wantContentType: "text/plain; charset=utf-8",
+			wantBody:        "100% latte",
+		},
+		{
+			name:            "formatted string",
+			render:          func(c *Context) { c.String(http.StatusAccepted, "%d x %s", 2, "latte") },
+			wantStatus:      http.StatusAccepted,
+			wantContentType: "text/plain; charset=utf-8",
+			wantBody:        "2 x latte",
+		},
+		{
+			name:            "XML",
+			render:          func(c *Context) { c.XML(http.StatusOK, item{ID: 1, Name: "Latte"}) },
+			wantStatus:      http.StatusOK,
+			wantContentType: "application/xml; charset=utf-8",
+			wantBody:        `<item id="1"><name>Latte</name></item>`,
+		},
+		{
+			name:       "XML that cannot be encoded",
+			render:     func(c *Context) { c.XML(http.StatusOK, map[string]int{"a": 1}) },
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:            "data",
+			render:          func(c *Context) { c.Data(http.StatusCreated, "image/png", []byte{0x89, 'P', 'N', 'G'}) },
+			wantStatus:      http.StatusCreated,
+			wantContentType: "image/png",
+			wantBody:        "\x89PNG",
+		},
+		{
+			name:       "redirect",
+			render:     func(c *Context) { c.Redirect(http.StatusSeeOther, "/orders/7") },
+			wantStatus: http.StatusSeeOther,
+			wantHeader: map[string]string{"Location": "/orders/7"},
+		},
+		{
+			name:       "redirect with 201 Created",
+			render:     func(c *Context) { c.Redirect(http.StatusCreated, "/orders/7") },
+			wantStatus: http.StatusCreated,
+			wantHeader: map[string]string{"Location": "/orders/7"},
+		},
+		{
+			name:       "redirect with a status that is not a redirect",
+			render:     func(c *Context) { c.Redirect(http.StatusOK, "/orders/7") },
+			wantStatus: http.StatusOK,
+			wantHeader: map[string]string{"Location": ""},
+		},
+		{
+			name:            "file attachment",
+			render:          func(c *Context) { c.FileAttachment(path, `sales "march".csv`) },
+			wantStatus:      http.StatusOK,
+			wantContentType: "text/csv; charset=utf-8",
+			wantBody:        "id,total\n1,4.5\n",
+			wantHeader:      map[string]string{"Content-Disposition": `attachment; filename="sales march.csv"`},
+		},
+		{
+			name:            "file attachment named after the file",
+			render:          func(c *Context) { c.FileAttachment(path, "") },
+			wantStatus:      http.StatusOK,
+			wantContentType: "text/csv; charset=utf-8",
+			wantBody:        "id,total\n1,4.5\n",
+			wantHeader:      map[string]string{"Content-Disposition": `attachment; filename="report.csv"`},
+		},
+		{
+			name:            "missing file attachment",
+			render:          func(c *Context) { c.FileAttachment(filepath.Join(dir, "missing.csv"), "") },
+			wantStatus:      http.StatusNotFound,
+			wantContentType: "text/plain; charset=utf-8",
+			wantBody:        "404 page not found\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// A POST, so the redirects are sent without the HTML body of a GET
+			r := Default()
+			r.POST("/export", tt.render)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/export", nil))
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if got := w.Header().Get("Content-Type"); got != tt.wantContentType {
+				t.Errorf("Content-Type = %q, want %q", got, tt.wantContentType)
+			}
+			if got := w.Body.String(); got != tt.wantBody {
+				t.Errorf("body = %q, want %q", got, tt.wantBody)
+			}
+			for key, want := range tt.wantHeader {
+				if got := w.Header().Get(key); got != want {
+					t.Errorf("%s = %q, want %q", key, got, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,21 @@
+package god
+
+import "net/http"
+
+// Data renders raw bytes with the given content type.
+type Data struct {
+	ContentType string
+	Data        []byte
+}
+
+func (r *Data) Render(code int, w http.ResponseWriter) error {
+	r.WriteContentType(w)
+
+	w.WriteHeader(code)
+	_, err := w.Write(r.Data)
+	return err
+}
+
+func (r *Data) WriteContentType(w http.ResponseWriter) {
+	writeContentType(r.ContentType, w)
+}
@@ -0,0 +1,22 @@
+package god
+
+import "strings"
+
+// Errors are the errors collected with Context.Error while handling a request.
+type Errors []error
+
+// Last returns the last error, or nil if there are none.
+func (e Errors) Last() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e[len(e)-1]
+}
+
+func (e Errors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, err := range e {
+		messages = append(messages, err.Error())
+	}
+	return strings.Join(messages, "; ")
+}
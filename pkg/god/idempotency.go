@@ -0,0 +1,229 @@
+package god
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+
+	"god/binding"
+)
+
+const (
+	// IdempotencyKeyHeader is the request header that makes a request idempotent.
+	IdempotencyKeyHeader = "Idempotency-Key"
+
+	// IdempotentReplayedHeader is set on a response replayed from the store.
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	// MaxIdempotencyKeyLength is the maximal length of an idempotency key.
+	MaxIdempotencyKeyLength = 255
+)
+
+// Errors passed by Idempotency to IdempotencyConfig.Reject.
+var (
+	ErrIdempotencyKeyInvalid = errors.New("god: idempotency key is too long")
+	ErrIdempotencyKeyReused  = errors.New("god: idempotency key was used for another request")
+	ErrIdempotencyInProgress = errors.New("god: request with the idempotency key is in progress")
+)
+
+// IdempotentResponse is a request stored under an idempotency key and, once it was
+// handled, its response.
+type IdempotentResponse struct {
+	// RequestHash identifies the method, the path and the body of the request.
+	RequestHash string
+	// Completed is set once the response is stored.
+	Completed bool
+
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+// IdempotencyStore keeps the requests made with idempotency keys and their responses.
+type IdempotencyStore interface {
+	// Begin claims the key for the request with the hash and returns nil. If the key is
+	// claimed already, it returns the request stored under it instead.
+	Begin(ctx context.Context, key, requestHash string) (*IdempotentResponse, error)
+	// Complete stores the response of the request that claimed the key.
+	Complete(ctx context.Context, key string, response IdempotentResponse) error
+	// Release frees the key of a request that failed, so that it can be retried.
+	Release(ctx context.Context, key string) error
+}
+
+// IdempotencyConfig configures the Idempotency middleware.
+type IdempotencyConfig struct {
+	Store IdempotencyStore
+
+	// Scope returns the namespace of the keys of the request, e.g. its principal, so
+	// that clients cannot replay the responses of each other. By default keys are global.
+	Scope func(c *Context) string
+
+	// Reject responds to a request that cannot be handled: one with an invalid key,
+	// a reused key or a key whose first request is in progress, and one whose key
+	// could not be claimed. By default it responds with a problem of status 400,
+	// 422, 409 or 500.
+	Reject func(c *Context, err error)
+}
+
+// Idempotency returns a middleware that makes retries of requests with an
+// Idempotency-Key header safe. The first request with a key is handled and its
+// response is stored; a retry with the same key and the same request gets the stored
+// response without being handled again. A retry with another method, path or body is
+// rejected with ErrIdempotencyKeyReused, and a retry that arrives while the first
+// request is being handled with ErrIdempotencyInProgress. Responses with a server
+// error are not stored, so such requests can be retried. Requests without the header
+// are handled as usual.
+func Idempotency(config IdempotencyConfig) HandlerFunc {
+	reject := config.Reject
+	if reject == nil {
+		reject = rejectIdempotent
+	}
+
+	return func(c *Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > MaxIdempotencyKeyLength {
+			c.Abort()
+			reject(c, ErrIdempotencyKeyInvalid)
+			return
+		}
+		if config.Scope != nil {
+			key = config.Scope(c) + ":" + key
+		}
+
+		hash, err := requestHash(c)
+		if err != nil {
+			c.Abort()
+			reject(c, err)
+			return
+		}
+
+		ctx := c.Request.Context()
+		stored, err := config.Store.Begin(ctx, key, hash)
+		switch {
+		case err != nil:
+			c.Abort()
+			reject(c, err)
+			return
+		case stored == nil:
+		case stored.RequestHash != hash:
+			c.Abort()
+			reject(c, ErrIdempotencyKeyReused)
+			return
+		case !stored.Completed:
+			c.Abort()
+			c.Header("Retry-After", "1")
+			reject(c, ErrIdempotencyInProgress)
+			return
+		default:
+			c.Abort()
+			replay(c, *stored)
+			return
+		}
+
+		completed := false
+		defer func() {
+			// The key is freed when the handlers fail, panic included.
+			if !completed {
+				_ = config.Store.Release(context.WithoutCancel(ctx), key)
+			}
+		}()
+
+		recorder := &recordingWriter{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+		c.Writer = recorder.ResponseWriter
+
+		if c.Writer.Status() >= http.StatusInternalServerError {
+			return
+		}
+		err = config.Store.Complete(context.WithoutCancel(ctx), key, IdempotentResponse{
+			RequestHash: hash,
+			Completed:   true,
+			Status:      c.Writer.Status(),
+			Header:      c.Writer.Header().Clone(),
+			Body:        recorder.body.Bytes(),
+		})
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		completed = true
+	}
+}
+
+// requestHash returns the hash of the method, the path and the body of the request.
+// The body is read, limited to binding.MaxBodySize, and put back for the handlers.
+func requestHash(c *Context) (string, error) {
+	var body []byte
+	if c.Request.Body != nil {
+		reader := io.Reader(c.Request.Body)
+		if binding.MaxBodySize > 0 {
+			reader = http.MaxBytesReader(c.Writer, c.Request.Body, binding.MaxBodySize)
+		}
+
+		var err error
+		body, err = io.ReadAll(reader)
+		if err != nil {
+			return "", err
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	h := sha256.New()
+	h.Write([]byte(c.Request.Method + " " + c.Request.URL.RequestURI() + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// replay sends the stored response. Headers already set on the response, such as a
+// request ID, are kept.
+func replay(c *Context, stored IdempotentResponse) {
+	header := c.Writer.Header()
+	for name, values := range stored.Header {
+		if _, ok := header[name]; !ok {
+			header[name] = values
+		}
+	}
+	header.Set(IdempotentReplayedHeader, "true")
+
+	c.Writer.WriteHeader(stored.Status)
+	if len(stored.Body) > 0 {
+		_, _ = c.Writer.Write(stored.Body)
+	}
+}
+
+func rejectIdempotent(c *Context, err error) {
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.Is(err, ErrIdempotencyKeyInvalid):
+		c.Problem(Problem{Status: http.StatusBadRequest, Detail: err.Error()})
+	case errors.Is(err, ErrIdempotencyKeyReused):
+		c.Problem(Problem{Status: http.StatusUnprocessableEntity, Detail: err.Error()})
+	case errors.Is(err, ErrIdempotencyInProgress):
+		c.Problem(Problem{Status: http.StatusConflict, Detail: err.Error()})
+	case errors.As(err, &tooLarge):
+		c.Problem(Problem{Status: http.StatusRequestEntityTooLarge})
+	default:
+		c.Problem(Problem{Status: http.StatusInternalServerError})
+	}
+}
+
+// recordingWriter keeps a copy of the body written through it.
+type recordingWriter struct {
+	ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *recordingWriter) Write(data []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(data)
+	w.body.Write(data[:n])
+	return n, err
+}
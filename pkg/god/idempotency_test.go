@@ -0,0 +1,224 @@
+package god
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// memoryStore is an IdempotencyStore that keeps the responses in a map.
+type memoryStore struct {
+	mu        sync.Mutex
+	responses map[string]IdempotentResponse
+	released  []string
+	err       error
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{responses: make(map[string]IdempotentResponse)}
+}
+
+func (s *memoryStore) Begin(ctx context.Context, key, requestHash string) (*IdempotentResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err != nil {
+		return nil, s.err
+	}
+	if stored, ok := s.responses[key]; ok {
+		return &stored, nil
+	}
+	s.responses[key] = IdempotentResponse{RequestHash: requestHash}
+	return nil, nil
+}
+
+func (s *memoryStore) Complete(ctx context.Context, key string, response IdempotentResponse) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.responses[key] = response
+	return nil
+}
+
+func (s *memoryStore) Release(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.responses, key)
+	s.released = append(s.released, key)
+	return nil
+}
+
+// idempotentRouter serves POST /orders through the Idempotency middleware with the store.
+func idempotentRouter(store IdempotencyStore, handler HandlerFunc) *Router {
+	r := Default()
+	r.Use(Idempotency(IdempotencyConfig{
+		Store: store,
+		Scope: func(c *Context) string { return c.GetHeader("X-User") },
+	}))
+	r.POST("/orders", handler)
+	return r
+}
+
+func post(r http.Handler, key, user, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(body))
+	if key != "" {
+		req.Header.Set(IdempotencyKeyHeader, key)
+	}
+	req.Header.Set("X-User", user)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotencyReplay(t *testing.T) {
+	calls := 0
+	r := idempotentRouter(newMemoryStore(), func(c *Context) {
+		calls++
+		c.Header("Location", "/orders/1")
+		c.JSON(http.StatusCreated, map[string]int{"order": calls})
+	})
+
+	first := post(r, "key-1", "ann", `{"item":1}`)
+	second := post(r, "key-1", "ann", `{"item":1}`)
+
+	if calls != 1 {
+		t.Fatalf("handler called %d times, want 1", calls)
+	}
+	if second.Code != http.StatusCreated || second.Body.String() != first.Body.String() {
+		t.Errorf("replay = %d %q, want %d %q", second.Code, second.Body, first.Code, first.Body)
+	}
+	if second.Header().Get("Location") != "/orders/1" || second.Header().Get(IdempotentReplayedHeader) != "true" {
+		t.Errorf("replay headers = %v", second.Header())
+	}
+	if first.Header().Get(IdempotentReplayedHeader) != "" {
+		t.Errorf("the first response is marked as replayed")
+	}
+
+	// Keys are scoped, so another user with the same key is handled
+	if w := post(r, "key-1", "bob", `{"item":1}`); w.Code != http.StatusCreated || calls != 2 {
+		t.Errorf("request of another scope = %d after %d calls, want 201 after 2", w.Code, calls)
+	}
+
+	// Requests without a key are always handled
+	post(r, "", "ann", `{"item":1}`)
+	post(r, "", "ann", `{"item":1}`)
+	if calls != 4 {
+		t.Errorf("handler called %d times, want 4", calls)
+	}
+}
+
+func TestIdempotencyRejects(t *testing.T) {
+	calls := 0
+	store := newMemoryStore()
+	r := idempotentRouter(store, func(c *Context) {
+		calls++
+		c.Status(http.StatusCreated)
+	})
+
+	post(r, "key-1", "ann", `{"item":1}`)
+
+	tests := []struct {
+		name string
+		key  string
+		body string
+		err  error
+		want int
+	}{
+		{"body differs", "key-1", `{"item":2}`, nil, http.StatusUnprocessableEntity},
+		{"key too long", strings.Repeat("k", MaxIdempotencyKeyLength+1), `{"item":1}`, nil, http.StatusBadRequest},
+		{"store fails", "key-2", `{"item":1}`, errors.New("store is down"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store.err = tt.err
+			defer func() { store.err = nil }()
+
+			if w := post(r, tt.key, "ann", tt.body); w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+			if calls != 1 {
+				t.Errorf("handler called %d times, want 1", calls)
+			}
+		})
+	}
+}
+
+func TestIdempotencyInProgress(t *testing.T) {
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	r := idempotentRouter(newMemoryStore(), func(c *Context) {
+		close(entered)
+		<-release
+		c.Status(http.StatusCreated)
+	})
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() {
+		done <- post(r, "key-1", "ann", `{"item":1}`)
+	}()
+	<-entered
+
+	w := post(r, "key-1", "ann", `{"item":1}`)
+	if w.Code != http.StatusConflict || w.Header().Get("Retry-After") == "" {
+		t.Errorf("retry in progress = %d, Retry-After %q, want 409 with Retry-After", w.Code, w.Header().Get("Retry-After"))
+	}
+
+	close(release)
+	if first := <-done; first.Code != http.StatusCreated {
+		t.Errorf("first request = %d, want 201", first.Code)
+	}
+
+	if w := post(r, "key-1", "ann", `{"item":1}`); w.Code != http.StatusCreated || w.Header().Get(IdempotentReplayedHeader) != "true" {
+		t.Errorf("retry after completion = %d, want a replayed 201", w.Code)
+	}
+}
+
+func TestIdempotencyReleaseOnFailure(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler HandlerFunc
+	}{
+		{"server error", func(c *Context) { c.Status(http.StatusInternalServerError) }},
+		{"panic", func(c *Context) { panic("handler failed") }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newMemoryStore()
+			failing := true
+			calls := 0
+			r := idempotentRouter(store, func(c *Context) {
+				calls++
+				if failing {
+					tt.handler(c)
+					return
+				}
+				c.Status(http.StatusCreated)
+			})
+
+			func() {
+				defer func() { _ = recover() }()
+				post(r, "key-1", "ann", `{"item":1}`)
+			}()
+			if len(store.released) != 1 || store.released[0] != "ann:key-1" {
+				t.Fatalf("released keys = %v, want [ann:key-1]", store.released)
+			}
+
+			// The key is free again, so the retry is handled
+			failing = false
+			if w := post(r, "key-1", "ann", `{"item":1}`); w.Code != http.StatusCreated || calls != 2 {
+				t.Errorf("retry = %d after %d calls, want 201 after 2", w.Code, calls)
+			}
+			if len(store.released) != 1 {
+				t.Errorf("a completed request released its key")
+			}
+		})
+	}
+}
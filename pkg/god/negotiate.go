@@ -0,0 +1,110 @@
+package god
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"god/binding"
+)
+
+// formatNames maps the values of the "format" query parameter to MIME types.
+var formatNames = map[string]string{
+	"json": binding.MIMEJSON,
+	"xml":  binding.MIMEXML,
+	"html": binding.MIMEHTML,
+	"text": binding.MIMEPlain,
+	"yaml": binding.MIMEYAML,
+	"toml": binding.MIMETOML,
+	"csv":  binding.MIMECSV,
+	"xlsx": binding.MIMEXLSX,
+}
+
+// acceptRange is a media range of the Accept header with its quality.
+type acceptRange struct {
+	mime    string
+	quality float64
+}
+
+// NegotiateFormat returns the offered MIME type that suits the client best.
+// The "format" query parameter (json, csv, xlsx, ...) takes precedence over the
+// Accept header. Without either the first offered type is returned. An empty
+// string means that none of the offered types is acceptable.
+func (c *Context) NegotiateFormat(offered ...string) string {
+	if len(offered) == 0 {
+		return ""
+	}
+
+	if format := c.Query("format"); format != "" {
+		mime := formatNames[strings.ToLower(format)]
+		for _, o := range offered {
+			if o == mime {
+				return o
+			}
+		}
+		return ""
+	}
+
+	accepted := parseAccept(c.Request.Header.Get("Accept"))
+	if len(accepted) == 0 {
+		return offered[0]
+	}
+
+	for _, a := range accepted {
+		for _, o := range offered {
+			if matchMIME(a.mime, o) {
+				return o
+			}
+		}
+	}
+	return ""
+}
+
+// parseAccept parses the Accept header into the acceptable media ranges,
+// ordered by quality and then by specificity.
+func parseAccept(header string) []acceptRange {
+	var ranges []acceptRange
+	for _, part := range strings.Split(header, ",") {
+		params := strings.Split(part, ";")
+		mime := strings.ToLower(strings.TrimSpace(params[0]))
+		if mime == "" {
+			continue
+		}
+
+		quality := 1.0
+		for _, p := range params[1:] {
+			key, value, _ := strings.Cut(strings.TrimSpace(p), "=")
+			if strings.TrimSpace(key) != "q" {
+				continue
+			}
+			q, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err == nil {
+				quality = q
+			}
+		}
+		if quality <= 0 {
+			continue
+		}
+
+		ranges = append(ranges, acceptRange{mime: mime, quality: quality})
+	}
+
+	sort.SliceStable(ranges, func(i, j int) bool {
+		if ranges[i].quality != ranges[j].quality {
+			return ranges[i].quality > ranges[j].quality
+		}
+		return strings.Count(ranges[i].mime, "*") < strings.Count(ranges[j].mime, "*")
+	})
+	return ranges
+}
+
+// matchMIME reports whether the MIME type falls within the media range,
+// which may be */* or type/*.
+func matchMIME(mediaRange, mime string) bool {
+	if mediaRange == "*/*" || mediaRange == mime {
+		return true
+	}
+
+	prefix, ok := strings.CutSuffix(mediaRange, "/*")
+	return ok && strings.HasPrefix(mime, prefix+"/")
+}
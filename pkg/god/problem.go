@@ -0,0 +1,68 @@
+package god
+
+import (
+	"encoding/json"
+	"maps"
+	"net/http"
+)
+
+const (
+	problemContentType = "application/problem+json"
+)
+
+// Problem is an error response in the format of RFC 7807.
+type Problem struct {
+	// Type is a URI that identifies the problem type. It is "about:blank" if empty,
+	// and then Title should be the status text.
+	Type string
+	// Title is a short summary of the problem type that does not change between occurrences.
+	Title string
+	// Status is the HTTP status code of the response.
+	Status int
+	// Detail explains this occurrence of the problem.
+	Detail string
+	// Instance is a URI that identifies this occurrence. The request path is used if empty.
+	Instance string
+	// Extensions are additional members of the problem object, e.g. a machine-readable code.
+	Extensions H
+}
+
+// MarshalJSON writes the members of the problem object with the extensions beside them.
+func (p Problem) MarshalJSON() ([]byte, error) {
+	members := make(H, len(p.Extensions)+5)
+	maps.Copy(members, p.Extensions)
+
+	members["type"] = p.Type
+	if p.Type == "" {
+		members["type"] = "about:blank"
+	}
+	members["title"] = p.Title
+	if p.Title == "" {
+		members["title"] = http.StatusText(p.Status)
+	}
+	members["status"] = p.Status
+	if p.Detail != "" {
+		members["detail"] = p.Detail
+	}
+	if p.Instance != "" {
+		members["instance"] = p.Instance
+	}
+
+	return json.Marshal(members)
+}
+
+func (p *Problem) Render(code int, w http.ResponseWriter) error {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+
+	p.WriteContentType(w)
+	w.WriteHeader(code)
+	_, err = w.Write(data)
+	return err
+}
+
+func (p *Problem) WriteContentType(w http.ResponseWriter) {
+	writeContentType(problemContentType, w)
+}
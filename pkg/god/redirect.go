@@ -0,0 +1,21 @@
+package god
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// Redirect redirects the request to Location with a 3xx status code, or 201 Created.
+type Redirect struct {
+	Code     int
+	Request  *http.Request
+	Location string
+}
+
+func (r *Redirect) Render(w http.ResponseWriter) error {
+	if (r.Code < http.StatusMultipleChoices || r.Code > http.StatusPermanentRedirect) && r.Code != http.StatusCreated {
+		return fmt.Errorf("cannot redirect with status code %d", r.Code)
+	}
+	http.Redirect(w, r.Request, r.Location, r.Code)
+	return nil
+}
@@ -0,0 +1,77 @@
+package god
+
+import "net/http"
+
+const noWritten = -1
+
+// ResponseWriter is the http.ResponseWriter of a Context. The status code is kept
+// until the body is first written, so handlers and middlewares can change the
+// status and the headers until then.
+type ResponseWriter interface {
+	http.ResponseWriter
+	http.Flusher
+
+	// Status returns the status code of the response.
+	Status() int
+	// Size returns the number of bytes of the body written so far, or -1 if the
+	// headers were not sent yet.
+	Size() int
+	// Written reports whether the headers were sent.
+	Written() bool
+	// WriteHeaderNow sends the headers with the current status code.
+	WriteHeaderNow()
+}
+
+type responseWriter struct {
+	http.ResponseWriter
+	status int
+	size   int
+}
+
+func newResponseWriter(w http.ResponseWriter) *responseWriter {
+	return &responseWriter{ResponseWriter: w, status: http.StatusOK, size: noWritten}
+}
+
+// WriteHeader sets the status code. It has no effect once the headers were sent.
+func (w *responseWriter) WriteHeader(code int) {
+	if code > 0 && !w.Written() {
+		w.status = code
+	}
+}
+
+func (w *responseWriter) WriteHeaderNow() {
+	if !w.Written() {
+		w.size = 0
+		w.ResponseWriter.WriteHeader(w.status)
+	}
+}
+
+func (w *responseWriter) Write(data []byte) (int, error) {
+	w.WriteHeaderNow()
+	n, err := w.ResponseWriter.Write(data)
+	w.size += n
+	return n, err
+}
+
+func (w *responseWriter) Status() int {
+	return w.status
+}
+
+func (w *responseWriter) Size() int {
+	return w.size
+}
+
+func (w *responseWriter) Written() bool {
+	return w.size != noWritten
+}
+
+// Flush sends the headers and the buffered body to the client.
+func (w *responseWriter) Flush() {
+	w.WriteHeaderNow()
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Unwrap returns the underlying writer, for http.ResponseController.
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
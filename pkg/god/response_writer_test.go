@@ -0,0 +1,100 @@
+package god
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDeferredHeader(t *testing.T) {
+	tests := []struct {
+		name       string
+		handler    func(c *Context)
+		wantStatus int
+		wantBody   string
+		wantHeader string
+	}{
+		{
+			name:       "status without a body",
+			handler:    func(c *Context) { c.Status(http.StatusNoContent) },
+			wantStatus: http.StatusNoContent,
+			wantHeader: "set after the chain",
+		},
+		{
+			name: "last status before the body",
+			handler: func(c *Context) {
+				c.Status(http.StatusCreated)
+				c.Status(http.StatusAccepted)
+			},
+			wantStatus: http.StatusAccepted,
+			wantHeader: "set after the chain",
+		},
+		{
+			name:       "no status and no body",
+			handler:    func(c *Context) {},
+			wantStatus: http.StatusOK,
+			wantHeader: "set after the chain",
+		},
+		{
+			name: "status after the body",
+			handler: func(c *Context) {
+				c.String(http.StatusCreated, "latte")
+				c.Status(http.StatusInternalServerError)
+			},
+			wantStatus: http.StatusCreated,
+			wantBody:   "latte",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var written bool
+			var status, size int
+
+			r := Default()
+			r.Use(func(c *Context) {
+				c.Next()
+				c.Header("X-After", "set after the chain")
+				written, status, size = c.Writer.Written(), c.Writer.Status(), c.Writer.Size()
+			})
+			r.GET("/orders", tt.handler)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/orders", nil))
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if got := w.Body.String(); got != tt.wantBody {
+				t.Errorf("body = %q, want %q", got, tt.wantBody)
+			}
+			if got := w.Result().Header.Get("X-After"); got != tt.wantHeader {
+				t.Errorf("X-After = %q, want %q", got, tt.wantHeader)
+			}
+
+			// The middleware runs before the router sends the headers of a response without a body
+			wantWritten, wantSize := tt.wantBody != "", len(tt.wantBody)
+			if !wantWritten {
+				wantSize = -1
+			}
+			if written != wantWritten || status != tt.wantStatus || size != wantSize {
+				t.Errorf("after the chain Written, Status, Size = %v, %d, %d, want %v, %d, %d",
+					written, status, size, wantWritten, tt.wantStatus, wantSize)
+			}
+		})
+	}
+}
+
+func TestResponseWriterFlush(t *testing.T) {
+	w := httptest.NewRecorder()
+	rw := newResponseWriter(w)
+	rw.WriteHeader(http.StatusAccepted)
+	rw.Flush()
+
+	if !rw.Written() || rw.Size() != 0 {
+		t.Errorf("Written, Size = %v, %d, want true, 0", rw.Written(), rw.Size())
+	}
+	if w.Code != http.StatusAccepted || !w.Flushed {
+		t.Errorf("recorder status, flushed = %d, %v, want %d, true", w.Code, w.Flushed, http.StatusAccepted)
+	}
+}
@@ -15,10 +15,10 @@ import (
 		Read README.md for more information.
 */
 type Router struct {
-	mu     sync.RWMutex
-	routes map[string]map[string][]HandlerFunc
-	// middleware []HandlerFunc
-	log *slog.Logger
+	mu         sync.RWMutex
+	routes     map[string]map[string][]HandlerFunc
+	middleware HandlersChain
+	log        *slog.Logger
 }
 
 // Default creates a new default Router instance.
@@ -29,14 +29,21 @@ func Default() *Router {
 	}
 }
 
-// TODO: Use => adds middleware to the router.
+// Use adds middleware that runs before the handlers of every route, in the order
+// it was added. A middleware calls Next to run the rest of the chain or Abort to stop it.
+func (r *Router) Use(middleware ...HandlerFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.middleware = append(r.middleware, middleware...)
+}
 
 // Handle registers a new route with a method and path.
 func (r *Router) Handle(method, path string, handlers ...HandlerFunc) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	if _, ok := r.routes[method][path]; !ok {
+	if _, ok := r.routes[method]; !ok {
 		r.routes[method] = make(map[string][]HandlerFunc)
 	}
 
@@ -52,8 +59,7 @@ func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	if handlers, ok := r.routes[method][path]; ok {
 		c := NewContext(w, req)
 		c.fullPath = path
-		c.handlers = handlers
-		c.Next()
+		r.handle(c, handlers)
 		return
 	}
 
@@ -63,8 +69,7 @@ func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 			c := NewContext(w, req)
 			c.Params = params // Store the parsed parameters
 			c.fullPath = routePath
-			c.handlers = handlers
-			c.Next()
+			r.handle(c, handlers)
 			return
 		}
 	}
@@ -73,24 +78,39 @@ func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	http.NotFound(w, req)
 }
 
+// handle runs the middleware and the handlers of the route, and sends the headers
+// if none of them wrote a body.
+func (r *Router) handle(c *Context, handlers HandlersChain) {
+	c.handlers = make(HandlersChain, 0, len(r.middleware)+len(handlers))
+	c.handlers = append(c.handlers, r.middleware...)
+	c.handlers = append(c.handlers, handlers...)
+	c.Next()
+	c.Writer.WriteHeaderNow()
+}
+
 // GET registers a GET route.
-func (r *Router) GET(path string, handler HandlerFunc) {
-	r.Handle(http.MethodGet, path, handler)
+func (r *Router) GET(path string, handlers ...HandlerFunc) {
+	r.Handle(http.MethodGet, path, handlers...)
 }
 
 // POST registers a POST route.
-func (r *Router) POST(path string, handler HandlerFunc) {
-	r.Handle(http.MethodPost, path, handler)
+func (r *Router) POST(path string, handlers ...HandlerFunc) {
+	r.Handle(http.MethodPost, path, handlers...)
 }
 
 // PUT registers a PUT route.
-func (r *Router) PUT(path string, handler HandlerFunc) {
-	r.Handle(http.MethodPut, path, handler)
+func (r *Router) PUT(path string, handlers ...HandlerFunc) {
+	r.Handle(http.MethodPut, path, handlers...)
+}
+
+// PATCH registers a PATCH route.
+func (r *Router) PATCH(path string, handlers ...HandlerFunc) {
+	r.Handle(http.MethodPatch, path, handlers...)
 }
 
 // DELETE registers a DELETE route.
-func (r *Router) DELETE(path string, handler HandlerFunc) {
-	r.Handle(http.MethodDelete, path, handler)
+func (r *Router) DELETE(path string, handlers ...HandlerFunc) {
+	r.Handle(http.MethodDelete, path, handlers...)
 }
 
 // TODO: LoggerMiddleware
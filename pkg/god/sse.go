@@ -0,0 +1,79 @@
+package god
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const (
+	sseContentType = "text/event-stream"
+)
+
+// SSEvent is a server-sent event. Data is written as is if it is a string and
+// as JSON otherwise. An event with only a Comment is ignored by clients and can
+// be used as a heartbeat to keep the connection open.
+type SSEvent struct {
+	ID      string
+	Event   string
+	Retry   uint
+	Data    any
+	Comment string
+}
+
+func (r *SSEvent) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+	return r.Encode(w)
+}
+
+func (r *SSEvent) WriteContentType(w http.ResponseWriter) {
+	header := w.Header()
+	header.Set("Content-Type", sseContentType)
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+}
+
+// Encode writes the event in the text/event-stream format.
+func (r *SSEvent) Encode(w io.Writer) error {
+	var b strings.Builder
+
+	if r.Comment != "" {
+		writeSSELines(&b, ": ", r.Comment)
+	}
+	if r.ID != "" {
+		writeSSELines(&b, "id: ", r.ID)
+	}
+	if r.Event != "" {
+		writeSSELines(&b, "event: ", r.Event)
+	}
+	if r.Retry > 0 {
+		fmt.Fprintf(&b, "retry: %d\n", r.Retry)
+	}
+	if r.Data != nil {
+		data, ok := r.Data.(string)
+		if !ok {
+			encoded, err := json.Marshal(r.Data)
+			if err != nil {
+				return err
+			}
+			data = string(encoded)
+		}
+		writeSSELines(&b, "data: ", data)
+	}
+	b.WriteString("\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeSSELines writes every line of value as a separate field, since a field cannot span lines.
+func writeSSELines(b *strings.Builder, field, value string) {
+	value = strings.ReplaceAll(value, "\r\n", "\n")
+	for _, line := range strings.Split(value, "\n") {
+		b.WriteString(field)
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+}
@@ -0,0 +1,33 @@
+package god
+
+import (
+	"fmt"
+	"net/http"
+)
+
+const (
+	plainContentType = "text/plain; charset=utf-8"
+)
+
+// String renders plain text, formatted with fmt.Fprintf if Data is not empty.
+type String struct {
+	Format string
+	Data   []any
+}
+
+func (r *String) Render(code int, w http.ResponseWriter) error {
+	r.WriteContentType(w)
+
+	w.WriteHeader(code)
+	var err error
+	if len(r.Data) > 0 {
+		_, err = fmt.Fprintf(w, r.Format, r.Data...)
+	} else {
+		_, err = w.Write([]byte(r.Format))
+	}
+	return err
+}
+
+func (r *String) WriteContentType(w http.ResponseWriter) {
+	writeContentType(plainContentType, w)
+}
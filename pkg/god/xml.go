@@ -0,0 +1,31 @@
+package god
+
+import (
+	"encoding/xml"
+	"net/http"
+)
+
+const (
+	xmlContentType = "application/xml; charset=utf-8"
+)
+
+// XML renders Data encoded as XML.
+type XML struct {
+	Data any
+}
+
+func (r *XML) Render(code int, w http.ResponseWriter) error {
+	data, err := xml.Marshal(r.Data)
+	if err != nil {
+		return err
+	}
+
+	r.WriteContentType(w)
+	w.WriteHeader(code)
+	_, err = w.Write(data)
+	return err
+}
+
+func (r *XML) WriteContentType(w http.ResponseWriter) {
+	writeContentType(xmlContentType, w)
+}